@@ -2,39 +2,152 @@ package main
 
 import (
 	"context"
+	"errors"
+	"flag"
 	"fmt"
 	"log"
+	"log/slog"
 	"net/http"
 	"os"
 	"os/signal"
+	"strconv"
 	"syscall"
 	"time"
 
 	"github.com/olad5/sal-backend-service/internal/app/router"
+	"github.com/olad5/sal-backend-service/internal/config"
+	"github.com/olad5/sal-backend-service/internal/health"
+	"github.com/olad5/sal-backend-service/internal/lifecycle"
+	"github.com/olad5/sal-backend-service/internal/logging"
+	"google.golang.org/grpc"
 )
 
 func main() {
-	port := os.Getenv("PORT")
-	ctx := context.Background()
-	appRouter := router.NewHttpRouter(ctx)
-	server := &http.Server{Addr: ":" + port, Handler: appRouter}
-	go func() {
-		log.Printf("starting application server on  http://localhost:" + port + "\n")
-		if err := server.ListenAndServe(); err != http.ErrServerClosed {
-			fmt.Printf("HTTP server ListenAndServe: %v", err)
+	cfg, opts, err := config.Load(os.Args[1:], os.LookupEnv)
+	if errors.Is(err, flag.ErrHelp) {
+		config.PrintUsage(os.Stdout)
+		return
+	}
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(2)
+	}
+	if opts.PrintConfig {
+		if err := config.Print(os.Stdout, cfg); err != nil {
+			log.Fatal(err)
 		}
-	}()
-	signals := make(chan os.Signal, 1)
-	signal.Notify(signals, syscall.SIGINT, syscall.SIGTERM)
+		return
+	}
 
-	<-signals
+	// validated by config.Load
+	level, _ := logging.ParseLevel(cfg.Log.Level)
+	logger := logging.New(os.Stdout, level)
+	// routes the log package, and the few places without a logger of their
+	// own, through the same JSON handler
+	slog.SetDefault(logger.Logger)
 
-	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
-	defer cancel()
+	port := strconv.Itoa(cfg.Server.Port)
+	ctx := context.Background()
+	checker, err := health.NewChecker(cfg.Server.HealthCheckTimeout.Duration)
+	if err != nil {
+		log.Fatal(err)
+	}
+	manager, err := lifecycle.NewManager(logger.Logger)
+	if err != nil {
+		log.Fatal(err)
+	}
+	var grpcServer *lifecycle.GRPCServer
+	var grpcRegistrar grpc.ServiceRegistrar
+	var grpcErrs <-chan error
+	if cfg.GRPC.Port != 0 {
+		grpcServer = lifecycle.NewGRPCServer(":" + strconv.Itoa(cfg.GRPC.Port))
+		grpcRegistrar, grpcErrs = grpcServer.Server(), grpcServer.Err()
+	}
+	appRouter := router.NewHttpRouter(ctx, cfg, logger, checker, manager, grpcRegistrar)
+	tlsConfig, err := router.NewTLSConfig(cfg.TLS, logger.Logger, manager)
+	if err != nil {
+		log.Fatal(err)
+	}
+	// added last, so it is the first to stop and requests still being served
+	// can use everything else
+	server := lifecycle.NewHTTPServer(&http.Server{Addr: ":" + port, Handler: appRouter, TLSConfig: tlsConfig})
+	manager.Add("http_server", server, cfg.Server.ShutdownTimeout.Duration)
+	if grpcServer != nil {
+		manager.Add("grpc_server", grpcServer, cfg.Server.ShutdownTimeout.Duration)
+	}
 
-	if err := server.Shutdown(ctx); err != nil {
-		fmt.Printf("Server forced to shutdown: %v", err)
+	if err := manager.Start(ctx); err != nil {
+		logger.Error("failed to start", "error", err)
+		os.Exit(1)
+	}
+	scheme := "http"
+	if tlsConfig != nil {
+		scheme = "https"
+	}
+	logger.Info("starting application server", "addr", scheme+"://localhost:"+port)
+	if grpcServer != nil {
+		logger.Info("starting gRPC server", "addr", grpcServer.Addr().String())
 	}
+	checker.MarkReady()
 
-	fmt.Println("Server exited gracefully")
+	signals := make(chan os.Signal, 1)
+	signal.Notify(signals, syscall.SIGINT, syscall.SIGTERM, syscall.SIGHUP)
+	exitCode := 0
+wait:
+	for {
+		select {
+		case sig := <-signals:
+			if sig == syscall.SIGHUP {
+				reload(ctx, cfg, manager, logger)
+				continue
+			}
+			break wait
+		case err := <-server.Err():
+			logger.Error("HTTP server failed", "error", err)
+			exitCode = 1
+			break wait
+		case err := <-grpcErrs:
+			logger.Error("gRPC server failed", "error", err)
+			exitCode = 1
+			break wait
+		}
+	}
+
+	// fail readiness first, so load balancers stop routing here while the
+	// server still accepts requests
+	checker.MarkDraining()
+	logger.Info("draining", "delay", cfg.Server.DrainDelay.String())
+	time.Sleep(cfg.Server.DrainDelay.Duration)
+
+	if err := manager.Stop(); err != nil {
+		var stopErr *lifecycle.StopError
+		if errors.As(err, &stopErr) {
+			for _, failure := range stopErr.Failures {
+				logger.Error("component did not stop cleanly", "component", failure.Component, "error", failure.Err)
+			}
+		}
+		os.Exit(1)
+	}
+	logger.Info("server exited gracefully")
+	os.Exit(exitCode)
+}
+
+// reload reads the configuration again and applies what can be changed
+// without a restart. Changes that need a restart are reported each time until
+// the server is restarted, as running keeps the configuration it started
+// with.
+func reload(ctx context.Context, running config.Config, manager *lifecycle.Manager, logger *logging.Logger) {
+	next, _, err := config.Load(os.Args[1:], os.LookupEnv)
+	if err != nil {
+		logger.Error("failed to reload config, keeping the current one", "error", err)
+		return
+	}
+	if keys := config.RestartRequired(running, next); len(keys) > 0 {
+		logger.Warn("config changes need a restart and were not applied", "keys", keys)
+	}
+	if err := manager.Reload(ctx, next); err != nil {
+		logger.Error("failed to reload config", "error", err)
+		return
+	}
+	logger.Info("config reloaded")
 }
@@ -0,0 +1,72 @@
+package graphqlapi
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+
+	"github.com/olad5/sal-backend-service/internal/infra"
+	"github.com/olad5/sal-backend-service/internal/usecases/currency"
+	"github.com/olad5/sal-backend-service/internal/usecases/plans"
+	"github.com/olad5/sal-backend-service/internal/usecases/products"
+	"github.com/olad5/sal-backend-service/internal/usecases/tax"
+	appErrors "github.com/olad5/sal-backend-service/pkg/errors"
+)
+
+// Error codes sent in each error's extensions, so clients can tell failures
+// apart without matching messages.
+const (
+	codeBadUserInput   = "BAD_USER_INPUT"
+	codeNotFound       = "NOT_FOUND"
+	codeForbidden      = "FORBIDDEN"
+	codeConflict       = "CONFLICT"
+	codeQuotaExceeded  = "QUOTA_EXCEEDED"
+	codeQueryTooLarge  = "QUERY_TOO_LARGE"
+	codeInternalServer = "INTERNAL_SERVER_ERROR"
+)
+
+// apiError is an error with a code, formatted into its extensions.
+type apiError struct {
+	code    string
+	message string
+}
+
+func (e apiError) Error() string {
+	return e.message
+}
+
+func (e apiError) Extensions() map[string]interface{} {
+	return map[string]interface{}{"code": e.code}
+}
+
+func badUserInput(err error) error {
+	return apiError{codeBadUserInput, err.Error()}
+}
+
+// serviceError maps a service error the way the REST API does. Another
+// merchant's product is NOT_FOUND, so product IDs cannot be probed. Anything
+// unexpected is logged and its details are not sent to the client.
+func serviceError(ctx context.Context, logger *slog.Logger, err error) error {
+	switch {
+	case errors.Is(err, infra.ErrProductNotFound):
+		return apiError{codeNotFound, infra.ErrProductNotFound.Error()}
+	case errors.Is(err, products.ErrUserNotAuthorized):
+		return apiError{codeNotFound, appErrors.ErrUnauthorized}
+	case errors.Is(err, products.ErrProductAlreadyExists):
+		return apiError{codeConflict, products.ErrProductAlreadyExists.Error()}
+	case errors.Is(err, infra.ErrSkuCodeTaken):
+		return apiError{codeConflict, infra.ErrSkuCodeTaken.Error()}
+	case errors.Is(err, infra.ErrBarcodeTaken):
+		return apiError{codeConflict, infra.ErrBarcodeTaken.Error()}
+	case errors.Is(err, products.ErrSkuCodesExhausted):
+		return apiError{codeConflict, products.ErrSkuCodesExhausted.Error()}
+	case errors.Is(err, plans.ErrQuotaExceeded):
+		return apiError{codeQuotaExceeded, err.Error()}
+	case errors.Is(err, currency.ErrInvalidCurrency), errors.Is(err, currency.ErrUnsupportedCurrency),
+		errors.Is(err, tax.ErrInvalidRegion), errors.Is(err, tax.ErrUnsupportedRegion):
+		return apiError{codeBadUserInput, err.Error()}
+	default:
+		logger.ErrorContext(ctx, "GraphQL resolver failed", "error", err)
+		return apiError{codeInternalServer, appErrors.ErrSomethingWentWrong}
+	}
+}
@@ -0,0 +1,106 @@
+// Package graphqlapi serves the product catalogue over GraphQL, so clients
+// can fetch what a page needs in one request. It answers through the same
+// ProductService as the REST handlers.
+package graphqlapi
+
+import (
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"net/http"
+
+	"github.com/graphql-go/graphql"
+	"github.com/graphql-go/graphql/gqlerrors"
+	"github.com/graphql-go/graphql/language/parser"
+	"github.com/graphql-go/graphql/language/source"
+	"github.com/olad5/sal-backend-service/internal/usecases/currency"
+	"github.com/olad5/sal-backend-service/internal/usecases/products"
+	"github.com/olad5/sal-backend-service/internal/usecases/tax"
+	"github.com/olad5/sal-backend-service/pkg/tracing"
+)
+
+// maxRequestBytes bounds the query and variables of one request.
+const maxRequestBytes = 64 << 10
+
+type request struct {
+	Query         string                 `json:"query"`
+	OperationName string                 `json:"operationName"`
+	Variables     map[string]interface{} `json:"variables"`
+}
+
+type Handler struct {
+	schema graphql.Schema
+	limits Limits
+	logger *slog.Logger
+}
+
+func NewHandler(productService *products.ProductService, currencyService *currency.CurrencyService, taxService *tax.TaxService, limits Limits, logger *slog.Logger) (*Handler, error) {
+	if productService == nil {
+		return nil, errors.New("GraphQL Handler failed to initialize, productService is nil")
+	}
+	if currencyService == nil {
+		return nil, errors.New("GraphQL Handler failed to initialize, currencyService is nil")
+	}
+	if taxService == nil {
+		return nil, errors.New("GraphQL Handler failed to initialize, taxService is nil")
+	}
+	if logger == nil {
+		return nil, errors.New("GraphQL Handler failed to initialize, logger is nil")
+	}
+	if limits.MaxDepth < 1 || limits.MaxComplexity < 1 {
+		return nil, errors.New("GraphQL Handler failed to initialize, limits must be positive")
+	}
+	schema, err := newSchema(productService, currencyService, taxService, logger)
+	if err != nil {
+		return nil, err
+	}
+	return &Handler{schema: schema, limits: limits, logger: logger}, nil
+}
+
+// ServeHTTP answers POSTed GraphQL requests. Requests that cannot be run at
+// all, because they do not parse, are invalid or exceed the limits, get a 400,
+// errors while resolving are reported next to the data with a 200.
+func (h *Handler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	var body request
+	decoder := json.NewDecoder(http.MaxBytesReader(w, r.Body, maxRequestBytes))
+	if err := decoder.Decode(&body); err != nil || body.Query == "" {
+		writeResult(w, http.StatusBadRequest, &graphql.Result{Errors: gqlerrors.FormatErrors(errors.New("the body must be JSON with a query"))})
+		return
+	}
+
+	_, span := tracing.Start(ctx, "validate query")
+	document, err := parser.Parse(parser.ParseParams{Source: source.NewSource(&source.Source{Body: []byte(body.Query), Name: "GraphQL request"})})
+	if err != nil {
+		span.End()
+		writeResult(w, http.StatusBadRequest, &graphql.Result{Errors: gqlerrors.FormatErrors(err)})
+		return
+	}
+	if validation := graphql.ValidateDocument(&h.schema, document, nil); !validation.IsValid {
+		span.End()
+		writeResult(w, http.StatusBadRequest, &graphql.Result{Errors: validation.Errors})
+		return
+	}
+	err = checkLimits(&h.schema, document, body.OperationName, body.Variables, h.limits)
+	span.End()
+	if err != nil {
+		writeResult(w, http.StatusBadRequest, &graphql.Result{Errors: gqlerrors.FormatErrors(gqlerrors.NewError(err.Error(), nil, "", nil, nil, err))})
+		return
+	}
+
+	result := graphql.Execute(graphql.ExecuteParams{
+		Schema:        h.schema,
+		AST:           document,
+		OperationName: body.OperationName,
+		Args:          body.Variables,
+		Context:       ctx,
+	})
+	writeResult(w, http.StatusOK, result)
+}
+
+func writeResult(w http.ResponseWriter, status int, result *graphql.Result) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(result)
+}
@@ -0,0 +1,156 @@
+package graphqlapi
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/graphql-go/graphql"
+	"github.com/graphql-go/graphql/language/ast"
+)
+
+// Limits bound the work a single query can ask for. Depth counts nested
+// field selections, complexity counts every selected field, with the fields
+// below a paginated connection counted once per requested item. Introspection
+// fields are not counted.
+type Limits struct {
+	MaxDepth      int
+	MaxComplexity int
+}
+
+// queryCost walks an operation with its fragments expanded, measuring the
+// depth and complexity Limits bound.
+type queryCost struct {
+	schema    *graphql.Schema
+	fragments map[string]*ast.FragmentDefinition
+	variables map[string]interface{}
+	// expanding guards against fragment cycles, which validation rejects
+	// before the cost is measured anyway
+	expanding map[string]bool
+}
+
+// checkLimits measures the operation that will be executed. Documents without
+// that operation are left to execution to reject.
+func checkLimits(schema *graphql.Schema, document *ast.Document, operationName string, variables map[string]interface{}, limits Limits) error {
+	cost := queryCost{
+		schema:    schema,
+		fragments: map[string]*ast.FragmentDefinition{},
+		variables: variables,
+		expanding: map[string]bool{},
+	}
+	var operations []*ast.OperationDefinition
+	for _, definition := range document.Definitions {
+		switch definition := definition.(type) {
+		case *ast.FragmentDefinition:
+			cost.fragments[definition.Name.Value] = definition
+		case *ast.OperationDefinition:
+			if operationName == "" || (definition.Name != nil && definition.Name.Value == operationName) {
+				operations = append(operations, definition)
+			}
+		}
+	}
+	if len(operations) != 1 {
+		return nil
+	}
+
+	operation := operations[0]
+	root := schema.QueryType()
+	if operation.Operation == ast.OperationTypeMutation {
+		root = schema.MutationType()
+	}
+	depth, complexity := cost.selectionSet(operation.SelectionSet, root, 1)
+	if depth > limits.MaxDepth {
+		return apiError{codeQueryTooLarge, fmt.Sprintf("query depth %d exceeds the maximum of %d", depth, limits.MaxDepth)}
+	}
+	if complexity > limits.MaxComplexity {
+		return apiError{codeQueryTooLarge, fmt.Sprintf("query complexity %d exceeds the maximum of %d", complexity, limits.MaxComplexity)}
+	}
+	return nil
+}
+
+func (c queryCost) selectionSet(set *ast.SelectionSet, parent graphql.Type, depth int) (int, int) {
+	if set == nil {
+		return depth - 1, 0
+	}
+	maxDepth, complexity := depth, 0
+	add := func(selectionDepth, selectionComplexity int) {
+		if selectionDepth > maxDepth {
+			maxDepth = selectionDepth
+		}
+		complexity += selectionComplexity
+	}
+
+	for _, selection := range set.Selections {
+		switch selection := selection.(type) {
+		case *ast.Field:
+			if strings.HasPrefix(selection.Name.Value, "__") {
+				continue
+			}
+			object, ok := parent.(*graphql.Object)
+			if !ok {
+				continue
+			}
+			field, ok := object.Fields()[selection.Name.Value]
+			if !ok {
+				continue
+			}
+			named, _ := graphql.GetNamed(field.Type).(graphql.Type)
+			childDepth, childComplexity := c.selectionSet(selection.SelectionSet, named, depth+1)
+			add(childDepth, 1+c.pageSize(selection, field)*childComplexity)
+		case *ast.InlineFragment:
+			typ := parent
+			if selection.TypeCondition != nil {
+				typ = c.schema.Type(selection.TypeCondition.Name.Value)
+			}
+			add(c.selectionSet(selection.SelectionSet, typ, depth))
+		case *ast.FragmentSpread:
+			name := selection.Name.Value
+			fragment, ok := c.fragments[name]
+			if !ok || c.expanding[name] {
+				continue
+			}
+			c.expanding[name] = true
+			add(c.selectionSet(fragment.SelectionSet, c.schema.Type(fragment.TypeCondition.Name.Value), depth))
+			delete(c.expanding, name)
+		}
+	}
+	return maxDepth, complexity
+}
+
+// pageSize is the number of items a connection field asks for through its
+// first argument, or 1 for fields that are not paginated.
+func (c queryCost) pageSize(selection *ast.Field, field *graphql.FieldDefinition) int {
+	var argument *graphql.Argument
+	for _, arg := range field.Args {
+		if arg.Name() == "first" {
+			argument = arg
+		}
+	}
+	if argument == nil {
+		return 1
+	}
+	size := 0
+	if value, ok := argument.DefaultValue.(int); ok {
+		size = value
+	}
+	for _, arg := range selection.Arguments {
+		if arg.Name.Value != "first" {
+			continue
+		}
+		switch value := arg.Value.(type) {
+		case *ast.IntValue:
+			size, _ = strconv.Atoi(value.Value)
+		case *ast.Variable:
+			switch variable := c.variables[value.Name.Value].(type) {
+			case float64:
+				size = int(variable)
+			case int:
+				size = variable
+			}
+		}
+	}
+	if size < 1 {
+		return 1
+	}
+	return size
+}
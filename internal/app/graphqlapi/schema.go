@@ -0,0 +1,713 @@
+package graphqlapi
+
+import (
+	"context"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"log/slog"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/google/uuid"
+	"github.com/graphql-go/graphql"
+	"github.com/olad5/sal-backend-service/internal/app/middleware"
+	"github.com/olad5/sal-backend-service/internal/domain"
+	"github.com/olad5/sal-backend-service/internal/usecases/currency"
+	"github.com/olad5/sal-backend-service/internal/usecases/products"
+	"github.com/olad5/sal-backend-service/internal/usecases/tax"
+	appErrors "github.com/olad5/sal-backend-service/pkg/errors"
+)
+
+const (
+	defaultPageSize = 20
+	maxPageSize     = 100
+)
+
+var errInvalidCursor = errors.New("after is not a cursor returned by this connection")
+
+// connection and edge are the sources of the connection types, a Relay style
+// page of products or search hits.
+type connection struct {
+	Edges      []edge   `json:"edges"`
+	PageInfo   pageInfo `json:"pageInfo"`
+	TotalCount int      `json:"totalCount"`
+}
+
+type edge struct {
+	Cursor string         `json:"cursor"`
+	Score  float64        `json:"score"`
+	Node   domain.Product `json:"node"`
+}
+
+type pageInfo struct {
+	HasNextPage bool    `json:"hasNextPage"`
+	EndCursor   *string `json:"endCursor"`
+}
+
+// resolver answers the queries and mutations through the ProductService, which
+// applies the same rules and merchant checks as over REST.
+type resolver struct {
+	productService  *products.ProductService
+	currencyService *currency.CurrencyService
+	taxService      *tax.TaxService
+	logger          *slog.Logger
+}
+
+// convertedPrices is the source of the ConvertedPrices type.
+type convertedPrices struct {
+	Currency            string  `json:"currency"`
+	Price               float64 `json:"price"`
+	EffectivePrice      float64 `json:"effectivePrice"`
+	BaseCurrency        string  `json:"baseCurrency"`
+	ExchangeRate        float64 `json:"exchangeRate"`
+	ExchangeRateVersion int     `json:"exchangeRateVersion"`
+}
+
+// taxedPrices is the source of the TaxedPrices type.
+type taxedPrices struct {
+	Region         string            `json:"region"`
+	TaxClass       string            `json:"taxClass"`
+	Rate           float64           `json:"rate"`
+	TaxInclusive   bool              `json:"taxInclusive"`
+	Price          float64           `json:"price"`
+	EffectivePrice float64           `json:"effectivePrice"`
+	Regular        domain.TaxedPrice `json:"regular"`
+	Effective      domain.TaxedPrice `json:"effective"`
+}
+
+func newSchema(productService *products.ProductService, currencyService *currency.CurrencyService, taxService *tax.TaxService, logger *slog.Logger) (graphql.Schema, error) {
+	r := resolver{productService, currencyService, taxService, logger}
+
+	imageType := graphql.NewObject(graphql.ObjectConfig{
+		Name: "ProductImage",
+		Fields: graphql.Fields{
+			"id":          imageField(graphql.NewNonNull(graphql.ID), func(i domain.ProductImage) interface{} { return i.ID.String() }),
+			"altText":     imageField(graphql.NewNonNull(graphql.String), func(i domain.ProductImage) interface{} { return i.AltText }),
+			"position":    imageField(graphql.NewNonNull(graphql.Int), func(i domain.ProductImage) interface{} { return i.Position }),
+			"isPrimary":   imageField(graphql.NewNonNull(graphql.Boolean), func(i domain.ProductImage) interface{} { return i.IsPrimary }),
+			"contentType": imageField(graphql.NewNonNull(graphql.String), func(i domain.ProductImage) interface{} { return i.ContentType }),
+		},
+	})
+	convertedPricesType := graphql.NewObject(graphql.ObjectConfig{
+		Name: "ConvertedPrices",
+		Fields: graphql.Fields{
+			"currency":            &graphql.Field{Type: graphql.NewNonNull(graphql.String)},
+			"price":               &graphql.Field{Type: graphql.NewNonNull(graphql.Float)},
+			"effectivePrice":      &graphql.Field{Type: graphql.NewNonNull(graphql.Float)},
+			"baseCurrency":        &graphql.Field{Type: graphql.NewNonNull(graphql.String)},
+			"exchangeRate":        &graphql.Field{Type: graphql.NewNonNull(graphql.Float)},
+			"exchangeRateVersion": &graphql.Field{Type: graphql.NewNonNull(graphql.Int)},
+		},
+	})
+	taxedPriceType := graphql.NewObject(graphql.ObjectConfig{
+		Name: "TaxedPrice",
+		Fields: graphql.Fields{
+			"net":   taxedPriceField(func(p domain.TaxedPrice) float64 { return p.Net }),
+			"tax":   taxedPriceField(func(p domain.TaxedPrice) float64 { return p.Tax }),
+			"gross": taxedPriceField(func(p domain.TaxedPrice) float64 { return p.Gross }),
+		},
+	})
+	taxedPricesType := graphql.NewObject(graphql.ObjectConfig{
+		Name:        "TaxedPrices",
+		Description: "price and effectivePrice are gross when taxInclusive and net otherwise",
+		Fields: graphql.Fields{
+			"region":         &graphql.Field{Type: graphql.NewNonNull(graphql.String)},
+			"taxClass":       &graphql.Field{Type: graphql.NewNonNull(graphql.String)},
+			"rate":           &graphql.Field{Type: graphql.NewNonNull(graphql.Float)},
+			"taxInclusive":   &graphql.Field{Type: graphql.NewNonNull(graphql.Boolean)},
+			"price":          &graphql.Field{Type: graphql.NewNonNull(graphql.Float)},
+			"effectivePrice": &graphql.Field{Type: graphql.NewNonNull(graphql.Float)},
+			"regular":        &graphql.Field{Type: graphql.NewNonNull(taxedPriceType)},
+			"effective":      &graphql.Field{Type: graphql.NewNonNull(taxedPriceType)},
+		},
+	})
+	taxDisplayType := graphql.NewEnum(graphql.EnumConfig{
+		Name: "TaxDisplay",
+		Values: graphql.EnumValueConfigMap{
+			"INCLUSIVE": &graphql.EnumValueConfig{Value: true},
+			"EXCLUSIVE": &graphql.EnumValueConfig{Value: false},
+		},
+	})
+	attributeType := graphql.NewObject(graphql.ObjectConfig{
+		Name: "Attribute",
+		Fields: graphql.Fields{
+			"name":  &graphql.Field{Type: graphql.NewNonNull(graphql.String)},
+			"value": &graphql.Field{Type: graphql.NewNonNull(graphql.String)},
+		},
+	})
+	attributeInputType := graphql.NewInputObject(graphql.InputObjectConfig{
+		Name: "AttributeInput",
+		Fields: graphql.InputObjectConfigFieldMap{
+			"name":  &graphql.InputObjectFieldConfig{Type: graphql.NewNonNull(graphql.String)},
+			"value": &graphql.InputObjectFieldConfig{Type: graphql.NewNonNull(graphql.String)},
+		},
+	})
+	productType := graphql.NewObject(graphql.ObjectConfig{
+		Name: "Product",
+		Fields: graphql.Fields{
+			"skuId":          productField(graphql.NewNonNull(graphql.ID), func(p domain.Product) interface{} { return p.SKUID.String() }),
+			"skuCode":        productField(graphql.NewNonNull(graphql.String), func(p domain.Product) interface{} { return p.SKUCode }),
+			"gtin":           productField(graphql.String, func(p domain.Product) interface{} { return optional(p.GTIN) }),
+			"isbn":           productField(graphql.String, func(p domain.Product) interface{} { return optional(p.ISBN) }),
+			"merchantId":     productField(graphql.NewNonNull(graphql.ID), func(p domain.Product) interface{} { return p.MerchantId.String() }),
+			"name":           productField(graphql.NewNonNull(graphql.String), func(p domain.Product) interface{} { return p.Name }),
+			"description":    productField(graphql.NewNonNull(graphql.String), func(p domain.Product) interface{} { return p.Description }),
+			"price":          productField(graphql.NewNonNull(graphql.Float), func(p domain.Product) interface{} { return p.Price }),
+			"effectivePrice": productField(graphql.NewNonNull(graphql.Float), func(p domain.Product) interface{} { return p.EffectivePrice() }),
+			"taxClass":       productField(graphql.NewNonNull(graphql.String), func(p domain.Product) interface{} { return string(p.TaxClass) }),
+			"category":       productField(graphql.String, func(p domain.Product) interface{} { return optional(p.Category) }),
+			"tags":           productField(graphql.NewNonNull(graphql.NewList(graphql.NewNonNull(graphql.String))), func(p domain.Product) interface{} { return append([]string{}, p.Tags...) }),
+			"attributes":     productField(graphql.NewNonNull(graphql.NewList(graphql.NewNonNull(attributeType))), func(p domain.Product) interface{} { return attributes(p) }),
+			"images":         productField(graphql.NewNonNull(graphql.NewList(graphql.NewNonNull(imageType))), func(p domain.Product) interface{} { return p.Images }),
+			"createdAt":      productField(graphql.NewNonNull(graphql.DateTime), func(p domain.Product) interface{} { return p.CreatedAt }),
+			"updatedAt":      productField(graphql.NewNonNull(graphql.DateTime), func(p domain.Product) interface{} { return p.UpdatedAt }),
+			"converted": &graphql.Field{
+				Type:        graphql.NewNonNull(convertedPricesType),
+				Description: "the prices in another ISO 4217 currency, at the latest exchange rates",
+				Args: graphql.FieldConfigArgument{
+					"currency": &graphql.ArgumentConfig{Type: graphql.NewNonNull(graphql.String)},
+				},
+				Resolve: r.converted,
+			},
+			"tax": &graphql.Field{
+				Type:        graphql.NewNonNull(taxedPricesType),
+				Description: "the prices with a region's tax for the product's tax class",
+				Args: graphql.FieldConfigArgument{
+					"region":  &graphql.ArgumentConfig{Type: graphql.NewNonNull(graphql.String)},
+					"display": &graphql.ArgumentConfig{Type: taxDisplayType, DefaultValue: true},
+				},
+				Resolve: r.tax,
+			},
+		},
+	})
+	categoryType := graphql.NewObject(graphql.ObjectConfig{
+		Name: "Category",
+		Fields: graphql.Fields{
+			"name": &graphql.Field{Type: graphql.NewNonNull(graphql.String), Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+				return p.Source.(domain.TermCount).Term, nil
+			}},
+			"productCount": &graphql.Field{Type: graphql.NewNonNull(graphql.Int), Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+				return p.Source.(domain.TermCount).Count, nil
+			}},
+		},
+	})
+	pageInfoType := graphql.NewObject(graphql.ObjectConfig{
+		Name: "PageInfo",
+		Fields: graphql.Fields{
+			"hasNextPage": &graphql.Field{Type: graphql.NewNonNull(graphql.Boolean)},
+			"endCursor":   &graphql.Field{Type: graphql.String},
+		},
+	})
+	productConnection := connectionType("Product", productType, pageInfoType, nil)
+	searchConnection := connectionType("ProductSearch", productType, pageInfoType, graphql.Fields{
+		"score": &graphql.Field{Type: graphql.NewNonNull(graphql.Float)},
+	})
+
+	pageArgs := func(args graphql.FieldConfigArgument) graphql.FieldConfigArgument {
+		args["first"] = &graphql.ArgumentConfig{Type: graphql.Int, DefaultValue: defaultPageSize}
+		args["after"] = &graphql.ArgumentConfig{Type: graphql.String}
+		return args
+	}
+	productIDArgs := graphql.FieldConfigArgument{
+		"merchantId": &graphql.ArgumentConfig{Type: graphql.NewNonNull(graphql.ID)},
+		"skuId":      &graphql.ArgumentConfig{Type: graphql.NewNonNull(graphql.ID)},
+	}
+	productInputFields := func(required bool) graphql.InputObjectConfigFieldMap {
+		nonNull := func(t graphql.Input) graphql.Input {
+			if required {
+				return graphql.NewNonNull(t)
+			}
+			return t
+		}
+		return graphql.InputObjectConfigFieldMap{
+			"merchantId":  &graphql.InputObjectFieldConfig{Type: graphql.NewNonNull(graphql.ID)},
+			"skuCode":     &graphql.InputObjectFieldConfig{Type: graphql.String},
+			"gtin":        &graphql.InputObjectFieldConfig{Type: graphql.String},
+			"isbn":        &graphql.InputObjectFieldConfig{Type: graphql.String},
+			"name":        &graphql.InputObjectFieldConfig{Type: nonNull(graphql.String)},
+			"description": &graphql.InputObjectFieldConfig{Type: nonNull(graphql.String)},
+			"price":       &graphql.InputObjectFieldConfig{Type: nonNull(graphql.Float)},
+			"taxClass":    &graphql.InputObjectFieldConfig{Type: graphql.String},
+			"category":    &graphql.InputObjectFieldConfig{Type: graphql.String},
+			"tags":        &graphql.InputObjectFieldConfig{Type: graphql.NewList(graphql.NewNonNull(graphql.String))},
+			"attributes":  &graphql.InputObjectFieldConfig{Type: graphql.NewList(graphql.NewNonNull(attributeInputType))},
+		}
+	}
+	createInput := productInputFields(true)
+	createInput["skuId"] = &graphql.InputObjectFieldConfig{Type: graphql.ID, Description: "generated when not given"}
+
+	query := graphql.NewObject(graphql.ObjectConfig{
+		Name: "Query",
+		Fields: graphql.Fields{
+			"product": &graphql.Field{
+				Type:    productType,
+				Args:    productIDArgs,
+				Resolve: r.product,
+			},
+			"merchantProducts": &graphql.Field{
+				Type: graphql.NewNonNull(productConnection),
+				Args: pageArgs(graphql.FieldConfigArgument{
+					"merchantId": &graphql.ArgumentConfig{Type: graphql.NewNonNull(graphql.ID)},
+				}),
+				Resolve: r.merchantProducts,
+			},
+			"search": &graphql.Field{
+				Type: graphql.NewNonNull(searchConnection),
+				Args: pageArgs(graphql.FieldConfigArgument{
+					"merchantId": &graphql.ArgumentConfig{Type: graphql.NewNonNull(graphql.ID)},
+					"query":      &graphql.ArgumentConfig{Type: graphql.NewNonNull(graphql.String)},
+				}),
+				Resolve: r.search,
+			},
+			"categories": &graphql.Field{
+				Type:        graphql.NewNonNull(graphql.NewList(graphql.NewNonNull(categoryType))),
+				Description: "the merchant's product categories, sorted by name",
+				Args: graphql.FieldConfigArgument{
+					"merchantId": &graphql.ArgumentConfig{Type: graphql.NewNonNull(graphql.ID)},
+				},
+				Resolve: r.categories,
+			},
+		},
+	})
+	mutation := graphql.NewObject(graphql.ObjectConfig{
+		Name: "Mutation",
+		Fields: graphql.Fields{
+			"createProduct": &graphql.Field{
+				Type: graphql.NewNonNull(productType),
+				Args: graphql.FieldConfigArgument{
+					"input": &graphql.ArgumentConfig{Type: graphql.NewNonNull(graphql.NewInputObject(graphql.InputObjectConfig{
+						Name:   "CreateProductInput",
+						Fields: createInput,
+					}))},
+				},
+				Resolve: r.createProduct,
+			},
+			"updateProduct": &graphql.Field{
+				Type: graphql.NewNonNull(productType),
+				Args: graphql.FieldConfigArgument{
+					"skuId": &graphql.ArgumentConfig{Type: graphql.NewNonNull(graphql.ID)},
+					"input": &graphql.ArgumentConfig{Type: graphql.NewNonNull(graphql.NewInputObject(graphql.InputObjectConfig{
+						Name:   "UpdateProductInput",
+						Fields: productInputFields(true),
+					}))},
+				},
+				Resolve: r.updateProduct,
+			},
+			"deleteProduct": &graphql.Field{
+				Type:    graphql.NewNonNull(graphql.ID),
+				Args:    productIDArgs,
+				Resolve: r.deleteProduct,
+			},
+		},
+	})
+	return graphql.NewSchema(graphql.SchemaConfig{Query: query, Mutation: mutation})
+}
+
+func connectionType(name string, nodeType, pageInfoType *graphql.Object, edgeFields graphql.Fields) *graphql.Object {
+	fields := graphql.Fields{
+		"cursor": &graphql.Field{Type: graphql.NewNonNull(graphql.String)},
+		"node":   &graphql.Field{Type: graphql.NewNonNull(nodeType)},
+	}
+	for fieldName, field := range edgeFields {
+		fields[fieldName] = field
+	}
+	edgeType := graphql.NewObject(graphql.ObjectConfig{Name: name + "Edge", Fields: fields})
+	return graphql.NewObject(graphql.ObjectConfig{
+		Name: name + "Connection",
+		Fields: graphql.Fields{
+			"edges":      &graphql.Field{Type: graphql.NewNonNull(graphql.NewList(graphql.NewNonNull(edgeType)))},
+			"pageInfo":   &graphql.Field{Type: graphql.NewNonNull(pageInfoType)},
+			"totalCount": &graphql.Field{Type: graphql.NewNonNull(graphql.Int)},
+		},
+	})
+}
+
+func productField(t graphql.Output, value func(domain.Product) interface{}) *graphql.Field {
+	return &graphql.Field{Type: t, Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+		return value(p.Source.(domain.Product)), nil
+	}}
+}
+
+func taxedPriceField(value func(domain.TaxedPrice) float64) *graphql.Field {
+	return &graphql.Field{Type: graphql.NewNonNull(graphql.Float), Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+		return value(p.Source.(domain.TaxedPrice)), nil
+	}}
+}
+
+func imageField(t graphql.Output, value func(domain.ProductImage) interface{}) *graphql.Field {
+	return &graphql.Field{Type: t, Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+		return value(p.Source.(domain.ProductImage)), nil
+	}}
+}
+
+// attribute is the source of the Attribute type.
+type attribute struct {
+	Name  string `json:"name"`
+	Value string `json:"value"`
+}
+
+// attributes lists a product's attributes sorted by name.
+func attributes(product domain.Product) []attribute {
+	items := []attribute{}
+	for name, value := range product.Attributes {
+		items = append(items, attribute{Name: name, Value: value})
+	}
+	sort.Slice(items, func(i, j int) bool { return items[i].Name < items[j].Name })
+	return items
+}
+
+func optional(value string) interface{} {
+	if value == "" {
+		return nil
+	}
+	return value
+}
+
+func (r resolver) product(p graphql.ResolveParams) (interface{}, error) {
+	merchantId, skuId, err := productIDs(p.Context, p.Args)
+	if err != nil {
+		return nil, err
+	}
+	product, err := r.productService.GetProduct(p.Context, merchantId, skuId)
+	if err != nil {
+		return nil, serviceError(p.Context, r.logger, err)
+	}
+	return product, nil
+}
+
+func (r resolver) converted(p graphql.ResolveParams) (interface{}, error) {
+	product := p.Source.(domain.Product)
+	code, _ := p.Args["currency"].(string)
+	rate, err := r.currencyService.GetExchangeRate(p.Context, code)
+	if err != nil {
+		return nil, serviceError(p.Context, r.logger, err)
+	}
+	return convertedPrices{
+		Currency:            rate.Currency,
+		Price:               currency.Convert(rate, product.Price),
+		EffectivePrice:      currency.Convert(rate, product.EffectivePrice()),
+		BaseCurrency:        rate.BaseCurrency,
+		ExchangeRate:        rate.Rate,
+		ExchangeRateVersion: rate.TableVersion,
+	}, nil
+}
+
+func (r resolver) tax(p graphql.ResolveParams) (interface{}, error) {
+	product := p.Source.(domain.Product)
+	code, _ := p.Args["region"].(string)
+	inclusive, _ := p.Args["display"].(bool)
+	region, err := r.taxService.GetTaxRegion(p.Context, code)
+	if err != nil {
+		return nil, serviceError(p.Context, r.logger, err)
+	}
+	taxClass := product.TaxClass
+	if taxClass == "" {
+		taxClass = domain.StandardTaxClass
+	}
+	prices := taxedPrices{
+		Region:       region.Code,
+		TaxClass:     string(taxClass),
+		Rate:         region.Rates[taxClass],
+		TaxInclusive: inclusive,
+		Regular:      tax.Calculate(region, taxClass, product.Price),
+		Effective:    tax.Calculate(region, taxClass, product.EffectivePrice()),
+	}
+	prices.Price, prices.EffectivePrice = prices.Regular.Net, prices.Effective.Net
+	if inclusive {
+		prices.Price, prices.EffectivePrice = prices.Regular.Gross, prices.Effective.Gross
+	}
+	return prices, nil
+}
+
+func (r resolver) merchantProducts(p graphql.ResolveParams) (interface{}, error) {
+	merchantId, err := merchantID(p.Context, p.Args["merchantId"])
+	if err != nil {
+		return nil, err
+	}
+	first, err := pageSize(p.Args)
+	if err != nil {
+		return nil, err
+	}
+	merchantProducts, err := r.productService.GetProductsByMerchantId(p.Context, merchantId)
+	if err != nil {
+		return nil, serviceError(p.Context, r.logger, err)
+	}
+
+	// cursors are SKU IDs, so a page stays in place when earlier products
+	// are deleted
+	start := 0
+	if after, ok := p.Args["after"].(string); ok {
+		skuId, err := decodeCursor(after)
+		if err != nil {
+			return nil, err
+		}
+		start = -1
+		for index, product := range merchantProducts {
+			if product.SKUID.String() == skuId {
+				start = index + 1
+			}
+		}
+		if start < 0 {
+			return nil, badUserInput(errInvalidCursor)
+		}
+	}
+	end := start + first
+	if end > len(merchantProducts) {
+		end = len(merchantProducts)
+	}
+
+	page := connection{Edges: []edge{}, TotalCount: len(merchantProducts)}
+	for _, product := range merchantProducts[start:end] {
+		page.Edges = append(page.Edges, edge{Cursor: encodeCursor(product.SKUID.String()), Node: product})
+	}
+	page.PageInfo = newPageInfo(page.Edges, end < len(merchantProducts))
+	return page, nil
+}
+
+func (r resolver) search(p graphql.ResolveParams) (interface{}, error) {
+	merchantId, err := merchantID(p.Context, p.Args["merchantId"])
+	if err != nil {
+		return nil, err
+	}
+	query, _ := p.Args["query"].(string)
+	if query == "" {
+		return nil, badUserInput(errors.New("query required"))
+	}
+	first, err := pageSize(p.Args)
+	if err != nil {
+		return nil, err
+	}
+	// search hits are ranked, so cursors are offsets into the ranking
+	offset := 0
+	if after, ok := p.Args["after"].(string); ok {
+		value, err := decodeCursor(after)
+		if err != nil {
+			return nil, err
+		}
+		if offset, err = strconv.Atoi(value); err != nil || offset < 0 {
+			return nil, badUserInput(errInvalidCursor)
+		}
+		offset++
+	}
+
+	hits, total, err := r.productService.SearchProducts(p.Context, merchantId, query, first, offset)
+	if err != nil {
+		return nil, serviceError(p.Context, r.logger, err)
+	}
+	page := connection{Edges: []edge{}, TotalCount: total}
+	for index, hit := range hits {
+		page.Edges = append(page.Edges, edge{Cursor: encodeCursor(strconv.Itoa(offset + index)), Score: hit.Score, Node: hit.Product})
+	}
+	page.PageInfo = newPageInfo(page.Edges, offset+len(hits) < total)
+	return page, nil
+}
+
+func (r resolver) categories(p graphql.ResolveParams) (interface{}, error) {
+	merchantId, err := merchantID(p.Context, p.Args["merchantId"])
+	if err != nil {
+		return nil, err
+	}
+	categories, err := r.productService.GetCategories(p.Context, merchantId)
+	if err != nil {
+		return nil, serviceError(p.Context, r.logger, err)
+	}
+	return categories, nil
+}
+
+func (r resolver) createProduct(p graphql.ResolveParams) (interface{}, error) {
+	input, _ := p.Args["input"].(map[string]interface{})
+	merchantId, err := merchantID(p.Context, input["merchantId"])
+	if err != nil {
+		return nil, err
+	}
+	// the server generates an ID when the client does not bring its own
+	skuId := uuid.Nil
+	if input["skuId"] != nil {
+		if skuId, err = parseID(input["skuId"]); err != nil {
+			return nil, err
+		}
+	}
+	fields, err := parseProductInput(input)
+	if err != nil {
+		return nil, err
+	}
+	product, err := r.productService.CreateProduct(p.Context, merchantId, skuId, fields.skuCode, fields.gtin, fields.isbn, fields.name, fields.description, fields.price, fields.taxClass, fields.category, fields.tags, fields.attributes)
+	if err != nil {
+		return nil, serviceError(p.Context, r.logger, err)
+	}
+	return product, nil
+}
+
+func (r resolver) updateProduct(p graphql.ResolveParams) (interface{}, error) {
+	input, _ := p.Args["input"].(map[string]interface{})
+	merchantId, err := merchantID(p.Context, input["merchantId"])
+	if err != nil {
+		return nil, err
+	}
+	skuId, err := parseID(p.Args["skuId"])
+	if err != nil {
+		return nil, err
+	}
+	fields, err := parseProductInput(input)
+	if err != nil {
+		return nil, err
+	}
+	product, err := r.productService.UpdateProduct(p.Context, merchantId, skuId, fields.skuCode, fields.gtin, fields.isbn, fields.name, fields.description, fields.price, fields.taxClass, fields.category, fields.tags, fields.attributes)
+	if err != nil {
+		return nil, serviceError(p.Context, r.logger, err)
+	}
+	return product, nil
+}
+
+func (r resolver) deleteProduct(p graphql.ResolveParams) (interface{}, error) {
+	merchantId, skuId, err := productIDs(p.Context, p.Args)
+	if err != nil {
+		return nil, err
+	}
+	if err := r.productService.DeleteProduct(p.Context, merchantId, skuId); err != nil {
+		return nil, serviceError(p.Context, r.logger, err)
+	}
+	return skuId.String(), nil
+}
+
+func parseID(value interface{}) (uuid.UUID, error) {
+	id, _ := value.(string)
+	parsed, err := uuid.Parse(id)
+	if err != nil {
+		return uuid.Nil, badUserInput(appErrors.ErrInvalidID)
+	}
+	return parsed, nil
+}
+
+// merchantID parses a merchantId argument and checks that the request may act
+// for that merchant, as the REST API does.
+func merchantID(ctx context.Context, value interface{}) (uuid.UUID, error) {
+	merchantId, err := parseID(value)
+	if err != nil {
+		return uuid.Nil, err
+	}
+	if err := middleware.AuthorizeMerchant(ctx, merchantId); err != nil {
+		return uuid.Nil, apiError{codeForbidden, err.Error()}
+	}
+	return merchantId, nil
+}
+
+func productIDs(ctx context.Context, args map[string]interface{}) (uuid.UUID, uuid.UUID, error) {
+	merchantId, err := merchantID(ctx, args["merchantId"])
+	if err != nil {
+		return uuid.Nil, uuid.Nil, err
+	}
+	skuId, err := parseID(args["skuId"])
+	if err != nil {
+		return uuid.Nil, uuid.Nil, err
+	}
+	return merchantId, skuId, nil
+}
+
+func pageSize(args map[string]interface{}) (int, error) {
+	first, _ := args["first"].(int)
+	if first < 1 || first > maxPageSize {
+		return 0, badUserInput(fmt.Errorf("first must be between 1 and %d", maxPageSize))
+	}
+	return first, nil
+}
+
+func newPageInfo(edges []edge, hasNextPage bool) pageInfo {
+	info := pageInfo{HasNextPage: hasNextPage}
+	if len(edges) > 0 {
+		info.EndCursor = &edges[len(edges)-1].Cursor
+	}
+	return info
+}
+
+func encodeCursor(value string) string {
+	return base64.RawURLEncoding.EncodeToString([]byte("cursor:" + value))
+}
+
+func decodeCursor(cursor string) (string, error) {
+	decoded, err := base64.RawURLEncoding.DecodeString(cursor)
+	if err != nil || !strings.HasPrefix(string(decoded), "cursor:") {
+		return "", badUserInput(errInvalidCursor)
+	}
+	return strings.TrimPrefix(string(decoded), "cursor:"), nil
+}
+
+type productInput struct {
+	skuCode     string
+	gtin        string
+	isbn        string
+	name        string
+	description string
+	price       float64
+	taxClass    domain.TaxClass
+	category    string
+	tags        []string
+	attributes  map[string]string
+}
+
+// parseProductInput applies the REST API's rules to a create or update input.
+func parseProductInput(input map[string]interface{}) (productInput, error) {
+	str := func(key string) string {
+		value, _ := input[key].(string)
+		return value
+	}
+	fields := productInput{name: str("name"), description: str("description"), category: str("category")}
+	fields.price, _ = input["price"].(float64)
+	switch {
+	case fields.name == "":
+		return productInput{}, badUserInput(errors.New("name required"))
+	case fields.description == "":
+		return productInput{}, badUserInput(errors.New("description required"))
+	case fields.price < 0:
+		return productInput{}, badUserInput(errors.New("price cannot be less than zero"))
+	}
+
+	var err error
+	if value := str("skuCode"); value != "" {
+		if fields.skuCode, err = products.NormalizeSKUCode(value); err != nil {
+			return productInput{}, badUserInput(err)
+		}
+	}
+	if value := str("gtin"); value != "" {
+		if fields.gtin, err = products.NormalizeGTIN(value); err != nil {
+			return productInput{}, badUserInput(err)
+		}
+	}
+	if value := str("isbn"); value != "" {
+		if fields.isbn, err = products.NormalizeISBN(value); err != nil {
+			return productInput{}, badUserInput(err)
+		}
+	}
+	if value := str("taxClass"); value != "" {
+		if fields.taxClass, err = tax.ParseTaxClass(value); err != nil {
+			return productInput{}, badUserInput(err)
+		}
+	}
+	// a missing list leaves an update's tags or attributes alone and an
+	// empty one clears them
+	if values, ok := input["tags"].([]interface{}); ok {
+		tags := []string{}
+		for _, value := range values {
+			tag, _ := value.(string)
+			tags = append(tags, tag)
+		}
+		fields.tags = products.NormalizeTags(tags)
+	}
+	if values, ok := input["attributes"].([]interface{}); ok {
+		attributes := map[string]string{}
+		for _, value := range values {
+			pair, _ := value.(map[string]interface{})
+			name, _ := pair["name"].(string)
+			if _, ok := attributes[name]; ok {
+				return productInput{}, badUserInput(products.ErrInvalidAttributeName)
+			}
+			attributes[name], _ = pair["value"].(string)
+		}
+		if fields.attributes, err = products.NormalizeAttributes(attributes); err != nil {
+			return productInput{}, badUserInput(err)
+		}
+	}
+	return fields, nil
+}
@@ -0,0 +1,55 @@
+package grpcapi
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+
+	"github.com/olad5/sal-backend-service/internal/infra"
+	"github.com/olad5/sal-backend-service/internal/usecases/currency"
+	"github.com/olad5/sal-backend-service/internal/usecases/plans"
+	"github.com/olad5/sal-backend-service/internal/usecases/products"
+	"github.com/olad5/sal-backend-service/internal/usecases/tax"
+	appErrors "github.com/olad5/sal-backend-service/pkg/errors"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// statusError maps a service error to the code the REST API's status stands
+// for: 404 is NOT_FOUND, 409 ALREADY_EXISTS or FAILED_PRECONDITION and 403
+// RESOURCE_EXHAUSTED for quotas. Another merchant's product is NOT_FOUND, as
+// over REST, so product IDs cannot be probed. An existing sku_id is
+// ALREADY_EXISTS where REST answers 400. Anything else is INTERNAL and
+// logged, its details are not sent to the client.
+func statusError(ctx context.Context, logger *slog.Logger, err error) error {
+	switch {
+	case errors.Is(err, infra.ErrProductNotFound):
+		return status.Error(codes.NotFound, infra.ErrProductNotFound.Error())
+	case errors.Is(err, products.ErrUserNotAuthorized):
+		return status.Error(codes.NotFound, appErrors.ErrUnauthorized)
+	case errors.Is(err, products.ErrProductAlreadyExists):
+		return status.Error(codes.AlreadyExists, products.ErrProductAlreadyExists.Error())
+	case errors.Is(err, infra.ErrSkuCodeTaken):
+		return status.Error(codes.AlreadyExists, infra.ErrSkuCodeTaken.Error())
+	case errors.Is(err, infra.ErrBarcodeTaken):
+		return status.Error(codes.AlreadyExists, infra.ErrBarcodeTaken.Error())
+	case errors.Is(err, products.ErrSkuCodesExhausted):
+		return status.Error(codes.FailedPrecondition, products.ErrSkuCodesExhausted.Error())
+	case errors.Is(err, plans.ErrQuotaExceeded):
+		return status.Error(codes.ResourceExhausted, err.Error())
+	case errors.Is(err, currency.ErrInvalidCurrency), errors.Is(err, currency.ErrUnsupportedCurrency),
+		errors.Is(err, tax.ErrInvalidRegion), errors.Is(err, tax.ErrUnsupportedRegion):
+		return status.Error(codes.InvalidArgument, err.Error())
+	case errors.Is(err, context.Canceled), errors.Is(err, context.DeadlineExceeded):
+		return status.FromContextError(err).Err()
+	default:
+		logger.ErrorContext(ctx, "gRPC request failed", "error", err)
+		return status.Error(codes.Internal, appErrors.ErrSomethingWentWrong)
+	}
+}
+
+var errInvalidTaxDisplay = errors.New("tax_display must be inclusive or exclusive")
+
+func invalidArgument(err error) error {
+	return status.Error(codes.InvalidArgument, err.Error())
+}
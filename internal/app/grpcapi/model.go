@@ -0,0 +1,78 @@
+package grpcapi
+
+import (
+	productsv1 "github.com/olad5/sal-backend-service/api/proto/products/v1"
+	"github.com/olad5/sal-backend-service/internal/domain"
+	"github.com/olad5/sal-backend-service/internal/events"
+	"github.com/olad5/sal-backend-service/internal/usecases/currency"
+	"github.com/olad5/sal-backend-service/internal/usecases/tax"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+func toProduct(product domain.Product) *productsv1.Product {
+	return &productsv1.Product{
+		SkuId:          product.SKUID.String(),
+		SkuCode:        product.SKUCode,
+		Gtin:           product.GTIN,
+		Isbn:           product.ISBN,
+		MerchantId:     product.MerchantId.String(),
+		Name:           product.Name,
+		Description:    product.Description,
+		Price:          product.Price,
+		EffectivePrice: product.EffectivePrice(),
+		TaxClass:       string(product.TaxClass),
+		Category:       product.Category,
+		Tags:           product.Tags,
+		Attributes:     product.Attributes,
+		CreatedAt:      timestamppb.New(product.CreatedAt),
+		UpdatedAt:      timestamppb.New(product.UpdatedAt),
+	}
+}
+
+func toConvertedPrices(product domain.Product, rate domain.ExchangeRate) *productsv1.ConvertedPrices {
+	return &productsv1.ConvertedPrices{
+		Currency:            rate.Currency,
+		Price:               currency.Convert(rate, product.Price),
+		EffectivePrice:      currency.Convert(rate, product.EffectivePrice()),
+		BaseCurrency:        rate.BaseCurrency,
+		ExchangeRate:        rate.Rate,
+		ExchangeRateVersion: int32(rate.TableVersion),
+	}
+}
+
+func toTaxedPrices(product domain.Product, region domain.TaxRegion, inclusive bool) *productsv1.TaxedPrices {
+	taxClass := product.TaxClass
+	if taxClass == "" {
+		taxClass = domain.StandardTaxClass
+	}
+	regular := tax.Calculate(region, taxClass, product.Price)
+	effective := tax.Calculate(region, taxClass, product.EffectivePrice())
+	price, effectivePrice := regular.Net, effective.Net
+	if inclusive {
+		price, effectivePrice = regular.Gross, effective.Gross
+	}
+	return &productsv1.TaxedPrices{
+		Region:         region.Code,
+		TaxClass:       string(taxClass),
+		Rate:           region.Rates[taxClass],
+		TaxInclusive:   inclusive,
+		Price:          price,
+		EffectivePrice: effectivePrice,
+		Regular:        &productsv1.TaxedPrice{Net: regular.Net, Tax: regular.Tax, Gross: regular.Gross},
+		Effective:      &productsv1.TaxedPrice{Net: effective.Net, Tax: effective.Tax, Gross: effective.Gross},
+	}
+}
+
+var eventTypes = map[events.ProductEventType]productsv1.ProductEvent_Type{
+	events.ProductCreated: productsv1.ProductEvent_TYPE_CREATED,
+	events.ProductUpdated: productsv1.ProductEvent_TYPE_UPDATED,
+	events.ProductDeleted: productsv1.ProductEvent_TYPE_DELETED,
+}
+
+func toProductEvent(event events.ProductEvent) *productsv1.ProductEvent {
+	return &productsv1.ProductEvent{
+		Type:       eventTypes[event.Type],
+		Product:    toProduct(event.Product),
+		OccurredAt: timestamppb.New(event.OccurredAt),
+	}
+}
@@ -0,0 +1,295 @@
+// Package grpcapi serves the product catalogue over gRPC for internal
+// services, on the same ProductService as the REST handlers.
+package grpcapi
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"sync"
+
+	"github.com/google/uuid"
+	productsv1 "github.com/olad5/sal-backend-service/api/proto/products/v1"
+	"github.com/olad5/sal-backend-service/internal/domain"
+	"github.com/olad5/sal-backend-service/internal/events"
+	"github.com/olad5/sal-backend-service/internal/usecases/currency"
+	"github.com/olad5/sal-backend-service/internal/usecases/products"
+	"github.com/olad5/sal-backend-service/internal/usecases/tax"
+	appErrors "github.com/olad5/sal-backend-service/pkg/errors"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+// watchBufferSize is how many events a watcher may fall behind by before its
+// stream is ended.
+const watchBufferSize = 256
+
+type ProductServer struct {
+	productsv1.UnimplementedProductServiceServer
+	productService  *products.ProductService
+	currencyService *currency.CurrencyService
+	taxService      *tax.TaxService
+	eventBus        *events.Bus
+	logger          *slog.Logger
+}
+
+func NewProductServer(productService *products.ProductService, currencyService *currency.CurrencyService, taxService *tax.TaxService, eventBus *events.Bus, logger *slog.Logger) (*ProductServer, error) {
+	if productService == nil {
+		return nil, errors.New("ProductServer failed to initialize, productService is nil")
+	}
+	if currencyService == nil {
+		return nil, errors.New("ProductServer failed to initialize, currencyService is nil")
+	}
+	if taxService == nil {
+		return nil, errors.New("ProductServer failed to initialize, taxService is nil")
+	}
+	if eventBus == nil {
+		return nil, errors.New("ProductServer failed to initialize, eventBus is nil")
+	}
+	if logger == nil {
+		return nil, errors.New("ProductServer failed to initialize, logger is nil")
+	}
+	return &ProductServer{productService: productService, currencyService: currencyService, taxService: taxService, eventBus: eventBus, logger: logger}, nil
+}
+
+func (s *ProductServer) CreateProduct(ctx context.Context, request *productsv1.CreateProductRequest) (*productsv1.Product, error) {
+	if err := requireProductFields(request.Name, request.Description, request.Price); err != nil {
+		return nil, err
+	}
+	merchantId, err := parseID(request.MerchantId)
+	if err != nil {
+		return nil, err
+	}
+	// the server generates an ID when the client does not bring its own
+	skuId := uuid.Nil
+	if request.SkuId != "" {
+		if skuId, err = parseID(request.SkuId); err != nil {
+			return nil, err
+		}
+	}
+	fields, err := parseProductFields(request.SkuCode, request.Gtin, request.Isbn, request.TaxClass, request.Tags, request.Attributes)
+	if err != nil {
+		return nil, err
+	}
+
+	product, err := s.productService.CreateProduct(ctx, merchantId, skuId, fields.skuCode, fields.gtin, fields.isbn, request.Name, request.Description, request.Price, fields.taxClass, request.Category, fields.tags, fields.attributes)
+	if err != nil {
+		return nil, statusError(ctx, s.logger, err)
+	}
+	return toProduct(product), nil
+}
+
+func (s *ProductServer) GetProduct(ctx context.Context, request *productsv1.GetProductRequest) (*productsv1.Product, error) {
+	merchantId, skuId, err := parseProductIDs(request.MerchantId, request.SkuId)
+	if err != nil {
+		return nil, err
+	}
+	var rate *domain.ExchangeRate
+	if request.Currency != "" {
+		found, err := s.currencyService.GetExchangeRate(ctx, request.Currency)
+		if err != nil {
+			return nil, statusError(ctx, s.logger, err)
+		}
+		rate = &found
+	}
+	var region *domain.TaxRegion
+	taxInclusive := true
+	switch request.TaxDisplay {
+	case "", "inclusive":
+	case "exclusive":
+		taxInclusive = false
+	default:
+		return nil, invalidArgument(errInvalidTaxDisplay)
+	}
+	if request.Region != "" {
+		found, err := s.taxService.GetTaxRegion(ctx, request.Region)
+		if err != nil {
+			return nil, statusError(ctx, s.logger, err)
+		}
+		region = &found
+	}
+	product, err := s.productService.GetProduct(ctx, merchantId, skuId)
+	if err != nil {
+		return nil, statusError(ctx, s.logger, err)
+	}
+	response := toProduct(product)
+	if rate != nil {
+		response.Converted = toConvertedPrices(product, *rate)
+	}
+	if region != nil {
+		response.Tax = toTaxedPrices(product, *region, taxInclusive)
+	}
+	return response, nil
+}
+
+func (s *ProductServer) UpdateProduct(ctx context.Context, request *productsv1.UpdateProductRequest) (*productsv1.Product, error) {
+	if err := requireProductFields(request.Name, request.Description, request.Price); err != nil {
+		return nil, err
+	}
+	merchantId, skuId, err := parseProductIDs(request.MerchantId, request.SkuId)
+	if err != nil {
+		return nil, err
+	}
+	fields, err := parseProductFields(request.SkuCode, request.Gtin, request.Isbn, request.TaxClass, request.Tags, request.Attributes)
+	if err != nil {
+		return nil, err
+	}
+
+	product, err := s.productService.UpdateProduct(ctx, merchantId, skuId, fields.skuCode, fields.gtin, fields.isbn, request.Name, request.Description, request.Price, fields.taxClass, request.Category, fields.tags, fields.attributes)
+	if err != nil {
+		return nil, statusError(ctx, s.logger, err)
+	}
+	return toProduct(product), nil
+}
+
+func (s *ProductServer) DeleteProduct(ctx context.Context, request *productsv1.DeleteProductRequest) (*productsv1.DeleteProductResponse, error) {
+	merchantId, skuId, err := parseProductIDs(request.MerchantId, request.SkuId)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.productService.DeleteProduct(ctx, merchantId, skuId); err != nil {
+		return nil, statusError(ctx, s.logger, err)
+	}
+	return &productsv1.DeleteProductResponse{}, nil
+}
+
+func (s *ProductServer) ListProducts(request *productsv1.ListProductsRequest, stream productsv1.ProductService_ListProductsServer) error {
+	ctx := stream.Context()
+	merchantId, err := parseID(request.MerchantId)
+	if err != nil {
+		return err
+	}
+	merchantProducts, err := s.productService.GetProductsByMerchantId(ctx, merchantId)
+	if err != nil {
+		return statusError(ctx, s.logger, err)
+	}
+	for _, product := range merchantProducts {
+		if err := stream.Send(toProduct(product)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (s *ProductServer) WatchProducts(request *productsv1.WatchProductsRequest, stream productsv1.ProductService_WatchProductsServer) error {
+	ctx := stream.Context()
+	merchantId, err := parseID(request.MerchantId)
+	if err != nil {
+		return err
+	}
+
+	// the bus delivers events synchronously to the writer, so a slow watcher
+	// is dropped rather than allowed to hold writes up
+	pending := make(chan events.ProductEvent, watchBufferSize)
+	fellBehind := make(chan struct{})
+	var once sync.Once
+	unsubscribe := s.eventBus.Subscribe(func(ctx context.Context, event events.ProductEvent) {
+		if event.Product.MerchantId != merchantId {
+			return
+		}
+		select {
+		case pending <- event:
+		default:
+			once.Do(func() { close(fellBehind) })
+		}
+	})
+	defer unsubscribe()
+
+	// the headers tell the client every change from now on will be sent
+	if err := stream.SendHeader(metadata.MD{}); err != nil {
+		return err
+	}
+	for {
+		select {
+		case <-ctx.Done():
+			return status.FromContextError(ctx.Err()).Err()
+		case <-fellBehind:
+			return status.Error(codes.ResourceExhausted, "watcher fell too far behind, watch again")
+		case event := <-pending:
+			if err := stream.Send(toProductEvent(event)); err != nil {
+				return err
+			}
+		}
+	}
+}
+
+func parseID(id string) (uuid.UUID, error) {
+	parsed, err := uuid.Parse(id)
+	if err != nil {
+		return uuid.Nil, invalidArgument(appErrors.ErrInvalidID)
+	}
+	return parsed, nil
+}
+
+func parseProductIDs(merchantId, skuId string) (uuid.UUID, uuid.UUID, error) {
+	parsedMerchantId, err := parseID(merchantId)
+	if err != nil {
+		return uuid.Nil, uuid.Nil, err
+	}
+	parsedSkuId, err := parseID(skuId)
+	if err != nil {
+		return uuid.Nil, uuid.Nil, err
+	}
+	return parsedMerchantId, parsedSkuId, nil
+}
+
+// requireProductFields applies the REST API's rules for the fields every
+// create and update must have.
+func requireProductFields(name, description string, price float64) error {
+	switch {
+	case name == "":
+		return status.Error(codes.InvalidArgument, "name required")
+	case description == "":
+		return status.Error(codes.InvalidArgument, "description required")
+	case price < 0:
+		return status.Error(codes.InvalidArgument, "price cannot be less than zero")
+	}
+	return nil
+}
+
+type productFields struct {
+	skuCode    string
+	gtin       string
+	isbn       string
+	taxClass   domain.TaxClass
+	tags       []string
+	attributes map[string]string
+}
+
+// parseProductFields normalizes the optional fields, leaving empty ones
+// empty. Protobuf cannot tell an empty list or map from a missing one, so
+// empty tags and attributes are left unchanged by updates.
+func parseProductFields(skuCode, gtin, isbn, taxClass string, tags []string, attributes map[string]string) (productFields, error) {
+	var fields productFields
+	var err error
+	if skuCode != "" {
+		if fields.skuCode, err = products.NormalizeSKUCode(skuCode); err != nil {
+			return productFields{}, invalidArgument(err)
+		}
+	}
+	if gtin != "" {
+		if fields.gtin, err = products.NormalizeGTIN(gtin); err != nil {
+			return productFields{}, invalidArgument(err)
+		}
+	}
+	if isbn != "" {
+		if fields.isbn, err = products.NormalizeISBN(isbn); err != nil {
+			return productFields{}, invalidArgument(err)
+		}
+	}
+	if taxClass != "" {
+		if fields.taxClass, err = tax.ParseTaxClass(taxClass); err != nil {
+			return productFields{}, invalidArgument(err)
+		}
+	}
+	if len(tags) > 0 {
+		fields.tags = products.NormalizeTags(tags)
+	}
+	if len(attributes) > 0 {
+		if fields.attributes, err = products.NormalizeAttributes(attributes); err != nil {
+			return productFields{}, invalidArgument(err)
+		}
+	}
+	return fields, nil
+}
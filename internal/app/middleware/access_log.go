@@ -0,0 +1,67 @@
+package middleware
+
+import (
+	"log/slog"
+	"net/http"
+
+	"github.com/go-chi/chi/v5"
+	chiMiddleware "github.com/go-chi/chi/v5/middleware"
+	"github.com/olad5/sal-backend-service/pkg/clock"
+)
+
+// AccessLog logs every request once it is served, with the route pattern it
+// matched rather than its path, so lines for one route can be grouped. Server
+// errors are logged as errors.
+func AccessLog(logger *slog.Logger, clock clock.Clock) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			start := clock.Now()
+			ww := chiMiddleware.NewWrapResponseWriter(w, r.ProtoMajor)
+			next.ServeHTTP(ww, r)
+
+			status := ww.Status()
+			if status == 0 {
+				status = http.StatusOK
+			}
+			attrs := []slog.Attr{
+				slog.String("method", r.Method),
+				slog.String("route", routePattern(r)),
+				slog.String("path", r.URL.Path),
+				slog.Int("status", status),
+				slog.Float64("latency_ms", float64(clock.Now().Sub(start).Microseconds())/1000),
+				slog.Int("bytes", ww.BytesWritten()),
+				slog.String("remote_addr", r.RemoteAddr),
+			}
+			if merchantId := requestMerchantId(r); merchantId != "" {
+				attrs = append(attrs, slog.String("merchant_id", merchantId))
+			}
+
+			level := slog.LevelInfo
+			if status >= http.StatusInternalServerError {
+				level = slog.LevelError
+			}
+			logger.LogAttrs(r.Context(), level, "request", attrs...)
+		})
+	}
+}
+
+func routePattern(r *http.Request) string {
+	if rctx := chi.RouteContext(r.Context()); rctx != nil {
+		if pattern := rctx.RoutePattern(); pattern != "" {
+			return pattern
+		}
+	}
+	return "unmatched"
+}
+
+// requestMerchantId is the merchant from the X-Merchant-ID header or, failing
+// that, the route's merchant_id.
+func requestMerchantId(r *http.Request) string {
+	if merchantId, ok := RequestMerchant(r); ok {
+		return merchantId.String()
+	}
+	if rctx := chi.RouteContext(r.Context()); rctx != nil {
+		return rctx.URLParam("merchant_id")
+	}
+	return ""
+}
@@ -0,0 +1,32 @@
+package middleware
+
+import (
+	"crypto/subtle"
+	"net/http"
+	"strings"
+
+	"github.com/olad5/sal-backend-service/pkg/utils"
+)
+
+// AdminToken only lets through requests that carry the admin token as an
+// Authorization bearer token. token is called on every request, so a reload
+// takes effect straight away, and while it returns "" every request is
+// refused.
+func AdminToken(token func() string) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			expected := token()
+			if expected == "" {
+				utils.ErrorResponse(w, "the admin API is disabled", http.StatusForbidden)
+				return
+			}
+			given, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+			if !ok || subtle.ConstantTimeCompare([]byte(given), []byte(expected)) != 1 {
+				w.Header().Set("WWW-Authenticate", `Bearer realm="admin"`)
+				utils.ErrorResponse(w, "a valid admin token is required", http.StatusUnauthorized)
+				return
+			}
+			next.ServeHTTP(w, r)
+		})
+	}
+}
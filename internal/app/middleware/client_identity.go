@@ -0,0 +1,159 @@
+package middleware
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"sync/atomic"
+
+	"github.com/google/uuid"
+	"github.com/olad5/sal-backend-service/pkg/utils"
+)
+
+// ClientIdentity is who a client certificate belongs to, either a merchant,
+// which may only act for itself, or a partner integration, which may act for
+// any merchant through the X-Merchant-ID header.
+type ClientIdentity struct {
+	// Subject is the certificate subject in RFC 2253 form, as printed by
+	// openssl x509 -subject -nameopt RFC2253, such as "CN=acme-sync,O=Acme"
+	Subject    string    `json:"subject"`
+	MerchantId uuid.UUID `json:"merchant_id"`
+	Partner    string    `json:"partner"`
+}
+
+func (i ClientIdentity) IsPartner() bool {
+	return i.Partner != ""
+}
+
+// ClientIdentities maps client certificate subjects to identities, it can be
+// replaced while requests are served.
+type ClientIdentities struct {
+	bySubject atomic.Pointer[map[string]ClientIdentity]
+}
+
+func NewClientIdentities(identities []ClientIdentity) *ClientIdentities {
+	c := &ClientIdentities{}
+	c.Set(identities)
+	return c
+}
+
+func (c *ClientIdentities) Set(identities []ClientIdentity) {
+	bySubject := make(map[string]ClientIdentity, len(identities))
+	for _, identity := range identities {
+		bySubject[identity.Subject] = identity
+	}
+	c.bySubject.Store(&bySubject)
+}
+
+func (c *ClientIdentities) Lookup(subject string) (ClientIdentity, bool) {
+	identity, ok := (*c.bySubject.Load())[subject]
+	return identity, ok
+}
+
+// LoadClientIdentitiesFile reads a JSON file of the form
+// {"identities": [{"subject": "CN=acme-sync", "merchant_id": "..."}, ...]}.
+// Each identity names either a merchant_id or a partner.
+func LoadClientIdentitiesFile(path string) ([]ClientIdentity, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var file struct {
+		Identities []ClientIdentity `json:"identities"`
+	}
+	if err := json.Unmarshal(data, &file); err != nil {
+		return nil, fmt.Errorf("failed to parse %s: %w", path, err)
+	}
+
+	var errs []error
+	seen := map[string]bool{}
+	for i, identity := range file.Identities {
+		switch {
+		case identity.Subject == "":
+			errs = append(errs, fmt.Errorf("identity %d has no subject", i))
+		case seen[identity.Subject]:
+			errs = append(errs, fmt.Errorf("subject %q is listed more than once", identity.Subject))
+		case (identity.MerchantId == uuid.Nil) == (identity.Partner == ""):
+			errs = append(errs, fmt.Errorf("subject %q must name either a merchant_id or a partner", identity.Subject))
+		}
+		seen[identity.Subject] = true
+	}
+	if err := errors.Join(errs...); err != nil {
+		return nil, fmt.Errorf("invalid identities in %s: %w", path, err)
+	}
+	return file.Identities, nil
+}
+
+// ErrMerchantForbidden is returned when a merchant's client certificate is
+// used to act for another merchant.
+var ErrMerchantForbidden = errors.New("client certificate belongs to another merchant")
+
+type clientIdentityKey struct{}
+
+// IdentityFromContext returns the identity of the verified client certificate
+// the request was made with, if any.
+func IdentityFromContext(ctx context.Context) (ClientIdentity, bool) {
+	identity, ok := ctx.Value(clientIdentityKey{}).(ClientIdentity)
+	return identity, ok
+}
+
+// RequestIdentity returns the identity of the verified client certificate the
+// request was made with, if any.
+func RequestIdentity(r *http.Request) (ClientIdentity, bool) {
+	return IdentityFromContext(r.Context())
+}
+
+// VerifiedMerchant returns the merchant a request was made for with a verified
+// client certificate, the certificate's own merchant or the X-Merchant-ID set
+// by a partner.
+func VerifiedMerchant(r *http.Request) (uuid.UUID, bool) {
+	if _, ok := RequestIdentity(r); !ok {
+		return uuid.Nil, false
+	}
+	return RequestMerchant(r)
+}
+
+// AuthorizeMerchant checks that a request acting for merchantId, as named in
+// its body, path or arguments, may do so. A merchant's client certificate may
+// only act for that merchant, partners and requests without a certificate may
+// act for any.
+func AuthorizeMerchant(ctx context.Context, merchantId uuid.UUID) error {
+	identity, ok := IdentityFromContext(ctx)
+	if !ok || identity.IsPartner() || identity.MerchantId == merchantId {
+		return nil
+	}
+	return ErrMerchantForbidden
+}
+
+// ClientCertificateIdentity maps the request's verified client certificate to
+// its identity. Requests from a merchant's certificate are made as that
+// merchant, a different X-Merchant-ID is refused. Certificates without an
+// identity are refused, requests without a certificate are passed on as they
+// are, the TLS client auth mode decides whether those are allowed.
+func ClientCertificateIdentity(identities *ClientIdentities) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if r.TLS == nil || len(r.TLS.VerifiedChains) == 0 {
+				next.ServeHTTP(w, r)
+				return
+			}
+			subject := r.TLS.PeerCertificates[0].Subject.String()
+			identity, ok := identities.Lookup(subject)
+			if !ok {
+				utils.ErrorResponse(w, fmt.Sprintf("client certificate %q is not mapped to a merchant or partner", subject), http.StatusForbidden)
+				return
+			}
+			if !identity.IsPartner() {
+				if header := r.Header.Get(MerchantHeader); header != "" && header != identity.MerchantId.String() {
+					utils.ErrorResponse(w, ErrMerchantForbidden.Error(), http.StatusForbidden)
+					return
+				}
+				r.Header.Set(MerchantHeader, identity.MerchantId.String())
+			}
+			next.ServeHTTP(w, r.WithContext(context.WithValue(r.Context(), clientIdentityKey{}, identity)))
+		})
+	}
+}
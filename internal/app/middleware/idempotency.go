@@ -0,0 +1,269 @@
+package middleware
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"io"
+	"log/slog"
+	"mime"
+	"mime/multipart"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/olad5/sal-backend-service/internal/domain"
+	"github.com/olad5/sal-backend-service/internal/infra"
+	"github.com/olad5/sal-backend-service/pkg/clock"
+	"github.com/olad5/sal-backend-service/pkg/utils"
+)
+
+const (
+	IdempotencyKeyHeader     = "Idempotency-Key"
+	IdempotentReplayedHeader = "Idempotent-Replayed"
+	DefaultIdempotencyKeyTTL = 24 * time.Hour
+	maxIdempotencyKeyLength  = 255
+	// requests with a key are buffered in full to fingerprint them
+	maxIdempotentBodySize     = 16 << 20
+	errIdempotencyKeyInvalid  = "Idempotency-Key must be between 1 and 255 characters"
+	errIdempotencyKeyMismatch = "Idempotency-Key has already been used with a different request"
+	errIdempotencyKeyInFlight = "a request with this Idempotency-Key is still being processed"
+)
+
+// Idempotency replays the first response to a write carrying an
+// Idempotency-Key header when the same request is retried with the same key.
+// Keys are scoped to the merchant the request is made for, see
+// idempotencyScope, and expire after ttl. Reusing a key for
+// a different request is rejected, and server errors are not stored so that
+// they can be retried.
+func Idempotency(repo infra.IdempotencyRepository, clock clock.Clock, logger *slog.Logger, ttl time.Duration) (func(http.Handler) http.Handler, error) {
+	if repo == nil {
+		return nil, errors.New("Idempotency middleware failed to initialize, repo is nil")
+	}
+	if clock == nil {
+		return nil, errors.New("Idempotency middleware failed to initialize, clock is nil")
+	}
+	if logger == nil {
+		return nil, errors.New("Idempotency middleware failed to initialize, logger is nil")
+	}
+	if ttl <= 0 {
+		ttl = DefaultIdempotencyKeyTTL
+	}
+
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			key, ok := r.Header[http.CanonicalHeaderKey(IdempotencyKeyHeader)]
+			if !ok || isSafeMethod(r.Method) {
+				next.ServeHTTP(w, r)
+				return
+			}
+			if len(key) != 1 || key[0] == "" || len(key[0]) > maxIdempotencyKeyLength {
+				utils.ErrorResponse(w, errIdempotencyKeyInvalid, http.StatusBadRequest)
+				return
+			}
+
+			ctx := r.Context()
+			body := []byte{}
+			if r.Body != nil {
+				var err error
+				body, err = io.ReadAll(io.LimitReader(r.Body, maxIdempotentBodySize+1))
+				if err != nil {
+					utils.ErrorResponse(w, "failed to read request body", http.StatusBadRequest)
+					return
+				}
+				if len(body) > maxIdempotentBodySize {
+					utils.ErrorResponse(w, "request body too large", http.StatusRequestEntityTooLarge)
+					return
+				}
+				r.Body = io.NopCloser(bytes.NewReader(body))
+			}
+
+			now := clock.Now()
+			record := domain.IdempotencyRecord{
+				Scope:       idempotencyScope(r, body),
+				Key:         key[0],
+				RequestHash: requestHash(r, body),
+				CreatedAt:   now,
+				ExpiresAt:   now.Add(ttl),
+			}
+			existing, created, err := repo.CreateIdempotencyRecord(ctx, record)
+			if err != nil {
+				utils.ErrorResponse(w, "something went wrong", http.StatusInternalServerError)
+				return
+			}
+			if !created {
+				switch {
+				case existing.RequestHash != record.RequestHash:
+					utils.ErrorResponse(w, errIdempotencyKeyMismatch, http.StatusUnprocessableEntity)
+				case !existing.Completed:
+					utils.ErrorResponse(w, errIdempotencyKeyInFlight, http.StatusConflict)
+				default:
+					replay(w, existing)
+				}
+				return
+			}
+
+			recorder := &responseRecorder{ResponseWriter: w, statusCode: http.StatusOK}
+			defer func() {
+				// server errors and panics release the key so the request
+				// can be retried
+				if p := recover(); p != nil || recorder.statusCode >= http.StatusInternalServerError {
+					if err := repo.DeleteIdempotencyRecord(ctx, record.Scope, record.Key); err != nil {
+						logger.ErrorContext(ctx, "failed to release idempotency key", "key", record.Key, "error", err)
+					}
+					if p != nil {
+						panic(p)
+					}
+					return
+				}
+				record.Completed = true
+				record.StatusCode = recorder.statusCode
+				record.Header = replayableHeader(w.Header())
+				record.Body = recorder.body.Bytes()
+				if err := repo.UpdateIdempotencyRecord(ctx, record); err != nil {
+					logger.ErrorContext(ctx, "failed to store idempotent response", "key", record.Key, "error", err)
+				}
+			}()
+			next.ServeHTTP(recorder, r)
+		})
+	}, nil
+}
+
+// idempotencyScope identifies the merchant a request is made for, so that
+// merchants sharing a client IP cannot replay each other's responses: the
+// merchant of a verified client certificate, else the merchant_id in the body
+// or the path. Requests without a merchant are scoped to their client IP.
+// X-Merchant-ID alone is not trusted.
+func idempotencyScope(r *http.Request, body []byte) string {
+	merchantId, ok := VerifiedMerchant(r)
+	if !ok {
+		merchantId, ok = bodyMerchant(r, body)
+	}
+	if !ok {
+		merchantId, ok = pathMerchant(r.URL.Path)
+	}
+	if !ok {
+		return ClientIP(r)
+	}
+	return "merchant:" + merchantId.String()
+}
+
+// bodyMerchant returns the merchant_id field of a JSON or multipart body.
+func bodyMerchant(r *http.Request, body []byte) (uuid.UUID, bool) {
+	mediaType, params, err := mime.ParseMediaType(r.Header.Get("Content-Type"))
+	if err != nil {
+		return uuid.Nil, false
+	}
+	var id string
+	switch mediaType {
+	case "application/json":
+		var request struct {
+			MerchantId string `json:"merchant_id"`
+		}
+		if json.Unmarshal(body, &request) != nil {
+			return uuid.Nil, false
+		}
+		id = request.MerchantId
+	case "multipart/form-data":
+		reader := multipart.NewReader(bytes.NewReader(body), params["boundary"])
+		for {
+			part, err := reader.NextPart()
+			if err != nil {
+				return uuid.Nil, false
+			}
+			if part.FormName() == "merchant_id" {
+				value, _ := io.ReadAll(io.LimitReader(part, 64))
+				id = string(value)
+				break
+			}
+		}
+	}
+	merchantId, err := uuid.Parse(id)
+	return merchantId, err == nil
+}
+
+// pathMerchant returns the merchant of a /merchants/{merchant_id} path.
+func pathMerchant(path string) (uuid.UUID, bool) {
+	segments := strings.Split(path, "/")
+	for i := 0; i+1 < len(segments); i++ {
+		if segments[i] == "merchants" {
+			merchantId, err := uuid.Parse(segments[i+1])
+			return merchantId, err == nil
+		}
+	}
+	return uuid.Nil, false
+}
+
+// perRequestHeaders describe the request being answered rather than the
+// response, so a replay keeps the retry's own values: its request ID, rate
+// limit state and trace context.
+var perRequestHeaders = []string{
+	RequestIDHeader,
+	"RateLimit-Limit",
+	"RateLimit-Remaining",
+	"RateLimit-Reset",
+	"Retry-After",
+	"Traceparent",
+	"Tracestate",
+}
+
+// replayableHeader is the part of a response's header stored for replays.
+func replayableHeader(header http.Header) http.Header {
+	stored := header.Clone()
+	for _, name := range perRequestHeaders {
+		stored.Del(name)
+	}
+	return stored
+}
+
+func replay(w http.ResponseWriter, record domain.IdempotencyRecord) {
+	for name, values := range record.Header {
+		w.Header()[name] = values
+	}
+	w.Header().Set(IdempotentReplayedHeader, "true")
+	w.WriteHeader(record.StatusCode)
+	if _, err := w.Write(record.Body); err != nil {
+		slog.Warn("failed to send response", "error", err)
+	}
+}
+
+// requestHash fingerprints everything that makes a retry the same request.
+func requestHash(r *http.Request, body []byte) string {
+	hash := sha256.New()
+	hash.Write([]byte(r.Method + " " + r.URL.RequestURI() + "\n"))
+	hash.Write(body)
+	return hex.EncodeToString(hash.Sum(nil))
+}
+
+func isSafeMethod(method string) bool {
+	switch method {
+	case http.MethodGet, http.MethodHead, http.MethodOptions:
+		return true
+	default:
+		return false
+	}
+}
+
+type responseRecorder struct {
+	http.ResponseWriter
+	statusCode  int
+	wroteHeader bool
+	body        bytes.Buffer
+}
+
+func (r *responseRecorder) WriteHeader(statusCode int) {
+	if !r.wroteHeader {
+		r.statusCode = statusCode
+		r.wroteHeader = true
+	}
+	r.ResponseWriter.WriteHeader(statusCode)
+}
+
+func (r *responseRecorder) Write(data []byte) (int, error) {
+	r.wroteHeader = true
+	r.body.Write(data)
+	return r.ResponseWriter.Write(data)
+}
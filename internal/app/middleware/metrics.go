@@ -0,0 +1,40 @@
+package middleware
+
+import (
+	"net/http"
+	"strconv"
+
+	chiMiddleware "github.com/go-chi/chi/v5/middleware"
+	"github.com/olad5/sal-backend-service/pkg/clock"
+	"github.com/olad5/sal-backend-service/pkg/metrics"
+)
+
+// Metrics counts and times every request by its route pattern, so paths
+// carrying IDs do not each become a series of their own.
+func Metrics(registry *metrics.Registry, clock clock.Clock) func(http.Handler) http.Handler {
+	requests := registry.NewCounterVec("sal_http_requests_total",
+		"HTTP requests served, by route pattern and status.", "method", "route", "status")
+	duration := registry.NewHistogramVec("sal_http_request_duration_seconds",
+		"Time taken to serve HTTP requests, by route pattern.", metrics.DefaultBuckets, "method", "route")
+	inFlight := registry.NewGauge("sal_http_requests_in_flight",
+		"HTTP requests currently being served.")
+
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			inFlight.Inc()
+			defer inFlight.Dec()
+
+			start := clock.Now()
+			ww := chiMiddleware.NewWrapResponseWriter(w, r.ProtoMajor)
+			next.ServeHTTP(ww, r)
+
+			status := ww.Status()
+			if status == 0 {
+				status = http.StatusOK
+			}
+			route := routePattern(r)
+			requests.WithLabelValues(r.Method, route, strconv.Itoa(status)).Inc()
+			duration.WithLabelValues(r.Method, route).Observe(clock.Now().Sub(start).Seconds())
+		})
+	}
+}
@@ -0,0 +1,34 @@
+// Package middleware holds the HTTP middleware shared by the API routes.
+package middleware
+
+import (
+	"net"
+	"net/http"
+
+	"github.com/google/uuid"
+)
+
+// MerchantHeader identifies the merchant making a request. It is only trusted
+// on requests made with a verified client certificate, see
+// ClientCertificateIdentity and VerifiedMerchant.
+const MerchantHeader = "X-Merchant-ID"
+
+// RequestMerchant returns the merchant making the request, if any.
+func RequestMerchant(r *http.Request) (uuid.UUID, bool) {
+	merchantId, err := uuid.Parse(r.Header.Get(MerchantHeader))
+	if err != nil {
+		return uuid.Nil, false
+	}
+	return merchantId, true
+}
+
+// ClientIP identifies the client IP a request came from. It reads only
+// RemoteAddr, as X-Forwarded-For can be set by any client, so behind a proxy or
+// load balancer every client shares the proxy's address and its IP rate limit.
+func ClientIP(r *http.Request) string {
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		return "ip:" + r.RemoteAddr
+	}
+	return "ip:" + host
+}
@@ -0,0 +1,38 @@
+package router
+
+import (
+	"encoding/json"
+	"log/slog"
+	"net/http"
+
+	"github.com/olad5/sal-backend-service/internal/health"
+)
+
+// healthHandler writes a health report as it is, without the response
+// envelope, and answers 503 when it is not ok so probes only need the status
+// code.
+func healthHandler(report func(r *http.Request) health.Report) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		result := report(r)
+		w.Header().Set("Content-Type", "application/json")
+		w.Header().Set("Cache-Control", "no-store")
+		if result.Status != health.StatusOK {
+			w.WriteHeader(http.StatusServiceUnavailable)
+		}
+		if err := json.NewEncoder(w).Encode(result); err != nil {
+			slog.Warn("failed to send response", "error", err)
+		}
+	}
+}
+
+func fetchLiveness(checker *health.Checker) http.HandlerFunc {
+	return healthHandler(func(r *http.Request) health.Report {
+		return checker.Liveness(r.Context())
+	})
+}
+
+func fetchReadiness(checker *health.Checker) http.HandlerFunc {
+	return healthHandler(func(r *http.Request) health.Report {
+		return checker.Readiness(r.Context())
+	})
+}
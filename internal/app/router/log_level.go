@@ -0,0 +1,47 @@
+package router
+
+import (
+	"encoding/json"
+	"net/http"
+	"strings"
+
+	"github.com/olad5/sal-backend-service/internal/logging"
+	appErrors "github.com/olad5/sal-backend-service/pkg/errors"
+	"github.com/olad5/sal-backend-service/pkg/utils"
+)
+
+type logLevelDTO struct {
+	Level string `json:"level"`
+}
+
+func toLogLevelDTO(logger *logging.Logger) logLevelDTO {
+	return logLevelDTO{Level: strings.ToLower(logger.Level().String())}
+}
+
+func fetchLogLevel(logger *logging.Logger) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		utils.SuccessResponse(w, "Log level retrieved successfully", toLogLevelDTO(logger))
+	}
+}
+
+// updateLogLevel changes the level of every logger the router built, so
+// debug logs can be turned on while investigating without a restart.
+func updateLogLevel(logger *logging.Logger) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		var request logLevelDTO
+		if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+			utils.ErrorResponse(w, appErrors.ErrInvalidJson, http.StatusBadRequest)
+			return
+		}
+		level, err := logging.ParseLevel(request.Level)
+		if err != nil {
+			utils.ErrorResponse(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+
+		previous := logger.Level()
+		logger.SetLevel(level)
+		logger.InfoContext(r.Context(), "log level changed", "from", previous.String(), "to", level.String())
+		utils.SuccessResponse(w, "Log level updated successfully", toLogLevelDTO(logger))
+	}
+}
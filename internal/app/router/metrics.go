@@ -0,0 +1,47 @@
+package router
+
+import (
+	"context"
+	"log/slog"
+	"net/http"
+
+	"github.com/olad5/sal-backend-service/internal/domain"
+	"github.com/olad5/sal-backend-service/internal/usecases/products"
+	"github.com/olad5/sal-backend-service/pkg/metrics"
+)
+
+// registerCatalogMetrics adds the business gauges, which are counted from the
+// catalogue each time the metrics are scraped.
+func registerCatalogMetrics(registry *metrics.Registry, productService *products.ProductService) {
+	registry.NewGaugeFunc("sal_products", "Products in every catalogue, by pricing status.", []string{"status"},
+		func(ctx context.Context) ([]metrics.Sample, error) {
+			stats, err := productService.GetCatalogStats(ctx)
+			if err != nil {
+				return nil, err
+			}
+			samples := make([]metrics.Sample, 0, len(domain.ProductStatuses))
+			for _, status := range domain.ProductStatuses {
+				samples = append(samples, metrics.Sample{
+					LabelValues: []string{string(status)},
+					Value:       float64(stats.ProductsByStatus[status]),
+				})
+			}
+			return samples, nil
+		})
+	registry.NewGaugeFunc("sal_merchants", "Merchants with at least one product.", nil,
+		func(ctx context.Context) ([]metrics.Sample, error) {
+			stats, err := productService.GetCatalogStats(ctx)
+			if err != nil {
+				return nil, err
+			}
+			return []metrics.Sample{{Value: float64(stats.Merchants)}}, nil
+		})
+}
+
+// metricsHandler serves the registry, logging collectors that failed rather
+// than failing the scrape.
+func metricsHandler(registry *metrics.Registry, logger *slog.Logger) http.Handler {
+	return metrics.Handler(registry, func(ctx context.Context, err error) {
+		logger.ErrorContext(ctx, "failed to collect metrics", "error", err)
+	})
+}
@@ -0,0 +1,342 @@
+package router
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"time"
+
+	currencyHandlers "github.com/olad5/sal-backend-service/internal/handlers/currency"
+	planHandlers "github.com/olad5/sal-backend-service/internal/handlers/plans"
+	pricingHandlers "github.com/olad5/sal-backend-service/internal/handlers/pricing"
+	handlers "github.com/olad5/sal-backend-service/internal/handlers/products"
+	taxHandlers "github.com/olad5/sal-backend-service/internal/handlers/tax"
+	"github.com/olad5/sal-backend-service/internal/health"
+	"github.com/olad5/sal-backend-service/internal/usecases/currency"
+	"github.com/olad5/sal-backend-service/pkg/openapi"
+)
+
+const apiVersion = "1.0.0"
+
+// apiRoute describes one JSON endpoint under /api. Body is the request body
+// and Data the data of the success envelope, both nil when there is none.
+type apiRoute struct {
+	method      string
+	path        string
+	operationId string
+	summary     string
+	tag         string
+	params      []openapi.Parameter
+	body        interface{}
+	data        interface{}
+	errors      []int
+}
+
+type errorResponse struct {
+	Status  bool   `json:"status"`
+	Message string `json:"message"`
+}
+
+// apiSpec describes every route registered in NewHttpRouter. Keep it in step
+// with the router: the integration tests fail for routes without an entry.
+func apiSpec() *openapi.Document {
+	doc := openapi.New("SAL Backend Service", apiVersion, "Product catalogue API for SAL merchants.")
+	errorSchema := doc.Define("ErrorResponse", errorResponse{})
+
+	skuId := openapi.PathParam("sku_id", "Internal product ID, a UUID")
+	merchantId := openapi.PathParam("merchant_id", "Merchant ID, a UUID")
+	currencyParam := openapi.QueryParam("currency", "Also return prices converted to this ISO 4217 currency", &openapi.Schema{Type: "string"})
+	regionParam := openapi.QueryParam("region", "Also return prices taxed for this region", &openapi.Schema{Type: "string"})
+	taxDisplayParam := openapi.QueryParam("tax_display", "Whether taxed prices are reported gross or net", &openapi.Schema{Type: "string", Enum: []string{"inclusive", "exclusive"}})
+	facetsParam := openapi.QueryParam("facets", "Comma separated facets to aggregate", &openapi.Schema{Type: "string", Enum: []string{"price", "status"}})
+	priceBucketsParam := openapi.QueryParam("price_buckets", "Comma separated price bucket bounds", &openapi.Schema{Type: "string"})
+	limitParam := openapi.QueryParam("limit", "Maximum number of results", &openapi.Schema{Type: "integer"})
+	merchantOnly := struct {
+		MerchantId string `json:"merchant_id"`
+	}{}
+	productBody := struct {
+		SKUID       string            `json:"sku_id,omitempty"`
+		SKUCode     string            `json:"sku_code,omitempty"`
+		GTIN        string            `json:"gtin,omitempty"`
+		ISBN        string            `json:"isbn,omitempty"`
+		MerchantId  string            `json:"merchant_id"`
+		Name        string            `json:"name"`
+		Description string            `json:"description"`
+		Price       float64           `json:"price"`
+		TaxClass    string            `json:"tax_class,omitempty"`
+		Category    string            `json:"category,omitempty"`
+		Tags        []string          `json:"tags,omitempty"`
+		Attributes  map[string]string `json:"attributes,omitempty"`
+	}{}
+
+	routes := []apiRoute{
+		{http.MethodPost, "/api/products", "createProduct", "Create a product", "products", nil, productBody, handlers.ProductDTO{}, []int{400, 403, 409}},
+		{http.MethodPatch, "/api/products/{sku_id}", "editProduct", "Edit a product", "products", []openapi.Parameter{skuId}, productBody, handlers.ProductDTO{}, []int{400, 404, 409}},
+		{http.MethodDelete, "/api/products/{sku_id}", "deleteProduct", "Delete a product and its images", "products", []openapi.Parameter{skuId}, merchantOnly, nil, []int{400, 404}},
+		{http.MethodPost, "/api/products/{sku_id}/price-changes", "schedulePriceChange", "Schedule a change to the regular price", "pricing", []openapi.Parameter{skuId}, struct {
+			MerchantId  string    `json:"merchant_id"`
+			Price       float64   `json:"price"`
+			EffectiveAt time.Time `json:"effective_at"`
+		}{}, handlers.ProductDTO{}, []int{400, 404}},
+		{http.MethodPost, "/api/products/{sku_id}/sales", "scheduleSale", "Schedule a time boxed sale price", "pricing", []openapi.Parameter{skuId}, struct {
+			MerchantId string    `json:"merchant_id"`
+			Price      float64   `json:"price"`
+			StartsAt   time.Time `json:"starts_at"`
+			EndsAt     time.Time `json:"ends_at"`
+		}{}, handlers.ProductDTO{}, []int{400, 404, 409}},
+		{http.MethodDelete, "/api/products/{sku_id}/price-schedules/{schedule_id}", "cancelPriceSchedule", "Cancel a scheduled price change or sale", "pricing", []openapi.Parameter{skuId, openapi.PathParam("schedule_id", "Price change or sale ID")}, merchantOnly, handlers.ProductDTO{}, []int{400, 404}},
+		{http.MethodPatch, "/api/products/{sku_id}/images/{image_id}", "editProductImage", "Edit an image's alt text, position or primary flag", "media", []openapi.Parameter{skuId, openapi.PathParam("image_id", "Image ID")}, struct {
+			MerchantId string  `json:"merchant_id"`
+			AltText    *string `json:"alt_text"`
+			Position   *int    `json:"position"`
+			IsPrimary  bool    `json:"is_primary,omitempty"`
+		}{}, handlers.ProductImageDTO{}, []int{400, 404}},
+		{http.MethodDelete, "/api/products/{sku_id}/images/{image_id}", "deleteProductImage", "Delete an image", "media", []openapi.Parameter{skuId, openapi.PathParam("image_id", "Image ID")}, merchantOnly, nil, []int{400, 404}},
+		{http.MethodGet, "/api/merchants/{merchant_id}/products", "fetchMerchantProducts", "List a merchant's products", "products", []openapi.Parameter{
+			merchantId,
+			openapi.QueryParam("gtin", "Only return the product with this GTIN or ISBN-13", &openapi.Schema{Type: "string"}),
+			openapi.QueryParam("isbn", "Only return the product with this ISBN-10 or ISBN-13", &openapi.Schema{Type: "string"}),
+			currencyParam, regionParam, taxDisplayParam, facetsParam, priceBucketsParam,
+		}, nil, handlers.ProductPagedDTO{}, []int{400, 404}},
+		{http.MethodGet, "/api/merchants/{merchant_id}/products/search", "searchMerchantProducts", "Full text search over a merchant's products", "products", []openapi.Parameter{
+			merchantId,
+			openapi.QueryParam("q", "Search query, quoted phrases and trailing * prefixes are supported", &openapi.Schema{Type: "string"}),
+			limitParam,
+			openapi.QueryParam("offset", "Number of results to skip", &openapi.Schema{Type: "integer"}),
+			currencyParam, regionParam, taxDisplayParam, facetsParam, priceBucketsParam,
+		}, nil, handlers.ProductSearchPagedDTO{}, []int{400}},
+		{http.MethodGet, "/api/merchants/{merchant_id}/products/suggest", "suggestProductNames", "Typo tolerant product name suggestions", "products", []openapi.Parameter{
+			merchantId,
+			openapi.QueryParam("prefix", "What the user has typed so far", &openapi.Schema{Type: "string"}),
+			limitParam,
+		}, nil, handlers.ProductSuggestionsDTO{}, []int{400}},
+		{http.MethodGet, "/api/merchants/{merchant_id}/products/by-code/{code}", "fetchProductByCode", "Fetch a product by its SKU code", "products", []openapi.Parameter{merchantId, openapi.PathParam("code", "SKU code, matched case insensitively"), currencyParam, regionParam, taxDisplayParam}, nil, handlers.ProductDTO{}, []int{400, 404}},
+		{http.MethodGet, "/api/merchants/{merchant_id}/sku-code-pattern", "fetchSKUCodePattern", "Fetch the pattern SKU codes are generated from", "products", []openapi.Parameter{merchantId}, nil, handlers.SKUCodePatternDTO{}, []int{400}},
+		{http.MethodPut, "/api/merchants/{merchant_id}/sku-code-pattern", "updateSKUCodePattern", "Set the pattern SKU codes are generated from", "products", []openapi.Parameter{merchantId}, struct {
+			Pattern string `json:"pattern"`
+		}{}, handlers.SKUCodePatternDTO{}, []int{400}},
+		{http.MethodPost, "/api/merchants/{merchant_id}/pricing-rules", "createPricingRule", "Create a pricing rule", "pricing", []openapi.Parameter{merchantId}, struct {
+			SKUID         string  `json:"sku_id,omitempty"`
+			Category      string  `json:"category,omitempty"`
+			CustomerGroup string  `json:"customer_group,omitempty"`
+			MinQuantity   *int    `json:"min_quantity"`
+			Type          string  `json:"type"`
+			Value         float64 `json:"value"`
+		}{}, pricingHandlers.PricingRuleDTO{}, []int{400, 404}},
+		{http.MethodGet, "/api/merchants/{merchant_id}/pricing-rules", "fetchMerchantPricingRules", "List a merchant's pricing rules", "pricing", []openapi.Parameter{merchantId}, nil, pricingHandlers.PricingRulePagedDTO{}, []int{400}},
+		{http.MethodDelete, "/api/merchants/{merchant_id}/pricing-rules/{rule_id}", "deletePricingRule", "Delete a pricing rule", "pricing", []openapi.Parameter{merchantId, openapi.PathParam("rule_id", "Pricing rule ID")}, nil, nil, []int{400, 404}},
+		{http.MethodPost, "/api/products/{sku_id}/quote", "quoteProductPrice", "Quote a product's price for a quantity and customer group", "pricing", []openapi.Parameter{skuId}, struct {
+			Quantity      int    `json:"quantity"`
+			CustomerGroup string `json:"customer_group,omitempty"`
+		}{}, pricingHandlers.PriceQuoteDTO{}, []int{400, 404}},
+		{http.MethodGet, "/api/exchange-rates", "fetchExchangeRates", "Fetch the latest exchange rate table", "currency", nil, nil, currencyHandlers.ExchangeRateTableDTO{}, []int{404}},
+		{http.MethodGet, "/api/exchange-rates/{version}", "fetchExchangeRatesByVersion", "Fetch an earlier exchange rate table", "currency", []openapi.Parameter{openapi.PathParam("version", "Table version, a positive integer")}, nil, currencyHandlers.ExchangeRateTableDTO{}, []int{400, 404}},
+		{http.MethodGet, "/api/tax-regions", "fetchTaxRegions", "List tax regions and their rates", "tax", nil, nil, taxHandlers.TaxRegionsDTO{}, nil},
+		{http.MethodGet, "/api/plans", "fetchPlans", "List the available plans", "plans", nil, nil, planHandlers.PlansDTO{}, nil},
+		{http.MethodGet, "/api/merchants/{merchant_id}/usage", "fetchMerchantUsage", "Show a merchant's usage against their plan", "plans", []openapi.Parameter{merchantId}, nil, planHandlers.PlanUsageDTO{}, []int{400}},
+	}
+
+	for _, route := range routes {
+		op := openapi.Operation{
+			OperationID: route.operationId,
+			Summary:     route.summary,
+			Tags:        []string{route.tag},
+			Parameters:  route.params,
+			Responses: map[string]openapi.Response{
+				"200": openapi.JSONResponse("Success", envelope(doc, route.data)),
+			},
+		}
+		if route.body != nil {
+			op.RequestBody = openapi.JSONBody(doc.SchemaOf(route.body))
+		}
+		addAPIResponses(&op, route.method, errorSchema, route.errors...)
+		doc.Add(route.method, route.path, op)
+	}
+
+	// admin routes are not rate limited or idempotent, but need the admin token
+	adminRoutes := []apiRoute{
+		{http.MethodGet, "/admin/log-level", "fetchLogLevel", "Fetch the minimum level logs are written at", "admin", nil, nil, logLevelDTO{}, nil},
+		{http.MethodPut, "/admin/log-level", "updateLogLevel", "Change the minimum log level without a restart", "admin", nil, logLevelDTO{}, logLevelDTO{}, []int{400}},
+		{http.MethodPut, "/admin/merchants/{merchant_id}/plan", "assignMerchantPlan", "Move a merchant to a plan", "plans", []openapi.Parameter{merchantId}, struct {
+			Plan string `json:"plan"`
+		}{}, planHandlers.PlanDTO{}, []int{400, 404}},
+		{http.MethodPut, "/admin/exchange-rates", "updateExchangeRates", "Publish a new exchange rate table version", "currency", nil, currency.ExchangeRatesFile{}, currencyHandlers.ExchangeRateTableDTO{}, []int{400}},
+		{http.MethodPut, "/admin/tax-regions/{region}", "updateTaxRegion", "Set a region's tax rates", "tax", []openapi.Parameter{openapi.PathParam("region", "Region code such as DE or GB")}, struct {
+			Rates map[string]float64 `json:"rates"`
+		}{}, taxHandlers.TaxRegionDTO{}, []int{400}},
+	}
+	for _, route := range adminRoutes {
+		op := openapi.Operation{
+			OperationID: route.operationId,
+			Summary:     route.summary,
+			Tags:        []string{route.tag},
+			Parameters:  route.params,
+			Responses: map[string]openapi.Response{
+				"200": openapi.JSONResponse("Success", envelope(doc, route.data)),
+			},
+		}
+		if route.body != nil {
+			op.RequestBody = openapi.JSONBody(doc.SchemaOf(route.body))
+		}
+		addAdminResponses(&op, errorSchema, route.errors...)
+		doc.Add(route.method, route.path, op)
+	}
+
+	upload := openapi.Operation{
+		OperationID: "uploadProductImage",
+		Summary:     "Upload a product image",
+		Tags:        []string{"media"},
+		Parameters:  []openapi.Parameter{skuId},
+		RequestBody: &openapi.RequestBody{Required: true, Content: map[string]openapi.MediaType{
+			"multipart/form-data": {Schema: &openapi.Schema{
+				Type: "object",
+				Properties: map[string]*openapi.Schema{
+					"merchant_id": {Type: "string", Format: "uuid"},
+					"image":       {Type: "string", Format: "binary", Description: "JPEG, PNG or GIF"},
+					"alt_text":    {Type: "string"},
+					"is_primary":  {Type: "boolean"},
+				},
+				Required: []string{"merchant_id", "image"},
+			}},
+		}},
+		Responses: map[string]openapi.Response{
+			"200": openapi.JSONResponse("Success", envelope(doc, handlers.ProductImageDTO{})),
+		},
+	}
+	addAPIResponses(&upload, http.MethodPost, errorSchema, 400, 403, 404, 413, 415)
+	doc.Add(http.MethodPost, "/api/products/{sku_id}/images", upload)
+
+	doc.Add(http.MethodGet, "/", openapi.Operation{
+		OperationID: "live",
+		Summary:     "Report that the service is running",
+		Tags:        []string{"service"},
+		Responses: map[string]openapi.Response{
+			"200": {Description: "Success", Content: map[string]openapi.MediaType{"text/plain": {Schema: &openapi.Schema{Type: "string"}}}},
+		},
+	})
+	doc.Add(http.MethodGet, "/openapi.json", openapi.Operation{
+		OperationID: "openAPISpec",
+		Summary:     "This document",
+		Tags:        []string{"service"},
+		Responses: map[string]openapi.Response{
+			"200": openapi.JSONResponse("Success", &openapi.Schema{Type: "object"}),
+		},
+	})
+	healthReport := doc.SchemaOf(health.Report{})
+	for _, probe := range []struct{ path, operationId, summary string }{
+		{"/healthz", "fetchLiveness", "Whether the service is alive, failing when only a restart would help"},
+		{"/readyz", "fetchReadiness", "Whether the service is ready for traffic, failing while starting, draining or when a component is down"},
+	} {
+		doc.Add(http.MethodGet, probe.path, openapi.Operation{
+			OperationID: probe.operationId,
+			Summary:     probe.summary,
+			Tags:        []string{"service"},
+			Responses: map[string]openapi.Response{
+				"200": openapi.JSONResponse("Every component is ok", healthReport),
+				"503": openapi.JSONResponse(http.StatusText(http.StatusServiceUnavailable), healthReport),
+			},
+		})
+	}
+	doc.Add(http.MethodGet, "/metrics", openapi.Operation{
+		OperationID: "fetchMetrics",
+		Summary:     "Request, repository and catalogue metrics in the Prometheus text format",
+		Tags:        []string{"service"},
+		Responses: map[string]openapi.Response{
+			"200": {Description: "Success", Content: map[string]openapi.MediaType{"text/plain": {Schema: &openapi.Schema{Type: "string"}}}},
+		},
+	})
+	graphqlResult := &openapi.Schema{
+		Type: "object",
+		Properties: map[string]*openapi.Schema{
+			"data":   {Type: "object", Nullable: true},
+			"errors": {Type: "array", Items: &openapi.Schema{Type: "object"}},
+		},
+	}
+	doc.Add(http.MethodPost, "/graphql", openapi.Operation{
+		OperationID: "graphql",
+		Summary:     "Run a GraphQL query or mutation on products",
+		Tags:        []string{"products"},
+		RequestBody: openapi.JSONBody(&openapi.Schema{
+			Type: "object",
+			Properties: map[string]*openapi.Schema{
+				"query":         {Type: "string"},
+				"operationName": {Type: "string"},
+				"variables":     {Type: "object"},
+			},
+			Required: []string{"query"},
+		}),
+		Responses: map[string]openapi.Response{
+			"200": openapi.JSONResponse("The result, with any errors from resolving fields", graphqlResult),
+			"400": openapi.JSONResponse("The query does not parse, is invalid or exceeds the depth or complexity limit", graphqlResult),
+			"429": openapi.JSONResponse(http.StatusText(http.StatusTooManyRequests), errorSchema),
+		},
+	})
+	for _, media := range []struct{ suffix, operationId, summary string }{
+		{"", "serveProductImage", "Serve a product image"},
+		{"/thumbnail", "serveProductImageThumbnail", "Serve a product image's thumbnail"},
+	} {
+		doc.Add(http.MethodGet, "/media/products/{sku_id}/images/{image_id}"+media.suffix, openapi.Operation{
+			OperationID: media.operationId,
+			Summary:     media.summary,
+			Tags:        []string{"media"},
+			Parameters:  []openapi.Parameter{skuId, openapi.PathParam("image_id", "Image ID")},
+			Responses: map[string]openapi.Response{
+				"200": {Description: "The image", Content: map[string]openapi.MediaType{"image/*": {Schema: &openapi.Schema{Type: "string", Format: "binary"}}}},
+				"404": {Description: http.StatusText(http.StatusNotFound)},
+			},
+		})
+	}
+	return doc
+}
+
+// envelope wraps data in the {status, message, data} body of
+// utils.SuccessResponse.
+func envelope(doc *openapi.Document, data interface{}) *openapi.Schema {
+	dataSchema := &openapi.Schema{Nullable: true}
+	if data != nil {
+		dataSchema = doc.SchemaOf(data)
+	}
+	return &openapi.Schema{
+		Type: "object",
+		Properties: map[string]*openapi.Schema{
+			"status":  {Type: "boolean"},
+			"message": {Type: "string"},
+			"data":    dataSchema,
+		},
+		Required: []string{"status", "message", "data"},
+	}
+}
+
+// addAPIResponses adds the error responses every /api route can produce, and
+// the Idempotency-Key header on writes, to op.
+func addAPIResponses(op *openapi.Operation, method string, errorSchema *openapi.Schema, codes ...int) {
+	codes = append(codes, http.StatusForbidden, http.StatusTooManyRequests, http.StatusInternalServerError)
+	if method != http.MethodGet {
+		codes = append(codes, http.StatusConflict, http.StatusUnprocessableEntity)
+		op.Parameters = append(op.Parameters, openapi.HeaderParam("Idempotency-Key", "Replays the first response when a write is retried with the same key"))
+	}
+	for _, code := range codes {
+		op.Responses[strconv.Itoa(code)] = openapi.JSONResponse(http.StatusText(code), errorSchema)
+	}
+}
+
+// addAdminResponses documents codes and the responses of every admin route.
+func addAdminResponses(op *openapi.Operation, errorSchema *openapi.Schema, codes ...int) {
+	op.Responses[strconv.Itoa(http.StatusUnauthorized)] = openapi.JSONResponse("The admin bearer token is missing or wrong", errorSchema)
+	op.Responses[strconv.Itoa(http.StatusForbidden)] = openapi.JSONResponse("The admin API is disabled", errorSchema)
+	codes = append(codes, http.StatusInternalServerError)
+	for _, code := range codes {
+		op.Responses[strconv.Itoa(code)] = openapi.JSONResponse(http.StatusText(code), errorSchema)
+	}
+}
+
+func openAPIHandler(doc *openapi.Document) (http.HandlerFunc, error) {
+	body, err := json.Marshal(doc)
+	if err != nil {
+		return nil, err
+	}
+	return func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.Write(body)
+	}, nil
+}
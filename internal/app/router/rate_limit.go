@@ -0,0 +1,70 @@
+package router
+
+import (
+	"net/http"
+	"sync/atomic"
+	"time"
+
+	"github.com/olad5/sal-backend-service/internal/app/middleware"
+	"github.com/olad5/sal-backend-service/internal/config"
+	"github.com/olad5/sal-backend-service/internal/usecases/plans"
+	"github.com/olad5/sal-backend-service/pkg/ratelimit"
+)
+
+const (
+	// bounds the limiter's memory; idle buckets are full again after a
+	// minute, so dropping them after ten loses nothing
+	rateLimitMaxKeys     = 100000
+	rateLimitIdleTimeout = 10 * time.Minute
+)
+
+type rateLimits struct {
+	reads  ratelimit.Rate
+	writes ratelimit.Rate
+}
+
+// rateLimitsFromConfig converts the configured requests per minute, where 0
+// disables that limit.
+func rateLimitsFromConfig(cfg config.RateLimitConfig) *rateLimits {
+	return &rateLimits{
+		reads:  ratelimit.PerMinute(cfg.ReadsPerMinute),
+		writes: ratelimit.PerMinute(cfg.WritesPerMinute),
+	}
+}
+
+// rateLimitPolicy limits reads and writes separately per merchant for requests
+// made with a verified client certificate, and per client IP for the rest, so
+// merchants and partners sharing an address do not share an allowance. Every
+// request naming a merchant is also held to the requests per minute of the
+// merchant's plan across all routes. X-Merchant-ID alone is not trusted, so
+// requests without a certificate count against a plan bucket of their own and
+// cannot spend the allowance of the merchant's verified requests. limits is
+// read on every request, so a reload takes effect straight away.
+func rateLimitPolicy(limits *atomic.Pointer[rateLimits], planService *plans.PlanService) ratelimit.Policy {
+	return func(r *http.Request) []ratelimit.Bucket {
+		current := limits.Load()
+		class, rate := "write", current.writes
+		switch r.Method {
+		case http.MethodGet, http.MethodHead, http.MethodOptions:
+			class, rate = "read", current.reads
+		}
+
+		var buckets []ratelimit.Bucket
+		planKey := "plan:"
+		merchantId, ok := middleware.VerifiedMerchant(r)
+		if ok {
+			buckets = append(buckets, ratelimit.Bucket{Key: class + ":merchant:" + merchantId.String(), Rate: rate})
+		} else {
+			buckets = append(buckets, ratelimit.Bucket{Key: class + ":" + middleware.ClientIP(r), Rate: rate})
+			planKey = "plan:unverified:"
+			merchantId, ok = middleware.RequestMerchant(r)
+		}
+		if !ok {
+			return buckets
+		}
+		if plan, err := planService.GetMerchantPlan(r.Context(), merchantId); err == nil {
+			buckets = append(buckets, ratelimit.Bucket{Key: planKey + merchantId.String(), Rate: ratelimit.PerMinute(plan.RequestsPerMinute)})
+		}
+		return buckets
+	}
+}
@@ -5,45 +5,360 @@ import (
 	"fmt"
 	"log"
 	"net/http"
+	"sync/atomic"
+	"time"
 
 	"github.com/go-chi/chi/v5"
 	"github.com/go-chi/chi/v5/middleware"
+	productsv1 "github.com/olad5/sal-backend-service/api/proto/products/v1"
+	"github.com/olad5/sal-backend-service/internal/app/graphqlapi"
+	"github.com/olad5/sal-backend-service/internal/app/grpcapi"
 
+	appMiddleware "github.com/olad5/sal-backend-service/internal/app/middleware"
+	"github.com/olad5/sal-backend-service/internal/config"
+	"github.com/olad5/sal-backend-service/internal/events"
+	currencyHandlers "github.com/olad5/sal-backend-service/internal/handlers/currency"
+	planHandlers "github.com/olad5/sal-backend-service/internal/handlers/plans"
+	pricingHandlers "github.com/olad5/sal-backend-service/internal/handlers/pricing"
 	handlers "github.com/olad5/sal-backend-service/internal/handlers/products"
+	taxHandlers "github.com/olad5/sal-backend-service/internal/handlers/tax"
+	"github.com/olad5/sal-backend-service/internal/health"
+	"github.com/olad5/sal-backend-service/internal/infra/instrumented"
+	"github.com/olad5/sal-backend-service/internal/infra/local"
 	"github.com/olad5/sal-backend-service/internal/infra/memory"
+	"github.com/olad5/sal-backend-service/internal/infra/search"
+	"github.com/olad5/sal-backend-service/internal/lifecycle"
+	"github.com/olad5/sal-backend-service/internal/logging"
+	"github.com/olad5/sal-backend-service/internal/usecases/currency"
+	"github.com/olad5/sal-backend-service/internal/usecases/plans"
+	"github.com/olad5/sal-backend-service/internal/usecases/pricing"
 	"github.com/olad5/sal-backend-service/internal/usecases/products"
+	"github.com/olad5/sal-backend-service/internal/usecases/tax"
+	"github.com/olad5/sal-backend-service/pkg/clock"
+	"github.com/olad5/sal-backend-service/pkg/metrics"
+	"github.com/olad5/sal-backend-service/pkg/ratelimit"
+	"google.golang.org/grpc"
 )
 
-func NewHttpRouter(ctx context.Context) http.Handler {
-	productRepo, err := memory.NewMemoryProductRepo()
+// componentStopTimeout bounds how long background components may take to stop.
+const componentStopTimeout = 5 * time.Second
+
+// NewHttpRouter builds the service. The components its health depends on are
+// registered with checker, and the ones that run in the background or can be
+// reloaded are added to manager, which must be started for them to run. The
+// gRPC API is registered with grpcServer unless it is nil.
+func NewHttpRouter(ctx context.Context, cfg config.Config, logger *logging.Logger, checker *health.Checker, manager *lifecycle.Manager, grpcServer grpc.ServiceRegistrar) http.Handler {
+	if cfg.Storage.Driver != config.DriverMemory {
+		log.Fatal("Unsupported Storage Driver ", cfg.Storage.Driver)
+	}
+	appClock := clock.New()
+	manager.Add("logger", lifecycle.Hooks{OnReload: func(ctx context.Context, cfg config.Config) error {
+		// validated by config.Load
+		level, _ := logging.ParseLevel(cfg.Log.Level)
+		logger.SetLevel(level)
+		return nil
+	}}, componentStopTimeout)
+	tracer, err := newTracer(cfg.Tracing, appClock, logger.Logger, manager)
+	if err != nil {
+		log.Fatal("Error Initializing Tracer", err)
+	}
+	registry := metrics.NewRegistry()
+	repoMetrics, err := instrumented.NewMetrics(registry, appClock)
+	if err != nil {
+		log.Fatal("Error Initializing Repository Metrics", err)
+	}
+
+	memoryProductRepo, err := memory.NewMemoryProductRepo()
 	if err != nil {
 		log.Fatal("Error Initializing Product Repo", err)
 	}
+	productRepo, err := instrumented.NewProductRepository(memoryProductRepo, repoMetrics)
+	if err != nil {
+		log.Fatal("Error Initializing Product Repo", err)
+	}
+
+	checker.Register("product_repository", memoryProductRepo)
+
+	blobStorage, err := local.NewLocalBlobStorage(cfg.Storage.MediaDir)
+	if err != nil {
+		log.Fatal("Error Initializing Blob Storage", err)
+	}
+	checker.Register("blob_storage", blobStorage)
+
+	searchIndex, err := search.NewProductIndex()
+	if err != nil {
+		log.Fatal("Error Initializing Search Index", err)
+	}
+	checker.Register("search_index", searchIndex)
+
+	eventBus := events.NewBus()
+	eventBus.Subscribe(searchIndex.HandleProductEvent)
+
+	memoryMerchantPlanRepo, err := memory.NewMemoryMerchantPlanRepo()
+	if err != nil {
+		log.Fatal("Error Initializing Merchant Plan Repo", err)
+	}
+	merchantPlanRepo, err := instrumented.NewMerchantPlanRepository(memoryMerchantPlanRepo, repoMetrics)
+	if err != nil {
+		log.Fatal("Error Initializing Merchant Plan Repo", err)
+	}
+	checker.Register("merchant_plan_repository", memoryMerchantPlanRepo)
+
+	planService, err := plans.NewPlanService(merchantPlanRepo, productRepo, appClock)
+	if err != nil {
+		log.Fatal("Error Initializing PlanService")
+	}
 
-	productService, err := products.NewProductService(productRepo)
+	memorySKUCodePatternRepo, err := memory.NewMemorySKUCodePatternRepo()
+	if err != nil {
+		log.Fatal("Error Initializing SKU Code Pattern Repo", err)
+	}
+	skuCodePatternRepo, err := instrumented.NewSKUCodePatternRepository(memorySKUCodePatternRepo, repoMetrics)
+	if err != nil {
+		log.Fatal("Error Initializing SKU Code Pattern Repo", err)
+	}
+	checker.Register("sku_code_pattern_repository", memorySKUCodePatternRepo)
+
+	productService, err := products.NewProductService(productRepo, blobStorage, searchIndex, eventBus, appClock, planService, skuCodePatternRepo, logger.Logger)
 	if err != nil {
 		log.Fatal("Error Initializing ProductService")
 	}
+	registerCatalogMetrics(registry, productService)
 
-	productHandler, err := handlers.NewProductHandler(*productService)
+	priceScheduler, err := products.NewPriceScheduler(productService, appClock, logger.Logger, cfg.Catalog.PriceSchedulerPollInterval.Duration)
+	if err != nil {
+		log.Fatal("Error Initializing PriceScheduler", err)
+	}
+	eventBus.Subscribe(priceScheduler.HandleProductEvent)
+	// a stopped scheduler only comes back with a restart
+	checker.RegisterLiveness("price_scheduler", priceScheduler)
+	manager.Add("price_scheduler", lifecycle.NewWorker(priceScheduler.Run), componentStopTimeout)
+
+	memoryExchangeRateRepo, err := memory.NewMemoryExchangeRateRepo()
+	if err != nil {
+		log.Fatal("Error Initializing Exchange Rate Repo", err)
+	}
+	exchangeRateRepo, err := instrumented.NewExchangeRateRepository(memoryExchangeRateRepo, repoMetrics)
+	if err != nil {
+		log.Fatal("Error Initializing Exchange Rate Repo", err)
+	}
+	checker.Register("exchange_rate_repository", memoryExchangeRateRepo)
+
+	currencyService, err := currency.NewCurrencyService(exchangeRateRepo, appClock)
+	if err != nil {
+		log.Fatal("Error Initializing CurrencyService")
+	}
+	if path := cfg.Catalog.ExchangeRatesFile; path != "" {
+		if _, err := currencyService.LoadExchangeRatesFile(ctx, path); err != nil {
+			log.Fatal("Error Loading Exchange Rates File", err)
+		}
+	}
+	// each reload adds a new version of the table, even when the file has not
+	// changed
+	manager.Add("exchange_rates", lifecycle.Hooks{OnReload: func(ctx context.Context, cfg config.Config) error {
+		if path := cfg.Catalog.ExchangeRatesFile; path != "" {
+			_, err := currencyService.LoadExchangeRatesFile(ctx, path)
+			return err
+		}
+		return nil
+	}}, componentStopTimeout)
+
+	currencyHandler, err := currencyHandlers.NewCurrencyHandler(*currencyService)
+	if err != nil {
+		log.Fatal("failed to create the Currency handler: ", err)
+	}
+
+	memoryTaxRateRepo, err := memory.NewMemoryTaxRateRepo()
+	if err != nil {
+		log.Fatal("Error Initializing Tax Rate Repo", err)
+	}
+	taxRateRepo, err := instrumented.NewTaxRateRepository(memoryTaxRateRepo, repoMetrics)
+	if err != nil {
+		log.Fatal("Error Initializing Tax Rate Repo", err)
+	}
+	checker.Register("tax_rate_repository", memoryTaxRateRepo)
+
+	taxService, err := tax.NewTaxService(taxRateRepo, appClock)
+	if err != nil {
+		log.Fatal("Error Initializing TaxService")
+	}
+	if path := cfg.Catalog.TaxRatesFile; path != "" {
+		if _, err := taxService.LoadTaxRatesFile(ctx, path); err != nil {
+			log.Fatal("Error Loading Tax Rates File", err)
+		}
+	}
+	manager.Add("tax_rates", lifecycle.Hooks{OnReload: func(ctx context.Context, cfg config.Config) error {
+		if path := cfg.Catalog.TaxRatesFile; path != "" {
+			_, err := taxService.LoadTaxRatesFile(ctx, path)
+			return err
+		}
+		return nil
+	}}, componentStopTimeout)
+
+	if grpcServer != nil {
+		productServer, err := grpcapi.NewProductServer(productService, currencyService, taxService, eventBus, logger.Logger)
+		if err != nil {
+			log.Fatal("Error Initializing gRPC ProductServer", err)
+		}
+		productsv1.RegisterProductServiceServer(grpcServer, productServer)
+	}
+
+	taxHandler, err := taxHandlers.NewTaxHandler(*taxService)
+	if err != nil {
+		log.Fatal("failed to create the Tax handler: ", err)
+	}
+
+	productHandler, err := handlers.NewProductHandler(*productService, *currencyService, *taxService, logger.Logger)
 	if err != nil {
 		log.Fatal("failed to create the Product handler: ", err)
 	}
+
+	memoryPricingRuleRepo, err := memory.NewMemoryPricingRuleRepo()
+	if err != nil {
+		log.Fatal("Error Initializing Pricing Rule Repo", err)
+	}
+	pricingRuleRepo, err := instrumented.NewPricingRuleRepository(memoryPricingRuleRepo, repoMetrics)
+	if err != nil {
+		log.Fatal("Error Initializing Pricing Rule Repo", err)
+	}
+	checker.Register("pricing_rule_repository", memoryPricingRuleRepo)
+
+	pricingService, err := pricing.NewPricingService(pricingRuleRepo, productRepo, appClock)
+	if err != nil {
+		log.Fatal("Error Initializing PricingService")
+	}
+
+	pricingHandler, err := pricingHandlers.NewPricingHandler(*pricingService)
+	if err != nil {
+		log.Fatal("failed to create the Pricing handler: ", err)
+	}
+
+	planHandler, err := planHandlers.NewPlanHandler(*planService)
+	if err != nil {
+		log.Fatal("failed to create the Plan handler: ", err)
+	}
+
+	limits := &atomic.Pointer[rateLimits]{}
+	limits.Store(rateLimitsFromConfig(cfg.RateLimit))
+	manager.Add("rate_limits", lifecycle.Hooks{OnReload: func(ctx context.Context, cfg config.Config) error {
+		limits.Store(rateLimitsFromConfig(cfg.RateLimit))
+		return nil
+	}}, componentStopTimeout)
+	limiter, err := ratelimit.NewLimiter(appClock, rateLimitMaxKeys, rateLimitIdleTimeout)
+	if err != nil {
+		log.Fatal("Error Initializing Rate Limiter", err)
+	}
+
+	memoryIdempotencyRepo, err := memory.NewMemoryIdempotencyRepo()
+	if err != nil {
+		log.Fatal("Error Initializing Idempotency Repo", err)
+	}
+	idempotencyRepo, err := instrumented.NewIdempotencyRepository(memoryIdempotencyRepo, repoMetrics)
+	if err != nil {
+		log.Fatal("Error Initializing Idempotency Repo", err)
+	}
+	checker.Register("idempotency_repository", memoryIdempotencyRepo)
+	idempotency, err := appMiddleware.Idempotency(idempotencyRepo, appClock, logger.Logger, cfg.Idempotency.KeyTTL.Duration)
+	if err != nil {
+		log.Fatal("Error Initializing Idempotency Middleware", err)
+	}
+	clientIdentities, err := newClientIdentities(cfg.TLS, manager)
+	if err != nil {
+		log.Fatal("Error Loading Client Identities", err)
+	}
+	graphqlHandler, err := graphqlapi.NewHandler(productService, currencyService, taxService, graphqlapi.Limits{
+		MaxDepth:      cfg.GraphQL.MaxDepth,
+		MaxComplexity: cfg.GraphQL.MaxComplexity,
+	}, logger.Logger)
+	if err != nil {
+		log.Fatal("Error Initializing GraphQL Handler", err)
+	}
+	openAPI, err := openAPIHandler(apiSpec())
+	if err != nil {
+		log.Fatal("Error Building OpenAPI Spec", err)
+	}
+	adminToken := &atomic.Pointer[string]{}
+	adminToken.Store(&cfg.Admin.Token)
+	manager.Add("admin_token", lifecycle.Hooks{OnReload: func(ctx context.Context, cfg config.Config) error {
+		adminToken.Store(&cfg.Admin.Token)
+		return nil
+	}}, componentStopTimeout)
+
 	router := chi.NewRouter()
+	router.Use(appMiddleware.RequestID)
+	router.Use(appMiddleware.Tracing(tracer))
+	router.Use(appMiddleware.AccessLog(logger.Logger, appClock))
+	router.Use(appMiddleware.Metrics(registry, appClock))
 
 	router.Get("/", func(w http.ResponseWriter, r *http.Request) {
 		fmt.Fprint(w, "SAL Backend Service is live\n")
 	})
+	router.Get("/openapi.json", openAPI)
+	router.Get("/healthz", fetchLiveness(checker))
+	router.Get("/readyz", fetchReadiness(checker))
+	router.Method(http.MethodGet, "/metrics", metricsHandler(registry, logger.Logger))
+
+	router.Route("/admin", func(r chi.Router) {
+		r.Use(middleware.SetHeader("Content-Type", "application/json"))
+		r.Use(appMiddleware.AdminToken(func() string { return *adminToken.Load() }))
+		r.Get("/log-level", fetchLogLevel(logger))
+		r.Group(func(r chi.Router) {
+			r.Use(middleware.AllowContentType("application/json"))
+			r.Put("/log-level", updateLogLevel(logger))
+			r.Put("/merchants/{merchant_id}/plan", planHandler.AssignMerchantPlan)
+			r.Put("/exchange-rates", currencyHandler.UpdateExchangeRates)
+			r.Put("/tax-regions/{region}", taxHandler.UpdateTaxRegion)
+		})
+	})
+
+	// shared by the REST API and GraphQL, whose requests are POSTs and so
+	// count against the write limit
+	apiMiddleware := chi.Middlewares{middleware.SetHeader("Content-Type", "application/json")}
+	if clientIdentities != nil {
+		// before anything that reads X-Merchant-ID
+		apiMiddleware = append(apiMiddleware, appMiddleware.ClientCertificateIdentity(clientIdentities))
+	}
+	apiMiddleware = append(apiMiddleware, ratelimit.Middleware(limiter, rateLimitPolicy(limits, planService)), idempotency)
+
+	router.With(apiMiddleware...).With(middleware.AllowContentType("application/json")).Method(http.MethodPost, "/graphql", graphqlHandler)
 
 	router.Route("/api", func(r chi.Router) {
-		r.Use(
-			middleware.AllowContentType("application/json"),
-			middleware.SetHeader("Content-Type", "application/json"),
-		)
-		r.Post("/products", productHandler.CreateProduct)
-		r.Patch("/products/{sku_id}", productHandler.EditProduct)
-		r.Delete("/products/{sku_id}", productHandler.DeleteProduct)
-		r.Get("/merchants/{merchant_id}/products", productHandler.FetchMerchantProducts)
+		r.Use(apiMiddleware...)
+
+		r.Group(func(r chi.Router) {
+			r.Use(middleware.AllowContentType("application/json"))
+			r.Post("/products", productHandler.CreateProduct)
+			r.Patch("/products/{sku_id}", productHandler.EditProduct)
+			r.Delete("/products/{sku_id}", productHandler.DeleteProduct)
+			r.Post("/products/{sku_id}/price-changes", productHandler.SchedulePriceChange)
+			r.Post("/products/{sku_id}/sales", productHandler.ScheduleSale)
+			r.Delete("/products/{sku_id}/price-schedules/{schedule_id}", productHandler.CancelPriceSchedule)
+			r.Patch("/products/{sku_id}/images/{image_id}", productHandler.EditProductImage)
+			r.Delete("/products/{sku_id}/images/{image_id}", productHandler.DeleteProductImage)
+			r.Get("/merchants/{merchant_id}/products", productHandler.FetchMerchantProducts)
+			r.Get("/merchants/{merchant_id}/products/search", productHandler.SearchMerchantProducts)
+			r.Get("/merchants/{merchant_id}/products/suggest", productHandler.SuggestProductNames)
+			r.Get("/merchants/{merchant_id}/products/by-code/{code}", productHandler.FetchProductByCode)
+			r.Get("/merchants/{merchant_id}/sku-code-pattern", productHandler.FetchSKUCodePattern)
+			r.Put("/merchants/{merchant_id}/sku-code-pattern", productHandler.UpdateSKUCodePattern)
+			r.Post("/merchants/{merchant_id}/pricing-rules", pricingHandler.CreatePricingRule)
+			r.Get("/merchants/{merchant_id}/pricing-rules", pricingHandler.FetchMerchantPricingRules)
+			r.Delete("/merchants/{merchant_id}/pricing-rules/{rule_id}", pricingHandler.DeletePricingRule)
+			r.Post("/products/{sku_id}/quote", pricingHandler.QuoteProductPrice)
+			r.Get("/exchange-rates", currencyHandler.FetchExchangeRates)
+			r.Get("/exchange-rates/{version}", currencyHandler.FetchExchangeRatesByVersion)
+			r.Get("/tax-regions", taxHandler.FetchTaxRegions)
+			r.Get("/plans", planHandler.FetchPlans)
+			r.Get("/merchants/{merchant_id}/usage", planHandler.FetchMerchantUsage)
+		})
+
+		r.Group(func(r chi.Router) {
+			r.Use(middleware.AllowContentType("multipart/form-data"))
+			r.Post("/products/{sku_id}/images", productHandler.UploadProductImage)
+		})
 	})
+
+	router.Get("/media/products/{sku_id}/images/{image_id}", productHandler.ServeProductImage)
+	router.Get("/media/products/{sku_id}/images/{image_id}/thumbnail", productHandler.ServeProductImageThumbnail)
 	return router
 }
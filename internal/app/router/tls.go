@@ -0,0 +1,60 @@
+package router
+
+import (
+	"context"
+	"crypto/tls"
+	"log/slog"
+
+	appMiddleware "github.com/olad5/sal-backend-service/internal/app/middleware"
+	"github.com/olad5/sal-backend-service/internal/config"
+	"github.com/olad5/sal-backend-service/internal/lifecycle"
+	"github.com/olad5/sal-backend-service/pkg/certs"
+)
+
+var clientAuthTypes = map[string]tls.ClientAuthType{
+	config.TLSClientAuthNone:     tls.NoClientCert,
+	config.TLSClientAuthOptional: tls.VerifyClientCertIfGiven,
+	config.TLSClientAuthRequire:  tls.RequireAndVerifyClientCert,
+}
+
+// NewTLSConfig returns the server's TLS config, or nil when TLS is off. The
+// certificate files are watched for changes by a component added to manager.
+func NewTLSConfig(cfg config.TLSConfig, logger *slog.Logger, manager *lifecycle.Manager) (*tls.Config, error) {
+	if !cfg.Enabled() {
+		return nil, nil
+	}
+	clientCAFile := ""
+	if cfg.ClientAuth != config.TLSClientAuthNone {
+		clientCAFile = cfg.ClientCAFile
+	}
+	reloader, err := certs.NewReloader(cfg.CertFile, cfg.KeyFile, clientCAFile, cfg.ReloadInterval.Duration, func(err error) {
+		logger.Error("failed to reload certificates, keeping the current ones", "error", err)
+	})
+	if err != nil {
+		return nil, err
+	}
+	manager.Add("certificates", lifecycle.NewWorker(reloader.Run), componentStopTimeout)
+	return reloader.ServerConfig(clientAuthTypes[cfg.ClientAuth]), nil
+}
+
+// newClientIdentities loads the identities client certificates map to, or
+// returns nil when client certificates are not asked for.
+func newClientIdentities(cfg config.TLSConfig, manager *lifecycle.Manager) (*appMiddleware.ClientIdentities, error) {
+	if !cfg.Enabled() || cfg.ClientAuth == config.TLSClientAuthNone {
+		return nil, nil
+	}
+	identities, err := appMiddleware.LoadClientIdentitiesFile(cfg.ClientIdentitiesFile)
+	if err != nil {
+		return nil, err
+	}
+	clientIdentities := appMiddleware.NewClientIdentities(identities)
+	manager.Add("client_identities", lifecycle.Hooks{OnReload: func(ctx context.Context, cfg config.Config) error {
+		identities, err := appMiddleware.LoadClientIdentitiesFile(cfg.TLS.ClientIdentitiesFile)
+		if err != nil {
+			return err
+		}
+		clientIdentities.Set(identities)
+		return nil
+	}}, componentStopTimeout)
+	return clientIdentities, nil
+}
@@ -0,0 +1,72 @@
+package router
+
+import (
+	"context"
+	"log/slog"
+	"net/http"
+	"os"
+	"time"
+
+	"github.com/olad5/sal-backend-service/internal/config"
+	"github.com/olad5/sal-backend-service/internal/lifecycle"
+	"github.com/olad5/sal-backend-service/pkg/clock"
+	"github.com/olad5/sal-backend-service/pkg/tracing"
+)
+
+const (
+	otlpBatchSize     = 512
+	otlpQueueSize     = 4 * otlpBatchSize
+	otlpBatchInterval = 5 * time.Second
+	otlpTimeout       = 10 * time.Second
+
+	spanFileStopTimeout = time.Second
+)
+
+// discardExporter drops spans when tracing is off, spans are still started
+// for callers that sent a sampled traceparent so their IDs reach the logs.
+type discardExporter struct{}
+
+func (discardExporter) ExportSpans(ctx context.Context, spans []tracing.SpanData) error {
+	return nil
+}
+
+// newTracer builds the tracer for cfg. Files and exporters that run in the
+// background are added to manager, to be closed and flushed on shutdown.
+func newTracer(cfg config.TracingConfig, clock clock.Clock, logger *slog.Logger, manager *lifecycle.Manager) (*tracing.Tracer, error) {
+	onError := func(err error) {
+		logger.Error("failed to export spans", "error", err)
+	}
+
+	var exporter tracing.Exporter
+	switch cfg.Exporter {
+	case config.TracingExporterStdout:
+		exporter = tracing.NewJSONExporter(os.Stdout)
+	case config.TracingExporterFile:
+		file, err := os.OpenFile(cfg.File, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o644)
+		if err != nil {
+			return nil, err
+		}
+		manager.Add("span_file", lifecycle.Hooks{OnStop: func(ctx context.Context) error {
+			return file.Close()
+		}}, spanFileStopTimeout)
+		exporter = tracing.NewJSONExporter(file)
+	case config.TracingExporterOTLP:
+		client, err := tracing.NewOTLPHTTPClient(cfg.OTLPEndpoint, &http.Client{Timeout: otlpTimeout})
+		if err != nil {
+			return nil, err
+		}
+		otlpExporter, err := tracing.NewOTLPExporter(client, "github.com/olad5/sal-backend-service")
+		if err != nil {
+			return nil, err
+		}
+		batchExporter, err := tracing.NewBatchExporter(otlpExporter, otlpBatchSize, otlpQueueSize, otlpBatchInterval, onError)
+		if err != nil {
+			return nil, err
+		}
+		manager.Add("span_exporter", lifecycle.NewWorker(batchExporter.Run), otlpTimeout)
+		exporter = batchExporter
+	default:
+		return tracing.NewTracer(cfg.ServiceName, discardExporter{}, tracing.TraceIDRatio(0), clock, onError)
+	}
+	return tracing.NewTracer(cfg.ServiceName, exporter, tracing.TraceIDRatio(cfg.SampleRatio), clock, onError)
+}
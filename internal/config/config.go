@@ -0,0 +1,322 @@
+// Package config holds the server's configuration. Values come from defaults,
+// a JSON config file, environment variables and command line flags, each
+// layer overriding the ones before it.
+package config
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/url"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/olad5/sal-backend-service/internal/logging"
+)
+
+// Config is the server's configuration. Each field names its key in the
+// config file, its environment variable and its flag; fields tagged
+// secret:"true" are redacted by Redacted, and fields tagged reload:"true" can
+// be changed without a restart by sending the server SIGHUP.
+type Config struct {
+	Server      ServerConfig      `json:"server"`
+	Storage     StorageConfig     `json:"storage"`
+	Catalog     CatalogConfig     `json:"catalog"`
+	RateLimit   RateLimitConfig   `json:"rate_limit"`
+	Idempotency IdempotencyConfig `json:"idempotency"`
+	Log         LogConfig         `json:"log"`
+	Tracing     TracingConfig     `json:"tracing"`
+	TLS         TLSConfig         `json:"tls"`
+	GRPC        GRPCConfig        `json:"grpc"`
+	GraphQL     GraphQLConfig     `json:"graphql"`
+	Admin       AdminConfig       `json:"admin"`
+}
+
+type ServerConfig struct {
+	Port int `json:"port" env:"PORT" flag:"port" usage:"port to listen on"`
+	// ShutdownTimeout bounds how long in flight requests may take to finish
+	// after a shutdown signal
+	ShutdownTimeout Duration `json:"shutdown_timeout" env:"SHUTDOWN_TIMEOUT" flag:"shutdown-timeout" usage:"time allowed for requests to finish on shutdown"`
+	// DrainDelay is how long /readyz fails before the server stops accepting
+	// requests, so load balancers can take it out of rotation first
+	DrainDelay         Duration `json:"drain_delay" env:"DRAIN_DELAY" flag:"drain-delay" usage:"time /readyz fails for before shutting down"`
+	HealthCheckTimeout Duration `json:"health_check_timeout" env:"HEALTH_CHECK_TIMEOUT" flag:"health-check-timeout" usage:"time each component has to answer a health check"`
+}
+
+type StorageConfig struct {
+	// Driver picks the repositories, memory is the only driver so far
+	Driver   string `json:"driver" env:"STORAGE_DRIVER" flag:"storage-driver" usage:"storage driver, memory"`
+	MediaDir string `json:"media_dir" env:"MEDIA_DIR" flag:"media-dir" usage:"directory product images are stored in"`
+}
+
+type CatalogConfig struct {
+	ExchangeRatesFile          string   `json:"exchange_rates_file" env:"EXCHANGE_RATES_FILE" flag:"exchange-rates-file" usage:"JSON file of exchange rates loaded at startup and on reload" reload:"true"`
+	TaxRatesFile               string   `json:"tax_rates_file" env:"TAX_RATES_FILE" flag:"tax-rates-file" usage:"JSON file of tax rates loaded at startup and on reload" reload:"true"`
+	PriceSchedulerPollInterval Duration `json:"price_scheduler_poll_interval" env:"PRICE_SCHEDULER_POLL_INTERVAL" flag:"price-scheduler-poll-interval" usage:"how often scheduled price changes are checked"`
+}
+
+// RateLimitConfig limits requests per merchant or client IP, 0 disables a
+// limit.
+type RateLimitConfig struct {
+	ReadsPerMinute  int `json:"reads_per_minute" env:"RATE_LIMIT_READS_PER_MINUTE" flag:"rate-limit-reads-per-minute" usage:"read requests allowed per minute, 0 disables the limit" reload:"true"`
+	WritesPerMinute int `json:"writes_per_minute" env:"RATE_LIMIT_WRITES_PER_MINUTE" flag:"rate-limit-writes-per-minute" usage:"write requests allowed per minute, 0 disables the limit" reload:"true"`
+}
+
+type IdempotencyConfig struct {
+	KeyTTL Duration `json:"key_ttl" env:"IDEMPOTENCY_KEY_TTL" flag:"idempotency-key-ttl" usage:"how long Idempotency-Key responses are replayed"`
+}
+
+type LogConfig struct {
+	// Level is the starting level, it can be changed at runtime through
+	// /admin/log-level or a reload
+	Level string `json:"level" env:"LOG_LEVEL" flag:"log-level" usage:"minimum log level: debug, info, warn or error" reload:"true"`
+}
+
+type TracingConfig struct {
+	// Exporter is where finished spans go: nowhere, stdout, File or the OTLP
+	// collector at OTLPEndpoint
+	Exporter     string  `json:"exporter" env:"TRACING_EXPORTER" flag:"tracing-exporter" usage:"where spans are sent: none, stdout, file or otlp"`
+	File         string  `json:"file" env:"TRACING_FILE" flag:"tracing-file" usage:"file spans are appended to as JSON lines, for the file exporter"`
+	OTLPEndpoint string  `json:"otlp_endpoint" env:"TRACING_OTLP_ENDPOINT" flag:"tracing-otlp-endpoint" usage:"OTLP/HTTP traces URL such as http://localhost:4318/v1/traces, for the otlp exporter"`
+	SampleRatio  float64 `json:"sample_ratio" env:"TRACING_SAMPLE_RATIO" flag:"tracing-sample-ratio" usage:"share of new traces recorded, from 0 to 1, traces started by callers follow their traceparent"`
+	ServiceName  string  `json:"service_name" env:"TRACING_SERVICE_NAME" flag:"tracing-service-name" usage:"service name spans are reported under"`
+}
+
+// TLSConfig serves HTTPS when CertFile is set. Certificate files are reloaded
+// when they change, the identities file on SIGHUP.
+type TLSConfig struct {
+	CertFile string `json:"cert_file" env:"TLS_CERT_FILE" flag:"tls-cert-file" usage:"PEM certificate chain, HTTPS is served when set"`
+	KeyFile  string `json:"key_file" env:"TLS_KEY_FILE" flag:"tls-key-file" usage:"PEM private key of the certificate"`
+	// ClientCAFile and ClientAuth turn on mutual TLS, client certificates are
+	// mapped to merchants or partners through ClientIdentitiesFile
+	ClientCAFile         string   `json:"client_ca_file" env:"TLS_CLIENT_CA_FILE" flag:"tls-client-ca-file" usage:"PEM CA certificates client certificates are verified against"`
+	ClientAuth           string   `json:"client_auth" env:"TLS_CLIENT_AUTH" flag:"tls-client-auth" usage:"client certificates: none, optional or require"`
+	ClientIdentitiesFile string   `json:"client_identities_file" env:"TLS_CLIENT_IDENTITIES_FILE" flag:"tls-client-identities-file" usage:"JSON file mapping client certificate subjects to merchants or partners" reload:"true"`
+	ReloadInterval       Duration `json:"reload_interval" env:"TLS_RELOAD_INTERVAL" flag:"tls-reload-interval" usage:"how often certificate files are checked for changes"`
+}
+
+func (c TLSConfig) Enabled() bool {
+	return c.CertFile != ""
+}
+
+// GRPCConfig is the gRPC API for internal services. It is served without TLS
+// and takes merchant IDs at face value, so it is off unless a port is set and
+// must not be exposed publicly.
+type GRPCConfig struct {
+	Port int `json:"port" env:"GRPC_PORT" flag:"grpc-port" usage:"port the gRPC API listens on, 0 (the default) disables it"`
+}
+
+// GraphQLConfig bounds the queries /graphql accepts. Complexity counts every
+// selected field, once per requested item below a paginated connection.
+type GraphQLConfig struct {
+	MaxDepth      int `json:"max_depth" env:"GRAPHQL_MAX_DEPTH" flag:"graphql-max-depth" usage:"deepest field nesting a GraphQL query may select"`
+	MaxComplexity int `json:"max_complexity" env:"GRAPHQL_MAX_COMPLEXITY" flag:"graphql-max-complexity" usage:"most fields a GraphQL query may select, counting each requested page item"`
+}
+
+// AdminConfig guards the /admin routes, which change the service for every
+// merchant. They are refused while no token is set.
+type AdminConfig struct {
+	Token string `json:"token" env:"ADMIN_TOKEN" flag:"admin-token" usage:"bearer token the /admin routes require, they are disabled when empty" secret:"true" reload:"true"`
+}
+
+// Duration is a time.Duration written as a string such as "10s" in config
+// files.
+type Duration struct {
+	time.Duration
+}
+
+func (d Duration) MarshalJSON() ([]byte, error) {
+	return json.Marshal(d.String())
+}
+
+func (d *Duration) UnmarshalJSON(data []byte) error {
+	var value string
+	if err := json.Unmarshal(data, &value); err != nil {
+		return fmt.Errorf("durations are strings such as \"10s\": %w", err)
+	}
+	parsed, err := time.ParseDuration(value)
+	if err != nil {
+		return err
+	}
+	d.Duration = parsed
+	return nil
+}
+
+const (
+	DriverMemory = "memory"
+
+	TracingExporterNone   = "none"
+	TracingExporterStdout = "stdout"
+	TracingExporterFile   = "file"
+	TracingExporterOTLP   = "otlp"
+
+	TLSClientAuthNone     = "none"
+	TLSClientAuthOptional = "optional"
+	TLSClientAuthRequire  = "require"
+
+	defaultPort            = 4000
+	defaultGraphQLDepth    = 10
+	defaultShutdownTimeout = 10 * time.Second
+	defaultHealthTimeout   = 2 * time.Second
+	defaultReadsPerMinute  = 1200
+	defaultWritesPerMinute = 300
+	defaultKeyTTL          = 24 * time.Hour
+	defaultPollInterval    = time.Second
+	defaultTLSReload       = 10 * time.Second
+	// a full page of 100 products with every field
+	defaultGraphQLComplexity = 2500
+)
+
+// Default returns the configuration used when nothing is overridden.
+func Default() Config {
+	return Config{
+		Server: ServerConfig{
+			Port:               defaultPort,
+			ShutdownTimeout:    Duration{defaultShutdownTimeout},
+			HealthCheckTimeout: Duration{defaultHealthTimeout},
+		},
+		Storage: StorageConfig{
+			Driver:   DriverMemory,
+			MediaDir: filepath.Join(os.TempDir(), "sal-backend-service", "media"),
+		},
+		Catalog: CatalogConfig{
+			PriceSchedulerPollInterval: Duration{defaultPollInterval},
+		},
+		RateLimit: RateLimitConfig{
+			ReadsPerMinute:  defaultReadsPerMinute,
+			WritesPerMinute: defaultWritesPerMinute,
+		},
+		Idempotency: IdempotencyConfig{
+			KeyTTL: Duration{defaultKeyTTL},
+		},
+		Log: LogConfig{
+			Level: "info",
+		},
+		Tracing: TracingConfig{
+			Exporter:    TracingExporterNone,
+			SampleRatio: 1,
+			ServiceName: "sal-backend-service",
+		},
+		TLS: TLSConfig{
+			ClientAuth:     TLSClientAuthNone,
+			ReloadInterval: Duration{defaultTLSReload},
+		},
+		GraphQL: GraphQLConfig{
+			MaxDepth:      defaultGraphQLDepth,
+			MaxComplexity: defaultGraphQLComplexity,
+		},
+	}
+}
+
+// Validate reports every invalid value at once.
+func (c Config) Validate() error {
+	var errs []error
+	invalid := func(key, format string, args ...interface{}) {
+		errs = append(errs, fmt.Errorf("%s %s", key, fmt.Sprintf(format, args...)))
+	}
+
+	if c.Server.Port < 1 || c.Server.Port > 65535 {
+		invalid("server.port", "must be between 1 and 65535, got %d", c.Server.Port)
+	}
+	if c.Server.ShutdownTimeout.Duration <= 0 {
+		invalid("server.shutdown_timeout", "must be positive, got %s", c.Server.ShutdownTimeout)
+	}
+	if c.Server.DrainDelay.Duration < 0 {
+		invalid("server.drain_delay", "must not be negative, got %s", c.Server.DrainDelay)
+	}
+	if c.Server.HealthCheckTimeout.Duration <= 0 {
+		invalid("server.health_check_timeout", "must be positive, got %s", c.Server.HealthCheckTimeout)
+	}
+	if c.Storage.Driver != DriverMemory {
+		invalid("storage.driver", "must be %q, got %q", DriverMemory, c.Storage.Driver)
+	}
+	if c.Storage.MediaDir == "" {
+		invalid("storage.media_dir", "must not be empty")
+	}
+	for _, file := range []struct{ key, path string }{
+		{"catalog.exchange_rates_file", c.Catalog.ExchangeRatesFile},
+		{"catalog.tax_rates_file", c.Catalog.TaxRatesFile},
+		{"tls.cert_file", c.TLS.CertFile},
+		{"tls.key_file", c.TLS.KeyFile},
+		{"tls.client_ca_file", c.TLS.ClientCAFile},
+		{"tls.client_identities_file", c.TLS.ClientIdentitiesFile},
+	} {
+		key, path := file.key, file.path
+		if path == "" {
+			continue
+		}
+		if info, err := os.Stat(path); err != nil {
+			invalid(key, "cannot be read: %v", err)
+		} else if info.IsDir() {
+			invalid(key, "must be a file, %s is a directory", path)
+		}
+	}
+	if c.Catalog.PriceSchedulerPollInterval.Duration <= 0 {
+		invalid("catalog.price_scheduler_poll_interval", "must be positive, got %s", c.Catalog.PriceSchedulerPollInterval)
+	}
+	if c.RateLimit.ReadsPerMinute < 0 {
+		invalid("rate_limit.reads_per_minute", "must not be negative, got %d", c.RateLimit.ReadsPerMinute)
+	}
+	if c.RateLimit.WritesPerMinute < 0 {
+		invalid("rate_limit.writes_per_minute", "must not be negative, got %d", c.RateLimit.WritesPerMinute)
+	}
+	if c.Idempotency.KeyTTL.Duration <= 0 {
+		invalid("idempotency.key_ttl", "must be positive, got %s", c.Idempotency.KeyTTL)
+	}
+	if _, err := logging.ParseLevel(c.Log.Level); err != nil {
+		invalid("log.level", "is invalid: %v", err)
+	}
+	switch c.Tracing.Exporter {
+	case TracingExporterNone, TracingExporterStdout:
+	case TracingExporterFile:
+		if c.Tracing.File == "" {
+			invalid("tracing.file", "must be set for the %s exporter", TracingExporterFile)
+		}
+	case TracingExporterOTLP:
+		if endpoint, err := url.Parse(c.Tracing.OTLPEndpoint); err != nil || endpoint.Host == "" || (endpoint.Scheme != "http" && endpoint.Scheme != "https") {
+			invalid("tracing.otlp_endpoint", "must be an http or https URL for the %s exporter, got %q", TracingExporterOTLP, c.Tracing.OTLPEndpoint)
+		}
+	default:
+		invalid("tracing.exporter", "must be none, stdout, file or otlp, got %q", c.Tracing.Exporter)
+	}
+	if c.Tracing.SampleRatio < 0 || c.Tracing.SampleRatio > 1 {
+		invalid("tracing.sample_ratio", "must be between 0 and 1, got %g", c.Tracing.SampleRatio)
+	}
+	if c.Tracing.ServiceName == "" {
+		invalid("tracing.service_name", "must not be empty")
+	}
+	if (c.TLS.CertFile == "") != (c.TLS.KeyFile == "") {
+		invalid("tls.key_file", "must be set together with tls.cert_file")
+	}
+	switch c.TLS.ClientAuth {
+	case TLSClientAuthNone:
+	case TLSClientAuthOptional, TLSClientAuthRequire:
+		if !c.TLS.Enabled() {
+			invalid("tls.client_auth", "needs tls.cert_file to be set")
+		}
+		if c.TLS.ClientCAFile == "" {
+			invalid("tls.client_ca_file", "must be set when tls.client_auth is %s", c.TLS.ClientAuth)
+		}
+		if c.TLS.ClientIdentitiesFile == "" {
+			invalid("tls.client_identities_file", "must be set when tls.client_auth is %s", c.TLS.ClientAuth)
+		}
+	default:
+		invalid("tls.client_auth", "must be none, optional or require, got %q", c.TLS.ClientAuth)
+	}
+	if c.TLS.ReloadInterval.Duration <= 0 {
+		invalid("tls.reload_interval", "must be positive, got %s", c.TLS.ReloadInterval)
+	}
+	if c.GRPC.Port < 0 || c.GRPC.Port > 65535 {
+		invalid("grpc.port", "must be between 0 and 65535, got %d", c.GRPC.Port)
+	} else if c.GRPC.Port != 0 && c.GRPC.Port == c.Server.Port {
+		invalid("grpc.port", "must differ from server.port, both are %d", c.GRPC.Port)
+	}
+	if c.GraphQL.MaxDepth < 1 {
+		invalid("graphql.max_depth", "must be positive, got %d", c.GraphQL.MaxDepth)
+	}
+	if c.GraphQL.MaxComplexity < 1 {
+		invalid("graphql.max_complexity", "must be positive, got %d", c.GraphQL.MaxComplexity)
+	}
+	return errors.Join(errs...)
+}
@@ -0,0 +1,253 @@
+package config
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ConfigFileEnv names the config file when the -config flag is not given.
+const ConfigFileEnv = "CONFIG_FILE"
+
+// Options are the command line flags that are not configuration values.
+type Options struct {
+	// File is the JSON config file, from -config or CONFIG_FILE
+	File string
+	// PrintConfig asks for the resolved configuration to be printed instead of
+	// starting the server
+	PrintConfig bool
+}
+
+// field is a configuration value and the names it can be set by.
+type field struct {
+	key    string
+	env    string
+	flag   string
+	usage  string
+	secret bool
+	reload bool
+	value  reflect.Value
+}
+
+// fields walks c in declaration order.
+func fields(c *Config) []field {
+	var found []field
+	var walk func(v reflect.Value, prefix string)
+	walk = func(v reflect.Value, prefix string) {
+		for i := 0; i < v.NumField(); i++ {
+			structField := v.Type().Field(i)
+			key := prefix + strings.Split(structField.Tag.Get("json"), ",")[0]
+			if structField.Type.Kind() == reflect.Struct && structField.Type != durationType {
+				walk(v.Field(i), key+".")
+				continue
+			}
+			found = append(found, field{
+				key:    key,
+				env:    structField.Tag.Get("env"),
+				flag:   structField.Tag.Get("flag"),
+				usage:  structField.Tag.Get("usage"),
+				secret: structField.Tag.Get("secret") == "true",
+				reload: structField.Tag.Get("reload") == "true",
+				value:  v.Field(i),
+			})
+		}
+	}
+	walk(reflect.ValueOf(c).Elem(), "")
+	return found
+}
+
+var durationType = reflect.TypeOf(Duration{})
+
+// set parses raw into the field's type.
+func (f field) set(raw string) error {
+	switch {
+	case f.value.Type() == durationType:
+		parsed, err := time.ParseDuration(raw)
+		if err != nil {
+			return fmt.Errorf("must be a duration such as 10s, got %q", raw)
+		}
+		f.value.Set(reflect.ValueOf(Duration{parsed}))
+	case f.value.Kind() == reflect.Int:
+		parsed, err := strconv.Atoi(raw)
+		if err != nil {
+			return fmt.Errorf("must be a whole number, got %q", raw)
+		}
+		f.value.SetInt(int64(parsed))
+	case f.value.Kind() == reflect.Float64:
+		parsed, err := strconv.ParseFloat(raw, 64)
+		if err != nil {
+			return fmt.Errorf("must be a number, got %q", raw)
+		}
+		f.value.SetFloat(parsed)
+	case f.value.Kind() == reflect.Bool:
+		parsed, err := strconv.ParseBool(raw)
+		if err != nil {
+			return fmt.Errorf("must be true or false, got %q", raw)
+		}
+		f.value.SetBool(parsed)
+	case f.value.Kind() == reflect.String:
+		f.value.SetString(raw)
+	default:
+		return fmt.Errorf("has unsupported type %s", f.value.Type())
+	}
+	return nil
+}
+
+func (f field) String() string {
+	if f.value.Type() == durationType {
+		return f.value.Interface().(Duration).String()
+	}
+	return fmt.Sprint(f.value.Interface())
+}
+
+// rawFlag records a flag's value, which is applied after the config file and
+// environment variables.
+type rawFlag struct {
+	name     string
+	fallback string
+	values   map[string]string
+}
+
+func (f *rawFlag) String() string {
+	if f == nil {
+		return ""
+	}
+	return f.fallback
+}
+
+func (f *rawFlag) Set(value string) error {
+	f.values[f.name] = value
+	return nil
+}
+
+func newFlagSet(c *Config, opts *Options, values map[string]string) *flag.FlagSet {
+	fs := flag.NewFlagSet("sal-backend-service", flag.ContinueOnError)
+	fs.SetOutput(io.Discard)
+	fs.StringVar(&opts.File, "config", "", "JSON config file, defaults to $"+ConfigFileEnv)
+	fs.BoolVar(&opts.PrintConfig, "print-config", false, "print the configuration with secrets redacted and exit")
+	for _, f := range fields(c) {
+		usage := f.usage
+		if f.env != "" {
+			usage += ", $" + f.env
+		}
+		fs.Var(&rawFlag{name: f.flag, fallback: f.String(), values: values}, f.flag, usage)
+	}
+	return fs
+}
+
+// PrintUsage writes the flags Load accepts.
+func PrintUsage(w io.Writer) {
+	c := Default()
+	fs := newFlagSet(&c, &Options{}, map[string]string{})
+	fs.SetOutput(w)
+	fmt.Fprintln(w, "Usage of sal-backend-service:")
+	fs.PrintDefaults()
+}
+
+// Load builds the configuration from the defaults, the config file,
+// environment variables and args, in increasing order of precedence, then
+// validates it. lookupEnv is os.LookupEnv outside of tests.
+func Load(args []string, lookupEnv func(string) (string, bool)) (Config, Options, error) {
+	c := Default()
+	var opts Options
+	flagValues := map[string]string{}
+	fs := newFlagSet(&c, &opts, flagValues)
+	if err := fs.Parse(args); err != nil {
+		return Config{}, opts, err
+	}
+	if fs.NArg() > 0 {
+		return Config{}, opts, fmt.Errorf("unexpected arguments %q", fs.Args())
+	}
+
+	if opts.File == "" {
+		opts.File, _ = lookupEnv(ConfigFileEnv)
+	}
+	if opts.File != "" {
+		if err := loadFile(&c, opts.File); err != nil {
+			return Config{}, opts, err
+		}
+	}
+
+	var errs []error
+	for _, f := range fields(&c) {
+		raw, ok := lookupEnv(f.env)
+		if f.env == "" || !ok || raw == "" {
+			continue
+		}
+		if err := f.set(raw); err != nil {
+			errs = append(errs, fmt.Errorf("%s %w", f.env, err))
+		}
+	}
+	for _, f := range fields(&c) {
+		raw, ok := flagValues[f.flag]
+		if !ok {
+			continue
+		}
+		if err := f.set(raw); err != nil {
+			errs = append(errs, fmt.Errorf("-%s %w", f.flag, err))
+		}
+	}
+	if err := errors.Join(errs...); err != nil {
+		return Config{}, opts, err
+	}
+
+	if err := c.Validate(); err != nil {
+		return Config{}, opts, fmt.Errorf("invalid configuration:\n%w", err)
+	}
+	return c, opts, nil
+}
+
+// loadFile overlays the values in a JSON config file, rejecting keys that are
+// not configuration values.
+func loadFile(c *Config, path string) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("reading config file: %w", err)
+	}
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(c); err != nil {
+		return fmt.Errorf("invalid config file %s: %w", path, err)
+	}
+	return nil
+}
+
+const redacted = "[REDACTED]"
+
+// RestartRequired lists the keys that differ between running and next and
+// are not tagged reload:"true", so cannot be applied by a reload.
+func RestartRequired(running, next Config) []string {
+	var keys []string
+	nextFields := fields(&next)
+	for i, f := range fields(&running) {
+		if !f.reload && f.String() != nextFields[i].String() {
+			keys = append(keys, f.key)
+		}
+	}
+	return keys
+}
+
+// Redacted returns a copy of c with its secrets replaced, for printing.
+func (c Config) Redacted() Config {
+	for _, f := range fields(&c) {
+		if f.secret && f.value.Kind() == reflect.String && f.value.String() != "" {
+			f.value.SetString(redacted)
+		}
+	}
+	return c
+}
+
+// Print writes c, with secrets redacted, as a JSON config file.
+func Print(w io.Writer, c Config) error {
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(c.Redacted())
+}
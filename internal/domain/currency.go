@@ -0,0 +1,40 @@
+package domain
+
+import "time"
+
+type RoundingMode string
+
+const (
+	RoundHalfUp   RoundingMode = "half_up"
+	RoundHalfEven RoundingMode = "half_even"
+	RoundUp       RoundingMode = "up"
+	RoundDown     RoundingMode = "down"
+)
+
+// CurrencyRounding rounds converted amounts to a multiple of Increment, e.g.
+// 0.01 for EUR, 1 for JPY or 0.05 for cash prices in CHF.
+type CurrencyRounding struct {
+	Increment float64
+	Mode      RoundingMode
+}
+
+// ExchangeRateTable holds how many units of each currency one unit of
+// BaseCurrency buys. Product prices are in BaseCurrency. Every update creates
+// a new table with the next Version.
+type ExchangeRateTable struct {
+	Version      int
+	BaseCurrency string
+	Rates        map[string]float64
+	Rounding     map[string]CurrencyRounding
+	UpdatedAt    time.Time
+}
+
+// ExchangeRate is a single rate taken from an ExchangeRateTable.
+type ExchangeRate struct {
+	BaseCurrency   string
+	Currency       string
+	Rate           float64
+	Rounding       CurrencyRounding
+	TableVersion   int
+	TableUpdatedAt time.Time
+}
@@ -0,0 +1,33 @@
+package domain
+
+type PriceBucket struct {
+	From  float64
+	To    *float64
+	Count int
+}
+
+type StatusCount struct {
+	Status ProductStatus
+	Count  int
+}
+
+// TermCount is how many products have a term, such as a category, a tag or
+// an attribute value.
+type TermCount struct {
+	Term  string
+	Count int
+}
+
+// AttributeFacet counts the products per value of one attribute.
+type AttributeFacet struct {
+	Name   string
+	Values []TermCount
+}
+
+type ProductFacets struct {
+	Price      []PriceBucket
+	Status     []StatusCount
+	Category   []TermCount
+	Tags       []TermCount
+	Attributes []AttributeFacet
+}
@@ -0,0 +1,22 @@
+package domain
+
+import (
+	"net/http"
+	"time"
+)
+
+// IdempotencyRecord is the first response given to a request carrying an
+// Idempotency-Key. Scope is who sent the request, so that two merchants can
+// use the same key independently.
+type IdempotencyRecord struct {
+	Scope       string
+	Key         string
+	RequestHash string
+	// Completed is false while the first request is still being handled
+	Completed  bool
+	StatusCode int
+	Header     http.Header
+	Body       []byte
+	CreatedAt  time.Time
+	ExpiresAt  time.Time
+}
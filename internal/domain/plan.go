@@ -0,0 +1,28 @@
+package domain
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Plan sets the limits a merchant's account works within.
+type Plan struct {
+	Name              string
+	MaxProducts       int
+	MaxImages         int
+	RequestsPerMinute int
+}
+
+type MerchantPlan struct {
+	MerchantId uuid.UUID
+	Plan       string
+	AssignedAt time.Time
+}
+
+type PlanUsage struct {
+	MerchantId uuid.UUID
+	Plan       Plan
+	Products   int
+	Images     int
+}
@@ -0,0 +1,51 @@
+package domain
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type PricingRuleType string
+
+const (
+	// PercentageDiscount takes Value percent off the unit price.
+	PercentageDiscount PricingRuleType = "percentage"
+	// FixedAmountDiscount takes Value off the unit price.
+	FixedAmountDiscount PricingRuleType = "fixed_amount"
+	// FixedPrice replaces the unit price with Value, which is how customer
+	// group price lists are expressed.
+	FixedPrice PricingRuleType = "fixed_price"
+)
+
+type PricingRule struct {
+	ID         uuid.UUID
+	MerchantId uuid.UUID
+	// SKUID scopes the rule to a single product, nil applies it to every
+	// product of the merchant.
+	SKUID *uuid.UUID
+	// Category scopes the rule to the products of a category, compared case
+	// insensitively. A rule is scoped to a SKU or a category, not both.
+	Category string
+	// CustomerGroup scopes the rule to a group, empty applies it to everyone.
+	CustomerGroup string
+	MinQuantity   int
+	Type          PricingRuleType
+	Value         float64
+	CreatedAt     time.Time
+}
+
+type AppliedPricingRule struct {
+	Rule           PricingRule
+	UnitPriceAfter float64
+}
+
+type PriceQuote struct {
+	SKUID         uuid.UUID
+	Quantity      int
+	CustomerGroup string
+	BaseUnitPrice float64
+	UnitPrice     float64
+	Total         float64
+	AppliedRules  []AppliedPricingRule
+}
@@ -1,17 +1,143 @@
 package domain
 
 import (
+	"strings"
 	"time"
 
 	"github.com/google/uuid"
 )
 
 type Product struct {
-	SKUID       uuid.UUID
+	SKUID uuid.UUID
+	// SKUCode is the merchant facing identifier, unique within the merchant
+	SKUCode string
+	// GTIN is a GTIN-8, 12, 13 or 14 and ISBN an ISBN-13, both digits only
+	// and unique within the merchant
+	GTIN        string
+	ISBN        string
 	Name        string
 	Description string
+	// Price is tax exclusive, tax is added per region when prices are read
+	Price    float64
+	TaxClass TaxClass
+	// Category is free text chosen by the merchant, empty when the product is
+	// uncategorized. Pricing rules can be scoped to it.
+	Category string
+	// Tags are free text labels and Attributes named values such as a
+	// colour or size, both used for faceted navigation. Attribute names are
+	// lower case.
+	Tags       []string
+	Attributes map[string]string
+	MerchantId uuid.UUID
+	Images     []ProductImage
+	// PriceChanges are future changes to the regular Price, Sales are time
+	// boxed discounts. Both are applied and reverted by the price scheduler.
+	PriceChanges []PriceChange
+	Sales        []Sale
+	CreatedAt    time.Time
+	UpdatedAt    time.Time
+}
+
+// EffectivePrice is the price customers pay right now, taking an active sale
+// into account.
+func (p Product) EffectivePrice() float64 {
+	if sale, ok := p.ActiveSale(); ok {
+		return sale.Price
+	}
+	return p.Price
+}
+
+func (p Product) ActiveSale() (Sale, bool) {
+	for _, sale := range p.Sales {
+		if sale.Active {
+			return sale, true
+		}
+	}
+	return Sale{}, false
+}
+
+type ProductStatus string
+
+const (
+	ProductStatusOnSale ProductStatus = "on_sale"
+	// ProductStatusScheduled products have a price change or sale coming up
+	ProductStatusScheduled ProductStatus = "scheduled"
+	ProductStatusRegular   ProductStatus = "regular"
+)
+
+var ProductStatuses = []ProductStatus{ProductStatusOnSale, ProductStatusScheduled, ProductStatusRegular}
+
+// Status summarizes the product's pricing for reporting. An active sale takes
+// precedence over upcoming schedules.
+func (p Product) Status() ProductStatus {
+	if _, ok := p.ActiveSale(); ok {
+		return ProductStatusOnSale
+	}
+	if len(p.PriceChanges) > 0 || len(p.Sales) > 0 {
+		return ProductStatusScheduled
+	}
+	return ProductStatusRegular
+}
+
+// CatalogStats are counts across every merchant's catalogue.
+type CatalogStats struct {
+	// Merchants counts merchants with at least one product
+	Merchants        int
+	ProductsByStatus map[ProductStatus]int
+}
+
+// Barcodes returns the product's GTIN and ISBN as GTIN-14s, the form barcodes
+// of different lengths are compared in.
+func (p Product) Barcodes() []string {
+	barcodes := []string{}
+	for _, code := range []string{p.GTIN, p.ISBN} {
+		if code != "" {
+			barcodes = append(barcodes, GTIN14(code))
+		}
+	}
+	return barcodes
+}
+
+// GTIN14 left pads a shorter GTIN with zeros, which leaves its check digit
+// valid.
+func GTIN14(code string) string {
+	if len(code) >= 14 {
+		return code
+	}
+	return strings.Repeat("0", 14-len(code)) + code
+}
+
+type PriceChange struct {
+	ID          uuid.UUID
 	Price       float64
-	MerchantId  uuid.UUID
-	CreatedAt   time.Time
-	UpdatedAt   time.Time
+	EffectiveAt time.Time
+}
+
+type Sale struct {
+	ID       uuid.UUID
+	Price    float64
+	StartsAt time.Time
+	EndsAt   time.Time
+	Active   bool
+}
+
+type ProductImage struct {
+	ID                   uuid.UUID
+	AltText              string
+	Position             int
+	IsPrimary            bool
+	ContentType          string
+	ThumbnailContentType string
+	Size                 int64
+	OriginalKey          string
+	ThumbnailKey         string
+	CreatedAt            time.Time
+}
+
+// SKUCodePattern generates SKU codes for products created without one. See
+// products.RenderSKUCode for the placeholders it supports.
+type SKUCodePattern struct {
+	MerchantId uuid.UUID
+	Pattern    string
+	UpdatedAt  time.Time
 }
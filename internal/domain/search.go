@@ -0,0 +1,16 @@
+package domain
+
+import "github.com/google/uuid"
+
+type ProductSearchHit struct {
+	Product              Product
+	Score                float64
+	NameHighlight        string
+	DescriptionHighlight string
+}
+
+type ProductSuggestion struct {
+	Name     string
+	SKUIDs   []uuid.UUID
+	Distance int
+}
@@ -0,0 +1,26 @@
+package domain
+
+import "time"
+
+type TaxClass string
+
+const (
+	StandardTaxClass TaxClass = "standard"
+	ReducedTaxClass  TaxClass = "reduced"
+	ZeroTaxClass     TaxClass = "zero"
+)
+
+// TaxRegion holds the tax rate of every tax class in a region, as a
+// percentage.
+type TaxRegion struct {
+	Code      string
+	Rates     map[TaxClass]float64
+	UpdatedAt time.Time
+}
+
+// TaxedPrice splits a price into its net amount and the tax on it.
+type TaxedPrice struct {
+	Net   float64
+	Tax   float64
+	Gross float64
+}
@@ -0,0 +1,72 @@
+package events
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/olad5/sal-backend-service/internal/domain"
+)
+
+type ProductEventType string
+
+const (
+	ProductCreated ProductEventType = "product.created"
+	ProductUpdated ProductEventType = "product.updated"
+	ProductDeleted ProductEventType = "product.deleted"
+)
+
+type ProductEvent struct {
+	Type       ProductEventType
+	Product    domain.Product
+	OccurredAt time.Time
+}
+
+type ProductEventHandler func(ctx context.Context, event ProductEvent)
+
+type subscription struct {
+	id      uint64
+	handler ProductEventHandler
+}
+
+// Bus delivers product events to every subscriber synchronously and in
+// subscription order, so read models are up to date once a write returns.
+type Bus struct {
+	subscriptions []subscription
+	nextId        uint64
+	lock          sync.RWMutex
+}
+
+func NewBus() *Bus {
+	return &Bus{}
+}
+
+func (b *Bus) Subscribe(handler ProductEventHandler) (unsubscribe func()) {
+	b.lock.Lock()
+	defer b.lock.Unlock()
+	b.nextId++
+	id := b.nextId
+	b.subscriptions = append(b.subscriptions, subscription{id, handler})
+
+	return func() {
+		b.lock.Lock()
+		defer b.lock.Unlock()
+		for index, s := range b.subscriptions {
+			if s.id == id {
+				b.subscriptions = append(b.subscriptions[:index:index], b.subscriptions[index+1:]...)
+				return
+			}
+		}
+	}
+}
+
+func (b *Bus) Publish(ctx context.Context, eventType ProductEventType, product domain.Product) {
+	b.lock.RLock()
+	subscriptions := b.subscriptions
+	b.lock.RUnlock()
+
+	event := ProductEvent{Type: eventType, Product: product, OccurredAt: time.Now()}
+	for _, s := range subscriptions {
+		s.handler(ctx, event)
+	}
+}
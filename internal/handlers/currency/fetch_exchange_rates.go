@@ -0,0 +1,45 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/olad5/sal-backend-service/internal/domain"
+	"github.com/olad5/sal-backend-service/internal/infra"
+	appErrors "github.com/olad5/sal-backend-service/pkg/errors"
+
+	"github.com/olad5/sal-backend-service/pkg/utils"
+)
+
+func (c CurrencyHandler) FetchExchangeRates(w http.ResponseWriter, r *http.Request) {
+	table, err := c.currencyService.GetExchangeRates(r.Context())
+	c.respondWithExchangeRates(w, table, err)
+}
+
+func (c CurrencyHandler) FetchExchangeRatesByVersion(w http.ResponseWriter, r *http.Request) {
+	version, err := strconv.Atoi(chi.URLParam(r, "version"))
+	if err != nil || version < 1 {
+		utils.ErrorResponse(w, "version must be a positive integer", http.StatusBadRequest)
+		return
+	}
+
+	table, err := c.currencyService.GetExchangeRatesByVersion(r.Context(), version)
+	c.respondWithExchangeRates(w, table, err)
+}
+
+func (c CurrencyHandler) respondWithExchangeRates(w http.ResponseWriter, table domain.ExchangeRateTable, err error) {
+	if err != nil {
+		switch {
+		case errors.Is(err, infra.ErrExchangeRatesNotFound):
+			utils.ErrorResponse(w, infra.ErrExchangeRatesNotFound.Error(), http.StatusNotFound)
+			return
+		default:
+			utils.ErrorResponse(w, appErrors.ErrSomethingWentWrong, http.StatusInternalServerError)
+			return
+		}
+	}
+
+	utils.SuccessResponse(w, "exchange rates retrieved successfully", ToExchangeRateTableDTO(table))
+}
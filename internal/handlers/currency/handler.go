@@ -0,0 +1,19 @@
+package handlers
+
+import (
+	"errors"
+
+	"github.com/olad5/sal-backend-service/internal/usecases/currency"
+)
+
+type CurrencyHandler struct {
+	currencyService currency.CurrencyService
+}
+
+func NewCurrencyHandler(currencyService currency.CurrencyService) (*CurrencyHandler, error) {
+	if currencyService == (currency.CurrencyService{}) {
+		return nil, errors.New("currency service cannot be empty")
+	}
+
+	return &CurrencyHandler{currencyService}, nil
+}
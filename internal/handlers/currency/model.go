@@ -0,0 +1,37 @@
+package handlers
+
+import (
+	"time"
+
+	"github.com/olad5/sal-backend-service/internal/domain"
+)
+
+type CurrencyRoundingDTO struct {
+	Increment float64 `json:"increment"`
+	Mode      string  `json:"mode"`
+}
+
+type ExchangeRateTableDTO struct {
+	Version      int                            `json:"version"`
+	BaseCurrency string                         `json:"base_currency"`
+	Rates        map[string]float64             `json:"rates"`
+	Rounding     map[string]CurrencyRoundingDTO `json:"rounding"`
+	UpdatedAt    *time.Time                     `json:"updated_at"`
+}
+
+func ToExchangeRateTableDTO(table domain.ExchangeRateTable) ExchangeRateTableDTO {
+	rounding := map[string]CurrencyRoundingDTO{}
+	for code, rule := range table.Rounding {
+		rounding[code] = CurrencyRoundingDTO{
+			Increment: rule.Increment,
+			Mode:      string(rule.Mode),
+		}
+	}
+	return ExchangeRateTableDTO{
+		Version:      table.Version,
+		BaseCurrency: table.BaseCurrency,
+		Rates:        table.Rates,
+		Rounding:     rounding,
+		UpdatedAt:    &table.UpdatedAt,
+	}
+}
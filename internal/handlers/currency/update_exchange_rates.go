@@ -0,0 +1,44 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"github.com/olad5/sal-backend-service/internal/usecases/currency"
+	appErrors "github.com/olad5/sal-backend-service/pkg/errors"
+
+	"github.com/olad5/sal-backend-service/pkg/utils"
+)
+
+func (c CurrencyHandler) UpdateExchangeRates(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	if r.Body == nil {
+		utils.ErrorResponse(w, appErrors.ErrMissingBody, http.StatusBadRequest)
+		return
+	}
+
+	var request currency.ExchangeRatesFile
+	err := json.NewDecoder(r.Body).Decode(&request)
+	if err != nil {
+		utils.ErrorResponse(w, appErrors.ErrInvalidJson, http.StatusBadRequest)
+		return
+	}
+
+	table, err := c.currencyService.UpdateExchangeRates(ctx, request)
+	if err != nil {
+		switch {
+		case errors.Is(err, currency.ErrInvalidCurrency),
+			errors.Is(err, currency.ErrUnsupportedCurrency),
+			errors.Is(err, currency.ErrInvalidExchangeRate),
+			errors.Is(err, currency.ErrInvalidRounding):
+			utils.ErrorResponse(w, err.Error(), http.StatusBadRequest)
+			return
+		default:
+			utils.ErrorResponse(w, appErrors.ErrSomethingWentWrong, http.StatusInternalServerError)
+			return
+		}
+	}
+
+	utils.SuccessResponse(w, "exchange rates updated successfully", ToExchangeRateTableDTO(table))
+}
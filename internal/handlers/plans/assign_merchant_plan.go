@@ -0,0 +1,52 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+	"github.com/olad5/sal-backend-service/internal/usecases/plans"
+	appErrors "github.com/olad5/sal-backend-service/pkg/errors"
+
+	"github.com/olad5/sal-backend-service/pkg/utils"
+)
+
+func (p PlanHandler) AssignMerchantPlan(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	merchantId, err := uuid.Parse(chi.URLParam(r, "merchant_id"))
+	if err != nil {
+		utils.ErrorResponse(w, appErrors.ErrInvalidID.Error(), http.StatusBadRequest)
+		return
+	}
+
+	if r.Body == nil {
+		utils.ErrorResponse(w, appErrors.ErrMissingBody, http.StatusBadRequest)
+		return
+	}
+	type requestDTO struct {
+		Plan string `json:"plan"`
+	}
+
+	var request requestDTO
+	err = json.NewDecoder(r.Body).Decode(&request)
+	if err != nil {
+		utils.ErrorResponse(w, appErrors.ErrInvalidJson, http.StatusBadRequest)
+		return
+	}
+
+	plan, err := p.planService.AssignPlan(ctx, merchantId, request.Plan)
+	if err != nil {
+		switch {
+		case errors.Is(err, plans.ErrPlanNotFound):
+			utils.ErrorResponse(w, plans.ErrPlanNotFound.Error(), http.StatusBadRequest)
+			return
+		default:
+			utils.ErrorResponse(w, appErrors.ErrSomethingWentWrong, http.StatusInternalServerError)
+			return
+		}
+	}
+
+	utils.SuccessResponse(w, "plan assigned successfully", ToPlanDTO(plan))
+}
@@ -0,0 +1,11 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/olad5/sal-backend-service/pkg/utils"
+)
+
+func (p PlanHandler) FetchPlans(w http.ResponseWriter, r *http.Request) {
+	utils.SuccessResponse(w, "plans retrieved successfully", ToPlansDTO(p.planService.GetPlans()))
+}
@@ -0,0 +1,19 @@
+package handlers
+
+import (
+	"errors"
+
+	"github.com/olad5/sal-backend-service/internal/usecases/plans"
+)
+
+type PlanHandler struct {
+	planService plans.PlanService
+}
+
+func NewPlanHandler(planService plans.PlanService) (*PlanHandler, error) {
+	if planService == (plans.PlanService{}) {
+		return nil, errors.New("plan service cannot be empty")
+	}
+
+	return &PlanHandler{planService}, nil
+}
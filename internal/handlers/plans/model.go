@@ -0,0 +1,67 @@
+package handlers
+
+import "github.com/olad5/sal-backend-service/internal/domain"
+
+type PlanDTO struct {
+	Name              string `json:"name"`
+	MaxProducts       int    `json:"max_products"`
+	MaxImages         int    `json:"max_images"`
+	RequestsPerMinute int    `json:"requests_per_minute"`
+}
+
+func ToPlanDTO(plan domain.Plan) PlanDTO {
+	return PlanDTO{
+		Name:              plan.Name,
+		MaxProducts:       plan.MaxProducts,
+		MaxImages:         plan.MaxImages,
+		RequestsPerMinute: plan.RequestsPerMinute,
+	}
+}
+
+type PlansDTO struct {
+	Plans []PlanDTO `json:"plans"`
+}
+
+func ToPlansDTO(plans []domain.Plan) PlansDTO {
+	items := []PlanDTO{}
+	for _, plan := range plans {
+		items = append(items, ToPlanDTO(plan))
+	}
+	return PlansDTO{Plans: items}
+}
+
+type QuotaUsageDTO struct {
+	Used      int `json:"used"`
+	Limit     int `json:"limit"`
+	Remaining int `json:"remaining"`
+}
+
+func ToQuotaUsageDTO(used, limit int) QuotaUsageDTO {
+	remaining := limit - used
+	if remaining < 0 {
+		remaining = 0
+	}
+	return QuotaUsageDTO{
+		Used:      used,
+		Limit:     limit,
+		Remaining: remaining,
+	}
+}
+
+type PlanUsageDTO struct {
+	MerchantId        string        `json:"merchant_id"`
+	Plan              PlanDTO       `json:"plan"`
+	Products          QuotaUsageDTO `json:"products"`
+	Images            QuotaUsageDTO `json:"images"`
+	RequestsPerMinute int           `json:"requests_per_minute"`
+}
+
+func ToPlanUsageDTO(usage domain.PlanUsage) PlanUsageDTO {
+	return PlanUsageDTO{
+		MerchantId:        usage.MerchantId.String(),
+		Plan:              ToPlanDTO(usage.Plan),
+		Products:          ToQuotaUsageDTO(usage.Products, usage.Plan.MaxProducts),
+		Images:            ToQuotaUsageDTO(usage.Images, usage.Plan.MaxImages),
+		RequestsPerMinute: usage.Plan.RequestsPerMinute,
+	}
+}
@@ -0,0 +1,90 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+	appMiddleware "github.com/olad5/sal-backend-service/internal/app/middleware"
+	"github.com/olad5/sal-backend-service/internal/domain"
+	"github.com/olad5/sal-backend-service/internal/infra"
+	"github.com/olad5/sal-backend-service/internal/usecases/pricing"
+	appErrors "github.com/olad5/sal-backend-service/pkg/errors"
+
+	"github.com/olad5/sal-backend-service/pkg/utils"
+)
+
+func (p PricingHandler) CreatePricingRule(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	merchantId, err := uuid.Parse(chi.URLParam(r, "merchant_id"))
+	if err != nil {
+		utils.ErrorResponse(w, appErrors.ErrInvalidID.Error(), http.StatusBadRequest)
+		return
+	}
+
+	if err := appMiddleware.AuthorizeMerchant(ctx, merchantId); err != nil {
+		utils.ErrorResponse(w, err.Error(), http.StatusForbidden)
+		return
+	}
+
+	if r.Body == nil {
+		utils.ErrorResponse(w, appErrors.ErrMissingBody, http.StatusBadRequest)
+		return
+	}
+	type requestDTO struct {
+		SKUID         string  `json:"sku_id"`
+		Category      string  `json:"category"`
+		CustomerGroup string  `json:"customer_group"`
+		MinQuantity   *int    `json:"min_quantity"`
+		Type          string  `json:"type"`
+		Value         float64 `json:"value"`
+	}
+
+	var request requestDTO
+	err = json.NewDecoder(r.Body).Decode(&request)
+	if err != nil {
+		utils.ErrorResponse(w, appErrors.ErrInvalidJson, http.StatusBadRequest)
+		return
+	}
+
+	var skuId *uuid.UUID
+	if request.SKUID != "" {
+		id, err := uuid.Parse(request.SKUID)
+		if err != nil {
+			utils.ErrorResponse(w, appErrors.ErrInvalidID.Error(), http.StatusBadRequest)
+			return
+		}
+		skuId = &id
+	}
+
+	minQuantity := 1
+	if request.MinQuantity != nil {
+		minQuantity = *request.MinQuantity
+	}
+
+	newRule, err := p.pricingService.CreatePricingRule(ctx, merchantId, skuId, request.Category, request.CustomerGroup, minQuantity, domain.PricingRuleType(request.Type), request.Value)
+	if err != nil {
+		switch {
+		case errors.Is(err, infra.ErrProductNotFound):
+			utils.ErrorResponse(w, infra.ErrProductNotFound.Error(), http.StatusNotFound)
+			return
+		case errors.Is(err, pricing.ErrUserNotAuthorized):
+			utils.ErrorResponse(w, appErrors.ErrUnauthorized, http.StatusNotFound)
+			return
+		case errors.Is(err, pricing.ErrInvalidRuleType),
+			errors.Is(err, pricing.ErrInvalidRuleValue),
+			errors.Is(err, pricing.ErrInvalidPercentage),
+			errors.Is(err, pricing.ErrInvalidMinQuantity),
+			errors.Is(err, pricing.ErrRuleScopeConflict):
+			utils.ErrorResponse(w, err.Error(), http.StatusBadRequest)
+			return
+		default:
+			utils.ErrorResponse(w, appErrors.ErrSomethingWentWrong, http.StatusInternalServerError)
+			return
+		}
+	}
+
+	utils.SuccessResponse(w, "pricing rule created successfully", ToPricingRuleDTO(newRule))
+}
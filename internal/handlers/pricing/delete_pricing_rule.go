@@ -0,0 +1,51 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+	appMiddleware "github.com/olad5/sal-backend-service/internal/app/middleware"
+	"github.com/olad5/sal-backend-service/internal/infra"
+	"github.com/olad5/sal-backend-service/internal/usecases/pricing"
+	appErrors "github.com/olad5/sal-backend-service/pkg/errors"
+
+	"github.com/olad5/sal-backend-service/pkg/utils"
+)
+
+func (p PricingHandler) DeletePricingRule(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	merchantId, err := uuid.Parse(chi.URLParam(r, "merchant_id"))
+	if err != nil {
+		utils.ErrorResponse(w, appErrors.ErrInvalidID.Error(), http.StatusBadRequest)
+		return
+	}
+
+	if err := appMiddleware.AuthorizeMerchant(ctx, merchantId); err != nil {
+		utils.ErrorResponse(w, err.Error(), http.StatusForbidden)
+		return
+	}
+	ruleId, err := uuid.Parse(chi.URLParam(r, "rule_id"))
+	if err != nil {
+		utils.ErrorResponse(w, appErrors.ErrInvalidID.Error(), http.StatusBadRequest)
+		return
+	}
+
+	err = p.pricingService.DeletePricingRule(ctx, merchantId, ruleId)
+	if err != nil {
+		switch {
+		case errors.Is(err, infra.ErrPricingRuleNotFound):
+			utils.ErrorResponse(w, infra.ErrPricingRuleNotFound.Error(), http.StatusNotFound)
+			return
+		case errors.Is(err, pricing.ErrUserNotAuthorized):
+			utils.ErrorResponse(w, appErrors.ErrUnauthorized, http.StatusNotFound)
+			return
+		default:
+			utils.ErrorResponse(w, appErrors.ErrSomethingWentWrong, http.StatusInternalServerError)
+			return
+		}
+	}
+
+	utils.SuccessResponse(w, "pricing rule deleted successfully", nil)
+}
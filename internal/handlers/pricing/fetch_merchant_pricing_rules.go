@@ -0,0 +1,34 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+	appMiddleware "github.com/olad5/sal-backend-service/internal/app/middleware"
+	appErrors "github.com/olad5/sal-backend-service/pkg/errors"
+
+	"github.com/olad5/sal-backend-service/pkg/utils"
+)
+
+func (p PricingHandler) FetchMerchantPricingRules(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	merchantId, err := uuid.Parse(chi.URLParam(r, "merchant_id"))
+	if err != nil {
+		utils.ErrorResponse(w, appErrors.ErrInvalidID.Error(), http.StatusBadRequest)
+		return
+	}
+
+	if err := appMiddleware.AuthorizeMerchant(ctx, merchantId); err != nil {
+		utils.ErrorResponse(w, err.Error(), http.StatusForbidden)
+		return
+	}
+
+	rules, err := p.pricingService.GetPricingRulesByMerchantId(ctx, merchantId)
+	if err != nil {
+		utils.ErrorResponse(w, appErrors.ErrSomethingWentWrong, http.StatusInternalServerError)
+		return
+	}
+
+	utils.SuccessResponse(w, "pricing rules retrieved successfully", ToPricingRulePagedDTO(rules))
+}
@@ -0,0 +1,19 @@
+package handlers
+
+import (
+	"errors"
+
+	"github.com/olad5/sal-backend-service/internal/usecases/pricing"
+)
+
+type PricingHandler struct {
+	pricingService pricing.PricingService
+}
+
+func NewPricingHandler(pricingService pricing.PricingService) (*PricingHandler, error) {
+	if pricingService == (pricing.PricingService{}) {
+		return nil, errors.New("pricing service cannot be empty")
+	}
+
+	return &PricingHandler{pricingService}, nil
+}
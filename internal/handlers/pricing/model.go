@@ -0,0 +1,88 @@
+package handlers
+
+import (
+	"time"
+
+	"github.com/olad5/sal-backend-service/internal/domain"
+)
+
+type PricingRuleDTO struct {
+	ID            string     `json:"id"`
+	MerchantId    string     `json:"merchant_id"`
+	SKUID         *string    `json:"sku_id"`
+	Category      string     `json:"category"`
+	CustomerGroup string     `json:"customer_group"`
+	MinQuantity   int        `json:"min_quantity"`
+	Type          string     `json:"type"`
+	Value         float64    `json:"value"`
+	CreatedAt     *time.Time `json:"created_at"`
+}
+
+func ToPricingRuleDTO(rule domain.PricingRule) PricingRuleDTO {
+	var skuId *string
+	if rule.SKUID != nil {
+		id := rule.SKUID.String()
+		skuId = &id
+	}
+	return PricingRuleDTO{
+		ID:            rule.ID.String(),
+		MerchantId:    rule.MerchantId.String(),
+		SKUID:         skuId,
+		Category:      rule.Category,
+		CustomerGroup: rule.CustomerGroup,
+		MinQuantity:   rule.MinQuantity,
+		Type:          string(rule.Type),
+		Value:         rule.Value,
+		CreatedAt:     &rule.CreatedAt,
+	}
+}
+
+type PricingRulePagedDTO struct {
+	Limit int              `json:"limit"`
+	Rules []PricingRuleDTO `json:"rules"`
+}
+
+func ToPricingRulePagedDTO(rules []domain.PricingRule) PricingRulePagedDTO {
+	items := []PricingRuleDTO{}
+	for _, rule := range rules {
+		items = append(items, ToPricingRuleDTO(rule))
+	}
+	return PricingRulePagedDTO{
+		Limit: len(items),
+		Rules: items,
+	}
+}
+
+type AppliedPricingRuleDTO struct {
+	Rule           PricingRuleDTO `json:"rule"`
+	UnitPriceAfter float64        `json:"unit_price_after"`
+}
+
+type PriceQuoteDTO struct {
+	SKUID         string                  `json:"sku_id"`
+	Quantity      int                     `json:"quantity"`
+	CustomerGroup string                  `json:"customer_group"`
+	BaseUnitPrice float64                 `json:"base_unit_price"`
+	UnitPrice     float64                 `json:"unit_price"`
+	Total         float64                 `json:"total"`
+	AppliedRules  []AppliedPricingRuleDTO `json:"applied_rules"`
+}
+
+func ToPriceQuoteDTO(quote domain.PriceQuote) PriceQuoteDTO {
+	appliedRules := []AppliedPricingRuleDTO{}
+	for _, applied := range quote.AppliedRules {
+		appliedRules = append(appliedRules, AppliedPricingRuleDTO{
+			Rule:           ToPricingRuleDTO(applied.Rule),
+			UnitPriceAfter: applied.UnitPriceAfter,
+		})
+	}
+	return PriceQuoteDTO{
+		SKUID:         quote.SKUID.String(),
+		Quantity:      quote.Quantity,
+		CustomerGroup: quote.CustomerGroup,
+		BaseUnitPrice: quote.BaseUnitPrice,
+		UnitPrice:     quote.UnitPrice,
+		Total:         quote.Total,
+		AppliedRules:  appliedRules,
+	}
+}
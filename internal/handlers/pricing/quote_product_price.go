@@ -0,0 +1,57 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+	"github.com/olad5/sal-backend-service/internal/infra"
+	"github.com/olad5/sal-backend-service/internal/usecases/pricing"
+	appErrors "github.com/olad5/sal-backend-service/pkg/errors"
+
+	"github.com/olad5/sal-backend-service/pkg/utils"
+)
+
+func (p PricingHandler) QuoteProductPrice(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	skuId, err := uuid.Parse(chi.URLParam(r, "sku_id"))
+	if err != nil {
+		utils.ErrorResponse(w, appErrors.ErrInvalidID.Error(), http.StatusBadRequest)
+		return
+	}
+
+	if r.Body == nil {
+		utils.ErrorResponse(w, appErrors.ErrMissingBody, http.StatusBadRequest)
+		return
+	}
+	type requestDTO struct {
+		Quantity      int    `json:"quantity"`
+		CustomerGroup string `json:"customer_group"`
+	}
+
+	var request requestDTO
+	err = json.NewDecoder(r.Body).Decode(&request)
+	if err != nil {
+		utils.ErrorResponse(w, appErrors.ErrInvalidJson, http.StatusBadRequest)
+		return
+	}
+
+	quote, err := p.pricingService.Quote(ctx, skuId, request.Quantity, request.CustomerGroup)
+	if err != nil {
+		switch {
+		case errors.Is(err, infra.ErrProductNotFound):
+			utils.ErrorResponse(w, infra.ErrProductNotFound.Error(), http.StatusNotFound)
+			return
+		case errors.Is(err, pricing.ErrInvalidQuoteQuantity):
+			utils.ErrorResponse(w, pricing.ErrInvalidQuoteQuantity.Error(), http.StatusBadRequest)
+			return
+		default:
+			utils.ErrorResponse(w, appErrors.ErrSomethingWentWrong, http.StatusInternalServerError)
+			return
+		}
+	}
+
+	utils.SuccessResponse(w, "price quoted successfully", ToPriceQuoteDTO(quote))
+}
@@ -0,0 +1,20 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/olad5/sal-backend-service/internal/usecases/products"
+)
+
+// parseBarcode reads the ?gtin= or ?isbn= filter of the listing endpoints,
+// returning the barcode normalized for lookup or "" when neither is set.
+// ISBN-13s are GTIN-13s, so ?gtin= finds books as well.
+func parseBarcode(r *http.Request) (string, error) {
+	if gtin := r.URL.Query().Get("gtin"); gtin != "" {
+		return products.NormalizeGTIN(gtin)
+	}
+	if isbn := r.URL.Query().Get("isbn"); isbn != "" {
+		return products.NormalizeISBN(isbn)
+	}
+	return "", nil
+}
@@ -1,12 +1,16 @@
 package handlers
 
 import (
-	"encoding/json"
 	"errors"
 	"net/http"
 
 	"github.com/google/uuid"
+	appMiddleware "github.com/olad5/sal-backend-service/internal/app/middleware"
+	"github.com/olad5/sal-backend-service/internal/domain"
+	"github.com/olad5/sal-backend-service/internal/infra"
+	"github.com/olad5/sal-backend-service/internal/usecases/plans"
 	"github.com/olad5/sal-backend-service/internal/usecases/products"
+	"github.com/olad5/sal-backend-service/internal/usecases/tax"
 	appErrors "github.com/olad5/sal-backend-service/pkg/errors"
 
 	"github.com/olad5/sal-backend-service/pkg/utils"
@@ -20,15 +24,22 @@ func (p ProductHandler) CreateProduct(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 	type requestDTO struct {
-		SKUID       string  `json:"sku_id"`
-		MerchantId  string  `json:"merchant_id"`
-		Name        string  `json:"name"`
-		Description string  `json:"description"`
-		Price       float64 `json:"price"`
+		SKUID       string            `json:"sku_id"`
+		SKUCode     string            `json:"sku_code"`
+		GTIN        string            `json:"gtin"`
+		ISBN        string            `json:"isbn"`
+		MerchantId  string            `json:"merchant_id"`
+		Name        string            `json:"name"`
+		Description string            `json:"description"`
+		Price       float64           `json:"price"`
+		TaxClass    string            `json:"tax_class"`
+		Category    string            `json:"category"`
+		Tags        []string          `json:"tags"`
+		Attributes  map[string]string `json:"attributes"`
 	}
 
 	var request requestDTO
-	err := json.NewDecoder(r.Body).Decode(&request)
+	err := decodeRequest(r, &request)
 	if err != nil {
 		utils.ErrorResponse(w, appErrors.ErrInvalidJson, http.StatusBadRequest)
 		return
@@ -47,23 +58,83 @@ func (p ProductHandler) CreateProduct(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	var taxClass domain.TaxClass
+	if request.TaxClass != "" {
+		taxClass, err = tax.ParseTaxClass(request.TaxClass)
+		if err != nil {
+			utils.ErrorResponse(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+	}
+
 	merchantId, err := uuid.Parse(request.MerchantId)
 	if err != nil {
 		utils.ErrorResponse(w, appErrors.ErrInvalidID.Error(), http.StatusBadRequest)
 		return
 	}
-	skuId, err := uuid.Parse(request.SKUID)
+
+	if err := appMiddleware.AuthorizeMerchant(ctx, merchantId); err != nil {
+		utils.ErrorResponse(w, err.Error(), http.StatusForbidden)
+		return
+	}
+	// the server generates an ID when the client does not bring its own
+	skuId := uuid.Nil
+	if request.SKUID != "" {
+		skuId, err = uuid.Parse(request.SKUID)
+		if err != nil {
+			utils.ErrorResponse(w, appErrors.ErrInvalidID.Error(), http.StatusBadRequest)
+			return
+		}
+	}
+	var skuCode string
+	if request.SKUCode != "" {
+		skuCode, err = products.NormalizeSKUCode(request.SKUCode)
+		if err != nil {
+			utils.ErrorResponse(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+	}
+
+	attributes, err := products.NormalizeAttributes(request.Attributes)
 	if err != nil {
-		utils.ErrorResponse(w, appErrors.ErrInvalidID.Error(), http.StatusBadRequest)
+		utils.ErrorResponse(w, err.Error(), http.StatusBadRequest)
 		return
 	}
 
-	newProduct, err := p.productService.CreateProduct(ctx, merchantId, skuId, request.Name, request.Description, request.Price)
+	var gtin, isbn string
+	if request.GTIN != "" {
+		gtin, err = products.NormalizeGTIN(request.GTIN)
+		if err != nil {
+			utils.ErrorResponse(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+	}
+	if request.ISBN != "" {
+		isbn, err = products.NormalizeISBN(request.ISBN)
+		if err != nil {
+			utils.ErrorResponse(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+	}
+
+	newProduct, err := p.productService.CreateProduct(ctx, merchantId, skuId, skuCode, gtin, isbn, request.Name, request.Description, request.Price, taxClass, request.Category, products.NormalizeTags(request.Tags), attributes)
 	if err != nil {
 		switch {
 		case errors.Is(err, products.ErrProductAlreadyExists):
 			utils.ErrorResponse(w, products.ErrProductAlreadyExists.Error(), http.StatusBadRequest)
 			return
+		case errors.Is(err, infra.ErrSkuCodeTaken):
+			utils.ErrorResponse(w, infra.ErrSkuCodeTaken.Error(), http.StatusConflict)
+			return
+		case errors.Is(err, infra.ErrBarcodeTaken):
+			utils.ErrorResponse(w, infra.ErrBarcodeTaken.Error(), http.StatusConflict)
+			return
+		case errors.Is(err, products.ErrSkuCodesExhausted):
+			utils.ErrorResponse(w, products.ErrSkuCodesExhausted.Error(), http.StatusConflict)
+			return
+		case errors.Is(err, plans.ErrQuotaExceeded):
+			utils.ErrorResponse(w, err.Error(), http.StatusForbidden)
+			return
 		default:
 			utils.ErrorResponse(w, appErrors.ErrSomethingWentWrong, http.StatusInternalServerError)
 			return
@@ -0,0 +1,23 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/olad5/sal-backend-service/internal/domain"
+)
+
+// parseCurrency reads the optional ?currency= query parameter shared by the
+// listing, search and by-code endpoints. A nil rate means prices are returned
+// only in the currency they are stored in.
+func (p ProductHandler) parseCurrency(r *http.Request) (*domain.ExchangeRate, error) {
+	code := r.URL.Query().Get("currency")
+	if code == "" {
+		return nil, nil
+	}
+
+	rate, err := p.currencyService.GetExchangeRate(r.Context(), code)
+	if err != nil {
+		return nil, err
+	}
+	return &rate, nil
+}
@@ -1,12 +1,12 @@
 package handlers
 
 import (
-	"encoding/json"
 	"errors"
 	"net/http"
 
 	"github.com/go-chi/chi/v5"
 	"github.com/google/uuid"
+	appMiddleware "github.com/olad5/sal-backend-service/internal/app/middleware"
 	"github.com/olad5/sal-backend-service/internal/infra"
 	"github.com/olad5/sal-backend-service/internal/usecases/products"
 	appErrors "github.com/olad5/sal-backend-service/pkg/errors"
@@ -37,7 +37,7 @@ func (p ProductHandler) DeleteProduct(w http.ResponseWriter, r *http.Request) {
 	}
 
 	var request requestDTO
-	err = json.NewDecoder(r.Body).Decode(&request)
+	err = decodeRequest(r, &request)
 	if err != nil {
 		utils.ErrorResponse(w, appErrors.ErrInvalidJson, http.StatusBadRequest)
 		return
@@ -49,6 +49,11 @@ func (p ProductHandler) DeleteProduct(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	if err := appMiddleware.AuthorizeMerchant(ctx, merchantId); err != nil {
+		utils.ErrorResponse(w, err.Error(), http.StatusForbidden)
+		return
+	}
+
 	err = p.productService.DeleteProduct(ctx, merchantId, skuId)
 	if err != nil {
 		switch {
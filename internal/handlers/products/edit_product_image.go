@@ -0,0 +1,83 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+	appMiddleware "github.com/olad5/sal-backend-service/internal/app/middleware"
+	"github.com/olad5/sal-backend-service/internal/infra"
+	"github.com/olad5/sal-backend-service/internal/usecases/products"
+	appErrors "github.com/olad5/sal-backend-service/pkg/errors"
+
+	"github.com/olad5/sal-backend-service/pkg/utils"
+)
+
+func (p ProductHandler) EditProductImage(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	skuId, err := uuid.Parse(chi.URLParam(r, "sku_id"))
+	if err != nil {
+		utils.ErrorResponse(w, appErrors.ErrInvalidID.Error(), http.StatusBadRequest)
+		return
+	}
+	imageId, err := uuid.Parse(chi.URLParam(r, "image_id"))
+	if err != nil {
+		utils.ErrorResponse(w, appErrors.ErrInvalidID.Error(), http.StatusBadRequest)
+		return
+	}
+
+	if r.Body == nil {
+		utils.ErrorResponse(w, appErrors.ErrMissingBody, http.StatusBadRequest)
+		return
+	}
+	type requestDTO struct {
+		MerchantId string  `json:"merchant_id"`
+		AltText    *string `json:"alt_text"`
+		Position   *int    `json:"position"`
+		IsPrimary  bool    `json:"is_primary"`
+	}
+
+	var request requestDTO
+	err = decodeRequest(r, &request)
+	if err != nil {
+		utils.ErrorResponse(w, appErrors.ErrInvalidJson, http.StatusBadRequest)
+		return
+	}
+
+	if request.Position != nil && *request.Position < 0 {
+		utils.ErrorResponse(w, "position cannot be less than zero", http.StatusBadRequest)
+		return
+	}
+
+	merchantId, err := uuid.Parse(request.MerchantId)
+	if err != nil {
+		utils.ErrorResponse(w, appErrors.ErrInvalidID.Error(), http.StatusBadRequest)
+		return
+	}
+
+	if err := appMiddleware.AuthorizeMerchant(ctx, merchantId); err != nil {
+		utils.ErrorResponse(w, err.Error(), http.StatusForbidden)
+		return
+	}
+
+	updatedImage, err := p.productService.UpdateProductImage(ctx, merchantId, skuId, imageId, request.AltText, request.Position, request.IsPrimary)
+	if err != nil {
+		switch {
+		case errors.Is(err, infra.ErrProductNotFound):
+			utils.ErrorResponse(w, infra.ErrProductNotFound.Error(), http.StatusNotFound)
+			return
+		case errors.Is(err, products.ErrImageNotFound):
+			utils.ErrorResponse(w, products.ErrImageNotFound.Error(), http.StatusNotFound)
+			return
+		case errors.Is(err, products.ErrUserNotAuthorized):
+			utils.ErrorResponse(w, appErrors.ErrUnauthorized, http.StatusNotFound)
+			return
+		default:
+			utils.ErrorResponse(w, appErrors.ErrSomethingWentWrong, http.StatusInternalServerError)
+			return
+		}
+	}
+
+	utils.SuccessResponse(w, "image updated successfully", ToProductImageDTO(skuId.String(), updatedImage))
+}
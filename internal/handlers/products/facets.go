@@ -0,0 +1,53 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/olad5/sal-backend-service/internal/usecases/products"
+)
+
+var (
+	errUnsupportedFacet   = errors.New("unsupported facet, supported facets are: price, status, category, tags, attributes")
+	errInvalidPriceBucket = errors.New("price_buckets must be a comma separated list of numbers")
+)
+
+// parseFacetRequest reads the optional ?facets= and ?price_buckets= query
+// parameters shared by the listing and search endpoints.
+func parseFacetRequest(r *http.Request) (products.FacetRequest, error) {
+	request := products.FacetRequest{}
+	value := r.URL.Query().Get("facets")
+	if value == "" {
+		return request, nil
+	}
+
+	for _, facet := range strings.Split(value, ",") {
+		switch strings.TrimSpace(facet) {
+		case "price":
+			request.Price = true
+		case "status":
+			request.Status = true
+		case "category":
+			request.Category = true
+		case "tags":
+			request.Tags = true
+		case "attributes":
+			request.Attributes = true
+		default:
+			return products.FacetRequest{}, errUnsupportedFacet
+		}
+	}
+
+	if buckets := r.URL.Query().Get("price_buckets"); buckets != "" {
+		for _, bucket := range strings.Split(buckets, ",") {
+			bound, err := strconv.ParseFloat(strings.TrimSpace(bucket), 64)
+			if err != nil {
+				return products.FacetRequest{}, errInvalidPriceBucket
+			}
+			request.PriceBuckets = append(request.PriceBuckets, bound)
+		}
+	}
+	return request, nil
+}
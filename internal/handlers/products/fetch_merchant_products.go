@@ -1,13 +1,18 @@
 package handlers
 
 import (
+	"context"
 	"errors"
 	"net/http"
 
 	"github.com/go-chi/chi/v5"
 	"github.com/google/uuid"
+	appMiddleware "github.com/olad5/sal-backend-service/internal/app/middleware"
+	"github.com/olad5/sal-backend-service/internal/domain"
 	"github.com/olad5/sal-backend-service/internal/infra"
+	"github.com/olad5/sal-backend-service/internal/usecases/currency"
 	"github.com/olad5/sal-backend-service/internal/usecases/products"
+	"github.com/olad5/sal-backend-service/internal/usecases/tax"
 	appErrors "github.com/olad5/sal-backend-service/pkg/errors"
 
 	"github.com/olad5/sal-backend-service/pkg/utils"
@@ -27,7 +32,58 @@ func (p ProductHandler) FetchMerchantProducts(w http.ResponseWriter, r *http.Req
 		return
 	}
 
-	merchantProducts, err := p.productService.GetProductsByMerchantId(ctx, merchantId)
+	if err := appMiddleware.AuthorizeMerchant(ctx, merchantId); err != nil {
+		utils.ErrorResponse(w, err.Error(), http.StatusForbidden)
+		return
+	}
+
+	facetRequest, err := parseFacetRequest(r)
+	if err != nil {
+		utils.ErrorResponse(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	rate, err := p.parseCurrency(r)
+	if err != nil {
+		switch {
+		case errors.Is(err, currency.ErrInvalidCurrency):
+			utils.ErrorResponse(w, currency.ErrInvalidCurrency.Error(), http.StatusBadRequest)
+			return
+		case errors.Is(err, currency.ErrUnsupportedCurrency):
+			utils.ErrorResponse(w, currency.ErrUnsupportedCurrency.Error(), http.StatusBadRequest)
+			return
+		default:
+			utils.ErrorResponse(w, appErrors.ErrSomethingWentWrong, http.StatusInternalServerError)
+			return
+		}
+	}
+
+	region, taxInclusive, err := p.parseTaxRegion(r)
+	if err != nil {
+		switch {
+		case errors.Is(err, errInvalidTaxDisplay),
+			errors.Is(err, tax.ErrInvalidRegion),
+			errors.Is(err, tax.ErrUnsupportedRegion):
+			utils.ErrorResponse(w, err.Error(), http.StatusBadRequest)
+			return
+		default:
+			utils.ErrorResponse(w, appErrors.ErrSomethingWentWrong, http.StatusInternalServerError)
+			return
+		}
+	}
+
+	barcode, err := parseBarcode(r)
+	if err != nil {
+		utils.ErrorResponse(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	var merchantProducts []domain.Product
+	if barcode != "" {
+		merchantProducts, err = p.fetchProductsByBarcode(ctx, merchantId, barcode)
+	} else {
+		merchantProducts, err = p.productService.GetProductsByMerchantId(ctx, merchantId)
+	}
 	if err != nil {
 		switch {
 		case errors.Is(err, infra.ErrProductNotFound):
@@ -42,5 +98,44 @@ func (p ProductHandler) FetchMerchantProducts(w http.ResponseWriter, r *http.Req
 		}
 	}
 
-	utils.SuccessResponse(w, "products retrieved successfully", ToProductPagedDTO(merchantProducts))
+	response := ToProductPagedDTO(merchantProducts)
+	if rate != nil {
+		for index, product := range merchantProducts {
+			response.Products[index].Converted = ToConvertedPricesDTO(product, *rate)
+		}
+	}
+	if region != nil {
+		for index, product := range merchantProducts {
+			response.Products[index].Tax = ToTaxedPricesDTO(product, *region, taxInclusive)
+		}
+	}
+	if !facetRequest.IsEmpty() {
+		facets, err := products.ComputeFacets(merchantProducts, facetRequest)
+		if err != nil {
+			switch {
+			case errors.Is(err, products.ErrInvalidPriceBuckets):
+				utils.ErrorResponse(w, products.ErrInvalidPriceBuckets.Error(), http.StatusBadRequest)
+				return
+			default:
+				utils.ErrorResponse(w, appErrors.ErrSomethingWentWrong, http.StatusInternalServerError)
+				return
+			}
+		}
+		response.Facets = ToProductFacetsDTO(facets)
+	}
+
+	utils.SuccessResponse(w, "products retrieved successfully", response)
+}
+
+// fetchProductsByBarcode lists the merchant's product with the barcode, or
+// none, so barcode lookups keep the shape of the regular listing.
+func (p ProductHandler) fetchProductsByBarcode(ctx context.Context, merchantId uuid.UUID, barcode string) ([]domain.Product, error) {
+	product, err := p.productService.GetProductByGTIN(ctx, merchantId, barcode)
+	if err != nil {
+		if errors.Is(err, infra.ErrProductNotFound) {
+			return []domain.Product{}, nil
+		}
+		return []domain.Product{}, err
+	}
+	return []domain.Product{product}, nil
 }
@@ -0,0 +1,85 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+	appMiddleware "github.com/olad5/sal-backend-service/internal/app/middleware"
+	"github.com/olad5/sal-backend-service/internal/infra"
+	"github.com/olad5/sal-backend-service/internal/usecases/currency"
+	"github.com/olad5/sal-backend-service/internal/usecases/tax"
+	appErrors "github.com/olad5/sal-backend-service/pkg/errors"
+
+	"github.com/olad5/sal-backend-service/pkg/utils"
+)
+
+func (p ProductHandler) FetchProductByCode(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	merchantId, err := uuid.Parse(chi.URLParam(r, "merchant_id"))
+	if err != nil {
+		utils.ErrorResponse(w, appErrors.ErrInvalidID.Error(), http.StatusBadRequest)
+		return
+	}
+
+	if err := appMiddleware.AuthorizeMerchant(ctx, merchantId); err != nil {
+		utils.ErrorResponse(w, err.Error(), http.StatusForbidden)
+		return
+	}
+	code := chi.URLParam(r, "code")
+	if code == "" {
+		utils.ErrorResponse(w, "code required", http.StatusBadRequest)
+		return
+	}
+
+	rate, err := p.parseCurrency(r)
+	if err != nil {
+		switch {
+		case errors.Is(err, currency.ErrInvalidCurrency):
+			utils.ErrorResponse(w, currency.ErrInvalidCurrency.Error(), http.StatusBadRequest)
+			return
+		case errors.Is(err, currency.ErrUnsupportedCurrency):
+			utils.ErrorResponse(w, currency.ErrUnsupportedCurrency.Error(), http.StatusBadRequest)
+			return
+		default:
+			utils.ErrorResponse(w, appErrors.ErrSomethingWentWrong, http.StatusInternalServerError)
+			return
+		}
+	}
+
+	region, taxInclusive, err := p.parseTaxRegion(r)
+	if err != nil {
+		switch {
+		case errors.Is(err, errInvalidTaxDisplay),
+			errors.Is(err, tax.ErrInvalidRegion),
+			errors.Is(err, tax.ErrUnsupportedRegion):
+			utils.ErrorResponse(w, err.Error(), http.StatusBadRequest)
+			return
+		default:
+			utils.ErrorResponse(w, appErrors.ErrSomethingWentWrong, http.StatusInternalServerError)
+			return
+		}
+	}
+
+	product, err := p.productService.GetProductBySkuCode(ctx, merchantId, code)
+	if err != nil {
+		switch {
+		case errors.Is(err, infra.ErrProductNotFound):
+			utils.ErrorResponse(w, infra.ErrProductNotFound.Error(), http.StatusNotFound)
+			return
+		default:
+			utils.ErrorResponse(w, appErrors.ErrSomethingWentWrong, http.StatusInternalServerError)
+			return
+		}
+	}
+
+	response := ToProductDTO(product)
+	if rate != nil {
+		response.Converted = ToConvertedPricesDTO(product, *rate)
+	}
+	if region != nil {
+		response.Tax = ToTaxedPricesDTO(product, *region, taxInclusive)
+	}
+	utils.SuccessResponse(w, "product retrieved successfully", response)
+}
@@ -1,19 +1,48 @@
 package handlers
 
 import (
+	"encoding/json"
 	"errors"
+	"log/slog"
+	"net/http"
 
+	"github.com/olad5/sal-backend-service/internal/usecases/currency"
 	"github.com/olad5/sal-backend-service/internal/usecases/products"
+	"github.com/olad5/sal-backend-service/internal/usecases/tax"
+	"github.com/olad5/sal-backend-service/pkg/tracing"
 )
 
 type ProductHandler struct {
-	productService products.ProductService
+	productService  products.ProductService
+	currencyService currency.CurrencyService
+	taxService      tax.TaxService
+	logger          *slog.Logger
 }
 
-func NewProductHandler(productService products.ProductService) (*ProductHandler, error) {
+func NewProductHandler(productService products.ProductService, currencyService currency.CurrencyService, taxService tax.TaxService, logger *slog.Logger) (*ProductHandler, error) {
 	if productService == (products.ProductService{}) {
 		return nil, errors.New("product service cannot be empty")
 	}
+	if currencyService == (currency.CurrencyService{}) {
+		return nil, errors.New("currency service cannot be empty")
+	}
+
+	if taxService == (tax.TaxService{}) {
+		return nil, errors.New("tax service cannot be empty")
+	}
+	if logger == nil {
+		return nil, errors.New("logger cannot be empty")
+	}
+
+	return &ProductHandler{productService, currencyService, taxService, logger}, nil
+}
 
-	return &ProductHandler{productService}, nil
+// decodeRequest decodes the JSON body into v in a span of its own, so time
+// spent reading large or slow bodies shows up apart from the service's.
+func decodeRequest(r *http.Request, v interface{}) error {
+	_, span := tracing.Start(r.Context(), "decode request")
+	defer span.End()
+	err := json.NewDecoder(r.Body).Decode(v)
+	span.RecordError(err)
+	return err
 }
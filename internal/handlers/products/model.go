@@ -1,36 +1,229 @@
 package handlers
 
 import (
+	"fmt"
 	"time"
 
 	"github.com/olad5/sal-backend-service/internal/domain"
+	"github.com/olad5/sal-backend-service/internal/usecases/currency"
+	"github.com/olad5/sal-backend-service/internal/usecases/tax"
 )
 
 type ProductDTO struct {
-	SKUID       string     `json:"sku_id"`
-	MerchantId  string     `json:"merchant_id"`
-	Name        string     `json:"name"`
-	Description string     `json:"description"`
-	Price       float64    `json:"price"`
-	CreatedAt   *time.Time `json:"created_at"`
-	UpdatedAt   *time.Time `json:"updated_at"`
+	SKUID       string `json:"sku_id"`
+	SKUCode     string `json:"sku_code"`
+	GTIN        string `json:"gtin"`
+	ISBN        string `json:"isbn"`
+	MerchantId  string `json:"merchant_id"`
+	Name        string `json:"name"`
+	Description string `json:"description"`
+	// Price is the regular price, kept alongside RegularPrice for existing clients
+	Price          float64           `json:"price"`
+	RegularPrice   float64           `json:"regular_price"`
+	EffectivePrice float64           `json:"effective_price"`
+	TaxClass       string            `json:"tax_class"`
+	Category       string            `json:"category"`
+	Tags           []string          `json:"tags"`
+	Attributes     map[string]string `json:"attributes"`
+	ActiveSale     *SaleDTO          `json:"active_sale"`
+	Sales          []SaleDTO         `json:"sales"`
+	PriceChanges   []PriceChangeDTO  `json:"price_changes"`
+	Images         []ProductImageDTO `json:"images"`
+	// Converted is only set when a currency was requested
+	Converted *ConvertedPricesDTO `json:"converted,omitempty"`
+	// Tax is only set when a region was requested
+	Tax       *TaxedPricesDTO `json:"tax,omitempty"`
+	CreatedAt *time.Time      `json:"created_at"`
+	UpdatedAt *time.Time      `json:"updated_at"`
 }
 
 func ToProductDTO(product domain.Product) ProductDTO {
+	images := []ProductImageDTO{}
+	for _, image := range product.Images {
+		images = append(images, ToProductImageDTO(product.SKUID.String(), image))
+	}
+	sales := []SaleDTO{}
+	var activeSale *SaleDTO
+	for _, sale := range product.Sales {
+		dto := ToSaleDTO(sale)
+		sales = append(sales, dto)
+		if sale.Active {
+			activeSale = &dto
+		}
+	}
+	priceChanges := []PriceChangeDTO{}
+	for _, change := range product.PriceChanges {
+		priceChanges = append(priceChanges, ToPriceChangeDTO(change))
+	}
+	tags := append([]string{}, product.Tags...)
+	attributes := map[string]string{}
+	for name, value := range product.Attributes {
+		attributes[name] = value
+	}
 	return ProductDTO{
-		SKUID:       product.SKUID.String(),
-		MerchantId:  product.MerchantId.String(),
-		Name:        product.Name,
-		Description: product.Description,
-		Price:       product.Price,
-		CreatedAt:   &product.CreatedAt,
-		UpdatedAt:   &product.UpdatedAt,
+		SKUID:          product.SKUID.String(),
+		SKUCode:        product.SKUCode,
+		GTIN:           product.GTIN,
+		ISBN:           product.ISBN,
+		MerchantId:     product.MerchantId.String(),
+		Name:           product.Name,
+		Description:    product.Description,
+		Price:          product.Price,
+		RegularPrice:   product.Price,
+		EffectivePrice: product.EffectivePrice(),
+		TaxClass:       string(product.TaxClass),
+		Category:       product.Category,
+		Tags:           tags,
+		Attributes:     attributes,
+		ActiveSale:     activeSale,
+		Sales:          sales,
+		PriceChanges:   priceChanges,
+		Images:         images,
+		CreatedAt:      &product.CreatedAt,
+		UpdatedAt:      &product.UpdatedAt,
+	}
+}
+
+type ExchangeRateDTO struct {
+	BaseCurrency string    `json:"base_currency"`
+	Currency     string    `json:"currency"`
+	Rate         float64   `json:"rate"`
+	Version      int       `json:"version"`
+	UpdatedAt    time.Time `json:"updated_at"`
+}
+
+type ConvertedPricesDTO struct {
+	Currency       string          `json:"currency"`
+	Price          float64         `json:"price"`
+	RegularPrice   float64         `json:"regular_price"`
+	EffectivePrice float64         `json:"effective_price"`
+	ExchangeRate   ExchangeRateDTO `json:"exchange_rate"`
+}
+
+func ToConvertedPricesDTO(product domain.Product, rate domain.ExchangeRate) *ConvertedPricesDTO {
+	regularPrice := currency.Convert(rate, product.Price)
+	return &ConvertedPricesDTO{
+		Currency:       rate.Currency,
+		Price:          regularPrice,
+		RegularPrice:   regularPrice,
+		EffectivePrice: currency.Convert(rate, product.EffectivePrice()),
+		ExchangeRate: ExchangeRateDTO{
+			BaseCurrency: rate.BaseCurrency,
+			Currency:     rate.Currency,
+			Rate:         rate.Rate,
+			Version:      rate.TableVersion,
+			UpdatedAt:    rate.TableUpdatedAt,
+		},
+	}
+}
+
+type TaxedPriceDTO struct {
+	Net   float64 `json:"net"`
+	Tax   float64 `json:"tax"`
+	Gross float64 `json:"gross"`
+}
+
+// TaxedPricesDTO reports Price, RegularPrice and EffectivePrice tax inclusive
+// or tax exclusive, with the full breakdown of both alongside.
+type TaxedPricesDTO struct {
+	Region         string        `json:"region"`
+	TaxClass       string        `json:"tax_class"`
+	Rate           float64       `json:"rate"`
+	TaxInclusive   bool          `json:"tax_inclusive"`
+	Price          float64       `json:"price"`
+	RegularPrice   float64       `json:"regular_price"`
+	EffectivePrice float64       `json:"effective_price"`
+	Regular        TaxedPriceDTO `json:"regular"`
+	Effective      TaxedPriceDTO `json:"effective"`
+}
+
+func ToTaxedPricesDTO(product domain.Product, region domain.TaxRegion, inclusive bool) *TaxedPricesDTO {
+	taxClass := product.TaxClass
+	if taxClass == "" {
+		taxClass = domain.StandardTaxClass
+	}
+	regular := tax.Calculate(region, taxClass, product.Price)
+	effective := tax.Calculate(region, taxClass, product.EffectivePrice())
+	regularPrice, effectivePrice := regular.Net, effective.Net
+	if inclusive {
+		regularPrice, effectivePrice = regular.Gross, effective.Gross
+	}
+	return &TaxedPricesDTO{
+		Region:         region.Code,
+		TaxClass:       string(taxClass),
+		Rate:           region.Rates[taxClass],
+		TaxInclusive:   inclusive,
+		Price:          regularPrice,
+		RegularPrice:   regularPrice,
+		EffectivePrice: effectivePrice,
+		Regular:        TaxedPriceDTO{regular.Net, regular.Tax, regular.Gross},
+		Effective:      TaxedPriceDTO{effective.Net, effective.Tax, effective.Gross},
+	}
+}
+
+type SaleDTO struct {
+	ID       string    `json:"id"`
+	Price    float64   `json:"price"`
+	StartsAt time.Time `json:"starts_at"`
+	EndsAt   time.Time `json:"ends_at"`
+	Active   bool      `json:"active"`
+}
+
+func ToSaleDTO(sale domain.Sale) SaleDTO {
+	return SaleDTO{
+		ID:       sale.ID.String(),
+		Price:    sale.Price,
+		StartsAt: sale.StartsAt,
+		EndsAt:   sale.EndsAt,
+		Active:   sale.Active,
+	}
+}
+
+type PriceChangeDTO struct {
+	ID          string    `json:"id"`
+	Price       float64   `json:"price"`
+	EffectiveAt time.Time `json:"effective_at"`
+}
+
+func ToPriceChangeDTO(change domain.PriceChange) PriceChangeDTO {
+	return PriceChangeDTO{
+		ID:          change.ID.String(),
+		Price:       change.Price,
+		EffectiveAt: change.EffectiveAt,
+	}
+}
+
+type ProductImageDTO struct {
+	ID           string     `json:"id"`
+	URL          string     `json:"url"`
+	ThumbnailURL string     `json:"thumbnail_url"`
+	AltText      string     `json:"alt_text"`
+	Position     int        `json:"position"`
+	IsPrimary    bool       `json:"is_primary"`
+	ContentType  string     `json:"content_type"`
+	Size         int64      `json:"size"`
+	CreatedAt    *time.Time `json:"created_at"`
+}
+
+func ToProductImageDTO(skuId string, image domain.ProductImage) ProductImageDTO {
+	url := fmt.Sprintf("/media/products/%s/images/%s", skuId, image.ID)
+	return ProductImageDTO{
+		ID:           image.ID.String(),
+		URL:          url,
+		ThumbnailURL: url + "/thumbnail",
+		AltText:      image.AltText,
+		Position:     image.Position,
+		IsPrimary:    image.IsPrimary,
+		ContentType:  image.ContentType,
+		Size:         image.Size,
+		CreatedAt:    &image.CreatedAt,
 	}
 }
 
 type ProductPagedDTO struct {
-	Limit    int          `json:"limit"`
-	Products []ProductDTO `json:"products"`
+	Limit    int               `json:"limit"`
+	Products []ProductDTO      `json:"products"`
+	Facets   *ProductFacetsDTO `json:"facets,omitempty"`
 }
 
 func ToProductPagedDTO(products []domain.Product) ProductPagedDTO {
@@ -43,3 +236,153 @@ func ToProductPagedDTO(products []domain.Product) ProductPagedDTO {
 		Products: items,
 	}
 }
+
+type ProductHighlightsDTO struct {
+	Name        string `json:"name"`
+	Description string `json:"description"`
+}
+
+type ProductSearchResultDTO struct {
+	Product    ProductDTO           `json:"product"`
+	Score      float64              `json:"score"`
+	Highlights ProductHighlightsDTO `json:"highlights"`
+}
+
+type ProductSearchPagedDTO struct {
+	Limit   int                      `json:"limit"`
+	Offset  int                      `json:"offset"`
+	Total   int                      `json:"total"`
+	Results []ProductSearchResultDTO `json:"results"`
+	Facets  *ProductFacetsDTO        `json:"facets,omitempty"`
+}
+
+func ToProductSearchPagedDTO(hits []domain.ProductSearchHit, limit, offset, total int) ProductSearchPagedDTO {
+	results := []ProductSearchResultDTO{}
+	for _, hit := range hits {
+		results = append(results, ProductSearchResultDTO{
+			Product: ToProductDTO(hit.Product),
+			Score:   hit.Score,
+			Highlights: ProductHighlightsDTO{
+				Name:        hit.NameHighlight,
+				Description: hit.DescriptionHighlight,
+			},
+		})
+	}
+	return ProductSearchPagedDTO{
+		Limit:   limit,
+		Offset:  offset,
+		Total:   total,
+		Results: results,
+	}
+}
+
+type ProductSuggestionDTO struct {
+	Name     string   `json:"name"`
+	SKUIDs   []string `json:"sku_ids"`
+	Distance int      `json:"distance"`
+}
+
+type ProductSuggestionsDTO struct {
+	Prefix      string                 `json:"prefix"`
+	Suggestions []ProductSuggestionDTO `json:"suggestions"`
+}
+
+func ToProductSuggestionsDTO(prefix string, suggestions []domain.ProductSuggestion) ProductSuggestionsDTO {
+	items := []ProductSuggestionDTO{}
+	for _, suggestion := range suggestions {
+		skuIds := []string{}
+		for _, skuId := range suggestion.SKUIDs {
+			skuIds = append(skuIds, skuId.String())
+		}
+		items = append(items, ProductSuggestionDTO{
+			Name:     suggestion.Name,
+			SKUIDs:   skuIds,
+			Distance: suggestion.Distance,
+		})
+	}
+	return ProductSuggestionsDTO{
+		Prefix:      prefix,
+		Suggestions: items,
+	}
+}
+
+type PriceBucketDTO struct {
+	From  float64  `json:"from"`
+	To    *float64 `json:"to"`
+	Count int      `json:"count"`
+}
+
+type StatusCountDTO struct {
+	Status string `json:"status"`
+	Count  int    `json:"count"`
+}
+
+type TermCountDTO struct {
+	Term  string `json:"term"`
+	Count int    `json:"count"`
+}
+
+type AttributeFacetDTO struct {
+	Name   string         `json:"name"`
+	Values []TermCountDTO `json:"values"`
+}
+
+type ProductFacetsDTO struct {
+	Price      []PriceBucketDTO    `json:"price,omitempty"`
+	Status     []StatusCountDTO    `json:"status,omitempty"`
+	Category   []TermCountDTO      `json:"category,omitempty"`
+	Tags       []TermCountDTO      `json:"tags,omitempty"`
+	Attributes []AttributeFacetDTO `json:"attributes,omitempty"`
+}
+
+func ToProductFacetsDTO(facets domain.ProductFacets) *ProductFacetsDTO {
+	dto := &ProductFacetsDTO{}
+	for _, bucket := range facets.Price {
+		dto.Price = append(dto.Price, PriceBucketDTO{
+			From:  bucket.From,
+			To:    bucket.To,
+			Count: bucket.Count,
+		})
+	}
+	for _, count := range facets.Status {
+		dto.Status = append(dto.Status, StatusCountDTO{
+			Status: string(count.Status),
+			Count:  count.Count,
+		})
+	}
+	dto.Category = toTermCountDTOs(facets.Category)
+	dto.Tags = toTermCountDTOs(facets.Tags)
+	for _, facet := range facets.Attributes {
+		dto.Attributes = append(dto.Attributes, AttributeFacetDTO{
+			Name:   facet.Name,
+			Values: toTermCountDTOs(facet.Values),
+		})
+	}
+	return dto
+}
+
+func toTermCountDTOs(counts []domain.TermCount) []TermCountDTO {
+	var items []TermCountDTO
+	for _, count := range counts {
+		items = append(items, TermCountDTO{Term: count.Term, Count: count.Count})
+	}
+	return items
+}
+
+type SKUCodePatternDTO struct {
+	MerchantId string     `json:"merchant_id"`
+	Pattern    string     `json:"pattern"`
+	UpdatedAt  *time.Time `json:"updated_at"`
+}
+
+func ToSKUCodePatternDTO(pattern domain.SKUCodePattern) SKUCodePatternDTO {
+	var updatedAt *time.Time
+	if !pattern.UpdatedAt.IsZero() {
+		updatedAt = &pattern.UpdatedAt
+	}
+	return SKUCodePatternDTO{
+		MerchantId: pattern.MerchantId.String(),
+		Pattern:    pattern.Pattern,
+		UpdatedAt:  updatedAt,
+	}
+}
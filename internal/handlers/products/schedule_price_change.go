@@ -0,0 +1,82 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+	appMiddleware "github.com/olad5/sal-backend-service/internal/app/middleware"
+	"github.com/olad5/sal-backend-service/internal/infra"
+	"github.com/olad5/sal-backend-service/internal/usecases/products"
+	appErrors "github.com/olad5/sal-backend-service/pkg/errors"
+
+	"github.com/olad5/sal-backend-service/pkg/utils"
+)
+
+func (p ProductHandler) SchedulePriceChange(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	skuId, err := uuid.Parse(chi.URLParam(r, "sku_id"))
+	if err != nil {
+		utils.ErrorResponse(w, appErrors.ErrInvalidID.Error(), http.StatusBadRequest)
+		return
+	}
+
+	if r.Body == nil {
+		utils.ErrorResponse(w, appErrors.ErrMissingBody, http.StatusBadRequest)
+		return
+	}
+	type requestDTO struct {
+		MerchantId  string    `json:"merchant_id"`
+		Price       float64   `json:"price"`
+		EffectiveAt time.Time `json:"effective_at"`
+	}
+
+	var request requestDTO
+	err = decodeRequest(r, &request)
+	if err != nil {
+		utils.ErrorResponse(w, appErrors.ErrInvalidJson, http.StatusBadRequest)
+		return
+	}
+
+	if request.Price < 0 {
+		utils.ErrorResponse(w, "price cannot be less than zero", http.StatusBadRequest)
+		return
+	}
+	if request.EffectiveAt.IsZero() {
+		utils.ErrorResponse(w, "effective_at required", http.StatusBadRequest)
+		return
+	}
+
+	merchantId, err := uuid.Parse(request.MerchantId)
+	if err != nil {
+		utils.ErrorResponse(w, appErrors.ErrInvalidID.Error(), http.StatusBadRequest)
+		return
+	}
+
+	if err := appMiddleware.AuthorizeMerchant(ctx, merchantId); err != nil {
+		utils.ErrorResponse(w, err.Error(), http.StatusForbidden)
+		return
+	}
+
+	updatedProduct, err := p.productService.SchedulePriceChange(ctx, merchantId, skuId, request.Price, request.EffectiveAt)
+	if err != nil {
+		switch {
+		case errors.Is(err, infra.ErrProductNotFound):
+			utils.ErrorResponse(w, infra.ErrProductNotFound.Error(), http.StatusNotFound)
+			return
+		case errors.Is(err, products.ErrUserNotAuthorized):
+			utils.ErrorResponse(w, appErrors.ErrUnauthorized, http.StatusNotFound)
+			return
+		case errors.Is(err, products.ErrScheduleInPast):
+			utils.ErrorResponse(w, products.ErrScheduleInPast.Error(), http.StatusBadRequest)
+			return
+		default:
+			utils.ErrorResponse(w, appErrors.ErrSomethingWentWrong, http.StatusInternalServerError)
+			return
+		}
+	}
+
+	utils.SuccessResponse(w, "price change scheduled successfully", ToProductDTO(updatedProduct))
+}
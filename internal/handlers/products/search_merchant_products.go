@@ -0,0 +1,135 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+	appMiddleware "github.com/olad5/sal-backend-service/internal/app/middleware"
+	"github.com/olad5/sal-backend-service/internal/usecases/currency"
+	"github.com/olad5/sal-backend-service/internal/usecases/products"
+	"github.com/olad5/sal-backend-service/internal/usecases/tax"
+	appErrors "github.com/olad5/sal-backend-service/pkg/errors"
+
+	"github.com/olad5/sal-backend-service/pkg/utils"
+)
+
+const (
+	defaultSearchLimit = 20
+	maxSearchLimit     = 100
+)
+
+func (p ProductHandler) SearchMerchantProducts(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	id := chi.URLParam(r, "merchant_id")
+	if id == "" {
+		utils.ErrorResponse(w, "merchant_id required", http.StatusBadRequest)
+		return
+	}
+
+	merchantId, err := uuid.Parse(id)
+	if err != nil {
+		utils.ErrorResponse(w, appErrors.ErrInvalidID.Error(), http.StatusBadRequest)
+		return
+	}
+
+	if err := appMiddleware.AuthorizeMerchant(ctx, merchantId); err != nil {
+		utils.ErrorResponse(w, err.Error(), http.StatusForbidden)
+		return
+	}
+
+	query := r.URL.Query().Get("q")
+	if query == "" {
+		utils.ErrorResponse(w, "q required", http.StatusBadRequest)
+		return
+	}
+
+	limit := defaultSearchLimit
+	if value := r.URL.Query().Get("limit"); value != "" {
+		limit, err = strconv.Atoi(value)
+		if err != nil || limit < 1 || limit > maxSearchLimit {
+			utils.ErrorResponse(w, "limit must be between 1 and "+strconv.Itoa(maxSearchLimit), http.StatusBadRequest)
+			return
+		}
+	}
+
+	offset := 0
+	if value := r.URL.Query().Get("offset"); value != "" {
+		offset, err = strconv.Atoi(value)
+		if err != nil || offset < 0 {
+			utils.ErrorResponse(w, "offset cannot be less than zero", http.StatusBadRequest)
+			return
+		}
+	}
+
+	facetRequest, err := parseFacetRequest(r)
+	if err != nil {
+		utils.ErrorResponse(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	rate, err := p.parseCurrency(r)
+	if err != nil {
+		switch {
+		case errors.Is(err, currency.ErrInvalidCurrency):
+			utils.ErrorResponse(w, currency.ErrInvalidCurrency.Error(), http.StatusBadRequest)
+			return
+		case errors.Is(err, currency.ErrUnsupportedCurrency):
+			utils.ErrorResponse(w, currency.ErrUnsupportedCurrency.Error(), http.StatusBadRequest)
+			return
+		default:
+			utils.ErrorResponse(w, appErrors.ErrSomethingWentWrong, http.StatusInternalServerError)
+			return
+		}
+	}
+
+	region, taxInclusive, err := p.parseTaxRegion(r)
+	if err != nil {
+		switch {
+		case errors.Is(err, errInvalidTaxDisplay),
+			errors.Is(err, tax.ErrInvalidRegion),
+			errors.Is(err, tax.ErrUnsupportedRegion):
+			utils.ErrorResponse(w, err.Error(), http.StatusBadRequest)
+			return
+		default:
+			utils.ErrorResponse(w, appErrors.ErrSomethingWentWrong, http.StatusInternalServerError)
+			return
+		}
+	}
+
+	hits, total, err := p.productService.SearchProducts(ctx, merchantId, query, limit, offset)
+	if err != nil {
+		utils.ErrorResponse(w, appErrors.ErrSomethingWentWrong, http.StatusInternalServerError)
+		return
+	}
+
+	response := ToProductSearchPagedDTO(hits, limit, offset, total)
+	if rate != nil {
+		for index, hit := range hits {
+			response.Results[index].Product.Converted = ToConvertedPricesDTO(hit.Product, *rate)
+		}
+	}
+	if region != nil {
+		for index, hit := range hits {
+			response.Results[index].Product.Tax = ToTaxedPricesDTO(hit.Product, *region, taxInclusive)
+		}
+	}
+	if !facetRequest.IsEmpty() {
+		facets, err := p.productService.SearchProductFacets(ctx, merchantId, query, facetRequest)
+		if err != nil {
+			switch {
+			case errors.Is(err, products.ErrInvalidPriceBuckets):
+				utils.ErrorResponse(w, products.ErrInvalidPriceBuckets.Error(), http.StatusBadRequest)
+				return
+			default:
+				utils.ErrorResponse(w, appErrors.ErrSomethingWentWrong, http.StatusInternalServerError)
+				return
+			}
+		}
+		response.Facets = ToProductFacetsDTO(facets)
+	}
+
+	utils.SuccessResponse(w, "products retrieved successfully", response)
+}
@@ -0,0 +1,61 @@
+package handlers
+
+import (
+	"errors"
+	"io"
+	"net/http"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+	"github.com/olad5/sal-backend-service/internal/infra"
+	"github.com/olad5/sal-backend-service/internal/usecases/products"
+	appErrors "github.com/olad5/sal-backend-service/pkg/errors"
+
+	"github.com/olad5/sal-backend-service/pkg/utils"
+)
+
+func (p ProductHandler) ServeProductImage(w http.ResponseWriter, r *http.Request) {
+	p.serveProductImage(w, r, false)
+}
+
+func (p ProductHandler) ServeProductImageThumbnail(w http.ResponseWriter, r *http.Request) {
+	p.serveProductImage(w, r, true)
+}
+
+func (p ProductHandler) serveProductImage(w http.ResponseWriter, r *http.Request, thumbnail bool) {
+	ctx := r.Context()
+	skuId, err := uuid.Parse(chi.URLParam(r, "sku_id"))
+	if err != nil {
+		utils.ErrorResponse(w, appErrors.ErrInvalidID.Error(), http.StatusBadRequest)
+		return
+	}
+	imageId, err := uuid.Parse(chi.URLParam(r, "image_id"))
+	if err != nil {
+		utils.ErrorResponse(w, appErrors.ErrInvalidID.Error(), http.StatusBadRequest)
+		return
+	}
+
+	contentType, content, err := p.productService.GetProductImageContent(ctx, skuId, imageId, thumbnail)
+	if err != nil {
+		switch {
+		case errors.Is(err, infra.ErrProductNotFound):
+			utils.ErrorResponse(w, infra.ErrProductNotFound.Error(), http.StatusNotFound)
+			return
+		case errors.Is(err, products.ErrImageNotFound), errors.Is(err, infra.ErrBlobNotFound):
+			utils.ErrorResponse(w, products.ErrImageNotFound.Error(), http.StatusNotFound)
+			return
+		default:
+			utils.ErrorResponse(w, appErrors.ErrSomethingWentWrong, http.StatusInternalServerError)
+			return
+		}
+	}
+	defer content.Close()
+
+	// image ids are never reused, so the content behind a URL never changes
+	w.Header().Set("Content-Type", contentType)
+	w.Header().Set("Cache-Control", "public, max-age=31536000, immutable")
+	w.WriteHeader(http.StatusOK)
+	if _, err := io.Copy(w, content); err != nil {
+		p.logger.WarnContext(r.Context(), "failed to send image", "error", err)
+	}
+}
@@ -0,0 +1,61 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+	appMiddleware "github.com/olad5/sal-backend-service/internal/app/middleware"
+	appErrors "github.com/olad5/sal-backend-service/pkg/errors"
+
+	"github.com/olad5/sal-backend-service/pkg/utils"
+)
+
+const (
+	defaultSuggestLimit = 5
+	maxSuggestLimit     = 10
+)
+
+func (p ProductHandler) SuggestProductNames(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	id := chi.URLParam(r, "merchant_id")
+	if id == "" {
+		utils.ErrorResponse(w, "merchant_id required", http.StatusBadRequest)
+		return
+	}
+
+	merchantId, err := uuid.Parse(id)
+	if err != nil {
+		utils.ErrorResponse(w, appErrors.ErrInvalidID.Error(), http.StatusBadRequest)
+		return
+	}
+
+	if err := appMiddleware.AuthorizeMerchant(ctx, merchantId); err != nil {
+		utils.ErrorResponse(w, err.Error(), http.StatusForbidden)
+		return
+	}
+
+	prefix := r.URL.Query().Get("prefix")
+	if prefix == "" {
+		utils.ErrorResponse(w, "prefix required", http.StatusBadRequest)
+		return
+	}
+
+	limit := defaultSuggestLimit
+	if value := r.URL.Query().Get("limit"); value != "" {
+		limit, err = strconv.Atoi(value)
+		if err != nil || limit < 1 || limit > maxSuggestLimit {
+			utils.ErrorResponse(w, "limit must be between 1 and "+strconv.Itoa(maxSuggestLimit), http.StatusBadRequest)
+			return
+		}
+	}
+
+	suggestions, err := p.productService.SuggestProductNames(ctx, merchantId, prefix, limit)
+	if err != nil {
+		utils.ErrorResponse(w, appErrors.ErrSomethingWentWrong, http.StatusInternalServerError)
+		return
+	}
+
+	utils.SuccessResponse(w, "suggestions retrieved successfully", ToProductSuggestionsDTO(prefix, suggestions))
+}
@@ -0,0 +1,36 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/olad5/sal-backend-service/internal/domain"
+)
+
+var errInvalidTaxDisplay = errors.New("tax_display must be inclusive or exclusive")
+
+// parseTaxRegion reads the optional ?region= and ?tax_display= query
+// parameters shared by the listing, search and by-code endpoints. Prices are
+// reported tax inclusive unless tax_display=exclusive is given. A nil region
+// means no tax is added.
+func (p ProductHandler) parseTaxRegion(r *http.Request) (*domain.TaxRegion, bool, error) {
+	inclusive := true
+	switch r.URL.Query().Get("tax_display") {
+	case "", "inclusive":
+	case "exclusive":
+		inclusive = false
+	default:
+		return nil, false, errInvalidTaxDisplay
+	}
+
+	code := r.URL.Query().Get("region")
+	if code == "" {
+		return nil, inclusive, nil
+	}
+
+	region, err := p.taxService.GetTaxRegion(r.Context(), code)
+	if err != nil {
+		return nil, false, err
+	}
+	return &region, inclusive, nil
+}
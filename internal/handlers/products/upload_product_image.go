@@ -0,0 +1,113 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+	appMiddleware "github.com/olad5/sal-backend-service/internal/app/middleware"
+	"github.com/olad5/sal-backend-service/internal/infra"
+	"github.com/olad5/sal-backend-service/internal/usecases/plans"
+	"github.com/olad5/sal-backend-service/internal/usecases/products"
+	appErrors "github.com/olad5/sal-backend-service/pkg/errors"
+
+	"github.com/olad5/sal-backend-service/pkg/utils"
+)
+
+// leaves room for the non-file form fields and multipart boundaries
+const multipartFormOverhead = 1 << 20
+
+func (p ProductHandler) UploadProductImage(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	id := chi.URLParam(r, "sku_id")
+	if id == "" {
+		utils.ErrorResponse(w, "sku_id required", http.StatusBadRequest)
+		return
+	}
+
+	skuId, err := uuid.Parse(id)
+	if err != nil {
+		utils.ErrorResponse(w, appErrors.ErrInvalidID.Error(), http.StatusBadRequest)
+		return
+	}
+
+	if r.Body == nil {
+		utils.ErrorResponse(w, appErrors.ErrMissingBody, http.StatusBadRequest)
+		return
+	}
+
+	r.Body = http.MaxBytesReader(w, r.Body, products.MaxImageSize+multipartFormOverhead)
+	if err := r.ParseMultipartForm(multipartFormOverhead); err != nil {
+		var maxBytesErr *http.MaxBytesError
+		if errors.As(err, &maxBytesErr) {
+			utils.ErrorResponse(w, products.ErrImageTooLarge.Error(), http.StatusRequestEntityTooLarge)
+			return
+		}
+		utils.ErrorResponse(w, appErrors.ErrInvalidMultipartForm, http.StatusBadRequest)
+		return
+	}
+	defer func() {
+		_ = r.MultipartForm.RemoveAll()
+	}()
+
+	merchantId, err := uuid.Parse(r.FormValue("merchant_id"))
+	if err != nil {
+		utils.ErrorResponse(w, appErrors.ErrInvalidID.Error(), http.StatusBadRequest)
+		return
+	}
+
+	if err := appMiddleware.AuthorizeMerchant(ctx, merchantId); err != nil {
+		utils.ErrorResponse(w, err.Error(), http.StatusForbidden)
+		return
+	}
+
+	var isPrimary bool
+	if value := r.FormValue("is_primary"); value != "" {
+		isPrimary, err = strconv.ParseBool(value)
+		if err != nil {
+			utils.ErrorResponse(w, "is_primary must be a boolean", http.StatusBadRequest)
+			return
+		}
+	}
+
+	file, _, err := r.FormFile("image")
+	if err != nil {
+		utils.ErrorResponse(w, "image required", http.StatusBadRequest)
+		return
+	}
+	defer file.Close()
+
+	newImage, err := p.productService.AddProductImage(ctx, merchantId, skuId, file, r.FormValue("alt_text"), isPrimary)
+	if err != nil {
+		switch {
+		case errors.Is(err, infra.ErrProductNotFound):
+			utils.ErrorResponse(w, infra.ErrProductNotFound.Error(), http.StatusNotFound)
+			return
+		case errors.Is(err, plans.ErrQuotaExceeded):
+			utils.ErrorResponse(w, err.Error(), http.StatusForbidden)
+			return
+		case errors.Is(err, products.ErrUserNotAuthorized):
+			utils.ErrorResponse(w, appErrors.ErrUnauthorized, http.StatusNotFound)
+			return
+		case errors.Is(err, products.ErrImageTooLarge):
+			utils.ErrorResponse(w, products.ErrImageTooLarge.Error(), http.StatusRequestEntityTooLarge)
+			return
+		case errors.Is(err, products.ErrUnsupportedImageType):
+			utils.ErrorResponse(w, products.ErrUnsupportedImageType.Error(), http.StatusUnsupportedMediaType)
+			return
+		case errors.Is(err, products.ErrInvalidImage):
+			utils.ErrorResponse(w, products.ErrInvalidImage.Error(), http.StatusBadRequest)
+			return
+		case errors.Is(err, products.ErrImageTooManyPixels):
+			utils.ErrorResponse(w, products.ErrImageTooManyPixels.Error(), http.StatusBadRequest)
+			return
+		default:
+			utils.ErrorResponse(w, appErrors.ErrSomethingWentWrong, http.StatusInternalServerError)
+			return
+		}
+	}
+
+	utils.SuccessResponse(w, "image uploaded successfully", ToProductImageDTO(skuId.String(), newImage))
+}
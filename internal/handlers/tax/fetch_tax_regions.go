@@ -0,0 +1,19 @@
+package handlers
+
+import (
+	"net/http"
+
+	appErrors "github.com/olad5/sal-backend-service/pkg/errors"
+
+	"github.com/olad5/sal-backend-service/pkg/utils"
+)
+
+func (t TaxHandler) FetchTaxRegions(w http.ResponseWriter, r *http.Request) {
+	regions, err := t.taxService.GetTaxRegions(r.Context())
+	if err != nil {
+		utils.ErrorResponse(w, appErrors.ErrSomethingWentWrong, http.StatusInternalServerError)
+		return
+	}
+
+	utils.SuccessResponse(w, "tax regions retrieved successfully", ToTaxRegionsDTO(regions))
+}
@@ -0,0 +1,19 @@
+package handlers
+
+import (
+	"errors"
+
+	"github.com/olad5/sal-backend-service/internal/usecases/tax"
+)
+
+type TaxHandler struct {
+	taxService tax.TaxService
+}
+
+func NewTaxHandler(taxService tax.TaxService) (*TaxHandler, error) {
+	if taxService == (tax.TaxService{}) {
+		return nil, errors.New("tax service cannot be empty")
+	}
+
+	return &TaxHandler{taxService}, nil
+}
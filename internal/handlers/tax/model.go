@@ -0,0 +1,37 @@
+package handlers
+
+import (
+	"time"
+
+	"github.com/olad5/sal-backend-service/internal/domain"
+)
+
+type TaxRegionDTO struct {
+	Region    string             `json:"region"`
+	Rates     map[string]float64 `json:"rates"`
+	UpdatedAt *time.Time         `json:"updated_at"`
+}
+
+func ToTaxRegionDTO(region domain.TaxRegion) TaxRegionDTO {
+	rates := map[string]float64{}
+	for class, rate := range region.Rates {
+		rates[string(class)] = rate
+	}
+	return TaxRegionDTO{
+		Region:    region.Code,
+		Rates:     rates,
+		UpdatedAt: &region.UpdatedAt,
+	}
+}
+
+type TaxRegionsDTO struct {
+	Regions []TaxRegionDTO `json:"regions"`
+}
+
+func ToTaxRegionsDTO(regions []domain.TaxRegion) TaxRegionsDTO {
+	items := []TaxRegionDTO{}
+	for _, region := range regions {
+		items = append(items, ToTaxRegionDTO(region))
+	}
+	return TaxRegionsDTO{Regions: items}
+}
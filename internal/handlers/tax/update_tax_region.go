@@ -0,0 +1,51 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/olad5/sal-backend-service/internal/usecases/tax"
+	appErrors "github.com/olad5/sal-backend-service/pkg/errors"
+
+	"github.com/olad5/sal-backend-service/pkg/utils"
+)
+
+func (t TaxHandler) UpdateTaxRegion(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	code := chi.URLParam(r, "region")
+
+	if r.Body == nil {
+		utils.ErrorResponse(w, appErrors.ErrMissingBody, http.StatusBadRequest)
+		return
+	}
+	type requestDTO struct {
+		Rates map[string]float64 `json:"rates"`
+	}
+
+	var request requestDTO
+	err := json.NewDecoder(r.Body).Decode(&request)
+	if err != nil {
+		utils.ErrorResponse(w, appErrors.ErrInvalidJson, http.StatusBadRequest)
+		return
+	}
+
+	region, err := t.taxService.SetTaxRegion(ctx, code, request.Rates)
+	if err != nil {
+		switch {
+		case errors.Is(err, tax.ErrInvalidRegion),
+			errors.Is(err, tax.ErrInvalidTaxClass),
+			errors.Is(err, tax.ErrInvalidTaxRate),
+			errors.Is(err, tax.ErrMissingTaxRate),
+			errors.Is(err, tax.ErrZeroTaxRate):
+			utils.ErrorResponse(w, err.Error(), http.StatusBadRequest)
+			return
+		default:
+			utils.ErrorResponse(w, appErrors.ErrSomethingWentWrong, http.StatusInternalServerError)
+			return
+		}
+	}
+
+	utils.SuccessResponse(w, "tax region updated successfully", ToTaxRegionDTO(region))
+}
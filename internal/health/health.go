@@ -0,0 +1,175 @@
+// Package health reports whether the service is alive and whether it is
+// ready for traffic, from the components registered with a Checker.
+package health
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/olad5/sal-backend-service/internal/infra"
+)
+
+// Phase is where the service is in its lifecycle. It is only ready between
+// finishing startup and starting to drain for shutdown.
+type Phase string
+
+const (
+	PhaseStarting Phase = "starting"
+	PhaseReady    Phase = "ready"
+	PhaseDraining Phase = "draining"
+)
+
+type Status string
+
+const (
+	StatusOK          Status = "ok"
+	StatusUnavailable Status = "unavailable"
+)
+
+type ComponentReport struct {
+	Status Status `json:"status"`
+	Error  string `json:"error,omitempty"`
+}
+
+type Report struct {
+	Status     Status                     `json:"status"`
+	Phase      Phase                      `json:"phase"`
+	Components map[string]ComponentReport `json:"components"`
+}
+
+type component struct {
+	name    string
+	checker infra.HealthChecker
+	// liveness components fail liveness too, for failures that only a
+	// restart fixes, such as a background worker that stopped
+	liveness bool
+}
+
+type Checker struct {
+	timeout    time.Duration
+	lock       sync.RWMutex
+	phase      Phase
+	components []component
+}
+
+// NewChecker starts in PhaseStarting. Each check is given timeout to answer.
+func NewChecker(timeout time.Duration) (*Checker, error) {
+	if timeout <= 0 {
+		return nil, errors.New("health Checker failed to initialize, timeout must be positive")
+	}
+	return &Checker{timeout: timeout, phase: PhaseStarting}, nil
+}
+
+// Register adds a component to the readiness checks.
+func (c *Checker) Register(name string, checker infra.HealthChecker) {
+	c.register(component{name: name, checker: checker})
+}
+
+// RegisterLiveness adds a component to both the liveness and readiness
+// checks.
+func (c *Checker) RegisterLiveness(name string, checker infra.HealthChecker) {
+	c.register(component{name: name, checker: checker, liveness: true})
+}
+
+func (c *Checker) register(comp component) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	for _, existing := range c.components {
+		if existing.name == comp.name {
+			panic("health: component " + comp.name + " registered twice")
+		}
+	}
+	c.components = append(c.components, comp)
+}
+
+func (c *Checker) Phase() Phase {
+	c.lock.RLock()
+	defer c.lock.RUnlock()
+	return c.phase
+}
+
+// MarkReady ends startup. A service that is already draining stays draining.
+func (c *Checker) MarkReady() {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	if c.phase == PhaseStarting {
+		c.phase = PhaseReady
+	}
+}
+
+// MarkDraining fails readiness from now on, so load balancers stop sending
+// requests before the server shuts down.
+func (c *Checker) MarkDraining() {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	c.phase = PhaseDraining
+}
+
+// Liveness checks the liveness components. It does not depend on the phase,
+// a service that is starting or draining is still alive.
+func (c *Checker) Liveness(ctx context.Context) Report {
+	phase, components := c.snapshot(true)
+	return c.check(ctx, phase, components)
+}
+
+// Readiness checks every component and fails outside PhaseReady.
+func (c *Checker) Readiness(ctx context.Context) Report {
+	phase, components := c.snapshot(false)
+	report := c.check(ctx, phase, components)
+	if phase != PhaseReady {
+		report.Status = StatusUnavailable
+	}
+	return report
+}
+
+func (c *Checker) snapshot(livenessOnly bool) (Phase, []component) {
+	c.lock.RLock()
+	defer c.lock.RUnlock()
+	var components []component
+	for _, comp := range c.components {
+		if comp.liveness || !livenessOnly {
+			components = append(components, comp)
+		}
+	}
+	return c.phase, components
+}
+
+var errCheckTimedOut = errors.New("health check timed out")
+
+// check runs the checks concurrently. A check that does not answer in time is
+// reported as failed and left to finish on its own.
+func (c *Checker) check(ctx context.Context, phase Phase, components []component) Report {
+	ctx, cancel := context.WithTimeout(ctx, c.timeout)
+	defer cancel()
+
+	results := make([]error, len(components))
+	var wg sync.WaitGroup
+	for i, comp := range components {
+		wg.Add(1)
+		go func(i int, comp component) {
+			defer wg.Done()
+			done := make(chan error, 1)
+			go func() { done <- comp.checker.CheckHealth(ctx) }()
+			select {
+			case err := <-done:
+				results[i] = err
+			case <-ctx.Done():
+				results[i] = errCheckTimedOut
+			}
+		}(i, comp)
+	}
+	wg.Wait()
+
+	report := Report{Status: StatusOK, Phase: phase, Components: map[string]ComponentReport{}}
+	for i, comp := range components {
+		if results[i] != nil {
+			report.Status = StatusUnavailable
+			report.Components[comp.name] = ComponentReport{Status: StatusUnavailable, Error: results[i].Error()}
+			continue
+		}
+		report.Components[comp.name] = ComponentReport{Status: StatusOK}
+	}
+	return report
+}
@@ -0,0 +1,16 @@
+package infra
+
+import (
+	"context"
+	"errors"
+
+	"github.com/olad5/sal-backend-service/internal/domain"
+)
+
+var ErrExchangeRatesNotFound = errors.New("exchange rates not found")
+
+type ExchangeRateRepository interface {
+	CreateExchangeRateTable(ctx context.Context, table domain.ExchangeRateTable) error
+	GetLatestExchangeRateTable(ctx context.Context) (domain.ExchangeRateTable, error)
+	GetExchangeRateTableByVersion(ctx context.Context, version int) (domain.ExchangeRateTable, error)
+}
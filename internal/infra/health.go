@@ -0,0 +1,10 @@
+package infra
+
+import "context"
+
+// HealthChecker is implemented by the repositories, storage and background
+// workers the service depends on. CheckHealth returns an error while the
+// component cannot do its work, and should return promptly once ctx is done.
+type HealthChecker interface {
+	CheckHealth(ctx context.Context) error
+}
@@ -0,0 +1,16 @@
+package infra
+
+import (
+	"context"
+
+	"github.com/olad5/sal-backend-service/internal/domain"
+)
+
+type IdempotencyRepository interface {
+	// CreateIdempotencyRecord stores record unless an unexpired record with
+	// the same scope and key exists, in which case that record is returned
+	// and created is false.
+	CreateIdempotencyRecord(ctx context.Context, record domain.IdempotencyRecord) (existing domain.IdempotencyRecord, created bool, err error)
+	UpdateIdempotencyRecord(ctx context.Context, record domain.IdempotencyRecord) error
+	DeleteIdempotencyRecord(ctx context.Context, scope, key string) error
+}
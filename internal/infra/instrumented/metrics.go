@@ -0,0 +1,86 @@
+// Package instrumented wraps repositories to record how long each operation
+// takes and how often it fails, and to trace each call as a span.
+package instrumented
+
+import (
+	"context"
+	"errors"
+
+	"github.com/olad5/sal-backend-service/internal/infra"
+	"github.com/olad5/sal-backend-service/pkg/clock"
+	"github.com/olad5/sal-backend-service/pkg/metrics"
+	"github.com/olad5/sal-backend-service/pkg/tracing"
+)
+
+type Metrics struct {
+	duration *metrics.HistogramVec
+	errors   *metrics.CounterVec
+	clock    clock.Clock
+}
+
+func NewMetrics(registry *metrics.Registry, clock clock.Clock) (*Metrics, error) {
+	if registry == nil {
+		return nil, errors.New("repository Metrics failed to initialize, registry is nil")
+	}
+	if clock == nil {
+		return nil, errors.New("repository Metrics failed to initialize, clock is nil")
+	}
+	return &Metrics{
+		duration: registry.NewHistogramVec("sal_repository_operation_duration_seconds",
+			"Time taken by repository operations.", metrics.DefaultBuckets, "repository", "operation"),
+		errors: registry.NewCounterVec("sal_repository_operation_errors_total",
+			"Repository operations that returned an error, by kind: not_found, conflict or error.", "repository", "operation", "kind"),
+		clock: clock,
+	}, nil
+}
+
+var notFoundErrors = []error{
+	infra.ErrProductNotFound,
+	infra.ErrSKUCodePatternNotFound,
+	infra.ErrMerchantPlanNotFound,
+	infra.ErrPricingRuleNotFound,
+	infra.ErrExchangeRatesNotFound,
+	infra.ErrTaxRegionNotFound,
+}
+
+// errorKind separates the errors callers expect, such as lookups of missing
+// rows, from failures of the store.
+func errorKind(err error) string {
+	for _, notFound := range notFoundErrors {
+		if errors.Is(err, notFound) {
+			return "not_found"
+		}
+	}
+	if errors.Is(err, infra.ErrSkuCodeTaken) || errors.Is(err, infra.ErrBarcodeTaken) {
+		return "conflict"
+	}
+	return "error"
+}
+
+func (m *Metrics) record(ctx context.Context, repository, operation string, call func(ctx context.Context) error) error {
+	ctx, span := tracing.Start(ctx, repository+"."+operation)
+	defer span.End()
+
+	start := m.clock.Now()
+	err := call(ctx)
+	m.duration.WithLabelValues(repository, operation).Observe(m.clock.Now().Sub(start).Seconds())
+	if err != nil {
+		kind := errorKind(err)
+		m.errors.WithLabelValues(repository, operation, kind).Inc()
+		// lookups of missing rows are answers, not failures
+		if kind != "not_found" {
+			span.RecordError(err)
+		}
+	}
+	return err
+}
+
+func observe[T any](ctx context.Context, m *Metrics, repository, operation string, call func(ctx context.Context) (T, error)) (T, error) {
+	var result T
+	err := m.record(ctx, repository, operation, func(ctx context.Context) error {
+		var err error
+		result, err = call(ctx)
+		return err
+	})
+	return result, err
+}
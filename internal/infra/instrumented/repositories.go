@@ -0,0 +1,285 @@
+package instrumented
+
+import (
+	"context"
+	"errors"
+
+	"github.com/google/uuid"
+	"github.com/olad5/sal-backend-service/internal/domain"
+	"github.com/olad5/sal-backend-service/internal/infra"
+)
+
+var errNilRepository = errors.New("instrumented repository failed to initialize, repository is nil")
+
+type ProductRepository struct {
+	repo    infra.ProductRepository
+	metrics *Metrics
+}
+
+func NewProductRepository(repo infra.ProductRepository, metrics *Metrics) (*ProductRepository, error) {
+	if repo == nil || metrics == nil {
+		return nil, errNilRepository
+	}
+	return &ProductRepository{repo, metrics}, nil
+}
+
+const productRepository = "product"
+
+func (r *ProductRepository) CreateProduct(ctx context.Context, product domain.Product, maxMerchantProducts int) error {
+	return r.metrics.record(ctx, productRepository, "CreateProduct", func(ctx context.Context) error {
+		return r.repo.CreateProduct(ctx, product, maxMerchantProducts)
+	})
+}
+
+func (r *ProductRepository) GetProductBySkuId(ctx context.Context, skuId uuid.UUID) (domain.Product, error) {
+	return observe(ctx, r.metrics, productRepository, "GetProductBySkuId", func(ctx context.Context) (domain.Product, error) {
+		return r.repo.GetProductBySkuId(ctx, skuId)
+	})
+}
+
+func (r *ProductRepository) GetProductsByMerchantId(ctx context.Context, merchantId uuid.UUID) ([]domain.Product, error) {
+	return observe(ctx, r.metrics, productRepository, "GetProductsByMerchantId", func(ctx context.Context) ([]domain.Product, error) {
+		return r.repo.GetProductsByMerchantId(ctx, merchantId)
+	})
+}
+
+func (r *ProductRepository) GetProductBySkuCode(ctx context.Context, merchantId uuid.UUID, skuCode string) (domain.Product, error) {
+	return observe(ctx, r.metrics, productRepository, "GetProductBySkuCode", func(ctx context.Context) (domain.Product, error) {
+		return r.repo.GetProductBySkuCode(ctx, merchantId, skuCode)
+	})
+}
+
+func (r *ProductRepository) GetProductByGTIN(ctx context.Context, merchantId uuid.UUID, gtin string) (domain.Product, error) {
+	return observe(ctx, r.metrics, productRepository, "GetProductByGTIN", func(ctx context.Context) (domain.Product, error) {
+		return r.repo.GetProductByGTIN(ctx, merchantId, gtin)
+	})
+}
+
+func (r *ProductRepository) UpdateProductByProductId(ctx context.Context, product domain.Product) error {
+	return r.metrics.record(ctx, productRepository, "UpdateProductByProductId", func(ctx context.Context) error {
+		return r.repo.UpdateProductByProductId(ctx, product)
+	})
+}
+
+func (r *ProductRepository) AddProductImage(ctx context.Context, product domain.Product, maxMerchantImages int) error {
+	return r.metrics.record(ctx, productRepository, "AddProductImage", func(ctx context.Context) error {
+		return r.repo.AddProductImage(ctx, product, maxMerchantImages)
+	})
+}
+
+func (r *ProductRepository) DeleteProductBySkuId(ctx context.Context, skuId uuid.UUID) error {
+	return r.metrics.record(ctx, productRepository, "DeleteProductBySkuId", func(ctx context.Context) error {
+		return r.repo.DeleteProductBySkuId(ctx, skuId)
+	})
+}
+
+func (r *ProductRepository) GetCatalogStats(ctx context.Context) (domain.CatalogStats, error) {
+	return observe(ctx, r.metrics, productRepository, "GetCatalogStats", func(ctx context.Context) (domain.CatalogStats, error) {
+		return r.repo.GetCatalogStats(ctx)
+	})
+}
+
+type SKUCodePatternRepository struct {
+	repo    infra.SKUCodePatternRepository
+	metrics *Metrics
+}
+
+func NewSKUCodePatternRepository(repo infra.SKUCodePatternRepository, metrics *Metrics) (*SKUCodePatternRepository, error) {
+	if repo == nil || metrics == nil {
+		return nil, errNilRepository
+	}
+	return &SKUCodePatternRepository{repo, metrics}, nil
+}
+
+const skuCodePatternRepository = "sku_code_pattern"
+
+func (r *SKUCodePatternRepository) SaveSKUCodePattern(ctx context.Context, pattern domain.SKUCodePattern) error {
+	return r.metrics.record(ctx, skuCodePatternRepository, "SaveSKUCodePattern", func(ctx context.Context) error {
+		return r.repo.SaveSKUCodePattern(ctx, pattern)
+	})
+}
+
+func (r *SKUCodePatternRepository) GetSKUCodePatternByMerchantId(ctx context.Context, merchantId uuid.UUID) (domain.SKUCodePattern, error) {
+	return observe(ctx, r.metrics, skuCodePatternRepository, "GetSKUCodePatternByMerchantId", func(ctx context.Context) (domain.SKUCodePattern, error) {
+		return r.repo.GetSKUCodePatternByMerchantId(ctx, merchantId)
+	})
+}
+
+func (r *SKUCodePatternRepository) NextSKUCodeSequence(ctx context.Context, merchantId uuid.UUID) (int, error) {
+	return observe(ctx, r.metrics, skuCodePatternRepository, "NextSKUCodeSequence", func(ctx context.Context) (int, error) {
+		return r.repo.NextSKUCodeSequence(ctx, merchantId)
+	})
+}
+
+type MerchantPlanRepository struct {
+	repo    infra.MerchantPlanRepository
+	metrics *Metrics
+}
+
+func NewMerchantPlanRepository(repo infra.MerchantPlanRepository, metrics *Metrics) (*MerchantPlanRepository, error) {
+	if repo == nil || metrics == nil {
+		return nil, errNilRepository
+	}
+	return &MerchantPlanRepository{repo, metrics}, nil
+}
+
+const merchantPlanRepository = "merchant_plan"
+
+func (r *MerchantPlanRepository) SaveMerchantPlan(ctx context.Context, merchantPlan domain.MerchantPlan) error {
+	return r.metrics.record(ctx, merchantPlanRepository, "SaveMerchantPlan", func(ctx context.Context) error {
+		return r.repo.SaveMerchantPlan(ctx, merchantPlan)
+	})
+}
+
+func (r *MerchantPlanRepository) GetMerchantPlanByMerchantId(ctx context.Context, merchantId uuid.UUID) (domain.MerchantPlan, error) {
+	return observe(ctx, r.metrics, merchantPlanRepository, "GetMerchantPlanByMerchantId", func(ctx context.Context) (domain.MerchantPlan, error) {
+		return r.repo.GetMerchantPlanByMerchantId(ctx, merchantId)
+	})
+}
+
+type PricingRuleRepository struct {
+	repo    infra.PricingRuleRepository
+	metrics *Metrics
+}
+
+func NewPricingRuleRepository(repo infra.PricingRuleRepository, metrics *Metrics) (*PricingRuleRepository, error) {
+	if repo == nil || metrics == nil {
+		return nil, errNilRepository
+	}
+	return &PricingRuleRepository{repo, metrics}, nil
+}
+
+const pricingRuleRepository = "pricing_rule"
+
+func (r *PricingRuleRepository) CreatePricingRule(ctx context.Context, rule domain.PricingRule) error {
+	return r.metrics.record(ctx, pricingRuleRepository, "CreatePricingRule", func(ctx context.Context) error {
+		return r.repo.CreatePricingRule(ctx, rule)
+	})
+}
+
+func (r *PricingRuleRepository) GetPricingRuleById(ctx context.Context, ruleId uuid.UUID) (domain.PricingRule, error) {
+	return observe(ctx, r.metrics, pricingRuleRepository, "GetPricingRuleById", func(ctx context.Context) (domain.PricingRule, error) {
+		return r.repo.GetPricingRuleById(ctx, ruleId)
+	})
+}
+
+func (r *PricingRuleRepository) GetPricingRulesByMerchantId(ctx context.Context, merchantId uuid.UUID) ([]domain.PricingRule, error) {
+	return observe(ctx, r.metrics, pricingRuleRepository, "GetPricingRulesByMerchantId", func(ctx context.Context) ([]domain.PricingRule, error) {
+		return r.repo.GetPricingRulesByMerchantId(ctx, merchantId)
+	})
+}
+
+func (r *PricingRuleRepository) DeletePricingRuleById(ctx context.Context, ruleId uuid.UUID) error {
+	return r.metrics.record(ctx, pricingRuleRepository, "DeletePricingRuleById", func(ctx context.Context) error {
+		return r.repo.DeletePricingRuleById(ctx, ruleId)
+	})
+}
+
+type ExchangeRateRepository struct {
+	repo    infra.ExchangeRateRepository
+	metrics *Metrics
+}
+
+func NewExchangeRateRepository(repo infra.ExchangeRateRepository, metrics *Metrics) (*ExchangeRateRepository, error) {
+	if repo == nil || metrics == nil {
+		return nil, errNilRepository
+	}
+	return &ExchangeRateRepository{repo, metrics}, nil
+}
+
+const exchangeRateRepository = "exchange_rate"
+
+func (r *ExchangeRateRepository) CreateExchangeRateTable(ctx context.Context, table domain.ExchangeRateTable) error {
+	return r.metrics.record(ctx, exchangeRateRepository, "CreateExchangeRateTable", func(ctx context.Context) error {
+		return r.repo.CreateExchangeRateTable(ctx, table)
+	})
+}
+
+func (r *ExchangeRateRepository) GetLatestExchangeRateTable(ctx context.Context) (domain.ExchangeRateTable, error) {
+	return observe(ctx, r.metrics, exchangeRateRepository, "GetLatestExchangeRateTable", func(ctx context.Context) (domain.ExchangeRateTable, error) {
+		return r.repo.GetLatestExchangeRateTable(ctx)
+	})
+}
+
+func (r *ExchangeRateRepository) GetExchangeRateTableByVersion(ctx context.Context, version int) (domain.ExchangeRateTable, error) {
+	return observe(ctx, r.metrics, exchangeRateRepository, "GetExchangeRateTableByVersion", func(ctx context.Context) (domain.ExchangeRateTable, error) {
+		return r.repo.GetExchangeRateTableByVersion(ctx, version)
+	})
+}
+
+type TaxRateRepository struct {
+	repo    infra.TaxRateRepository
+	metrics *Metrics
+}
+
+func NewTaxRateRepository(repo infra.TaxRateRepository, metrics *Metrics) (*TaxRateRepository, error) {
+	if repo == nil || metrics == nil {
+		return nil, errNilRepository
+	}
+	return &TaxRateRepository{repo, metrics}, nil
+}
+
+const taxRateRepository = "tax_rate"
+
+func (r *TaxRateRepository) SaveTaxRegion(ctx context.Context, region domain.TaxRegion) error {
+	return r.metrics.record(ctx, taxRateRepository, "SaveTaxRegion", func(ctx context.Context) error {
+		return r.repo.SaveTaxRegion(ctx, region)
+	})
+}
+
+func (r *TaxRateRepository) SaveTaxRegions(ctx context.Context, regions []domain.TaxRegion, removed []string) error {
+	return r.metrics.record(ctx, taxRateRepository, "SaveTaxRegions", func(ctx context.Context) error {
+		return r.repo.SaveTaxRegions(ctx, regions, removed)
+	})
+}
+
+func (r *TaxRateRepository) GetTaxRegionByCode(ctx context.Context, code string) (domain.TaxRegion, error) {
+	return observe(ctx, r.metrics, taxRateRepository, "GetTaxRegionByCode", func(ctx context.Context) (domain.TaxRegion, error) {
+		return r.repo.GetTaxRegionByCode(ctx, code)
+	})
+}
+
+func (r *TaxRateRepository) GetTaxRegions(ctx context.Context) ([]domain.TaxRegion, error) {
+	return observe(ctx, r.metrics, taxRateRepository, "GetTaxRegions", func(ctx context.Context) ([]domain.TaxRegion, error) {
+		return r.repo.GetTaxRegions(ctx)
+	})
+}
+
+type IdempotencyRepository struct {
+	repo    infra.IdempotencyRepository
+	metrics *Metrics
+}
+
+func NewIdempotencyRepository(repo infra.IdempotencyRepository, metrics *Metrics) (*IdempotencyRepository, error) {
+	if repo == nil || metrics == nil {
+		return nil, errNilRepository
+	}
+	return &IdempotencyRepository{repo, metrics}, nil
+}
+
+const idempotencyRepository = "idempotency"
+
+func (r *IdempotencyRepository) CreateIdempotencyRecord(ctx context.Context, record domain.IdempotencyRecord) (domain.IdempotencyRecord, bool, error) {
+	var created bool
+	existing, err := observe(ctx, r.metrics, idempotencyRepository, "CreateIdempotencyRecord", func(ctx context.Context) (domain.IdempotencyRecord, error) {
+		var (
+			existing domain.IdempotencyRecord
+			err      error
+		)
+		existing, created, err = r.repo.CreateIdempotencyRecord(ctx, record)
+		return existing, err
+	})
+	return existing, created, err
+}
+
+func (r *IdempotencyRepository) UpdateIdempotencyRecord(ctx context.Context, record domain.IdempotencyRecord) error {
+	return r.metrics.record(ctx, idempotencyRepository, "UpdateIdempotencyRecord", func(ctx context.Context) error {
+		return r.repo.UpdateIdempotencyRecord(ctx, record)
+	})
+}
+
+func (r *IdempotencyRepository) DeleteIdempotencyRecord(ctx context.Context, scope, key string) error {
+	return r.metrics.record(ctx, idempotencyRepository, "DeleteIdempotencyRecord", func(ctx context.Context) error {
+		return r.repo.DeleteIdempotencyRecord(ctx, scope, key)
+	})
+}
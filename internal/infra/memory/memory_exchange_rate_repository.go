@@ -0,0 +1,69 @@
+package memory
+
+import (
+	"context"
+	"sync"
+
+	"github.com/olad5/sal-backend-service/internal/domain"
+	"github.com/olad5/sal-backend-service/internal/infra"
+)
+
+type MemoryExchangeRateRepository struct {
+	tables map[int]domain.ExchangeRateTable
+	latest int
+	lock   sync.RWMutex
+}
+
+func NewMemoryExchangeRateRepo() (*MemoryExchangeRateRepository, error) {
+	return &MemoryExchangeRateRepository{
+		tables: map[int]domain.ExchangeRateTable{},
+	}, nil
+}
+
+func (m *MemoryExchangeRateRepository) CreateExchangeRateTable(ctx context.Context, table domain.ExchangeRateTable) error {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+	if m.tables == nil {
+		return ErrMemoryStoreAccess
+	}
+	m.tables[table.Version] = table
+	if table.Version > m.latest {
+		m.latest = table.Version
+	}
+	return nil
+}
+
+func (m *MemoryExchangeRateRepository) GetLatestExchangeRateTable(ctx context.Context) (domain.ExchangeRateTable, error) {
+	m.lock.RLock()
+	defer m.lock.RUnlock()
+	if m.tables == nil {
+		return domain.ExchangeRateTable{}, ErrMemoryStoreAccess
+	}
+	table, ok := m.tables[m.latest]
+	if !ok {
+		return domain.ExchangeRateTable{}, infra.ErrExchangeRatesNotFound
+	}
+	return table, nil
+}
+
+func (m *MemoryExchangeRateRepository) GetExchangeRateTableByVersion(ctx context.Context, version int) (domain.ExchangeRateTable, error) {
+	m.lock.RLock()
+	defer m.lock.RUnlock()
+	if m.tables == nil {
+		return domain.ExchangeRateTable{}, ErrMemoryStoreAccess
+	}
+	table, ok := m.tables[version]
+	if !ok {
+		return domain.ExchangeRateTable{}, infra.ErrExchangeRatesNotFound
+	}
+	return table, nil
+}
+
+func (m *MemoryExchangeRateRepository) CheckHealth(ctx context.Context) error {
+	m.lock.RLock()
+	defer m.lock.RUnlock()
+	if m.tables == nil {
+		return ErrMemoryStoreAccess
+	}
+	return nil
+}
@@ -0,0 +1,107 @@
+package memory
+
+import (
+	"container/heap"
+	"context"
+	"sync"
+	"time"
+
+	"github.com/olad5/sal-backend-service/internal/domain"
+)
+
+type idempotencyKey struct {
+	scope string
+	key   string
+}
+
+type idempotencyExpiry struct {
+	id        idempotencyKey
+	expiresAt time.Time
+}
+
+type idempotencyExpiryQueue []idempotencyExpiry
+
+func (q idempotencyExpiryQueue) Len() int            { return len(q) }
+func (q idempotencyExpiryQueue) Less(i, j int) bool  { return q[i].expiresAt.Before(q[j].expiresAt) }
+func (q idempotencyExpiryQueue) Swap(i, j int)       { q[i], q[j] = q[j], q[i] }
+func (q *idempotencyExpiryQueue) Push(x interface{}) { *q = append(*q, x.(idempotencyExpiry)) }
+func (q *idempotencyExpiryQueue) Pop() interface{} {
+	old := *q
+	item := old[len(old)-1]
+	*q = old[:len(old)-1]
+	return item
+}
+
+// MemoryIdempotencyRepository drops expired records as new ones are created,
+// so memory use is bounded by the number of keys seen within one TTL.
+type MemoryIdempotencyRepository struct {
+	records map[idempotencyKey]domain.IdempotencyRecord
+	expiry  idempotencyExpiryQueue
+	lock    sync.Mutex
+}
+
+func NewMemoryIdempotencyRepo() (*MemoryIdempotencyRepository, error) {
+	return &MemoryIdempotencyRepository{
+		records: map[idempotencyKey]domain.IdempotencyRecord{},
+	}, nil
+}
+
+func (m *MemoryIdempotencyRepository) CreateIdempotencyRecord(ctx context.Context, record domain.IdempotencyRecord) (domain.IdempotencyRecord, bool, error) {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+	if m.records == nil {
+		return domain.IdempotencyRecord{}, false, ErrMemoryStoreAccess
+	}
+	m.purgeExpired(record.CreatedAt)
+
+	id := idempotencyKey{record.Scope, record.Key}
+	if existing, ok := m.records[id]; ok {
+		return existing, false, nil
+	}
+	m.records[id] = record
+	heap.Push(&m.expiry, idempotencyExpiry{id, record.ExpiresAt})
+	return record, true, nil
+}
+
+func (m *MemoryIdempotencyRepository) UpdateIdempotencyRecord(ctx context.Context, record domain.IdempotencyRecord) error {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+	if m.records == nil {
+		return ErrMemoryStoreAccess
+	}
+	id := idempotencyKey{record.Scope, record.Key}
+	if _, ok := m.records[id]; ok {
+		m.records[id] = record
+	}
+	return nil
+}
+
+func (m *MemoryIdempotencyRepository) DeleteIdempotencyRecord(ctx context.Context, scope, key string) error {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+	if m.records == nil {
+		return ErrMemoryStoreAccess
+	}
+	delete(m.records, idempotencyKey{scope, key})
+	return nil
+}
+
+func (m *MemoryIdempotencyRepository) purgeExpired(now time.Time) {
+	for m.expiry.Len() > 0 && !m.expiry[0].expiresAt.After(now) {
+		item := heap.Pop(&m.expiry).(idempotencyExpiry)
+		// the key may have been deleted and reused since, only drop the
+		// record this expiry belongs to
+		if record, ok := m.records[item.id]; ok && record.ExpiresAt.Equal(item.expiresAt) {
+			delete(m.records, item.id)
+		}
+	}
+}
+
+func (m *MemoryIdempotencyRepository) CheckHealth(ctx context.Context) error {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+	if m.records == nil {
+		return ErrMemoryStoreAccess
+	}
+	return nil
+}
@@ -0,0 +1,53 @@
+package memory
+
+import (
+	"context"
+	"sync"
+
+	"github.com/google/uuid"
+	"github.com/olad5/sal-backend-service/internal/domain"
+	"github.com/olad5/sal-backend-service/internal/infra"
+)
+
+type MemoryMerchantPlanRepository struct {
+	merchantPlans map[uuid.UUID]domain.MerchantPlan
+	lock          sync.RWMutex
+}
+
+func NewMemoryMerchantPlanRepo() (*MemoryMerchantPlanRepository, error) {
+	return &MemoryMerchantPlanRepository{
+		merchantPlans: map[uuid.UUID]domain.MerchantPlan{},
+	}, nil
+}
+
+func (m *MemoryMerchantPlanRepository) SaveMerchantPlan(ctx context.Context, merchantPlan domain.MerchantPlan) error {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+	if m.merchantPlans == nil {
+		return ErrMemoryStoreAccess
+	}
+	m.merchantPlans[merchantPlan.MerchantId] = merchantPlan
+	return nil
+}
+
+func (m *MemoryMerchantPlanRepository) GetMerchantPlanByMerchantId(ctx context.Context, merchantId uuid.UUID) (domain.MerchantPlan, error) {
+	m.lock.RLock()
+	defer m.lock.RUnlock()
+	if m.merchantPlans == nil {
+		return domain.MerchantPlan{}, ErrMemoryStoreAccess
+	}
+	merchantPlan, ok := m.merchantPlans[merchantId]
+	if !ok {
+		return domain.MerchantPlan{}, infra.ErrMerchantPlanNotFound
+	}
+	return merchantPlan, nil
+}
+
+func (m *MemoryMerchantPlanRepository) CheckHealth(ctx context.Context) error {
+	m.lock.RLock()
+	defer m.lock.RUnlock()
+	if m.merchantPlans == nil {
+		return ErrMemoryStoreAccess
+	}
+	return nil
+}
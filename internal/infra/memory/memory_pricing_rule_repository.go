@@ -0,0 +1,91 @@
+package memory
+
+import (
+	"context"
+	"sync"
+
+	"github.com/google/uuid"
+	"github.com/olad5/sal-backend-service/internal/domain"
+	"github.com/olad5/sal-backend-service/internal/infra"
+)
+
+type MemoryPricingRuleRepository struct {
+	rules          map[uuid.UUID]domain.PricingRule
+	merchantsRules map[uuid.UUID][]uuid.UUID
+	lock           sync.RWMutex
+}
+
+func NewMemoryPricingRuleRepo() (*MemoryPricingRuleRepository, error) {
+	return &MemoryPricingRuleRepository{
+		map[uuid.UUID]domain.PricingRule{},
+		map[uuid.UUID][]uuid.UUID{},
+		sync.RWMutex{},
+	}, nil
+}
+
+func (m *MemoryPricingRuleRepository) CreatePricingRule(ctx context.Context, rule domain.PricingRule) error {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+	if m.rules == nil || m.merchantsRules == nil {
+		return ErrMemoryStoreAccess
+	}
+	m.rules[rule.ID] = rule
+	m.merchantsRules[rule.MerchantId] = append(m.merchantsRules[rule.MerchantId], rule.ID)
+	return nil
+}
+
+func (m *MemoryPricingRuleRepository) GetPricingRuleById(ctx context.Context, ruleId uuid.UUID) (domain.PricingRule, error) {
+	m.lock.RLock()
+	defer m.lock.RUnlock()
+	if m.rules == nil || m.merchantsRules == nil {
+		return domain.PricingRule{}, ErrMemoryStoreAccess
+	}
+	rule, ok := m.rules[ruleId]
+	if !ok {
+		return domain.PricingRule{}, infra.ErrPricingRuleNotFound
+	}
+	return rule, nil
+}
+
+func (m *MemoryPricingRuleRepository) GetPricingRulesByMerchantId(ctx context.Context, merchantId uuid.UUID) ([]domain.PricingRule, error) {
+	m.lock.RLock()
+	defer m.lock.RUnlock()
+	if m.rules == nil || m.merchantsRules == nil {
+		return []domain.PricingRule{}, ErrMemoryStoreAccess
+	}
+	rules := []domain.PricingRule{}
+	for _, ruleId := range m.merchantsRules[merchantId] {
+		rules = append(rules, m.rules[ruleId])
+	}
+	return rules, nil
+}
+
+func (m *MemoryPricingRuleRepository) DeletePricingRuleById(ctx context.Context, ruleId uuid.UUID) error {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+	if m.rules == nil || m.merchantsRules == nil {
+		return ErrMemoryStoreAccess
+	}
+	rule, ok := m.rules[ruleId]
+	if !ok {
+		return infra.ErrPricingRuleNotFound
+	}
+	ruleIds := m.merchantsRules[rule.MerchantId]
+	for index, id := range ruleIds {
+		if id == ruleId {
+			m.merchantsRules[rule.MerchantId] = append(ruleIds[:index:index], ruleIds[index+1:]...)
+			break
+		}
+	}
+	delete(m.rules, ruleId)
+	return nil
+}
+
+func (m *MemoryPricingRuleRepository) CheckHealth(ctx context.Context) error {
+	m.lock.RLock()
+	defer m.lock.RUnlock()
+	if m.rules == nil {
+		return ErrMemoryStoreAccess
+	}
+	return nil
+}
@@ -3,6 +3,7 @@ package memory
 import (
 	"context"
 	"errors"
+	"strings"
 	"sync"
 
 	"github.com/google/uuid"
@@ -15,18 +16,25 @@ var ErrMemoryStoreAccess = errors.New("error accessing memory store")
 type MemoryProductRepository struct {
 	products          map[uuid.UUID]domain.Product
 	merchantsProducts map[uuid.UUID][]domain.Product
-	lock              sync.RWMutex
+	// skuCodes maps each merchant's upper cased SKU codes to their products
+	skuCodes map[uuid.UUID]map[string]uuid.UUID
+	// barcodes maps each merchant's GTINs and ISBNs, padded to GTIN-14, to
+	// their products
+	barcodes map[uuid.UUID]map[string]uuid.UUID
+	lock     sync.RWMutex
 }
 
 func NewMemoryProductRepo() (*MemoryProductRepository, error) {
 	return &MemoryProductRepository{
 		map[uuid.UUID]domain.Product{},
 		map[uuid.UUID][]domain.Product{},
+		map[uuid.UUID]map[string]uuid.UUID{},
+		map[uuid.UUID]map[string]uuid.UUID{},
 		sync.RWMutex{},
 	}, nil
 }
 
-func (m *MemoryProductRepository) CreateProduct(ctx context.Context, product domain.Product) error {
+func (m *MemoryProductRepository) CreateProduct(ctx context.Context, product domain.Product, maxMerchantProducts int) error {
 	m.lock.Lock()
 	defer m.lock.Unlock()
 	if m.products == nil {
@@ -35,8 +43,19 @@ func (m *MemoryProductRepository) CreateProduct(ctx context.Context, product dom
 	if m.merchantsProducts == nil {
 		return ErrMemoryStoreAccess
 	}
+	if m.skuCodeTaken(product) {
+		return infra.ErrSkuCodeTaken
+	}
+	if m.barcodeTaken(product) {
+		return infra.ErrBarcodeTaken
+	}
+	if len(m.merchantsProducts[product.MerchantId]) >= maxMerchantProducts {
+		return infra.ErrProductLimitReached
+	}
 	m.products[product.SKUID] = product
 	m.merchantsProducts[product.MerchantId] = append(m.merchantsProducts[product.MerchantId], product)
+	m.indexSkuCode(product)
+	m.indexBarcodes(product)
 	return nil
 }
 
@@ -66,12 +85,39 @@ func (m *MemoryProductRepository) GetProductsByMerchantId(ctx context.Context, m
 	if m.merchantsProducts == nil {
 		return []domain.Product{}, ErrMemoryStoreAccess
 	}
+	// updates write into the stored slice under the write lock, so callers
+	// get a copy they can keep reading once the read lock is released
 	if items, ok := m.merchantsProducts[merchantId]; ok {
-		return items, nil
+		return append([]domain.Product(nil), items...), nil
 	}
 	return []domain.Product{}, nil
 }
 
+func (m *MemoryProductRepository) GetCatalogStats(ctx context.Context) (domain.CatalogStats, error) {
+	m.lock.RLock()
+	defer m.lock.RUnlock()
+	if m.products == nil {
+		return domain.CatalogStats{}, ErrMemoryStoreAccess
+	}
+	if m.merchantsProducts == nil {
+		return domain.CatalogStats{}, ErrMemoryStoreAccess
+	}
+
+	stats := domain.CatalogStats{ProductsByStatus: map[domain.ProductStatus]int{}}
+	for _, status := range domain.ProductStatuses {
+		stats.ProductsByStatus[status] = 0
+	}
+	for _, product := range m.products {
+		stats.ProductsByStatus[product.Status()]++
+	}
+	for _, merchantProducts := range m.merchantsProducts {
+		if len(merchantProducts) > 0 {
+			stats.Merchants++
+		}
+	}
+	return stats, nil
+}
+
 func (m *MemoryProductRepository) UpdateProductByProductId(ctx context.Context, updatedProduct domain.Product) error {
 	m.lock.Lock()
 	defer m.lock.Unlock()
@@ -81,14 +127,50 @@ func (m *MemoryProductRepository) UpdateProductByProductId(ctx context.Context,
 	if m.merchantsProducts == nil {
 		return ErrMemoryStoreAccess
 	}
+	return m.updateProduct(updatedProduct)
+}
 
-	m.products[updatedProduct.SKUID] = updatedProduct
+func (m *MemoryProductRepository) AddProductImage(ctx context.Context, updatedProduct domain.Product, maxMerchantImages int) error {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+	if m.products == nil {
+		return ErrMemoryStoreAccess
+	}
+	if m.merchantsProducts == nil {
+		return ErrMemoryStoreAccess
+	}
+
+	images := len(updatedProduct.Images)
+	for _, product := range m.merchantsProducts[updatedProduct.MerchantId] {
+		if product.SKUID != updatedProduct.SKUID {
+			images += len(product.Images)
+		}
+	}
+	if images > maxMerchantImages {
+		return infra.ErrImageLimitReached
+	}
+	return m.updateProduct(updatedProduct)
+}
+
+// updateProduct replaces a stored product, the caller holds the write lock.
+func (m *MemoryProductRepository) updateProduct(updatedProduct domain.Product) error {
+	if m.skuCodeTaken(updatedProduct) {
+		return infra.ErrSkuCodeTaken
+	}
+	if m.barcodeTaken(updatedProduct) {
+		return infra.ErrBarcodeTaken
+	}
 	merchantProducts := m.merchantsProducts[updatedProduct.MerchantId]
 	index, err := m.getIndexOfProduct(merchantProducts, updatedProduct.SKUID)
 	if err != nil {
 		return err
 	}
+	m.unindexSkuCode(m.products[updatedProduct.SKUID])
+	m.unindexBarcodes(m.products[updatedProduct.SKUID])
+	m.products[updatedProduct.SKUID] = updatedProduct
 	merchantProducts[index] = updatedProduct
+	m.indexSkuCode(updatedProduct)
+	m.indexBarcodes(updatedProduct)
 	return nil
 }
 
@@ -112,20 +194,115 @@ func (m *MemoryProductRepository) DeleteProductBySkuId(ctx context.Context, skuI
 	if err != nil {
 		return err
 	}
-	if err := removeElement(merchantProducts, index); err != nil {
+	remaining, err := removeElement(merchantProducts, index)
+	if err != nil {
 		return err
 	}
+	m.merchantsProducts[existingProduct.MerchantId] = remaining
 	delete(m.products, skuId)
+	m.unindexSkuCode(existingProduct)
+	m.unindexBarcodes(existingProduct)
 
 	return nil
 }
 
-func removeElement(slice []domain.Product, index int) error {
+func (m *MemoryProductRepository) GetProductBySkuCode(ctx context.Context, merchantId uuid.UUID, skuCode string) (domain.Product, error) {
+	m.lock.RLock()
+	defer m.lock.RUnlock()
+	if m.products == nil || m.skuCodes == nil {
+		return domain.Product{}, ErrMemoryStoreAccess
+	}
+
+	skuId, ok := m.skuCodes[merchantId][strings.ToUpper(skuCode)]
+	if !ok {
+		return domain.Product{}, infra.ErrProductNotFound
+	}
+	return m.getProductFromProductsStore(skuId)
+}
+
+// skuCodeTaken reports whether another product of the same merchant already
+// uses product's SKU code. Codes are compared case insensitively.
+func (m *MemoryProductRepository) skuCodeTaken(product domain.Product) bool {
+	if product.SKUCode == "" {
+		return false
+	}
+	skuId, ok := m.skuCodes[product.MerchantId][strings.ToUpper(product.SKUCode)]
+	return ok && skuId != product.SKUID
+}
+
+func (m *MemoryProductRepository) indexSkuCode(product domain.Product) {
+	if product.SKUCode == "" {
+		return
+	}
+	codes, ok := m.skuCodes[product.MerchantId]
+	if !ok {
+		codes = map[string]uuid.UUID{}
+		m.skuCodes[product.MerchantId] = codes
+	}
+	codes[strings.ToUpper(product.SKUCode)] = product.SKUID
+}
+
+func (m *MemoryProductRepository) unindexSkuCode(product domain.Product) {
+	codes := m.skuCodes[product.MerchantId]
+	key := strings.ToUpper(product.SKUCode)
+	if skuId, ok := codes[key]; ok && skuId == product.SKUID {
+		delete(codes, key)
+	}
+}
+
+func (m *MemoryProductRepository) GetProductByGTIN(ctx context.Context, merchantId uuid.UUID, gtin string) (domain.Product, error) {
+	m.lock.RLock()
+	defer m.lock.RUnlock()
+	if m.products == nil || m.barcodes == nil {
+		return domain.Product{}, ErrMemoryStoreAccess
+	}
+
+	skuId, ok := m.barcodes[merchantId][domain.GTIN14(gtin)]
+	if !ok {
+		return domain.Product{}, infra.ErrProductNotFound
+	}
+	return m.getProductFromProductsStore(skuId)
+}
+
+func (m *MemoryProductRepository) barcodeTaken(product domain.Product) bool {
+	for _, barcode := range product.Barcodes() {
+		if skuId, ok := m.barcodes[product.MerchantId][barcode]; ok && skuId != product.SKUID {
+			return true
+		}
+	}
+	return false
+}
+
+func (m *MemoryProductRepository) indexBarcodes(product domain.Product) {
+	for _, barcode := range product.Barcodes() {
+		codes, ok := m.barcodes[product.MerchantId]
+		if !ok {
+			codes = map[string]uuid.UUID{}
+			m.barcodes[product.MerchantId] = codes
+		}
+		codes[barcode] = product.SKUID
+	}
+}
+
+func (m *MemoryProductRepository) unindexBarcodes(product domain.Product) {
+	codes := m.barcodes[product.MerchantId]
+	for _, barcode := range product.Barcodes() {
+		if skuId, ok := codes[barcode]; ok && skuId == product.SKUID {
+			delete(codes, barcode)
+		}
+	}
+}
+
+// removeElement returns a copy of slice without the element at index, slices
+// already handed out by GetProductsByMerchantId are left as they were.
+func removeElement(slice []domain.Product, index int) ([]domain.Product, error) {
 	if index < 0 || index >= len(slice) {
-		return errors.New("out of bounds")
+		return nil, errors.New("out of bounds")
 	}
 
-	return nil
+	remaining := make([]domain.Product, 0, len(slice)-1)
+	remaining = append(remaining, slice[:index]...)
+	return append(remaining, slice[index+1:]...), nil
 }
 
 func (m *MemoryProductRepository) getProductFromProductsStore(skuId uuid.UUID) (domain.Product, error) {
@@ -143,3 +320,14 @@ func (m *MemoryProductRepository) getIndexOfProduct(products []domain.Product, s
 	}
 	return 0, infra.ErrProductNotFound
 }
+
+// CheckHealth takes the store's lock, so a store stuck behind a held lock
+// fails the check by timing out.
+func (m *MemoryProductRepository) CheckHealth(ctx context.Context) error {
+	m.lock.RLock()
+	defer m.lock.RUnlock()
+	if m.products == nil {
+		return ErrMemoryStoreAccess
+	}
+	return nil
+}
@@ -0,0 +1,65 @@
+package memory
+
+import (
+	"context"
+	"sync"
+
+	"github.com/google/uuid"
+	"github.com/olad5/sal-backend-service/internal/domain"
+	"github.com/olad5/sal-backend-service/internal/infra"
+)
+
+type MemorySKUCodePatternRepository struct {
+	patterns  map[uuid.UUID]domain.SKUCodePattern
+	sequences map[uuid.UUID]int
+	lock      sync.Mutex
+}
+
+func NewMemorySKUCodePatternRepo() (*MemorySKUCodePatternRepository, error) {
+	return &MemorySKUCodePatternRepository{
+		patterns:  map[uuid.UUID]domain.SKUCodePattern{},
+		sequences: map[uuid.UUID]int{},
+	}, nil
+}
+
+func (m *MemorySKUCodePatternRepository) SaveSKUCodePattern(ctx context.Context, pattern domain.SKUCodePattern) error {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+	if m.patterns == nil {
+		return ErrMemoryStoreAccess
+	}
+	m.patterns[pattern.MerchantId] = pattern
+	return nil
+}
+
+func (m *MemorySKUCodePatternRepository) GetSKUCodePatternByMerchantId(ctx context.Context, merchantId uuid.UUID) (domain.SKUCodePattern, error) {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+	if m.patterns == nil {
+		return domain.SKUCodePattern{}, ErrMemoryStoreAccess
+	}
+	pattern, ok := m.patterns[merchantId]
+	if !ok {
+		return domain.SKUCodePattern{}, infra.ErrSKUCodePatternNotFound
+	}
+	return pattern, nil
+}
+
+func (m *MemorySKUCodePatternRepository) NextSKUCodeSequence(ctx context.Context, merchantId uuid.UUID) (int, error) {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+	if m.sequences == nil {
+		return 0, ErrMemoryStoreAccess
+	}
+	m.sequences[merchantId]++
+	return m.sequences[merchantId], nil
+}
+
+func (m *MemorySKUCodePatternRepository) CheckHealth(ctx context.Context) error {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+	if m.patterns == nil {
+		return ErrMemoryStoreAccess
+	}
+	return nil
+}
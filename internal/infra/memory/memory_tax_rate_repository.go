@@ -0,0 +1,82 @@
+package memory
+
+import (
+	"context"
+	"sort"
+	"sync"
+
+	"github.com/olad5/sal-backend-service/internal/domain"
+	"github.com/olad5/sal-backend-service/internal/infra"
+)
+
+type MemoryTaxRateRepository struct {
+	regions map[string]domain.TaxRegion
+	lock    sync.RWMutex
+}
+
+func NewMemoryTaxRateRepo() (*MemoryTaxRateRepository, error) {
+	return &MemoryTaxRateRepository{
+		regions: map[string]domain.TaxRegion{},
+	}, nil
+}
+
+func (m *MemoryTaxRateRepository) SaveTaxRegion(ctx context.Context, region domain.TaxRegion) error {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+	if m.regions == nil {
+		return ErrMemoryStoreAccess
+	}
+	m.regions[region.Code] = region
+	return nil
+}
+
+func (m *MemoryTaxRateRepository) SaveTaxRegions(ctx context.Context, regions []domain.TaxRegion, removed []string) error {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+	if m.regions == nil {
+		return ErrMemoryStoreAccess
+	}
+	for _, code := range removed {
+		delete(m.regions, code)
+	}
+	for _, region := range regions {
+		m.regions[region.Code] = region
+	}
+	return nil
+}
+
+func (m *MemoryTaxRateRepository) GetTaxRegionByCode(ctx context.Context, code string) (domain.TaxRegion, error) {
+	m.lock.RLock()
+	defer m.lock.RUnlock()
+	if m.regions == nil {
+		return domain.TaxRegion{}, ErrMemoryStoreAccess
+	}
+	region, ok := m.regions[code]
+	if !ok {
+		return domain.TaxRegion{}, infra.ErrTaxRegionNotFound
+	}
+	return region, nil
+}
+
+func (m *MemoryTaxRateRepository) GetTaxRegions(ctx context.Context) ([]domain.TaxRegion, error) {
+	m.lock.RLock()
+	defer m.lock.RUnlock()
+	if m.regions == nil {
+		return []domain.TaxRegion{}, ErrMemoryStoreAccess
+	}
+	regions := []domain.TaxRegion{}
+	for _, region := range m.regions {
+		regions = append(regions, region)
+	}
+	sort.Slice(regions, func(a, b int) bool { return regions[a].Code < regions[b].Code })
+	return regions, nil
+}
+
+func (m *MemoryTaxRateRepository) CheckHealth(ctx context.Context) error {
+	m.lock.RLock()
+	defer m.lock.RUnlock()
+	if m.regions == nil {
+		return ErrMemoryStoreAccess
+	}
+	return nil
+}
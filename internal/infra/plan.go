@@ -0,0 +1,16 @@
+package infra
+
+import (
+	"context"
+	"errors"
+
+	"github.com/google/uuid"
+	"github.com/olad5/sal-backend-service/internal/domain"
+)
+
+var ErrMerchantPlanNotFound = errors.New("merchant plan not found")
+
+type MerchantPlanRepository interface {
+	SaveMerchantPlan(ctx context.Context, merchantPlan domain.MerchantPlan) error
+	GetMerchantPlanByMerchantId(ctx context.Context, merchantId uuid.UUID) (domain.MerchantPlan, error)
+}
@@ -0,0 +1,18 @@
+package infra
+
+import (
+	"context"
+	"errors"
+
+	"github.com/google/uuid"
+	"github.com/olad5/sal-backend-service/internal/domain"
+)
+
+var ErrPricingRuleNotFound = errors.New("pricing rule not found")
+
+type PricingRuleRepository interface {
+	CreatePricingRule(ctx context.Context, rule domain.PricingRule) error
+	GetPricingRuleById(ctx context.Context, ruleId uuid.UUID) (domain.PricingRule, error)
+	GetPricingRulesByMerchantId(ctx context.Context, merchantId uuid.UUID) ([]domain.PricingRule, error)
+	DeletePricingRuleById(ctx context.Context, ruleId uuid.UUID) error
+}
@@ -8,12 +8,31 @@ import (
 	"github.com/olad5/sal-backend-service/internal/domain"
 )
 
-var ErrProductNotFound = errors.New("product not found")
+var (
+	ErrProductNotFound = errors.New("product not found")
+	ErrSkuCodeTaken    = errors.New("sku_code is already used by another product")
+	ErrBarcodeTaken    = errors.New("gtin or isbn is already used by another product")
+	// ErrProductLimitReached is returned by CreateProduct when the merchant
+	// already has as many products as it may
+	ErrProductLimitReached = errors.New("merchant product limit reached")
+	// ErrImageLimitReached is returned by AddProductImage when the merchant
+	// already has as many images as it may
+	ErrImageLimitReached = errors.New("merchant image limit reached")
+)
 
 type ProductRepository interface {
-	CreateProduct(ctx context.Context, product domain.Product) error
+	// CreateProduct stores product unless its merchant already has
+	// maxMerchantProducts products, checking and storing atomically
+	CreateProduct(ctx context.Context, product domain.Product, maxMerchantProducts int) error
 	GetProductBySkuId(ctx context.Context, skuId uuid.UUID) (domain.Product, error)
 	GetProductsByMerchantId(ctx context.Context, merchantId uuid.UUID) ([]domain.Product, error)
+	GetProductBySkuCode(ctx context.Context, merchantId uuid.UUID, skuCode string) (domain.Product, error)
+	GetProductByGTIN(ctx context.Context, merchantId uuid.UUID, gtin string) (domain.Product, error)
 	UpdateProductByProductId(ctx context.Context, product domain.Product) error
+	// AddProductImage stores product, which has gained an image, unless that
+	// takes its merchant over maxMerchantImages, checking and storing
+	// atomically
+	AddProductImage(ctx context.Context, product domain.Product, maxMerchantImages int) error
 	DeleteProductBySkuId(ctx context.Context, skuId uuid.UUID) error
+	GetCatalogStats(ctx context.Context) (domain.CatalogStats, error)
 }
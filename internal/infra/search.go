@@ -0,0 +1,16 @@
+package infra
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+	"github.com/olad5/sal-backend-service/internal/domain"
+)
+
+type ProductSearchIndex interface {
+	IndexProduct(ctx context.Context, product domain.Product) error
+	RemoveProduct(ctx context.Context, product domain.Product) error
+	SearchProducts(ctx context.Context, merchantId uuid.UUID, query string, limit, offset int) ([]domain.ProductSearchHit, int, error)
+	MatchProducts(ctx context.Context, merchantId uuid.UUID, query string) ([]domain.Product, error)
+	SuggestProductNames(ctx context.Context, merchantId uuid.UUID, prefix string, limit int) ([]domain.ProductSuggestion, error)
+}
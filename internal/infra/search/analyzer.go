@@ -0,0 +1,113 @@
+package search
+
+import (
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+type token struct {
+	term     string
+	surface  string
+	start    int
+	end      int
+	position int
+}
+
+// tokenize splits text into runs of letters and digits, case folds them and
+// stems each one. start and end are byte offsets into text so matches can be
+// highlighted in the original string.
+func tokenize(text string) []token {
+	tokens := []token{}
+	start := -1
+	for i, r := range text {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) {
+			if start < 0 {
+				start = i
+			}
+			continue
+		}
+		if start >= 0 {
+			tokens = appendToken(tokens, text, start, i)
+			start = -1
+		}
+	}
+	if start >= 0 {
+		tokens = appendToken(tokens, text, start, len(text))
+	}
+	return tokens
+}
+
+func appendToken(tokens []token, text string, start, end int) []token {
+	surface := strings.ToLower(text[start:end])
+	return append(tokens, token{
+		term:     stem(surface),
+		surface:  surface,
+		start:    start,
+		end:      end,
+		position: len(tokens),
+	})
+}
+
+type clauseKind int
+
+const (
+	termClause clauseKind = iota
+	prefixClause
+	phraseClause
+)
+
+type clause struct {
+	kind  clauseKind
+	terms []string
+}
+
+// parseQuery turns a raw query into clauses that must all match. Quoted
+// sections become phrase clauses and words ending in '*' become prefix clauses.
+func parseQuery(query string) []clause {
+	clauses := []clause{}
+	for len(query) > 0 {
+		r, size := utf8.DecodeRuneInString(query)
+		switch {
+		case unicode.IsSpace(r):
+			query = query[size:]
+		case r == '"':
+			query = query[size:]
+			end := strings.IndexRune(query, '"')
+			if end < 0 {
+				end = len(query)
+			}
+			terms := []string{}
+			for _, t := range tokenize(query[:end]) {
+				terms = append(terms, t.term)
+			}
+			switch len(terms) {
+			case 0:
+			case 1:
+				clauses = append(clauses, clause{termClause, terms})
+			default:
+				clauses = append(clauses, clause{phraseClause, terms})
+			}
+			if end < len(query) {
+				end++
+			}
+			query = query[end:]
+		default:
+			end := strings.IndexFunc(query, func(r rune) bool { return unicode.IsSpace(r) || r == '"' })
+			if end < 0 {
+				end = len(query)
+			}
+			word := query[:end]
+			tokens := tokenize(word)
+			for i, t := range tokens {
+				if i == len(tokens)-1 && strings.HasSuffix(word, "*") {
+					clauses = append(clauses, clause{prefixClause, []string{t.surface}})
+					continue
+				}
+				clauses = append(clauses, clause{termClause, []string{t.term}})
+			}
+			query = query[end:]
+		}
+	}
+	return clauses
+}
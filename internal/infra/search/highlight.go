@@ -0,0 +1,67 @@
+package search
+
+import (
+	"html"
+	"strings"
+)
+
+const (
+	// number of tokens kept around the first match in description snippets
+	snippetTokens = 24
+
+	highlightStart = "<em>"
+	highlightEnd   = "</em>"
+	ellipsis       = "…"
+)
+
+// highlight wraps every token of text whose stem is in terms with <em> tags.
+// The text itself is HTML escaped, so a product name or description cannot
+// inject markup into clients that render highlights as HTML. When maxTokens
+// is positive only a window of that many tokens around the first match is
+// returned.
+func highlight(text string, terms map[string]bool, maxTokens int) string {
+	tokens := tokenize(text)
+	from, to := 0, len(tokens)
+	if maxTokens > 0 && len(tokens) > maxTokens {
+		first := 0
+		for index, t := range tokens {
+			if terms[t.term] {
+				first = index
+				break
+			}
+		}
+		from = first - maxTokens/4
+		if from < 0 {
+			from = 0
+		}
+		to = from + maxTokens
+		if to > len(tokens) {
+			to = len(tokens)
+			from = to - maxTokens
+		}
+	}
+
+	var builder strings.Builder
+	cursor := 0
+	if from > 0 {
+		builder.WriteString(ellipsis)
+		cursor = tokens[from].start
+	}
+	for _, t := range tokens[from:to] {
+		if !terms[t.term] {
+			continue
+		}
+		builder.WriteString(html.EscapeString(text[cursor:t.start]))
+		builder.WriteString(highlightStart)
+		builder.WriteString(html.EscapeString(text[t.start:t.end]))
+		builder.WriteString(highlightEnd)
+		cursor = t.end
+	}
+	if to < len(tokens) {
+		builder.WriteString(html.EscapeString(text[cursor:tokens[to-1].end]))
+		builder.WriteString(ellipsis)
+	} else {
+		builder.WriteString(html.EscapeString(text[cursor:]))
+	}
+	return builder.String()
+}
@@ -0,0 +1,431 @@
+package search
+
+import (
+	"context"
+	"errors"
+	"math"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/google/uuid"
+	"github.com/olad5/sal-backend-service/internal/domain"
+	"github.com/olad5/sal-backend-service/internal/events"
+)
+
+const (
+	// BM25 parameters, see https://en.wikipedia.org/wiki/Okapi_BM25
+	bm25K1 = 1.2
+	bm25B  = 0.75
+
+	// upper bound on how many indexed words a single prefix clause expands to
+	maxPrefixExpansions = 64
+)
+
+var ErrIndexAccess = errors.New("error accessing search index")
+
+type field int
+
+const (
+	nameField field = iota
+	descriptionField
+	fieldCount
+)
+
+// matches in the name count for more than matches in the description
+var fieldBoosts = [fieldCount]float64{2.0, 1.0}
+
+type posting struct {
+	positions [fieldCount][]int
+}
+
+type document struct {
+	product domain.Product
+	length  float64
+	terms   []string
+	words   []string
+}
+
+type merchantIndex struct {
+	documents   map[uuid.UUID]*document
+	postings    map[string]map[uuid.UUID]*posting
+	words       map[string]int
+	sortedWords []string
+	totalLength float64
+	names       *nameTrie
+}
+
+// ProductIndex is an in-process inverted index over product names and
+// descriptions, partitioned by merchant and ranked with BM25.
+type ProductIndex struct {
+	merchants map[uuid.UUID]*merchantIndex
+	lock      sync.RWMutex
+}
+
+func NewProductIndex() (*ProductIndex, error) {
+	return &ProductIndex{
+		merchants: map[uuid.UUID]*merchantIndex{},
+	}, nil
+}
+
+// HandleProductEvent keeps the index in sync with product writes.
+func (i *ProductIndex) HandleProductEvent(ctx context.Context, event events.ProductEvent) {
+	switch event.Type {
+	case events.ProductDeleted:
+		_ = i.RemoveProduct(ctx, event.Product)
+	default:
+		_ = i.IndexProduct(ctx, event.Product)
+	}
+}
+
+func (i *ProductIndex) IndexProduct(ctx context.Context, product domain.Product) error {
+	i.lock.Lock()
+	defer i.lock.Unlock()
+	if i.merchants == nil {
+		return ErrIndexAccess
+	}
+
+	merchant, ok := i.merchants[product.MerchantId]
+	if !ok {
+		merchant = &merchantIndex{
+			documents: map[uuid.UUID]*document{},
+			postings:  map[string]map[uuid.UUID]*posting{},
+			words:     map[string]int{},
+			names:     newNameTrie(),
+		}
+		i.merchants[product.MerchantId] = merchant
+	}
+	merchant.remove(product.SKUID)
+	merchant.add(product)
+	return nil
+}
+
+func (i *ProductIndex) RemoveProduct(ctx context.Context, product domain.Product) error {
+	i.lock.Lock()
+	defer i.lock.Unlock()
+	if i.merchants == nil {
+		return ErrIndexAccess
+	}
+
+	merchant, ok := i.merchants[product.MerchantId]
+	if !ok {
+		return nil
+	}
+	merchant.remove(product.SKUID)
+	if len(merchant.documents) == 0 {
+		delete(i.merchants, product.MerchantId)
+	}
+	return nil
+}
+
+func (i *ProductIndex) SearchProducts(ctx context.Context, merchantId uuid.UUID, query string, limit, offset int) ([]domain.ProductSearchHit, int, error) {
+	i.lock.RLock()
+	defer i.lock.RUnlock()
+	if i.merchants == nil {
+		return []domain.ProductSearchHit{}, 0, ErrIndexAccess
+	}
+
+	merchant, ok := i.merchants[merchantId]
+	clauses := parseQuery(query)
+	if !ok || len(clauses) == 0 {
+		return []domain.ProductSearchHit{}, 0, nil
+	}
+
+	candidates, matchedTerms := merchant.evaluate(clauses)
+	ranked := make([]*candidate, 0, len(candidates))
+	for _, c := range candidates {
+		ranked = append(ranked, c)
+	}
+	sort.Slice(ranked, func(a, b int) bool {
+		if ranked[a].score != ranked[b].score {
+			return ranked[a].score > ranked[b].score
+		}
+		if ranked[a].document.product.Name != ranked[b].document.product.Name {
+			return ranked[a].document.product.Name < ranked[b].document.product.Name
+		}
+		return ranked[a].document.product.SKUID.String() < ranked[b].document.product.SKUID.String()
+	})
+
+	total := len(ranked)
+	if offset > total {
+		offset = total
+	}
+	end := offset + limit
+	if limit <= 0 || end > total {
+		end = total
+	}
+
+	hits := []domain.ProductSearchHit{}
+	for _, c := range ranked[offset:end] {
+		hits = append(hits, domain.ProductSearchHit{
+			Product:              c.document.product,
+			Score:                c.score,
+			NameHighlight:        highlight(c.document.product.Name, matchedTerms, 0),
+			DescriptionHighlight: highlight(c.document.product.Description, matchedTerms, snippetTokens),
+		})
+	}
+	return hits, total, nil
+}
+
+// MatchProducts returns every product matching query without ranking or
+// highlighting them, for aggregations over the whole result set.
+func (i *ProductIndex) MatchProducts(ctx context.Context, merchantId uuid.UUID, query string) ([]domain.Product, error) {
+	i.lock.RLock()
+	defer i.lock.RUnlock()
+	if i.merchants == nil {
+		return []domain.Product{}, ErrIndexAccess
+	}
+
+	merchant, ok := i.merchants[merchantId]
+	clauses := parseQuery(query)
+	if !ok || len(clauses) == 0 {
+		return []domain.Product{}, nil
+	}
+
+	candidates, _ := merchant.evaluate(clauses)
+	matched := make([]domain.Product, 0, len(candidates))
+	for _, c := range candidates {
+		matched = append(matched, c.document.product)
+	}
+	return matched, nil
+}
+
+func (i *ProductIndex) SuggestProductNames(ctx context.Context, merchantId uuid.UUID, prefix string, limit int) ([]domain.ProductSuggestion, error) {
+	i.lock.RLock()
+	defer i.lock.RUnlock()
+	if i.merchants == nil {
+		return []domain.ProductSuggestion{}, ErrIndexAccess
+	}
+
+	merchant, ok := i.merchants[merchantId]
+	if !ok {
+		return []domain.ProductSuggestion{}, nil
+	}
+	if limit <= 0 || limit > MaxSuggestions {
+		limit = MaxSuggestions
+	}
+	return merchant.names.suggest(prefix, limit), nil
+}
+
+type candidate struct {
+	document *document
+	score    float64
+}
+
+// evaluate returns the documents matching every clause along with their
+// summed BM25 scores, and the set of index terms the clauses matched on.
+func (m *merchantIndex) evaluate(clauses []clause) (map[uuid.UUID]*candidate, map[string]bool) {
+	var candidates map[uuid.UUID]*candidate
+	matchedTerms := map[string]bool{}
+
+	for _, c := range clauses {
+		terms := c.terms
+		if c.kind == prefixClause {
+			terms = m.expandPrefix(c.terms[0])
+		}
+		clauseScores := m.match(c.kind, terms)
+		for _, term := range terms {
+			matchedTerms[term] = true
+		}
+
+		if candidates == nil {
+			candidates = map[uuid.UUID]*candidate{}
+			for skuId, score := range clauseScores {
+				candidates[skuId] = &candidate{m.documents[skuId], score}
+			}
+			continue
+		}
+		for skuId, existing := range candidates {
+			score, ok := clauseScores[skuId]
+			if !ok {
+				delete(candidates, skuId)
+				continue
+			}
+			existing.score += score
+		}
+	}
+	return candidates, matchedTerms
+}
+
+func (m *merchantIndex) add(product domain.Product) {
+	doc := &document{product: product}
+	seenTerms := map[string]bool{}
+	seenWords := map[string]bool{}
+	fieldTexts := [fieldCount]string{nameField: product.Name, descriptionField: product.Description}
+
+	for f, text := range fieldTexts {
+		tokens := tokenize(text)
+		doc.length += fieldBoosts[f] * float64(len(tokens))
+		for _, t := range tokens {
+			docs, ok := m.postings[t.term]
+			if !ok {
+				docs = map[uuid.UUID]*posting{}
+				m.postings[t.term] = docs
+			}
+			p, ok := docs[product.SKUID]
+			if !ok {
+				p = &posting{}
+				docs[product.SKUID] = p
+			}
+			p.positions[f] = append(p.positions[f], t.position)
+
+			if !seenTerms[t.term] {
+				seenTerms[t.term] = true
+				doc.terms = append(doc.terms, t.term)
+			}
+			if !seenWords[t.surface] {
+				seenWords[t.surface] = true
+				doc.words = append(doc.words, t.surface)
+				if m.words[t.surface] == 0 {
+					m.insertWord(t.surface)
+				}
+				m.words[t.surface]++
+			}
+		}
+	}
+
+	m.documents[product.SKUID] = doc
+	m.totalLength += doc.length
+	m.names.add(product.SKUID, product.Name)
+}
+
+func (m *merchantIndex) remove(skuId uuid.UUID) {
+	doc, ok := m.documents[skuId]
+	if !ok {
+		return
+	}
+	for _, term := range doc.terms {
+		delete(m.postings[term], skuId)
+		if len(m.postings[term]) == 0 {
+			delete(m.postings, term)
+		}
+	}
+	for _, word := range doc.words {
+		m.words[word]--
+		if m.words[word] <= 0 {
+			delete(m.words, word)
+			m.deleteWord(word)
+		}
+	}
+	m.totalLength -= doc.length
+	m.names.remove(skuId, doc.product.Name)
+	delete(m.documents, skuId)
+}
+
+// insertWord and deleteWord keep sortedWords in order as words are indexed
+// and dropped, so searches only read it and can share the index lock.
+func (m *merchantIndex) insertWord(word string) {
+	index := sort.SearchStrings(m.sortedWords, word)
+	m.sortedWords = append(m.sortedWords, "")
+	copy(m.sortedWords[index+1:], m.sortedWords[index:])
+	m.sortedWords[index] = word
+}
+
+func (m *merchantIndex) deleteWord(word string) {
+	index := sort.SearchStrings(m.sortedWords, word)
+	if index < len(m.sortedWords) && m.sortedWords[index] == word {
+		m.sortedWords = append(m.sortedWords[:index], m.sortedWords[index+1:]...)
+	}
+}
+
+// expandPrefix returns the stems of every indexed word starting with prefix.
+// Prefixes are matched against the unstemmed words so that "runn*" still
+// finds "running" even though it is indexed as "run".
+func (m *merchantIndex) expandPrefix(prefix string) []string {
+	seen := map[string]bool{}
+	terms := []string{}
+	for index := sort.SearchStrings(m.sortedWords, prefix); index < len(m.sortedWords); index++ {
+		word := m.sortedWords[index]
+		if !strings.HasPrefix(word, prefix) || len(terms) >= maxPrefixExpansions {
+			break
+		}
+		term := stem(word)
+		if !seen[term] {
+			seen[term] = true
+			terms = append(terms, term)
+		}
+	}
+	return terms
+}
+
+// match returns the BM25 score of every document satisfying a single clause.
+func (m *merchantIndex) match(kind clauseKind, terms []string) map[uuid.UUID]float64 {
+	scores := map[uuid.UUID]float64{}
+	switch kind {
+	case phraseClause:
+		for skuId := range m.postings[terms[0]] {
+			if m.containsPhrase(skuId, terms) {
+				score := 0.0
+				for _, term := range terms {
+					score += m.bm25(term, skuId)
+				}
+				scores[skuId] = score
+			}
+		}
+	default:
+		for _, term := range terms {
+			for skuId := range m.postings[term] {
+				scores[skuId] += m.bm25(term, skuId)
+			}
+		}
+	}
+	return scores
+}
+
+func (m *merchantIndex) containsPhrase(skuId uuid.UUID, terms []string) bool {
+	postings := make([]*posting, len(terms))
+	for index, term := range terms {
+		p, ok := m.postings[term][skuId]
+		if !ok {
+			return false
+		}
+		postings[index] = p
+	}
+
+	for f := field(0); f < fieldCount; f++ {
+		for _, start := range postings[0].positions[f] {
+			found := true
+			for offset := 1; offset < len(terms) && found; offset++ {
+				found = containsInt(postings[offset].positions[f], start+offset)
+			}
+			if found {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func (m *merchantIndex) bm25(term string, skuId uuid.UUID) float64 {
+	docs := m.postings[term]
+	p, ok := docs[skuId]
+	if !ok {
+		return 0
+	}
+
+	n := float64(len(m.documents))
+	df := float64(len(docs))
+	idf := math.Log(1 + (n-df+0.5)/(df+0.5))
+
+	tf := 0.0
+	for f := field(0); f < fieldCount; f++ {
+		tf += fieldBoosts[f] * float64(len(p.positions[f]))
+	}
+	averageLength := m.totalLength / n
+	length := m.documents[skuId].length
+	return idf * tf * (bm25K1 + 1) / (tf + bm25K1*(1-bm25B+bm25B*length/averageLength))
+}
+
+func containsInt(values []int, target int) bool {
+	index := sort.SearchInts(values, target)
+	return index < len(values) && values[index] == target
+}
+
+func (i *ProductIndex) CheckHealth(ctx context.Context) error {
+	i.lock.RLock()
+	defer i.lock.RUnlock()
+	if i.merchants == nil {
+		return ErrIndexAccess
+	}
+	return nil
+}
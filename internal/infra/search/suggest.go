@@ -0,0 +1,297 @@
+package search
+
+import (
+	"sort"
+	"strings"
+
+	"github.com/google/uuid"
+	"github.com/olad5/sal-backend-service/internal/domain"
+)
+
+// MaxSuggestions is the largest number of suggestions a single lookup can
+// return. Every trie node caches this many of its best completions.
+const MaxSuggestions = 10
+
+type suggestedName struct {
+	display string
+	skuIds  map[uuid.UUID]bool
+}
+
+type trieNode struct {
+	children map[rune]*trieNode
+	// names whose key has a word starting suffix ending at this node
+	names []string
+	// best completions anywhere below this node, ordered by rankBefore
+	top []string
+}
+
+// nameTrie indexes every word starting suffix of each product name, so that
+// "shi" suggests "Red Cotton Shirt" as well as "Shirt Dress".
+type nameTrie struct {
+	root  *trieNode
+	names map[string]*suggestedName
+}
+
+func newNameTrie() *nameTrie {
+	return &nameTrie{
+		root:  &trieNode{children: map[rune]*trieNode{}},
+		names: map[string]*suggestedName{},
+	}
+}
+
+func (t *nameTrie) add(skuId uuid.UUID, name string) {
+	key := normalizeName(name)
+	if key == "" {
+		return
+	}
+	entry, ok := t.names[key]
+	if !ok {
+		entry = &suggestedName{skuIds: map[uuid.UUID]bool{}}
+		t.names[key] = entry
+		for _, suffix := range wordSuffixes(key) {
+			t.insert(suffix, key)
+		}
+	}
+	entry.display = name
+	entry.skuIds[skuId] = true
+}
+
+func (t *nameTrie) remove(skuId uuid.UUID, name string) {
+	key := normalizeName(name)
+	entry, ok := t.names[key]
+	if !ok {
+		return
+	}
+	delete(entry.skuIds, skuId)
+	if len(entry.skuIds) > 0 {
+		return
+	}
+	delete(t.names, key)
+	for _, suffix := range wordSuffixes(key) {
+		t.delete(suffix, key)
+	}
+}
+
+func (t *nameTrie) insert(suffix []rune, key string) {
+	node := t.root
+	for _, r := range suffix {
+		child, ok := node.children[r]
+		if !ok {
+			child = &trieNode{children: map[rune]*trieNode{}}
+			node.children[r] = child
+		}
+		child.top = insertRanked(child.top, key)
+		node = child
+	}
+	node.names = append(node.names, key)
+}
+
+func (t *nameTrie) delete(suffix []rune, key string) {
+	path := []*trieNode{t.root}
+	node := t.root
+	for _, r := range suffix {
+		child, ok := node.children[r]
+		if !ok {
+			return
+		}
+		path = append(path, child)
+		node = child
+	}
+	node.names = removeString(node.names, key)
+
+	// rebuild the cached completions bottom up, dropping nodes that no
+	// longer lead anywhere
+	for depth := len(path) - 1; depth > 0; depth-- {
+		current := path[depth]
+		current.top = current.top[:0]
+		for _, name := range current.names {
+			current.top = insertRanked(current.top, name)
+		}
+		for _, child := range current.children {
+			for _, name := range child.top {
+				current.top = insertRanked(current.top, name)
+			}
+		}
+		if len(current.names) == 0 && len(current.children) == 0 {
+			delete(path[depth-1].children, suffix[depth-1])
+		}
+	}
+}
+
+// suggest returns up to limit names that start with query, allowing for a
+// few typos. Longer queries tolerate more edits.
+func (t *nameTrie) suggest(query string, limit int) []domain.ProductSuggestion {
+	q := []rune(normalizeName(query))
+	if len(q) == 0 {
+		return []domain.ProductSuggestion{}
+	}
+	maxDistance := allowedDistance(len(q))
+
+	walker := &fuzzyWalker{
+		query:       q,
+		maxDistance: maxDistance,
+		rows:        make([][]int, len(q)+maxDistance+2),
+		distances:   map[string]int{},
+	}
+	for depth := range walker.rows {
+		walker.rows[depth] = make([]int, len(q)+1)
+	}
+	for i := range walker.rows[0] {
+		walker.rows[0][i] = i
+	}
+	for r, child := range t.root.children {
+		walker.walk(child, 1, r, 0)
+	}
+	distances := walker.distances
+
+	keys := make([]string, 0, len(distances))
+	for key := range distances {
+		keys = append(keys, key)
+	}
+	sort.Slice(keys, func(a, b int) bool {
+		if distances[keys[a]] != distances[keys[b]] {
+			return distances[keys[a]] < distances[keys[b]]
+		}
+		return rankBefore(keys[a], keys[b])
+	})
+	if len(keys) > limit {
+		keys = keys[:limit]
+	}
+
+	suggestions := []domain.ProductSuggestion{}
+	for _, key := range keys {
+		entry := t.names[key]
+		skuIds := make([]uuid.UUID, 0, len(entry.skuIds))
+		for skuId := range entry.skuIds {
+			skuIds = append(skuIds, skuId)
+		}
+		sort.Slice(skuIds, func(a, b int) bool { return skuIds[a].String() < skuIds[b].String() })
+		suggestions = append(suggestions, domain.ProductSuggestion{
+			Name:     entry.display,
+			SKUIDs:   skuIds,
+			Distance: distances[key],
+		})
+	}
+	return suggestions
+}
+
+type fuzzyWalker struct {
+	query       []rune
+	maxDistance int
+	// rows[depth] holds the distances between each prefix of the query and
+	// the path to the node currently being visited at that depth
+	rows      [][]int
+	distances map[string]int
+}
+
+// walk computes one row of the optimal string alignment distance between the
+// query and the path to node, recording the node's completions whenever the
+// whole query is within maxDistance of that path. Branches are pruned once no
+// prefix of the query can still be within maxDistance.
+func (w *fuzzyWalker) walk(node *trieNode, depth int, r, parentRune rune) {
+	query := w.query
+	previous, row := w.rows[depth-1], w.rows[depth]
+	row[0] = depth
+	rowMin := row[0]
+	for i := 1; i <= len(query); i++ {
+		cost := 1
+		if query[i-1] == r {
+			cost = 0
+		}
+		row[i] = minInt(previous[i]+1, row[i-1]+1, previous[i-1]+cost)
+		if depth > 1 && i > 1 && query[i-1] == parentRune && query[i-2] == r {
+			row[i] = minInt(row[i], w.rows[depth-2][i-2]+1)
+		}
+		if row[i] < rowMin {
+			rowMin = row[i]
+		}
+	}
+
+	distance := row[len(query)]
+	if distance <= w.maxDistance {
+		for _, key := range node.top {
+			if existing, ok := w.distances[key]; !ok || distance < existing {
+				w.distances[key] = distance
+			}
+		}
+	}
+	// an exact match already holds the best completions of its whole subtree
+	if distance == 0 || rowMin > w.maxDistance {
+		return
+	}
+	for childRune, child := range node.children {
+		w.walk(child, depth+1, childRune, r)
+	}
+}
+
+func allowedDistance(queryLength int) int {
+	switch {
+	case queryLength < 3:
+		return 0
+	case queryLength < 6:
+		return 1
+	default:
+		return 2
+	}
+}
+
+func normalizeName(name string) string {
+	return strings.Join(strings.Fields(strings.ToLower(name)), " ")
+}
+
+func wordSuffixes(key string) [][]rune {
+	runes := []rune(key)
+	suffixes := [][]rune{runes}
+	for i, r := range runes {
+		if r == ' ' && i+1 < len(runes) {
+			suffixes = append(suffixes, runes[i+1:])
+		}
+	}
+	return suffixes
+}
+
+// rankBefore orders completions of equal distance, preferring shorter names
+// since they are the closest to what has been typed so far.
+func rankBefore(a, b string) bool {
+	if len(a) != len(b) {
+		return len(a) < len(b)
+	}
+	return a < b
+}
+
+func insertRanked(top []string, key string) []string {
+	for _, existing := range top {
+		if existing == key {
+			return top
+		}
+	}
+	index := sort.Search(len(top), func(i int) bool { return rankBefore(key, top[i]) })
+	if index >= MaxSuggestions {
+		return top
+	}
+	top = append(top, "")
+	copy(top[index+1:], top[index:])
+	top[index] = key
+	if len(top) > MaxSuggestions {
+		top = top[:MaxSuggestions]
+	}
+	return top
+}
+
+func removeString(values []string, value string) []string {
+	for index, existing := range values {
+		if existing == value {
+			return append(values[:index], values[index+1:]...)
+		}
+	}
+	return values
+}
+
+func minInt(first int, rest ...int) int {
+	for _, value := range rest {
+		if value < first {
+			first = value
+		}
+	}
+	return first
+}
@@ -0,0 +1,19 @@
+package infra
+
+import (
+	"context"
+	"errors"
+
+	"github.com/google/uuid"
+	"github.com/olad5/sal-backend-service/internal/domain"
+)
+
+var ErrSKUCodePatternNotFound = errors.New("sku code pattern not found")
+
+type SKUCodePatternRepository interface {
+	SaveSKUCodePattern(ctx context.Context, pattern domain.SKUCodePattern) error
+	GetSKUCodePatternByMerchantId(ctx context.Context, merchantId uuid.UUID) (domain.SKUCodePattern, error)
+	// NextSKUCodeSequence returns the merchant's next sequence number,
+	// starting at 1. Numbers are never handed out twice.
+	NextSKUCodeSequence(ctx context.Context, merchantId uuid.UUID) (int, error)
+}
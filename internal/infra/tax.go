@@ -0,0 +1,19 @@
+package infra
+
+import (
+	"context"
+	"errors"
+
+	"github.com/olad5/sal-backend-service/internal/domain"
+)
+
+var ErrTaxRegionNotFound = errors.New("tax region not found")
+
+type TaxRateRepository interface {
+	SaveTaxRegion(ctx context.Context, region domain.TaxRegion) error
+	// SaveTaxRegions saves regions and deletes the regions coded removed in
+	// one step, so readers see either none or all of the changes
+	SaveTaxRegions(ctx context.Context, regions []domain.TaxRegion, removed []string) error
+	GetTaxRegionByCode(ctx context.Context, code string) (domain.TaxRegion, error)
+	GetTaxRegions(ctx context.Context) ([]domain.TaxRegion, error)
+}
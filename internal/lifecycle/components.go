@@ -0,0 +1,246 @@
+package lifecycle
+
+import (
+	"context"
+	"errors"
+	"net"
+	"net/http"
+	"sync"
+
+	"github.com/olad5/sal-backend-service/internal/config"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// Worker runs a background loop, such as a scheduler, until it is stopped.
+type Worker struct {
+	run    func(ctx context.Context)
+	lock   sync.Mutex
+	cancel context.CancelFunc
+	done   chan struct{}
+}
+
+// NewWorker wraps run, which must return once its context is done.
+func NewWorker(run func(ctx context.Context)) *Worker {
+	return &Worker{run: run}
+}
+
+// Start runs the loop with a context of its own, so it outlives the context
+// Start was called with.
+func (w *Worker) Start(ctx context.Context) error {
+	w.lock.Lock()
+	defer w.lock.Unlock()
+	runCtx, cancel := context.WithCancel(context.WithoutCancel(ctx))
+	w.cancel = cancel
+	w.done = make(chan struct{})
+	go func(done chan struct{}) {
+		defer close(done)
+		w.run(runCtx)
+	}(w.done)
+	return nil
+}
+
+// Stop cancels the loop and waits for it to return.
+func (w *Worker) Stop(ctx context.Context) error {
+	w.lock.Lock()
+	cancel, done := w.cancel, w.done
+	w.lock.Unlock()
+	if cancel == nil {
+		return nil
+	}
+	cancel()
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// Hooks adapts functions to a Component, nil hooks do nothing.
+type Hooks struct {
+	OnStart  func(ctx context.Context) error
+	OnStop   func(ctx context.Context) error
+	OnReload func(ctx context.Context, cfg config.Config) error
+}
+
+func (h Hooks) Start(ctx context.Context) error {
+	if h.OnStart == nil {
+		return nil
+	}
+	return h.OnStart(ctx)
+}
+
+func (h Hooks) Stop(ctx context.Context) error {
+	if h.OnStop == nil {
+		return nil
+	}
+	return h.OnStop(ctx)
+}
+
+func (h Hooks) Reload(ctx context.Context, cfg config.Config) error {
+	if h.OnReload == nil {
+		return nil
+	}
+	return h.OnReload(ctx, cfg)
+}
+
+// HTTPServer serves until it is stopped, then lets in flight requests finish.
+// It serves HTTPS when the server has a TLSConfig.
+type HTTPServer struct {
+	server   *http.Server
+	errs     chan error
+	lock     sync.Mutex
+	listener net.Listener
+}
+
+func NewHTTPServer(server *http.Server) *HTTPServer {
+	return &HTTPServer{server: server, errs: make(chan error, 1)}
+}
+
+// Start listens before returning, so a port that is taken fails startup.
+func (s *HTTPServer) Start(ctx context.Context) error {
+	listener, err := net.Listen("tcp", s.server.Addr)
+	if err != nil {
+		return err
+	}
+	s.lock.Lock()
+	s.listener = listener
+	s.lock.Unlock()
+	go func() {
+		serve := s.server.Serve
+		if s.server.TLSConfig != nil {
+			// the certificate comes from the TLSConfig
+			serve = func(listener net.Listener) error {
+				return s.server.ServeTLS(listener, "", "")
+			}
+		}
+		if err := serve(listener); !errors.Is(err, http.ErrServerClosed) {
+			s.errs <- err
+		}
+	}()
+	return nil
+}
+
+// Addr is the address the server listens on once started, useful when it was
+// started on port 0.
+func (s *HTTPServer) Addr() net.Addr {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	if s.listener == nil {
+		return nil
+	}
+	return s.listener.Addr()
+}
+
+// Err receives the error the server stopped serving with, if it stops on its
+// own.
+func (s *HTTPServer) Err() <-chan error {
+	return s.errs
+}
+
+func (s *HTTPServer) Stop(ctx context.Context) error {
+	return s.server.Shutdown(ctx)
+}
+
+// GRPCServer serves gRPC until it is stopped, then lets in flight calls
+// finish. Streams are cancelled as soon as Stop is called, as watches would
+// otherwise hold shutdown up until it times out.
+type GRPCServer struct {
+	server        *grpc.Server
+	addr          string
+	errs          chan error
+	stopping      context.Context
+	cancelStreams context.CancelFunc
+	lock          sync.Mutex
+	listener      net.Listener
+}
+
+// NewGRPCServer builds the server with opts, services are registered on
+// Server before it is started.
+func NewGRPCServer(addr string, opts ...grpc.ServerOption) *GRPCServer {
+	s := &GRPCServer{addr: addr, errs: make(chan error, 1)}
+	s.stopping, s.cancelStreams = context.WithCancel(context.Background())
+	s.server = grpc.NewServer(append(opts, grpc.ChainStreamInterceptor(s.cancelOnStop))...)
+	return s
+}
+
+func (s *GRPCServer) Server() *grpc.Server {
+	return s.server
+}
+
+// Start listens before returning, so a port that is taken fails startup.
+func (s *GRPCServer) Start(ctx context.Context) error {
+	listener, err := net.Listen("tcp", s.addr)
+	if err != nil {
+		return err
+	}
+	s.lock.Lock()
+	s.listener = listener
+	s.lock.Unlock()
+	go func() {
+		if err := s.server.Serve(listener); err != nil && !errors.Is(err, grpc.ErrServerStopped) {
+			s.errs <- err
+		}
+	}()
+	return nil
+}
+
+// Addr is the address the server listens on once started.
+func (s *GRPCServer) Addr() net.Addr {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	if s.listener == nil {
+		return nil
+	}
+	return s.listener.Addr()
+}
+
+// Err receives the error the server stopped serving with, if it stops on its
+// own.
+func (s *GRPCServer) Err() <-chan error {
+	return s.errs
+}
+
+// Stop waits for unary calls to finish, closing every connection once ctx is
+// done.
+func (s *GRPCServer) Stop(ctx context.Context) error {
+	s.cancelStreams()
+	stopped := make(chan struct{})
+	go func() {
+		s.server.GracefulStop()
+		close(stopped)
+	}()
+	select {
+	case <-stopped:
+		return nil
+	case <-ctx.Done():
+		s.server.Stop()
+		return ctx.Err()
+	}
+}
+
+// cancelOnStop cancels the stream's context when Stop is called, ending it
+// with UNAVAILABLE so clients know to reconnect elsewhere.
+func (s *GRPCServer) cancelOnStop(srv interface{}, stream grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+	ctx, cancel := context.WithCancel(stream.Context())
+	defer cancel()
+	stop := context.AfterFunc(s.stopping, cancel)
+	defer stop()
+
+	err := handler(srv, stoppableStream{stream, ctx})
+	if err != nil && s.stopping.Err() != nil && status.Code(err) == codes.Canceled {
+		return status.Error(codes.Unavailable, "server is shutting down")
+	}
+	return err
+}
+
+type stoppableStream struct {
+	grpc.ServerStream
+	ctx context.Context
+}
+
+func (s stoppableStream) Context() context.Context {
+	return s.ctx
+}
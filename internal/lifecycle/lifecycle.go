@@ -0,0 +1,174 @@
+// Package lifecycle starts the service's long running components in
+// dependency order, stops them in reverse and hands them configuration
+// reloads.
+package lifecycle
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/olad5/sal-backend-service/internal/config"
+)
+
+// Component is something that runs for the life of the service, such as a
+// server, a background worker or a store that must be flushed.
+type Component interface {
+	// Start returns once the component is running, work that runs until
+	// Stop is called belongs in a goroutine of its own
+	Start(ctx context.Context) error
+	// Stop returns once the component has finished, or when ctx is done
+	Stop(ctx context.Context) error
+}
+
+// Reloader is implemented by components that can apply a new configuration
+// without a restart.
+type Reloader interface {
+	Reload(ctx context.Context, cfg config.Config) error
+}
+
+type entry struct {
+	name        string
+	component   Component
+	stopTimeout time.Duration
+}
+
+// Manager owns the service's components. Components are started in the
+// order they are added, so a component should be added after the ones it
+// depends on.
+type Manager struct {
+	logger  *slog.Logger
+	lock    sync.Mutex
+	entries []entry
+	started int
+}
+
+func NewManager(logger *slog.Logger) (*Manager, error) {
+	if logger == nil {
+		return nil, errors.New("lifecycle Manager failed to initialize, logger is nil")
+	}
+	return &Manager{logger: logger}, nil
+}
+
+// Add registers a component, giving it stopTimeout to stop.
+func (m *Manager) Add(name string, component Component, stopTimeout time.Duration) {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+	if m.started > 0 {
+		panic("lifecycle: component " + name + " added after Start")
+	}
+	for _, e := range m.entries {
+		if e.name == name {
+			panic("lifecycle: component " + name + " added twice")
+		}
+	}
+	m.entries = append(m.entries, entry{name, component, stopTimeout})
+}
+
+// Start starts every component in order. When one fails, the ones already
+// started are stopped again and the error is returned.
+func (m *Manager) Start(ctx context.Context) error {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+	for m.started < len(m.entries) {
+		e := m.entries[m.started]
+		if err := e.component.Start(ctx); err != nil {
+			startErr := fmt.Errorf("failed to start %s: %w", e.name, err)
+			if stopErr := m.stopStarted(); stopErr != nil {
+				return errors.Join(startErr, stopErr)
+			}
+			return startErr
+		}
+		m.logger.Debug("component started", "component", e.name)
+		m.started++
+	}
+	return nil
+}
+
+// StopError lists the components that failed to stop, or did not stop within
+// their timeout.
+type StopError struct {
+	Failures []ComponentError
+}
+
+type ComponentError struct {
+	Component string
+	Err       error
+}
+
+func (e *StopError) Error() string {
+	messages := make([]string, 0, len(e.Failures))
+	for _, failure := range e.Failures {
+		messages = append(messages, failure.Component+": "+failure.Err.Error())
+	}
+	return "failed to stop " + strings.Join(messages, "; ")
+}
+
+var ErrStopTimedOut = errors.New("did not stop in time")
+
+// Stop stops the started components in reverse order, each within its own
+// timeout, and carries on past ones that fail so every component gets its
+// chance to stop. The returned error is a *StopError.
+func (m *Manager) Stop() error {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+	return m.stopStarted()
+}
+
+func (m *Manager) stopStarted() error {
+	stopErr := &StopError{}
+	for ; m.started > 0; m.started-- {
+		e := m.entries[m.started-1]
+		if err := stopWithin(e); err != nil {
+			m.logger.Error("component failed to stop", "component", e.name, "error", err)
+			stopErr.Failures = append(stopErr.Failures, ComponentError{e.name, err})
+			continue
+		}
+		m.logger.Debug("component stopped", "component", e.name)
+	}
+	if len(stopErr.Failures) > 0 {
+		return stopErr
+	}
+	return nil
+}
+
+// stopWithin gives up waiting on components that ignore their context.
+func stopWithin(e entry) error {
+	ctx, cancel := context.WithTimeout(context.Background(), e.stopTimeout)
+	defer cancel()
+	done := make(chan error, 1)
+	go func() { done <- e.component.Stop(ctx) }()
+	select {
+	case err := <-done:
+		if errors.Is(err, context.DeadlineExceeded) {
+			return ErrStopTimedOut
+		}
+		return err
+	case <-ctx.Done():
+		return ErrStopTimedOut
+	}
+}
+
+// Reload hands cfg to every started component that is a Reloader, in start
+// order, and returns the errors of those that could not apply it.
+func (m *Manager) Reload(ctx context.Context, cfg config.Config) error {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+	var errs []error
+	for _, e := range m.entries[:m.started] {
+		reloader, ok := e.component.(Reloader)
+		if !ok {
+			continue
+		}
+		if err := reloader.Reload(ctx, cfg); err != nil {
+			errs = append(errs, fmt.Errorf("failed to reload %s: %w", e.name, err))
+			continue
+		}
+		m.logger.Debug("component reloaded", "component", e.name)
+	}
+	return errors.Join(errs...)
+}
@@ -0,0 +1,86 @@
+// Package logging builds the service's structured JSON logger. Every log line
+// written with a request's context carries its request ID and trace.
+package logging
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+	"strings"
+
+	"github.com/olad5/sal-backend-service/pkg/tracing"
+)
+
+// Logger is a slog.Logger whose level can be changed while it is in use.
+type Logger struct {
+	*slog.Logger
+	level *slog.LevelVar
+}
+
+func New(w io.Writer, level slog.Level) *Logger {
+	levelVar := new(slog.LevelVar)
+	levelVar.Set(level)
+	handler := slog.NewJSONHandler(w, &slog.HandlerOptions{Level: levelVar})
+	return &Logger{Logger: slog.New(contextHandler{handler}), level: levelVar}
+}
+
+// Discard returns a logger that writes nothing, for tests.
+func Discard() *Logger {
+	return New(io.Discard, slog.LevelError+1)
+}
+
+func (l *Logger) Level() slog.Level {
+	return l.level.Level()
+}
+
+func (l *Logger) SetLevel(level slog.Level) {
+	l.level.Set(level)
+}
+
+// ParseLevel accepts debug, info, warn and error in any case.
+func ParseLevel(value string) (slog.Level, error) {
+	var level slog.Level
+	switch strings.ToLower(value) {
+	case "debug", "info", "warn", "error":
+		err := level.UnmarshalText([]byte(value))
+		return level, err
+	}
+	return level, fmt.Errorf("unknown log level %q, want debug, info, warn or error", value)
+}
+
+type requestIDKey struct{}
+
+func WithRequestID(ctx context.Context, requestId string) context.Context {
+	return context.WithValue(ctx, requestIDKey{}, requestId)
+}
+
+// RequestID returns the ID of the request ctx belongs to, or "".
+func RequestID(ctx context.Context) string {
+	requestId, _ := ctx.Value(requestIDKey{}).(string)
+	return requestId
+}
+
+// contextHandler adds the request ID and the current span from the record's
+// context, so log lines can be found from a trace.
+type contextHandler struct {
+	slog.Handler
+}
+
+func (h contextHandler) Handle(ctx context.Context, record slog.Record) error {
+	if requestId := RequestID(ctx); requestId != "" {
+		record.AddAttrs(slog.String("request_id", requestId))
+	}
+	if sc := tracing.SpanContextFromContext(ctx); sc.IsValid() {
+		record.AddAttrs(slog.String("trace_id", sc.TraceID.String()), slog.String("span_id", sc.SpanID.String()))
+	}
+	return h.Handler.Handle(ctx, record)
+}
+
+func (h contextHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	return contextHandler{h.Handler.WithAttrs(attrs)}
+}
+
+func (h contextHandler) WithGroup(name string) slog.Handler {
+	return contextHandler{h.Handler.WithGroup(name)}
+}
@@ -0,0 +1,145 @@
+package salctl
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+)
+
+// Config is the profiles file, such as
+//
+//	{
+//	  "current_profile": "staging",
+//	  "profiles": {
+//	    "local": {"base_url": "http://localhost:4000"},
+//	    "staging": {"base_url": "https://sal.staging.example.com", "merchant_id": "..."}
+//	  }
+//	}
+type Config struct {
+	CurrentProfile string             `json:"current_profile"`
+	Profiles       map[string]Profile `json:"profiles"`
+}
+
+type Profile struct {
+	Name       string `json:"-"`
+	BaseURL    string `json:"base_url"`
+	MerchantId string `json:"merchant_id,omitempty"`
+}
+
+func defaultConfigPath() string {
+	if path := os.Getenv("SALCTL_CONFIG"); path != "" {
+		return path
+	}
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return ""
+	}
+	return filepath.Join(dir, "salctl", "config.json")
+}
+
+// readConfig reads the profiles file, a missing file is an empty config.
+func readConfig(path string) (Config, error) {
+	var config Config
+	if path == "" {
+		return config, nil
+	}
+	data, err := os.ReadFile(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return config, nil
+	}
+	if err != nil {
+		return config, err
+	}
+	if err := json.Unmarshal(data, &config); err != nil {
+		return config, fmt.Errorf("invalid profiles file %s: %w", path, err)
+	}
+	return config, nil
+}
+
+// loadProfile resolves the profile to use. Flags take precedence over
+// environment variables, which take precedence over the profile.
+func loadProfile(flags *globalFlags) (Profile, error) {
+	path := flags.configPath
+	if path == "" {
+		path = defaultConfigPath()
+	}
+	config, err := readConfig(path)
+	if err != nil {
+		return Profile{}, err
+	}
+
+	name := firstNonEmpty(flags.profile, os.Getenv("SALCTL_PROFILE"), config.CurrentProfile)
+	var profile Profile
+	if name != "" {
+		var ok bool
+		profile, ok = config.Profiles[name]
+		if !ok {
+			return Profile{}, usageError("profile %q not found in %s", name, path)
+		}
+		profile.Name = name
+	} else if defaultProfile, ok := config.Profiles["default"]; ok {
+		profile = defaultProfile
+		profile.Name = "default"
+	}
+
+	profile.BaseURL = firstNonEmpty(flags.baseURL, os.Getenv("SALCTL_BASE_URL"), profile.BaseURL)
+	profile.MerchantId = firstNonEmpty(flags.merchant, os.Getenv("SALCTL_MERCHANT_ID"), profile.MerchantId)
+	return profile, nil
+}
+
+func firstNonEmpty(values ...string) string {
+	for _, value := range values {
+		if value != "" {
+			return value
+		}
+	}
+	return ""
+}
+
+func listProfiles(e *env, args []string) error {
+	fs := newFlagSet("profiles list")
+	if _, err := e.parse(fs, args); err != nil {
+		return err
+	}
+	path := e.flags.configPath
+	if path == "" {
+		path = defaultConfigPath()
+	}
+	config, err := readConfig(path)
+	if err != nil {
+		return err
+	}
+
+	names := make([]string, 0, len(config.Profiles))
+	for name := range config.Profiles {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	type profileRow struct {
+		Name       string `json:"name"`
+		Current    bool   `json:"current"`
+		BaseURL    string `json:"base_url"`
+		MerchantId string `json:"merchant_id"`
+	}
+	rows := make([]profileRow, 0, len(names))
+	for _, name := range names {
+		profile := config.Profiles[name]
+		rows = append(rows, profileRow{name, name == e.profile.Name, profile.BaseURL, profile.MerchantId})
+	}
+
+	return e.printer().print(rows, func(t *table) {
+		t.header("NAME", "CURRENT", "BASE URL", "MERCHANT")
+		for _, row := range rows {
+			current := ""
+			if row.Current {
+				current = "*"
+			}
+			t.row(row.Name, current, row.BaseURL, row.MerchantId)
+		}
+	})
+}
@@ -0,0 +1,87 @@
+package salctl
+
+import (
+	"strconv"
+
+	"github.com/google/uuid"
+	"github.com/olad5/sal-backend-service/pkg/client"
+)
+
+type merchantReport struct {
+	MerchantId     uuid.UUID            `json:"merchant_id"`
+	Plan           client.Plan          `json:"plan"`
+	Products       client.QuotaUsage    `json:"products"`
+	Images         client.QuotaUsage    `json:"images"`
+	SKUCodePattern string               `json:"sku_code_pattern"`
+	PricingRules   []client.PricingRule `json:"pricing_rules"`
+}
+
+func inspectMerchant(e *env, args []string) error {
+	fs := newFlagSet("merchant inspect")
+	positional, err := e.parse(fs, args)
+	if err != nil {
+		return err
+	}
+	if len(positional) != 0 {
+		return usageError("unexpected arguments %q", positional)
+	}
+	merchantId, err := e.merchantId()
+	if err != nil {
+		return err
+	}
+
+	usage, err := e.client.GetUsage(e.ctx, merchantId)
+	if err != nil {
+		return err
+	}
+	pattern, err := e.client.GetSKUCodePattern(e.ctx, merchantId)
+	if err != nil {
+		return err
+	}
+	rules, err := e.client.ListPricingRules(e.ctx, merchantId)
+	if err != nil {
+		return err
+	}
+	if rules == nil {
+		rules = []client.PricingRule{}
+	}
+
+	report := merchantReport{
+		MerchantId:     merchantId,
+		Plan:           usage.Plan,
+		Products:       usage.Products,
+		Images:         usage.Images,
+		SKUCodePattern: pattern.Pattern,
+		PricingRules:   rules,
+	}
+	return e.printer().print(report, func(t *table) {
+		t.row("MERCHANT", merchantId.String())
+		t.row("PLAN", usage.Plan.Name)
+		t.row("PRODUCTS", quota(usage.Products))
+		t.row("IMAGES", quota(usage.Images))
+		t.row("REQUESTS PER MINUTE", strconv.Itoa(usage.RequestsPerMinute))
+		t.row("SKU CODE PATTERN", pattern.Pattern)
+		t.row("PRICING RULES", strconv.Itoa(len(rules)))
+		for _, rule := range rules {
+			t.row("", pricingRuleSummary(rule))
+		}
+	})
+}
+
+func quota(usage client.QuotaUsage) string {
+	return strconv.Itoa(usage.Used) + " of " + strconv.Itoa(usage.Limit)
+}
+
+func pricingRuleSummary(rule client.PricingRule) string {
+	summary := rule.ID.String() + " " + rule.Type + " " + strconv.FormatFloat(rule.Value, 'f', -1, 64)
+	if rule.SKUID != nil {
+		summary += " on " + rule.SKUID.String()
+	}
+	if rule.CustomerGroup != "" {
+		summary += " for " + rule.CustomerGroup
+	}
+	if rule.MinQuantity > 1 {
+		summary += " from " + strconv.Itoa(rule.MinQuantity) + " units"
+	}
+	return summary
+}
@@ -0,0 +1,217 @@
+package salctl
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"regexp"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+)
+
+type printer struct {
+	format string
+	w      io.Writer
+}
+
+func validateOutput(format string) error {
+	switch format {
+	case "table", "json", "yaml":
+		return nil
+	}
+	return usageError("unknown output format %q, want table, json or yaml", format)
+}
+
+// print writes value as JSON or YAML, or as the table built by fill.
+func (p printer) print(value interface{}, fill func(t *table)) error {
+	switch p.format {
+	case "json":
+		encoder := json.NewEncoder(p.w)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(value)
+	case "yaml":
+		return writeYAML(p.w, value)
+	}
+	t := &table{w: tabwriter.NewWriter(p.w, 0, 4, 2, ' ', 0)}
+	fill(t)
+	return t.w.Flush()
+}
+
+type table struct {
+	w *tabwriter.Writer
+}
+
+func (t *table) header(columns ...string) {
+	t.row(columns...)
+}
+
+func (t *table) row(columns ...string) {
+	for i, column := range columns {
+		// tabs and newlines would break the columns
+		columns[i] = strings.NewReplacer("\t", " ", "\n", " ").Replace(column)
+	}
+	fmt.Fprintln(t.w, strings.Join(columns, "\t"))
+}
+
+func formatPrice(price float64) string {
+	return strconv.FormatFloat(price, 'f', 2, 64)
+}
+
+// writeYAML writes value as YAML, keeping the field order of its JSON
+// encoding.
+func writeYAML(w io.Writer, value interface{}) error {
+	data, err := json.Marshal(value)
+	if err != nil {
+		return err
+	}
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+	node, err := decodeNode(decoder)
+	if err != nil {
+		return err
+	}
+
+	var out bytes.Buffer
+	switch {
+	case node.isMap() && len(node.keys) > 0:
+		writeYAMLMap(&out, node, 0)
+	case node.isList() && len(node.items) > 0:
+		writeYAMLList(&out, node, 0)
+	default:
+		out.WriteString(yamlScalar(node) + "\n")
+	}
+	_, err = w.Write(out.Bytes())
+	return err
+}
+
+// yamlNode is a decoded JSON value, with object keys in their original order.
+type yamlNode struct {
+	kind   json.Delim
+	keys   []string
+	values []*yamlNode
+	items  []*yamlNode
+	scalar interface{}
+}
+
+func (n *yamlNode) isMap() bool  { return n.kind == '{' }
+func (n *yamlNode) isList() bool { return n.kind == '[' }
+
+func decodeNode(decoder *json.Decoder) (*yamlNode, error) {
+	token, err := decoder.Token()
+	if err != nil {
+		return nil, err
+	}
+	delim, ok := token.(json.Delim)
+	if !ok {
+		return &yamlNode{scalar: token}, nil
+	}
+
+	node := &yamlNode{kind: delim}
+	for decoder.More() {
+		if delim == '{' {
+			key, err := decoder.Token()
+			if err != nil {
+				return nil, err
+			}
+			value, err := decodeNode(decoder)
+			if err != nil {
+				return nil, err
+			}
+			node.keys = append(node.keys, key.(string))
+			node.values = append(node.values, value)
+			continue
+		}
+		item, err := decodeNode(decoder)
+		if err != nil {
+			return nil, err
+		}
+		node.items = append(node.items, item)
+	}
+	// the closing delimiter
+	if _, err := decoder.Token(); err != nil {
+		return nil, err
+	}
+	return node, nil
+}
+
+func writeYAMLMap(out *bytes.Buffer, node *yamlNode, indent int) {
+	for i, key := range node.keys {
+		if i > 0 {
+			out.WriteString(strings.Repeat(" ", indent))
+		}
+		writeYAMLEntry(out, yamlKey(key)+":", node.values[i], indent)
+	}
+}
+
+func writeYAMLList(out *bytes.Buffer, node *yamlNode, indent int) {
+	for i, item := range node.items {
+		if i > 0 {
+			out.WriteString(strings.Repeat(" ", indent))
+		}
+		out.WriteString("- ")
+		switch {
+		case item.isMap() && len(item.keys) > 0:
+			writeYAMLMap(out, item, indent+2)
+		case item.isList() && len(item.items) > 0:
+			writeYAMLList(out, item, indent+2)
+		default:
+			out.WriteString(yamlScalar(item) + "\n")
+		}
+	}
+}
+
+// writeYAMLEntry writes "prefix value", with non empty maps and lists on the
+// following lines.
+func writeYAMLEntry(out *bytes.Buffer, prefix string, value *yamlNode, indent int) {
+	out.WriteString(prefix)
+	switch {
+	case value.isMap() && len(value.keys) > 0:
+		out.WriteString("\n" + strings.Repeat(" ", indent+2))
+		writeYAMLMap(out, value, indent+2)
+	case value.isList() && len(value.items) > 0:
+		out.WriteString("\n" + strings.Repeat(" ", indent+2))
+		writeYAMLList(out, value, indent+2)
+	default:
+		out.WriteString(" " + yamlScalar(value) + "\n")
+	}
+}
+
+var plainYAMLString = regexp.MustCompile(`^[A-Za-z0-9_./][A-Za-z0-9 _./+@()-]*$`)
+
+// yamlReserved are plain scalars YAML would not read back as strings
+var yamlReserved = regexp.MustCompile(`^(?i:y|n|yes|no|on|off|true|false|null|~|[-+]?(\.inf|\.nan)|[-+]?[0-9][0-9_.:eE+-]*|0x[0-9a-f]+|0o[0-7]+)$`)
+
+func yamlScalar(node *yamlNode) string {
+	switch {
+	case node.isMap():
+		return "{}"
+	case node.isList():
+		return "[]"
+	}
+	switch value := node.scalar.(type) {
+	case nil:
+		return "null"
+	case bool:
+		return strconv.FormatBool(value)
+	case json.Number:
+		return value.String()
+	case string:
+		return yamlString(value)
+	}
+	return fmt.Sprint(node.scalar)
+}
+
+func yamlKey(key string) string {
+	return yamlString(key)
+}
+
+func yamlString(value string) string {
+	if plainYAMLString.MatchString(value) && !yamlReserved.MatchString(value) && !strings.HasSuffix(value, " ") {
+		return value
+	}
+	// JSON strings are valid YAML double quoted scalars
+	quoted, _ := json.Marshal(value)
+	return string(quoted)
+}
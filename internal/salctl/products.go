@@ -0,0 +1,304 @@
+package salctl
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/google/uuid"
+	"github.com/olad5/sal-backend-service/pkg/client"
+)
+
+func listProducts(e *env, args []string) error {
+	fs := newFlagSet("products list")
+	query := fs.String("q", "", "search query, lists every product when empty")
+	if _, err := e.parse(fs, args); err != nil {
+		return err
+	}
+	merchantId, err := e.merchantId()
+	if err != nil {
+		return err
+	}
+
+	var products []client.Product
+	if *query == "" {
+		page, err := e.client.ListProducts(e.ctx, merchantId, client.ListProductsOptions{})
+		if err != nil {
+			return err
+		}
+		products = page.Products
+	} else {
+		it := e.client.SearchProductsIter(merchantId, *query, client.SearchOptions{Limit: 100})
+		for it.Next(e.ctx) {
+			products = append(products, it.Result().Product)
+		}
+		if err := it.Err(); err != nil {
+			return err
+		}
+	}
+	if products == nil {
+		products = []client.Product{}
+	}
+	return e.printer().print(products, func(t *table) { productTable(t, products) })
+}
+
+func productTable(t *table, products []client.Product) {
+	t.header("SKU ID", "SKU CODE", "NAME", "PRICE", "EFFECTIVE", "TAX CLASS")
+	for _, product := range products {
+		t.row(product.SKUID.String(), product.SKUCode, product.Name, formatPrice(product.Price), formatPrice(product.EffectivePrice), product.TaxClass)
+	}
+}
+
+func productDetails(t *table, product client.Product) {
+	t.row("SKU ID", product.SKUID.String())
+	t.row("SKU CODE", product.SKUCode)
+	t.row("GTIN", product.GTIN)
+	t.row("ISBN", product.ISBN)
+	t.row("MERCHANT", product.MerchantId.String())
+	t.row("NAME", product.Name)
+	t.row("DESCRIPTION", product.Description)
+	t.row("PRICE", formatPrice(product.Price))
+	t.row("EFFECTIVE PRICE", formatPrice(product.EffectivePrice))
+	t.row("TAX CLASS", product.TaxClass)
+	t.row("CATEGORY", product.Category)
+	t.row("TAGS", strings.Join(product.Tags, ", "))
+	t.row("IMAGES", strconv.Itoa(len(product.Images)))
+	t.row("UPDATED", product.UpdatedAt.Format("2006-01-02 15:04:05Z07:00"))
+}
+
+func getProduct(e *env, args []string) error {
+	fs := newFlagSet("products get")
+	positional, err := e.parse(fs, args)
+	if err != nil {
+		return err
+	}
+	if len(positional) != 1 {
+		return usageError("expected one SKU ID, SKU code or barcode")
+	}
+	merchantId, err := e.merchantId()
+	if err != nil {
+		return err
+	}
+
+	product, err := resolveProduct(e, merchantId, positional[0])
+	if err != nil {
+		return err
+	}
+	return e.printer().print(product, func(t *table) { productDetails(t, product) })
+}
+
+// resolveProduct finds a merchant's product by SKU ID, SKU code or barcode.
+func resolveProduct(e *env, merchantId uuid.UUID, ref string) (client.Product, error) {
+	if skuId, err := uuid.Parse(ref); err == nil {
+		return findProduct(e, merchantId, skuId)
+	}
+	product, err := e.client.GetProductByCode(e.ctx, merchantId, ref)
+	if errors.Is(err, client.ErrNotFound) && isBarcode(ref) {
+		return e.client.GetProductByBarcode(e.ctx, merchantId, ref)
+	}
+	return product, err
+}
+
+func isBarcode(ref string) bool {
+	digits := 0
+	for _, r := range ref {
+		switch {
+		case r >= '0' && r <= '9':
+			digits++
+		case r == '-' || r == ' ':
+		case r == 'X' || r == 'x':
+			// the ISBN-10 check digit
+		default:
+			return false
+		}
+	}
+	return digits >= 8
+}
+
+// findProduct finds a merchant's product by SKU ID. The API has no endpoint
+// for a single product, so it is looked up in the merchant's listing.
+func findProduct(e *env, merchantId, skuId uuid.UUID) (client.Product, error) {
+	page, err := e.client.ListProducts(e.ctx, merchantId, client.ListProductsOptions{})
+	if err != nil {
+		return client.Product{}, err
+	}
+	for _, product := range page.Products {
+		if product.SKUID == skuId {
+			return product, nil
+		}
+	}
+	return client.Product{}, client.ErrProductNotFound
+}
+
+// productFlags are the fields products create and edit accept.
+type productFlags struct {
+	name        string
+	description string
+	price       float64
+	skuCode     string
+	gtin        string
+	isbn        string
+	taxClass    string
+	category    string
+}
+
+func (p *productFlags) register(fs *flag.FlagSet) {
+	fs.StringVar(&p.name, "name", "", "product name")
+	fs.StringVar(&p.description, "description", "", "product description")
+	fs.Float64Var(&p.price, "price", 0, "regular price")
+	fs.StringVar(&p.skuCode, "sku-code", "", "merchant facing SKU code, generated when empty")
+	fs.StringVar(&p.gtin, "gtin", "", "GTIN-8, UPC-A, EAN-13 or GTIN-14 barcode")
+	fs.StringVar(&p.isbn, "isbn", "", "ISBN-10 or ISBN-13")
+	fs.StringVar(&p.taxClass, "tax-class", "", "tax class, such as standard, reduced or zero")
+	fs.StringVar(&p.category, "category", "", "product category, pricing rules can be scoped to it")
+}
+
+func setFlags(fs *flag.FlagSet) map[string]bool {
+	set := map[string]bool{}
+	fs.Visit(func(f *flag.Flag) {
+		set[f.Name] = true
+	})
+	return set
+}
+
+func createProduct(e *env, args []string) error {
+	fs := newFlagSet("products create")
+	var fields productFlags
+	fields.register(fs)
+	positional, err := e.parse(fs, args)
+	if err != nil {
+		return err
+	}
+	if len(positional) != 0 {
+		return usageError("unexpected arguments %q", positional)
+	}
+	set := setFlags(fs)
+	if !set["name"] || !set["description"] || !set["price"] {
+		return usageError("-name, -description and -price are required")
+	}
+	merchantId, err := e.merchantId()
+	if err != nil {
+		return err
+	}
+
+	product, err := e.client.CreateProduct(e.ctx, client.CreateProductRequest{
+		MerchantId:  merchantId,
+		SKUCode:     fields.skuCode,
+		GTIN:        fields.gtin,
+		ISBN:        fields.isbn,
+		Name:        fields.name,
+		Description: fields.description,
+		Price:       fields.price,
+		TaxClass:    fields.taxClass,
+		Category:    fields.category,
+	})
+	if err != nil {
+		return err
+	}
+	return e.printer().print(product, func(t *table) { productDetails(t, product) })
+}
+
+func editProduct(e *env, args []string) error {
+	fs := newFlagSet("products edit")
+	var fields productFlags
+	fields.register(fs)
+	positional, err := e.parse(fs, args)
+	if err != nil {
+		return err
+	}
+	if len(positional) != 1 {
+		return usageError("expected one SKU ID")
+	}
+	skuId, err := uuid.Parse(positional[0])
+	if err != nil {
+		return usageError("invalid SKU ID %q", positional[0])
+	}
+	set := setFlags(fs)
+	if len(set) == 0 {
+		return usageError("nothing to change")
+	}
+	merchantId, err := e.merchantId()
+	if err != nil {
+		return err
+	}
+
+	// the API replaces name, description and price, so unchanged fields are
+	// sent back as they are
+	current, err := findProduct(e, merchantId, skuId)
+	if err != nil {
+		return err
+	}
+	request := client.UpdateProductRequest{
+		MerchantId:  merchantId,
+		Name:        current.Name,
+		Description: current.Description,
+		Price:       current.Price,
+	}
+	if set["name"] {
+		request.Name = fields.name
+	}
+	if set["description"] {
+		request.Description = fields.description
+	}
+	if set["price"] {
+		request.Price = fields.price
+	}
+	request.SKUCode = fields.skuCode
+	request.GTIN = fields.gtin
+	request.ISBN = fields.isbn
+	request.TaxClass = fields.taxClass
+	request.Category = fields.category
+
+	product, err := e.client.UpdateProduct(e.ctx, skuId, request)
+	if err != nil {
+		return err
+	}
+	return e.printer().print(product, func(t *table) { productDetails(t, product) })
+}
+
+func deleteProducts(e *env, args []string) error {
+	fs := newFlagSet("products delete")
+	positional, err := e.parse(fs, args)
+	if err != nil {
+		return err
+	}
+	if len(positional) == 0 {
+		return usageError("expected at least one SKU ID")
+	}
+	skuIds := make([]uuid.UUID, 0, len(positional))
+	for _, arg := range positional {
+		skuId, err := uuid.Parse(arg)
+		if err != nil {
+			return usageError("invalid SKU ID %q", arg)
+		}
+		skuIds = append(skuIds, skuId)
+	}
+	merchantId, err := e.merchantId()
+	if err != nil {
+		return err
+	}
+
+	deleted := []uuid.UUID{}
+	var deleteErr error
+	for _, skuId := range skuIds {
+		if err := e.client.DeleteProduct(e.ctx, merchantId, skuId); err != nil {
+			deleteErr = fmt.Errorf("deleting %s: %w", skuId, err)
+			break
+		}
+		deleted = append(deleted, skuId)
+	}
+	if len(deleted) == 0 {
+		return deleteErr
+	}
+	if err := e.printer().print(deleted, func(t *table) {
+		t.header("DELETED")
+		for _, skuId := range deleted {
+			t.row(skuId.String())
+		}
+	}); err != nil {
+		return err
+	}
+	return deleteErr
+}
@@ -0,0 +1,277 @@
+// Package salctl implements the salctl command line tool, which manages a
+// SAL deployment's catalogue through pkg/client.
+package salctl
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"net"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/olad5/sal-backend-service/pkg/client"
+)
+
+// Exit codes returned by Run.
+const (
+	ExitOK          = 0
+	ExitError       = 1
+	ExitUsage       = 2
+	ExitNotFound    = 3
+	ExitConflict    = 4
+	ExitUnavailable = 5
+)
+
+var errUsage = errors.New("usage error")
+
+// usageError reports a mistake in the command line, printed with the
+// command's usage.
+func usageError(format string, args ...interface{}) error {
+	return fmt.Errorf("%w: %s", errUsage, fmt.Sprintf(format, args...))
+}
+
+// globalFlags are accepted before the command and by every command.
+type globalFlags struct {
+	configPath string
+	profile    string
+	baseURL    string
+	merchant   string
+	output     string
+	timeout    time.Duration
+}
+
+func (g *globalFlags) register(fs *flag.FlagSet) {
+	fs.StringVar(&g.configPath, "config", g.configPath, "path of the profiles file")
+	fs.StringVar(&g.profile, "profile", g.profile, "profile to use from the profiles file")
+	fs.StringVar(&g.baseURL, "base-url", g.baseURL, "base URL of the SAL API, overrides the profile")
+	fs.StringVar(&g.merchant, "merchant", g.merchant, "merchant ID, overrides the profile")
+	fs.StringVar(&g.output, "o", g.output, "output format: table, json or yaml")
+	fs.StringVar(&g.output, "output", g.output, "output format: table, json or yaml")
+	fs.DurationVar(&g.timeout, "timeout", g.timeout, "timeout of the whole command")
+}
+
+// env is everything a command runs with.
+type env struct {
+	ctx    context.Context
+	flags  *globalFlags
+	stdin  io.Reader
+	stdout io.Writer
+	stderr io.Writer
+
+	offline bool
+	profile Profile
+	client  *client.Client
+	cancel  context.CancelFunc
+}
+
+// merchantId returns the merchant the command acts for, from --merchant or
+// the profile.
+func (e *env) merchantId() (uuid.UUID, error) {
+	if e.profile.MerchantId == "" {
+		return uuid.Nil, usageError("no merchant, pass --merchant or set merchant_id in the profile")
+	}
+	merchantId, err := uuid.Parse(e.profile.MerchantId)
+	if err != nil {
+		return uuid.Nil, usageError("invalid merchant ID %q", e.profile.MerchantId)
+	}
+	return merchantId, nil
+}
+
+func (e *env) printer() printer {
+	return printer{format: e.flags.output, w: e.stdout}
+}
+
+type command struct {
+	name    string
+	usage   string
+	summary string
+	// run parses its own flags from args
+	run func(e *env, args []string) error
+	// offline commands do not need a client
+	offline bool
+}
+
+var commands = []command{
+	{name: "products list", usage: "products list [-q query]", summary: "list or search the merchant's products", run: listProducts},
+	{name: "products get", usage: "products get <sku-id|sku-code|gtin>", summary: "show one product", run: getProduct},
+	{name: "products create", usage: "products create -name n -description d -price p [-sku-code c] [-gtin g] [-isbn i] [-tax-class t] [-category c]", summary: "create a product", run: createProduct},
+	{name: "products edit", usage: "products edit <sku-id> [-name n] [-price p] [-description d] [-sku-code c] [-gtin g] [-isbn i] [-tax-class t] [-category c]", summary: "change some fields of a product", run: editProduct},
+	{name: "products delete", usage: "products delete <sku-id>...", summary: "delete products", run: deleteProducts},
+	{name: "import", usage: "import [-file path] [-format json|csv]", summary: "create products from a JSON or CSV file, - reads stdin", run: importProducts},
+	{name: "export", usage: "export [-file path] [-format json|csv]", summary: "write the merchant's products to a JSON or CSV file", run: exportProducts},
+	{name: "merchant inspect", usage: "merchant inspect", summary: "show the merchant's plan, usage, code pattern and pricing rules", run: inspectMerchant},
+	{name: "seed", usage: "seed [-count n] [-seed s]", summary: "create random products for testing", run: seedProducts},
+	{name: "profiles list", usage: "profiles list", summary: "list the profiles in the profiles file", run: listProfiles, offline: true},
+}
+
+func printUsage(w io.Writer) {
+	fmt.Fprint(w, `salctl manages products of a SAL Backend Service deployment.
+
+Usage:
+  salctl [global flags] <command> [flags] [args]
+
+Commands:
+`)
+	for _, cmd := range commands {
+		fmt.Fprintf(w, "  %-18s %s\n", cmd.name, cmd.summary)
+	}
+	fmt.Fprintf(w, `
+Global flags, also accepted after the command:
+  -config path      profiles file, defaults to $SALCTL_CONFIG or %s
+  -profile name     profile to use, defaults to $SALCTL_PROFILE or the file's current_profile
+  -base-url url     base URL of the API, overrides the profile and $SALCTL_BASE_URL
+  -merchant id      merchant ID, overrides the profile and $SALCTL_MERCHANT_ID
+  -o, -output fmt   table, json or yaml
+  -timeout d        timeout of the whole command, such as 30s
+
+Exit codes:
+  0 success, 1 error, 2 usage error, 3 not found, 4 conflict,
+  5 API unavailable or rate limited
+`, "<user config dir>/salctl/config.json")
+}
+
+// Run runs salctl with args, not including the program name, and returns the
+// process exit code.
+func Run(ctx context.Context, args []string, stdin io.Reader, stdout, stderr io.Writer) int {
+	flags := &globalFlags{output: "table"}
+	root := flag.NewFlagSet("salctl", flag.ContinueOnError)
+	root.SetOutput(io.Discard)
+	flags.register(root)
+	if err := root.Parse(args); err != nil {
+		if errors.Is(err, flag.ErrHelp) {
+			printUsage(stdout)
+			return ExitOK
+		}
+		fmt.Fprintf(stderr, "salctl: %v\n", err)
+		printUsage(stderr)
+		return ExitUsage
+	}
+
+	args = root.Args()
+	if len(args) == 0 || args[0] == "help" {
+		printUsage(stdout)
+		return ExitOK
+	}
+	cmd, rest, ok := findCommand(args)
+	if !ok {
+		fmt.Fprintf(stderr, "salctl: unknown command %q\n", strings.Join(args, " "))
+		printUsage(stderr)
+		return ExitUsage
+	}
+
+	e := &env{ctx: ctx, flags: flags, stdin: stdin, stdout: stdout, stderr: stderr}
+	err := runCommand(e, cmd, rest)
+	if err == nil {
+		return ExitOK
+	}
+	fmt.Fprintf(stderr, "salctl %s: %v\n", cmd.name, err)
+	if errors.Is(err, errUsage) {
+		fmt.Fprintf(stderr, "usage: salctl %s\n", cmd.usage)
+	}
+	return exitCode(err)
+}
+
+// findCommand matches the longest command name at the start of args.
+func findCommand(args []string) (command, []string, bool) {
+	sorted := make([]command, len(commands))
+	copy(sorted, commands)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		return len(strings.Fields(sorted[i].name)) > len(strings.Fields(sorted[j].name))
+	})
+	for _, cmd := range sorted {
+		words := strings.Fields(cmd.name)
+		if len(args) < len(words) {
+			continue
+		}
+		if strings.Join(args[:len(words)], " ") == cmd.name {
+			return cmd, args[len(words):], true
+		}
+	}
+	return command{}, nil, false
+}
+
+func runCommand(e *env, cmd command, args []string) error {
+	e.offline = cmd.offline
+	defer func() {
+		if e.cancel != nil {
+			e.cancel()
+		}
+	}()
+	return cmd.run(e, args)
+}
+
+func newFlagSet(name string) *flag.FlagSet {
+	return flag.NewFlagSet(name, flag.ContinueOnError)
+}
+
+// parse parses a command's flags, which may be mixed with its positional
+// arguments, together with the global flags, then resolves the profile and
+// builds the client.
+func (e *env) parse(fs *flag.FlagSet, args []string) ([]string, error) {
+	fs.SetOutput(io.Discard)
+	e.flags.register(fs)
+
+	var positional []string
+	for {
+		if err := fs.Parse(args); err != nil {
+			return nil, usageError("%v", err)
+		}
+		args = fs.Args()
+		if len(args) == 0 {
+			break
+		}
+		positional = append(positional, args[0])
+		args = args[1:]
+	}
+
+	if err := validateOutput(e.flags.output); err != nil {
+		return nil, err
+	}
+	profile, err := loadProfile(e.flags)
+	if err != nil {
+		return nil, err
+	}
+	e.profile = profile
+	if e.offline {
+		return positional, nil
+	}
+
+	if e.profile.BaseURL == "" {
+		return nil, usageError("no base URL, pass --base-url or set base_url in the profile")
+	}
+	e.client, err = client.New(e.profile.BaseURL, client.WithUserAgent("salctl"))
+	if err != nil {
+		return nil, usageError("%v", err)
+	}
+	if e.flags.timeout > 0 {
+		e.ctx, e.cancel = context.WithTimeout(e.ctx, e.flags.timeout)
+	}
+	return positional, nil
+}
+
+func exitCode(err error) int {
+	var apiErr *client.Error
+	var netErr net.Error
+	switch {
+	case errors.Is(err, errUsage):
+		return ExitUsage
+	case errors.As(err, &apiErr):
+		switch {
+		case errors.Is(err, client.ErrNotFound):
+			return ExitNotFound
+		case errors.Is(err, client.ErrConflict), errors.Is(err, client.ErrProductAlreadyExists):
+			return ExitConflict
+		case errors.Is(err, client.ErrRateLimited), apiErr.StatusCode >= 500:
+			return ExitUnavailable
+		}
+		return ExitError
+	case errors.Is(err, context.DeadlineExceeded), errors.As(err, &netErr):
+		return ExitUnavailable
+	}
+	return ExitError
+}
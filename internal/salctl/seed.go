@@ -0,0 +1,57 @@
+package salctl
+
+import (
+	"fmt"
+	"math"
+	"math/rand"
+	"time"
+
+	"github.com/olad5/sal-backend-service/pkg/client"
+)
+
+var (
+	seedAdjectives = []string{"Classic", "Organic", "Vintage", "Slim", "Waterproof", "Wool", "Cotton", "Leather", "Linen", "Recycled"}
+	seedNouns      = []string{"Shirt", "Scarf", "Jacket", "Mug", "Notebook", "Backpack", "Sneakers", "Lamp", "Socks", "Hat"}
+	seedColours    = []string{"red", "blue", "green", "black", "white", "grey", "navy", "olive"}
+)
+
+func seedProducts(e *env, args []string) error {
+	fs := newFlagSet("seed")
+	count := fs.Int("count", 10, "number of products to create")
+	seed := fs.Int64("seed", 0, "random seed, 0 uses the current time")
+	positional, err := e.parse(fs, args)
+	if err != nil {
+		return err
+	}
+	if len(positional) != 0 {
+		return usageError("unexpected arguments %q", positional)
+	}
+	if *count < 1 {
+		return usageError("-count must be at least 1")
+	}
+	merchantId, err := e.merchantId()
+	if err != nil {
+		return err
+	}
+	if *seed == 0 {
+		*seed = time.Now().UnixNano()
+	}
+	random := rand.New(rand.NewSource(*seed))
+
+	products := make([]client.Product, 0, *count)
+	for i := 0; i < *count; i++ {
+		colour := seedColours[random.Intn(len(seedColours))]
+		name := fmt.Sprintf("%s %s %s", seedAdjectives[random.Intn(len(seedAdjectives))], colour, seedNouns[random.Intn(len(seedNouns))])
+		product, err := e.client.CreateProduct(e.ctx, client.CreateProductRequest{
+			MerchantId:  merchantId,
+			Name:        name,
+			Description: fmt.Sprintf("A %s test product created by salctl seed", colour),
+			Price:       math.Round((1+random.Float64()*199)*100) / 100,
+		})
+		if err != nil {
+			return fmt.Errorf("created %d of %d products: %w", len(products), *count, err)
+		}
+		products = append(products, product)
+	}
+	return e.printer().print(products, func(t *table) { productTable(t, products) })
+}
@@ -0,0 +1,341 @@
+package salctl
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/google/uuid"
+	"github.com/olad5/sal-backend-service/pkg/client"
+)
+
+// productRecord is one product in an import or export file. Export writes
+// every field, so an export can be imported into another deployment. CSV
+// files separate tags with ";" and write attributes as "name=value" pairs
+// separated the same way.
+type productRecord struct {
+	SKUID       string            `json:"sku_id,omitempty"`
+	SKUCode     string            `json:"sku_code,omitempty"`
+	GTIN        string            `json:"gtin,omitempty"`
+	ISBN        string            `json:"isbn,omitempty"`
+	Name        string            `json:"name"`
+	Description string            `json:"description"`
+	Price       float64           `json:"price"`
+	TaxClass    string            `json:"tax_class,omitempty"`
+	Category    string            `json:"category,omitempty"`
+	Tags        []string          `json:"tags,omitempty"`
+	Attributes  map[string]string `json:"attributes,omitempty"`
+}
+
+var csvColumns = []string{"sku_id", "sku_code", "gtin", "isbn", "name", "description", "price", "tax_class", "category", "tags", "attributes"}
+
+func (r productRecord) csvRow() []string {
+	return []string{r.SKUID, r.SKUCode, r.GTIN, r.ISBN, r.Name, r.Description, strconv.FormatFloat(r.Price, 'f', -1, 64), r.TaxClass, r.Category, strings.Join(r.Tags, ";"), formatAttributes(r.Attributes)}
+}
+
+func formatAttributes(attributes map[string]string) string {
+	pairs := make([]string, 0, len(attributes))
+	for name, value := range attributes {
+		pairs = append(pairs, name+"="+value)
+	}
+	sort.Strings(pairs)
+	return strings.Join(pairs, ";")
+}
+
+func parseAttributes(value string) (map[string]string, error) {
+	if value == "" {
+		return nil, nil
+	}
+	attributes := map[string]string{}
+	for _, pair := range strings.Split(value, ";") {
+		name, value, ok := strings.Cut(pair, "=")
+		if !ok {
+			return nil, fmt.Errorf("invalid attribute %q, want name=value", pair)
+		}
+		attributes[strings.TrimSpace(name)] = strings.TrimSpace(value)
+	}
+	return attributes, nil
+}
+
+// transferFormat is the format flag, or the file's extension when the flag
+// is empty.
+func transferFormat(format, path string) (string, error) {
+	if format == "" {
+		if strings.EqualFold(filepath.Ext(path), ".csv") {
+			return "csv", nil
+		}
+		return "json", nil
+	}
+	if format != "json" && format != "csv" {
+		return "", usageError("unknown file format %q, want json or csv", format)
+	}
+	return format, nil
+}
+
+func exportProducts(e *env, args []string) error {
+	fs := newFlagSet("export")
+	path := fs.String("file", "-", "file to write, - writes to stdout")
+	format := fs.String("format", "", "json or csv, defaults to the file's extension")
+	positional, err := e.parse(fs, args)
+	if err != nil {
+		return err
+	}
+	if len(positional) != 0 {
+		return usageError("unexpected arguments %q", positional)
+	}
+	fileFormat, err := transferFormat(*format, *path)
+	if err != nil {
+		return err
+	}
+	merchantId, err := e.merchantId()
+	if err != nil {
+		return err
+	}
+
+	page, err := e.client.ListProducts(e.ctx, merchantId, client.ListProductsOptions{})
+	if err != nil {
+		return err
+	}
+	records := make([]productRecord, 0, len(page.Products))
+	for _, product := range page.Products {
+		records = append(records, productRecord{
+			SKUID:       product.SKUID.String(),
+			SKUCode:     product.SKUCode,
+			GTIN:        product.GTIN,
+			ISBN:        product.ISBN,
+			Name:        product.Name,
+			Description: product.Description,
+			Price:       product.Price,
+			TaxClass:    product.TaxClass,
+			Category:    product.Category,
+			Tags:        product.Tags,
+			Attributes:  product.Attributes,
+		})
+	}
+
+	w := e.stdout
+	if *path != "-" {
+		file, err := os.Create(*path)
+		if err != nil {
+			return err
+		}
+		defer file.Close()
+		w = file
+	}
+	if err := writeRecords(w, fileFormat, records); err != nil {
+		return err
+	}
+	fmt.Fprintf(e.stderr, "exported %d products\n", len(records))
+	return nil
+}
+
+func writeRecords(w io.Writer, format string, records []productRecord) error {
+	if format == "json" {
+		encoder := json.NewEncoder(w)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(records)
+	}
+	writer := csv.NewWriter(w)
+	if err := writer.Write(csvColumns); err != nil {
+		return err
+	}
+	for _, record := range records {
+		if err := writer.Write(record.csvRow()); err != nil {
+			return err
+		}
+	}
+	writer.Flush()
+	return writer.Error()
+}
+
+func readRecords(r io.Reader, format string) ([]productRecord, error) {
+	if format == "json" {
+		var records []productRecord
+		decoder := json.NewDecoder(r)
+		decoder.DisallowUnknownFields()
+		if err := decoder.Decode(&records); err != nil {
+			return nil, fmt.Errorf("invalid JSON file, want an array of products: %w", err)
+		}
+		return records, nil
+	}
+
+	reader := csv.NewReader(r)
+	header, err := reader.Read()
+	if err != nil {
+		return nil, fmt.Errorf("invalid CSV file: %w", err)
+	}
+	columns := map[string]int{}
+	for i, column := range header {
+		column = strings.ToLower(strings.TrimSpace(column))
+		known := false
+		for _, name := range csvColumns {
+			if name == column {
+				known = true
+			}
+		}
+		if !known {
+			return nil, usageError("unknown CSV column %q, want %s", column, strings.Join(csvColumns, ","))
+		}
+		columns[column] = i
+	}
+	if _, ok := columns["name"]; !ok {
+		return nil, usageError("CSV file has no name column")
+	}
+
+	var records []productRecord
+	for line := 2; ; line++ {
+		row, err := reader.Read()
+		if err == io.EOF {
+			return records, nil
+		}
+		if err != nil {
+			return nil, fmt.Errorf("invalid CSV file: %w", err)
+		}
+		field := func(name string) string {
+			if i, ok := columns[name]; ok {
+				return strings.TrimSpace(row[i])
+			}
+			return ""
+		}
+		record := productRecord{
+			SKUID:       field("sku_id"),
+			SKUCode:     field("sku_code"),
+			GTIN:        field("gtin"),
+			ISBN:        field("isbn"),
+			Name:        field("name"),
+			Description: field("description"),
+			TaxClass:    field("tax_class"),
+			Category:    field("category"),
+		}
+		if tags := field("tags"); tags != "" {
+			record.Tags = strings.Split(tags, ";")
+		}
+		if record.Attributes, err = parseAttributes(field("attributes")); err != nil {
+			return nil, fmt.Errorf("line %d: %w", line, err)
+		}
+		if price := field("price"); price != "" {
+			record.Price, err = strconv.ParseFloat(price, 64)
+			if err != nil {
+				return nil, fmt.Errorf("line %d: invalid price %q", line, price)
+			}
+		}
+		records = append(records, record)
+	}
+}
+
+type importResult struct {
+	Row   int        `json:"row"`
+	SKUID *uuid.UUID `json:"sku_id"`
+	Name  string     `json:"name"`
+	Error string     `json:"error,omitempty"`
+}
+
+func importProducts(e *env, args []string) error {
+	fs := newFlagSet("import")
+	path := fs.String("file", "-", "file to read, - reads from stdin")
+	format := fs.String("format", "", "json or csv, defaults to the file's extension")
+	dryRun := fs.Bool("dry-run", false, "only check the file")
+	positional, err := e.parse(fs, args)
+	if err != nil {
+		return err
+	}
+	if len(positional) != 0 {
+		return usageError("unexpected arguments %q", positional)
+	}
+	fileFormat, err := transferFormat(*format, *path)
+	if err != nil {
+		return err
+	}
+	merchantId, err := e.merchantId()
+	if err != nil {
+		return err
+	}
+
+	r := e.stdin
+	if *path != "-" {
+		file, err := os.Open(*path)
+		if err != nil {
+			return err
+		}
+		defer file.Close()
+		r = file
+	}
+	records, err := readRecords(r, fileFormat)
+	if err != nil {
+		return err
+	}
+
+	requests := make([]client.CreateProductRequest, 0, len(records))
+	for i, record := range records {
+		request := client.CreateProductRequest{
+			MerchantId:  merchantId,
+			SKUCode:     record.SKUCode,
+			GTIN:        record.GTIN,
+			ISBN:        record.ISBN,
+			Name:        record.Name,
+			Description: record.Description,
+			Price:       record.Price,
+			TaxClass:    record.TaxClass,
+			Category:    record.Category,
+			Tags:        record.Tags,
+			Attributes:  record.Attributes,
+		}
+		if record.SKUID != "" {
+			request.SKUID, err = uuid.Parse(record.SKUID)
+			if err != nil {
+				return fmt.Errorf("product %d: invalid sku_id %q", i+1, record.SKUID)
+			}
+		}
+		if record.Name == "" {
+			return fmt.Errorf("product %d: name is required", i+1)
+		}
+		requests = append(requests, request)
+	}
+	if *dryRun {
+		fmt.Fprintf(e.stderr, "%d products would be imported\n", len(requests))
+		return nil
+	}
+
+	// every product is attempted, the first failure decides the exit code
+	results := make([]importResult, 0, len(requests))
+	var firstErr error
+	failed := 0
+	for i, request := range requests {
+		result := importResult{Row: i + 1, Name: request.Name}
+		product, err := e.client.CreateProduct(e.ctx, request)
+		if err != nil {
+			result.Error = err.Error()
+			failed++
+			if firstErr == nil {
+				firstErr = err
+			}
+		} else {
+			result.SKUID = &product.SKUID
+		}
+		results = append(results, result)
+	}
+
+	if err := e.printer().print(results, func(t *table) {
+		t.header("ROW", "SKU ID", "NAME", "ERROR")
+		for _, result := range results {
+			skuId := ""
+			if result.SKUID != nil {
+				skuId = result.SKUID.String()
+			}
+			t.row(strconv.Itoa(result.Row), skuId, result.Name, result.Error)
+		}
+	}); err != nil {
+		return err
+	}
+	fmt.Fprintf(e.stderr, "imported %d products, %d failed\n", len(requests)-failed, failed)
+	if firstErr != nil {
+		return fmt.Errorf("%d of %d products failed to import, first error: %w", failed, len(requests), firstErr)
+	}
+	return nil
+}
@@ -0,0 +1,227 @@
+package currency
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"os"
+	"regexp"
+	"strings"
+	"sync"
+
+	"github.com/olad5/sal-backend-service/internal/domain"
+	"github.com/olad5/sal-backend-service/internal/infra"
+	"github.com/olad5/sal-backend-service/pkg/clock"
+)
+
+// DefaultRounding applies to every currency without its own rounding rule.
+var DefaultRounding = domain.CurrencyRounding{Increment: 0.01, Mode: domain.RoundHalfUp}
+
+var (
+	ErrInvalidCurrency      = errors.New("currency must be a three letter ISO 4217 code")
+	ErrUnsupportedCurrency  = errors.New("currency is not in the exchange rate table")
+	ErrInvalidExchangeRate  = errors.New("exchange rates must be greater than zero")
+	ErrInvalidRounding      = errors.New("rounding increment must be greater than zero and mode one of half_up, half_even, up or down")
+	ErrInvalidExchangeRates = errors.New("invalid exchange rates file")
+)
+
+var currencyCode = regexp.MustCompile(`^[A-Z]{3}$`)
+
+type CurrencyService struct {
+	rateRepo infra.ExchangeRateRepository
+	clock    clock.Clock
+	// lock serialises updates so that every table gets its own version
+	lock *sync.Mutex
+}
+
+// ExchangeRatesFile is the shape of both the exchange rates file and the
+// admin update request.
+type ExchangeRatesFile struct {
+	BaseCurrency string                          `json:"base_currency"`
+	Rates        map[string]float64              `json:"rates"`
+	Rounding     map[string]CurrencyRoundingFile `json:"rounding"`
+}
+
+type CurrencyRoundingFile struct {
+	Increment float64 `json:"increment"`
+	Mode      string  `json:"mode"`
+}
+
+func NewCurrencyService(rateRepo infra.ExchangeRateRepository, clock clock.Clock) (*CurrencyService, error) {
+	if rateRepo == nil {
+		return &CurrencyService{}, fmt.Errorf("CurrencyService failed to initialize, rateRepo is nil")
+	}
+	if clock == nil {
+		return &CurrencyService{}, fmt.Errorf("CurrencyService failed to initialize, clock is nil")
+	}
+	return &CurrencyService{rateRepo, clock, &sync.Mutex{}}, nil
+}
+
+// UpdateExchangeRates replaces the exchange rate table with a new version.
+// Earlier versions are kept so that a response can always be traced back to
+// the rates it used.
+func (c *CurrencyService) UpdateExchangeRates(ctx context.Context, rates ExchangeRatesFile) (domain.ExchangeRateTable, error) {
+	baseCurrency, err := NormalizeCurrency(rates.BaseCurrency)
+	if err != nil {
+		return domain.ExchangeRateTable{}, err
+	}
+
+	table := domain.ExchangeRateTable{
+		BaseCurrency: baseCurrency,
+		Rates:        map[string]float64{baseCurrency: 1},
+		Rounding:     map[string]domain.CurrencyRounding{},
+	}
+	for code, rate := range rates.Rates {
+		normalized, err := NormalizeCurrency(code)
+		if err != nil {
+			return domain.ExchangeRateTable{}, err
+		}
+		if rate <= 0 || math.IsInf(rate, 0) || math.IsNaN(rate) {
+			return domain.ExchangeRateTable{}, ErrInvalidExchangeRate
+		}
+		if normalized == baseCurrency && rate != 1 {
+			return domain.ExchangeRateTable{}, ErrInvalidExchangeRate
+		}
+		table.Rates[normalized] = rate
+	}
+	for code, rounding := range rates.Rounding {
+		normalized, err := NormalizeCurrency(code)
+		if err != nil {
+			return domain.ExchangeRateTable{}, err
+		}
+		if _, ok := table.Rates[normalized]; !ok {
+			return domain.ExchangeRateTable{}, ErrUnsupportedCurrency
+		}
+		rule := domain.CurrencyRounding{Increment: rounding.Increment, Mode: domain.RoundingMode(rounding.Mode)}
+		if rule.Mode == "" {
+			rule.Mode = DefaultRounding.Mode
+		}
+		if !validRounding(rule) {
+			return domain.ExchangeRateTable{}, ErrInvalidRounding
+		}
+		table.Rounding[normalized] = rule
+	}
+
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	latest, err := c.rateRepo.GetLatestExchangeRateTable(ctx)
+	if err != nil && !errors.Is(err, infra.ErrExchangeRatesNotFound) {
+		return domain.ExchangeRateTable{}, err
+	}
+	table.Version = latest.Version + 1
+	table.UpdatedAt = c.clock.Now()
+
+	err = c.rateRepo.CreateExchangeRateTable(ctx, table)
+	if err != nil {
+		return domain.ExchangeRateTable{}, err
+	}
+	return table, nil
+}
+
+// LoadExchangeRatesFile reads an exchange rate table from a JSON file in the
+// ExchangeRatesFile format and makes it the current version.
+func (c *CurrencyService) LoadExchangeRatesFile(ctx context.Context, path string) (domain.ExchangeRateTable, error) {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return domain.ExchangeRateTable{}, err
+	}
+
+	var rates ExchangeRatesFile
+	err = json.Unmarshal(content, &rates)
+	if err != nil {
+		return domain.ExchangeRateTable{}, fmt.Errorf("%w: %v", ErrInvalidExchangeRates, err)
+	}
+	return c.UpdateExchangeRates(ctx, rates)
+}
+
+func (c *CurrencyService) GetExchangeRates(ctx context.Context) (domain.ExchangeRateTable, error) {
+	return c.rateRepo.GetLatestExchangeRateTable(ctx)
+}
+
+func (c *CurrencyService) GetExchangeRatesByVersion(ctx context.Context, version int) (domain.ExchangeRateTable, error) {
+	return c.rateRepo.GetExchangeRateTableByVersion(ctx, version)
+}
+
+// GetExchangeRate looks up the current rate from the base currency to
+// currency.
+func (c *CurrencyService) GetExchangeRate(ctx context.Context, currency string) (domain.ExchangeRate, error) {
+	code, err := NormalizeCurrency(currency)
+	if err != nil {
+		return domain.ExchangeRate{}, err
+	}
+
+	table, err := c.rateRepo.GetLatestExchangeRateTable(ctx)
+	if err != nil {
+		if errors.Is(err, infra.ErrExchangeRatesNotFound) {
+			return domain.ExchangeRate{}, ErrUnsupportedCurrency
+		}
+		return domain.ExchangeRate{}, err
+	}
+
+	rate, ok := table.Rates[code]
+	if !ok {
+		return domain.ExchangeRate{}, ErrUnsupportedCurrency
+	}
+	rounding, ok := table.Rounding[code]
+	if !ok {
+		rounding = DefaultRounding
+	}
+	return domain.ExchangeRate{
+		BaseCurrency:   table.BaseCurrency,
+		Currency:       code,
+		Rate:           rate,
+		Rounding:       rounding,
+		TableVersion:   table.Version,
+		TableUpdatedAt: table.UpdatedAt,
+	}, nil
+}
+
+// Convert converts an amount in the base currency using rate and rounds it
+// with the target currency's rounding rule.
+func Convert(rate domain.ExchangeRate, amount float64) float64 {
+	return Round(amount*rate.Rate, rate.Rounding)
+}
+
+// Round rounds amount to a multiple of the rounding increment. The quotient
+// is first snapped to a fixed precision so that binary floating point noise,
+// such as 2.675 being stored as 2.67499..., cannot change the result.
+func Round(amount float64, rounding domain.CurrencyRounding) float64 {
+	if !validRounding(rounding) {
+		rounding = DefaultRounding
+	}
+	steps := math.Round(amount/rounding.Increment*1e6) / 1e6
+	switch rounding.Mode {
+	case domain.RoundHalfEven:
+		steps = math.RoundToEven(steps)
+	case domain.RoundUp:
+		steps = math.Ceil(steps)
+	case domain.RoundDown:
+		steps = math.Floor(steps)
+	default:
+		steps = math.Round(steps)
+	}
+	return math.Round(steps*rounding.Increment*1e8) / 1e8
+}
+
+func NormalizeCurrency(currency string) (string, error) {
+	code := strings.ToUpper(strings.TrimSpace(currency))
+	if !currencyCode.MatchString(code) {
+		return "", ErrInvalidCurrency
+	}
+	return code, nil
+}
+
+func validRounding(rounding domain.CurrencyRounding) bool {
+	if rounding.Increment <= 0 || math.IsInf(rounding.Increment, 0) || math.IsNaN(rounding.Increment) {
+		return false
+	}
+	switch rounding.Mode {
+	case domain.RoundHalfUp, domain.RoundHalfEven, domain.RoundUp, domain.RoundDown:
+		return true
+	default:
+		return false
+	}
+}
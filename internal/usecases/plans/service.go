@@ -0,0 +1,123 @@
+package plans
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+
+	"github.com/google/uuid"
+	"github.com/olad5/sal-backend-service/internal/domain"
+	"github.com/olad5/sal-backend-service/internal/infra"
+	"github.com/olad5/sal-backend-service/pkg/clock"
+)
+
+// DefaultPlan is used for merchants that were never assigned a plan. It is
+// sized for the 10k products per merchant the service is designed around.
+const DefaultPlan = "standard"
+
+var Plans = map[string]domain.Plan{
+	"free":       {Name: "free", MaxProducts: 100, MaxImages: 500, RequestsPerMinute: 60},
+	"standard":   {Name: "standard", MaxProducts: 10000, MaxImages: 50000, RequestsPerMinute: 600},
+	"enterprise": {Name: "enterprise", MaxProducts: 100000, MaxImages: 500000, RequestsPerMinute: 6000},
+}
+
+var (
+	ErrQuotaExceeded        = errors.New("quota exceeded")
+	ErrProductQuotaExceeded = fmt.Errorf("%w: product limit reached", ErrQuotaExceeded)
+	ErrImageQuotaExceeded   = fmt.Errorf("%w: image limit reached", ErrQuotaExceeded)
+	ErrPlanNotFound         = errors.New("plan not found")
+)
+
+type PlanService struct {
+	merchantPlanRepo infra.MerchantPlanRepository
+	productRepo      infra.ProductRepository
+	clock            clock.Clock
+}
+
+func NewPlanService(merchantPlanRepo infra.MerchantPlanRepository, productRepo infra.ProductRepository, clock clock.Clock) (*PlanService, error) {
+	if merchantPlanRepo == nil {
+		return &PlanService{}, fmt.Errorf("PlanService failed to initialize, merchantPlanRepo is nil")
+	}
+	if productRepo == nil {
+		return &PlanService{}, fmt.Errorf("PlanService failed to initialize, productRepo is nil")
+	}
+	if clock == nil {
+		return &PlanService{}, fmt.Errorf("PlanService failed to initialize, clock is nil")
+	}
+	return &PlanService{merchantPlanRepo, productRepo, clock}, nil
+}
+
+func (p *PlanService) GetPlans() []domain.Plan {
+	plans := make([]domain.Plan, 0, len(Plans))
+	for _, plan := range Plans {
+		plans = append(plans, plan)
+	}
+	sort.Slice(plans, func(a, b int) bool { return plans[a].MaxProducts < plans[b].MaxProducts })
+	return plans
+}
+
+func (p *PlanService) AssignPlan(ctx context.Context, merchantId uuid.UUID, planName string) (domain.Plan, error) {
+	plan, ok := Plans[planName]
+	if !ok {
+		return domain.Plan{}, ErrPlanNotFound
+	}
+
+	err := p.merchantPlanRepo.SaveMerchantPlan(ctx, domain.MerchantPlan{
+		MerchantId: merchantId,
+		Plan:       plan.Name,
+		AssignedAt: p.clock.Now(),
+	})
+	if err != nil {
+		return domain.Plan{}, err
+	}
+	return plan, nil
+}
+
+func (p *PlanService) GetMerchantPlan(ctx context.Context, merchantId uuid.UUID) (domain.Plan, error) {
+	merchantPlan, err := p.merchantPlanRepo.GetMerchantPlanByMerchantId(ctx, merchantId)
+	if err != nil {
+		if errors.Is(err, infra.ErrMerchantPlanNotFound) {
+			return Plans[DefaultPlan], nil
+		}
+		return domain.Plan{}, err
+	}
+
+	plan, ok := Plans[merchantPlan.Plan]
+	if !ok {
+		return Plans[DefaultPlan], nil
+	}
+	return plan, nil
+}
+
+func (p *PlanService) GetUsage(ctx context.Context, merchantId uuid.UUID) (domain.PlanUsage, error) {
+	plan, err := p.GetMerchantPlan(ctx, merchantId)
+	if err != nil {
+		return domain.PlanUsage{}, err
+	}
+
+	products, err := p.productRepo.GetProductsByMerchantId(ctx, merchantId)
+	if err != nil {
+		return domain.PlanUsage{}, err
+	}
+
+	usage := domain.PlanUsage{
+		MerchantId: merchantId,
+		Plan:       plan,
+		Products:   len(products),
+	}
+	for _, product := range products {
+		usage.Images += len(product.Images)
+	}
+	return usage, nil
+}
+
+// ProductQuotaExceeded is the error for a product refused because the
+// merchant already has as many as plan allows.
+func ProductQuotaExceeded(plan domain.Plan) error {
+	return fmt.Errorf("%w, the %s plan allows %d products", ErrProductQuotaExceeded, plan.Name, plan.MaxProducts)
+}
+
+func ImageQuotaExceeded(plan domain.Plan) error {
+	return fmt.Errorf("%w, the %s plan allows %d images", ErrImageQuotaExceeded, plan.Name, plan.MaxImages)
+}
@@ -0,0 +1,211 @@
+package pricing
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"strings"
+
+	"github.com/google/uuid"
+	"github.com/olad5/sal-backend-service/internal/domain"
+	"github.com/olad5/sal-backend-service/internal/infra"
+	"github.com/olad5/sal-backend-service/pkg/clock"
+)
+
+type PricingService struct {
+	ruleRepo    infra.PricingRuleRepository
+	productRepo infra.ProductRepository
+	clock       clock.Clock
+}
+
+var (
+	ErrUserNotAuthorized    = errors.New("unauthorized")
+	ErrInvalidRuleType      = errors.New("rule type must be one of percentage, fixed_amount or fixed_price")
+	ErrInvalidRuleValue     = errors.New("rule value cannot be less than zero")
+	ErrInvalidPercentage    = errors.New("percentage discount cannot be more than 100")
+	ErrInvalidMinQuantity   = errors.New("min_quantity must be at least 1")
+	ErrInvalidQuoteQuantity = errors.New("quantity must be at least 1")
+	ErrRuleScopeConflict    = errors.New("a rule can be scoped to a sku_id or a category, not both")
+)
+
+func NewPricingService(ruleRepo infra.PricingRuleRepository, productRepo infra.ProductRepository, clock clock.Clock) (*PricingService, error) {
+	if ruleRepo == nil {
+		return &PricingService{}, fmt.Errorf("PricingService failed to initialize, ruleRepo is nil")
+	}
+	if productRepo == nil {
+		return &PricingService{}, fmt.Errorf("PricingService failed to initialize, productRepo is nil")
+	}
+	if clock == nil {
+		return &PricingService{}, fmt.Errorf("PricingService failed to initialize, clock is nil")
+	}
+	return &PricingService{ruleRepo, productRepo, clock}, nil
+}
+
+func (p *PricingService) CreatePricingRule(ctx context.Context, merchantId uuid.UUID, skuId *uuid.UUID, category, customerGroup string, minQuantity int, ruleType domain.PricingRuleType, value float64) (domain.PricingRule, error) {
+	switch ruleType {
+	case domain.PercentageDiscount, domain.FixedAmountDiscount, domain.FixedPrice:
+	default:
+		return domain.PricingRule{}, ErrInvalidRuleType
+	}
+	if value < 0 {
+		return domain.PricingRule{}, ErrInvalidRuleValue
+	}
+	if ruleType == domain.PercentageDiscount && value > 100 {
+		return domain.PricingRule{}, ErrInvalidPercentage
+	}
+	if minQuantity < 1 {
+		return domain.PricingRule{}, ErrInvalidMinQuantity
+	}
+	category = strings.TrimSpace(category)
+	if skuId != nil && category != "" {
+		return domain.PricingRule{}, ErrRuleScopeConflict
+	}
+
+	if skuId != nil {
+		existingProduct, err := p.productRepo.GetProductBySkuId(ctx, *skuId)
+		if err != nil {
+			return domain.PricingRule{}, err
+		}
+		if existingProduct.MerchantId != merchantId {
+			return domain.PricingRule{}, ErrUserNotAuthorized
+		}
+	}
+
+	newRule := domain.PricingRule{
+		ID:            uuid.New(),
+		MerchantId:    merchantId,
+		SKUID:         skuId,
+		Category:      category,
+		CustomerGroup: customerGroup,
+		MinQuantity:   minQuantity,
+		Type:          ruleType,
+		Value:         value,
+		CreatedAt:     p.clock.Now(),
+	}
+
+	err := p.ruleRepo.CreatePricingRule(ctx, newRule)
+	if err != nil {
+		return domain.PricingRule{}, err
+	}
+	return newRule, nil
+}
+
+func (p *PricingService) GetPricingRulesByMerchantId(ctx context.Context, merchantId uuid.UUID) ([]domain.PricingRule, error) {
+	rules, err := p.ruleRepo.GetPricingRulesByMerchantId(ctx, merchantId)
+	if err != nil {
+		return []domain.PricingRule{}, err
+	}
+
+	return rules, nil
+}
+
+func (p *PricingService) DeletePricingRule(ctx context.Context, merchantId, ruleId uuid.UUID) error {
+	existingRule, err := p.ruleRepo.GetPricingRuleById(ctx, ruleId)
+	if err != nil {
+		return err
+	}
+
+	if merchantId != existingRule.MerchantId {
+		return ErrUserNotAuthorized
+	}
+
+	return p.ruleRepo.DeletePricingRuleById(ctx, ruleId)
+}
+
+// Quote prices quantity units of a product for a customer group.
+//
+// Rules are evaluated in two stages. First the cheapest applicable fixed_price
+// rule, if any, replaces the product's effective price; this is how customer
+// group price lists work. Then the single discount rule that gives the lowest
+// unit price is applied on top, so overlapping quantity breaks such as "10+"
+// and "50+" never stack.
+func (p *PricingService) Quote(ctx context.Context, skuId uuid.UUID, quantity int, customerGroup string) (domain.PriceQuote, error) {
+	if quantity < 1 {
+		return domain.PriceQuote{}, ErrInvalidQuoteQuantity
+	}
+
+	existingProduct, err := p.productRepo.GetProductBySkuId(ctx, skuId)
+	if err != nil {
+		return domain.PriceQuote{}, err
+	}
+
+	rules, err := p.ruleRepo.GetPricingRulesByMerchantId(ctx, existingProduct.MerchantId)
+	if err != nil {
+		return domain.PriceQuote{}, err
+	}
+
+	quote := domain.PriceQuote{
+		SKUID:         skuId,
+		Quantity:      quantity,
+		CustomerGroup: customerGroup,
+		BaseUnitPrice: existingProduct.EffectivePrice(),
+		AppliedRules:  []domain.AppliedPricingRule{},
+	}
+	unitPrice := quote.BaseUnitPrice
+
+	var priceList *domain.PricingRule
+	for index, rule := range rules {
+		if rule.Type == domain.FixedPrice && applies(rule, existingProduct, quantity, customerGroup) {
+			if priceList == nil || rule.Value < priceList.Value {
+				priceList = &rules[index]
+			}
+		}
+	}
+	if priceList != nil {
+		unitPrice = roundPrice(priceList.Value)
+		quote.AppliedRules = append(quote.AppliedRules, domain.AppliedPricingRule{Rule: *priceList, UnitPriceAfter: unitPrice})
+	}
+
+	var discount *domain.PricingRule
+	discountedPrice := unitPrice
+	for index, rule := range rules {
+		if rule.Type == domain.FixedPrice || !applies(rule, existingProduct, quantity, customerGroup) {
+			continue
+		}
+		if candidate := applyDiscount(rule, unitPrice); candidate < discountedPrice {
+			discount, discountedPrice = &rules[index], candidate
+		}
+	}
+	if discount != nil {
+		unitPrice = discountedPrice
+		quote.AppliedRules = append(quote.AppliedRules, domain.AppliedPricingRule{Rule: *discount, UnitPriceAfter: unitPrice})
+	}
+
+	quote.UnitPrice = unitPrice
+	quote.Total = roundPrice(unitPrice * float64(quantity))
+	return quote, nil
+}
+
+func applies(rule domain.PricingRule, product domain.Product, quantity int, customerGroup string) bool {
+	if rule.SKUID != nil && *rule.SKUID != product.SKUID {
+		return false
+	}
+	if rule.Category != "" && !strings.EqualFold(rule.Category, product.Category) {
+		return false
+	}
+	if rule.CustomerGroup != "" && rule.CustomerGroup != customerGroup {
+		return false
+	}
+	return quantity >= rule.MinQuantity
+}
+
+func applyDiscount(rule domain.PricingRule, unitPrice float64) float64 {
+	var discounted float64
+	switch rule.Type {
+	case domain.PercentageDiscount:
+		discounted = unitPrice * (100 - rule.Value) / 100
+	case domain.FixedAmountDiscount:
+		discounted = unitPrice - rule.Value
+	default:
+		return unitPrice
+	}
+	if discounted < 0 {
+		discounted = 0
+	}
+	return roundPrice(discounted)
+}
+
+func roundPrice(price float64) float64 {
+	return math.Round(price*100) / 100
+}
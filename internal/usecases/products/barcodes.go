@@ -0,0 +1,102 @@
+package products
+
+import (
+	"context"
+	"errors"
+	"strings"
+
+	"github.com/google/uuid"
+	"github.com/olad5/sal-backend-service/internal/domain"
+	"github.com/olad5/sal-backend-service/pkg/tracing"
+)
+
+var (
+	ErrInvalidGTIN = errors.New("gtin must be a GTIN-8, GTIN-12, GTIN-13 or GTIN-14 with a valid check digit")
+	ErrInvalidISBN = errors.New("isbn must be an ISBN-10 or ISBN-13 with a valid check digit")
+)
+
+// NormalizeGTIN strips spaces and dashes from a GTIN and validates its length
+// and check digit.
+func NormalizeGTIN(code string) (string, error) {
+	code = stripBarcode(code)
+	switch len(code) {
+	case 8, 12, 13, 14:
+	default:
+		return "", ErrInvalidGTIN
+	}
+	if !isDigits(code) || gtinCheckDigit(code[:len(code)-1]) != code[len(code)-1] {
+		return "", ErrInvalidGTIN
+	}
+	return code, nil
+}
+
+// NormalizeISBN validates an ISBN-10 or ISBN-13 and returns it as an ISBN-13,
+// which is also a valid GTIN-13.
+func NormalizeISBN(code string) (string, error) {
+	code = strings.ToUpper(stripBarcode(code))
+	switch len(code) {
+	case 10:
+		if !isDigits(code[:9]) || isbn10CheckDigit(code[:9]) != code[9] {
+			return "", ErrInvalidISBN
+		}
+		body := "978" + code[:9]
+		return body + string(gtinCheckDigit(body)), nil
+	case 13:
+		if !strings.HasPrefix(code, "978") && !strings.HasPrefix(code, "979") {
+			return "", ErrInvalidISBN
+		}
+		if _, err := NormalizeGTIN(code); err != nil {
+			return "", ErrInvalidISBN
+		}
+		return code, nil
+	}
+	return "", ErrInvalidISBN
+}
+
+// GetProductByGTIN finds the merchant's product whose GTIN or ISBN matches
+// gtin, which must already be normalized.
+func (p *ProductService) GetProductByGTIN(ctx context.Context, merchantId uuid.UUID, gtin string) (domain.Product, error) {
+	ctx, span := tracing.Start(ctx, "ProductService.GetProductByGTIN")
+	defer span.End()
+
+	return p.productRepo.GetProductByGTIN(ctx, merchantId, gtin)
+}
+
+func stripBarcode(code string) string {
+	return strings.NewReplacer(" ", "", "-", "").Replace(strings.TrimSpace(code))
+}
+
+func isDigits(code string) bool {
+	for _, c := range code {
+		if c < '0' || c > '9' {
+			return false
+		}
+	}
+	return code != ""
+}
+
+// gtinCheckDigit weighs digits 3 and 1 alternately from the right, which is
+// the same for every GTIN length.
+func gtinCheckDigit(body string) byte {
+	sum := 0
+	for i := len(body) - 1; i >= 0; i-- {
+		digit := int(body[i] - '0')
+		if (len(body)-1-i)%2 == 0 {
+			digit *= 3
+		}
+		sum += digit
+	}
+	return byte('0' + (10-sum%10)%10)
+}
+
+func isbn10CheckDigit(body string) byte {
+	sum := 0
+	for i := 0; i < len(body); i++ {
+		sum += int(body[i]-'0') * (10 - i)
+	}
+	check := (11 - sum%11) % 11
+	if check == 10 {
+		return 'X'
+	}
+	return byte('0' + check)
+}
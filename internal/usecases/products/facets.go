@@ -0,0 +1,212 @@
+package products
+
+import (
+	"context"
+	"errors"
+	"sort"
+	"strings"
+
+	"github.com/google/uuid"
+	"github.com/olad5/sal-backend-service/internal/domain"
+	"github.com/olad5/sal-backend-service/pkg/tracing"
+)
+
+var DefaultPriceBuckets = []float64{10, 25, 50, 100, 250, 500, 1000}
+
+var ErrInvalidPriceBuckets = errors.New("price buckets must be positive and in increasing order")
+
+// FacetRequest lists the aggregations to compute alongside a listing.
+// PriceBuckets are the upper bounds of each price range, the last range is
+// open ended. Status counts the products in each domain.ProductStatus and
+// Category, Tags and Attributes count the products per term.
+type FacetRequest struct {
+	Price        bool
+	PriceBuckets []float64
+	Status       bool
+	Category     bool
+	Tags         bool
+	Attributes   bool
+}
+
+func (f FacetRequest) IsEmpty() bool {
+	return !f.Price && !f.Status && !f.Category && !f.Tags && !f.Attributes
+}
+
+func ComputeFacets(products []domain.Product, request FacetRequest) (domain.ProductFacets, error) {
+	facets := domain.ProductFacets{}
+	if request.Price {
+		bounds := request.PriceBuckets
+		if len(bounds) == 0 {
+			bounds = DefaultPriceBuckets
+		}
+		histogram, err := priceHistogram(products, bounds)
+		if err != nil {
+			return domain.ProductFacets{}, err
+		}
+		facets.Price = histogram
+	}
+	if request.Status {
+		facets.Status = statusCounts(products)
+	}
+	if request.Category {
+		facets.Category = termCounts(products, func(product domain.Product) []string {
+			return []string{product.Category}
+		})
+	}
+	if request.Tags {
+		facets.Tags = termCounts(products, func(product domain.Product) []string {
+			return product.Tags
+		})
+	}
+	if request.Attributes {
+		facets.Attributes = attributeFacets(products)
+	}
+	return facets, nil
+}
+
+// SearchProductFacets computes facets over every product matching query,
+// not just the page of results being returned.
+func (p *ProductService) SearchProductFacets(ctx context.Context, merchantId uuid.UUID, query string, request FacetRequest) (domain.ProductFacets, error) {
+	ctx, span := tracing.Start(ctx, "ProductService.SearchProductFacets")
+	defer span.End()
+
+	matched, err := p.searchIndex.MatchProducts(ctx, merchantId, query)
+	if err != nil {
+		return domain.ProductFacets{}, err
+	}
+
+	return ComputeFacets(matched, request)
+}
+
+// GetCategories counts the merchant's products per category, sorted by
+// category. Categories that differ only in case are counted together, as
+// pricing rules match them, and uncategorized products are left out.
+func (p *ProductService) GetCategories(ctx context.Context, merchantId uuid.UUID) ([]domain.TermCount, error) {
+	ctx, span := tracing.Start(ctx, "ProductService.GetCategories")
+	defer span.End()
+
+	products, err := p.productRepo.GetProductsByMerchantId(ctx, merchantId)
+	if err != nil {
+		return []domain.TermCount{}, err
+	}
+
+	categories := termCounts(products, func(product domain.Product) []string {
+		return []string{product.Category}
+	})
+	sort.Slice(categories, func(i, j int) bool {
+		return strings.ToLower(categories[i].Term) < strings.ToLower(categories[j].Term)
+	})
+	return categories, nil
+}
+
+func priceHistogram(products []domain.Product, bounds []float64) ([]domain.PriceBucket, error) {
+	for index, bound := range bounds {
+		if bound <= 0 || index > 0 && bound <= bounds[index-1] {
+			return nil, ErrInvalidPriceBuckets
+		}
+	}
+
+	buckets := make([]domain.PriceBucket, len(bounds)+1)
+	for index := range buckets {
+		if index > 0 {
+			buckets[index].From = bounds[index-1]
+		}
+		if index < len(bounds) {
+			to := bounds[index]
+			buckets[index].To = &to
+		}
+	}
+
+	for _, product := range products {
+		index := 0
+		for index < len(bounds) && product.EffectivePrice() >= bounds[index] {
+			index++
+		}
+		buckets[index].Count++
+	}
+	return buckets, nil
+}
+
+// statusCounts counts the products in every status, including those no
+// product is in, in the order of domain.ProductStatuses.
+func statusCounts(products []domain.Product) []domain.StatusCount {
+	counts := make([]domain.StatusCount, len(domain.ProductStatuses))
+	indexes := map[domain.ProductStatus]int{}
+	for index, status := range domain.ProductStatuses {
+		counts[index].Status = status
+		indexes[status] = index
+	}
+	for _, product := range products {
+		counts[indexes[product.Status()]].Count++
+	}
+	return counts
+}
+
+// termCounts counts the products per term, most common first. Terms that
+// differ only in case are counted together under their most used spelling,
+// the first in byte order on a tie, so the result does not depend on the
+// order of products. Empty terms are left out.
+func termCounts(products []domain.Product, terms func(domain.Product) []string) []domain.TermCount {
+	counts := []domain.TermCount{}
+	indexes := map[string]int{}
+	spellings := []map[string]int{}
+	for _, product := range products {
+		for _, term := range terms(product) {
+			if term == "" {
+				continue
+			}
+			key := strings.ToLower(term)
+			index, ok := indexes[key]
+			if !ok {
+				index = len(counts)
+				indexes[key] = index
+				counts = append(counts, domain.TermCount{})
+				spellings = append(spellings, map[string]int{})
+			}
+			counts[index].Count++
+			spellings[index][term]++
+		}
+	}
+	for index, used := range spellings {
+		best := ""
+		for spelling, count := range used {
+			if best == "" || count > used[best] || count == used[best] && spelling < best {
+				best = spelling
+			}
+		}
+		counts[index].Term = best
+	}
+	sort.Slice(counts, func(i, j int) bool {
+		if counts[i].Count != counts[j].Count {
+			return counts[i].Count > counts[j].Count
+		}
+		return strings.ToLower(counts[i].Term) < strings.ToLower(counts[j].Term)
+	})
+	return counts
+}
+
+// attributeFacets counts the products per value of every attribute any
+// product has, sorted by attribute name.
+func attributeFacets(products []domain.Product) []domain.AttributeFacet {
+	names := []string{}
+	for _, product := range products {
+		for name := range product.Attributes {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+
+	facets := []domain.AttributeFacet{}
+	for index, name := range names {
+		if index > 0 && names[index-1] == name {
+			continue
+		}
+		facets = append(facets, domain.AttributeFacet{
+			Name: name,
+			Values: termCounts(products, func(product domain.Product) []string {
+				return []string{product.Attributes[name]}
+			}),
+		})
+	}
+	return facets
+}
@@ -0,0 +1,320 @@
+package products
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"image"
+	_ "image/gif"
+	"image/jpeg"
+	"image/png"
+	"io"
+	"net/http"
+
+	"github.com/google/uuid"
+	"github.com/olad5/sal-backend-service/internal/domain"
+	"github.com/olad5/sal-backend-service/internal/events"
+	"github.com/olad5/sal-backend-service/internal/infra"
+	"github.com/olad5/sal-backend-service/internal/usecases/plans"
+	"github.com/olad5/sal-backend-service/pkg/imaging"
+	"github.com/olad5/sal-backend-service/pkg/tracing"
+)
+
+const (
+	MaxImageSize       = 5 << 20
+	MaxImageWidth      = 8192
+	MaxImageHeight     = 8192
+	ThumbnailMaxWidth  = 320
+	ThumbnailMaxHeight = 320
+)
+
+var (
+	ErrImageTooLarge        = errors.New("image exceeds the maximum allowed size")
+	ErrImageTooManyPixels   = fmt.Errorf("image exceeds the maximum allowed dimensions of %dx%d pixels", MaxImageWidth, MaxImageHeight)
+	ErrUnsupportedImageType = errors.New("unsupported image type, only jpeg, png and gif are allowed")
+	ErrInvalidImage         = errors.New("image could not be decoded")
+	ErrImageNotFound        = errors.New("image not found")
+)
+
+var allowedImageTypes = map[string]bool{
+	"image/jpeg": true,
+	"image/png":  true,
+	"image/gif":  true,
+}
+
+func (p *ProductService) AddProductImage(ctx context.Context, merchantId, skuId uuid.UUID, data io.Reader, altText string, isPrimary bool) (domain.ProductImage, error) {
+	ctx, span := tracing.Start(ctx, "ProductService.AddProductImage")
+	defer span.End()
+	defer p.skuLocks.acquire(skuId)()
+
+	existingProduct, err := p.productRepo.GetProductBySkuId(ctx, skuId)
+	if err != nil {
+		return domain.ProductImage{}, err
+	}
+
+	if merchantId != existingProduct.MerchantId {
+		return domain.ProductImage{}, ErrUserNotAuthorized
+	}
+
+	// the repository enforces the plan's limit as it stores the image, so
+	// concurrent uploads to different products cannot take a merchant over it
+	plan, err := p.planService.GetMerchantPlan(ctx, merchantId)
+	if err != nil {
+		return domain.ProductImage{}, err
+	}
+
+	original, err := io.ReadAll(io.LimitReader(data, MaxImageSize+1))
+	if err != nil {
+		return domain.ProductImage{}, err
+	}
+	if len(original) > MaxImageSize {
+		return domain.ProductImage{}, ErrImageTooLarge
+	}
+
+	contentType := http.DetectContentType(original)
+	if !allowedImageTypes[contentType] {
+		return domain.ProductImage{}, ErrUnsupportedImageType
+	}
+
+	// checked before decoding, since a small but highly compressed file can
+	// decode to far more memory than its size suggests
+	config, _, err := image.DecodeConfig(bytes.NewReader(original))
+	if err != nil {
+		return domain.ProductImage{}, ErrInvalidImage
+	}
+	if config.Width > MaxImageWidth || config.Height > MaxImageHeight {
+		return domain.ProductImage{}, ErrImageTooManyPixels
+	}
+
+	decoded, _, err := image.Decode(bytes.NewReader(original))
+	if err != nil {
+		return domain.ProductImage{}, ErrInvalidImage
+	}
+
+	thumbnail, thumbnailContentType, err := encodeThumbnail(decoded, contentType)
+	if err != nil {
+		return domain.ProductImage{}, err
+	}
+
+	imageId := uuid.New()
+	newImage := domain.ProductImage{
+		ID:                   imageId,
+		AltText:              altText,
+		Position:             len(existingProduct.Images),
+		IsPrimary:            isPrimary || len(existingProduct.Images) == 0,
+		ContentType:          contentType,
+		ThumbnailContentType: thumbnailContentType,
+		Size:                 int64(len(original)),
+		OriginalKey:          imageBlobKey(skuId, imageId, "original"),
+		ThumbnailKey:         imageBlobKey(skuId, imageId, "thumbnail"),
+		CreatedAt:            p.clock.Now(),
+	}
+
+	if err := p.blobStorage.Put(ctx, newImage.OriginalKey, bytes.NewReader(original)); err != nil {
+		return domain.ProductImage{}, err
+	}
+	if err := p.blobStorage.Put(ctx, newImage.ThumbnailKey, bytes.NewReader(thumbnail)); err != nil {
+		_ = p.deleteImageBlobs(ctx, newImage)
+		return domain.ProductImage{}, err
+	}
+
+	images := append(copyImages(existingProduct.Images), newImage)
+	if newImage.IsPrimary {
+		setPrimaryImage(images, imageId)
+	}
+
+	updatedProduct := existingProduct
+	updatedProduct.Images = images
+	updatedProduct.UpdatedAt = p.clock.Now()
+
+	err = p.productRepo.AddProductImage(ctx, updatedProduct, plan.MaxImages)
+	if err != nil {
+		_ = p.deleteImageBlobs(ctx, newImage)
+		if errors.Is(err, infra.ErrImageLimitReached) {
+			return domain.ProductImage{}, plans.ImageQuotaExceeded(plan)
+		}
+		return domain.ProductImage{}, err
+	}
+	p.eventBus.Publish(ctx, events.ProductUpdated, updatedProduct)
+	return newImage, nil
+}
+
+// UpdateProductImage changes the alt text, position or primary flag of an
+// image. A product always keeps exactly one primary image, so the flag can
+// only be moved onto an image, never cleared from one.
+func (p *ProductService) UpdateProductImage(ctx context.Context, merchantId, skuId, imageId uuid.UUID, altText *string, position *int, isPrimary bool) (domain.ProductImage, error) {
+	ctx, span := tracing.Start(ctx, "ProductService.UpdateProductImage")
+	defer span.End()
+	defer p.skuLocks.acquire(skuId)()
+
+	existingProduct, err := p.productRepo.GetProductBySkuId(ctx, skuId)
+	if err != nil {
+		return domain.ProductImage{}, err
+	}
+
+	if merchantId != existingProduct.MerchantId {
+		return domain.ProductImage{}, ErrUserNotAuthorized
+	}
+
+	images := copyImages(existingProduct.Images)
+	index := indexOfImage(images, imageId)
+	if index < 0 {
+		return domain.ProductImage{}, ErrImageNotFound
+	}
+
+	if altText != nil {
+		images[index].AltText = *altText
+	}
+	if isPrimary {
+		setPrimaryImage(images, imageId)
+	}
+	if position != nil {
+		images = moveImage(images, index, *position)
+	}
+
+	updatedProduct := existingProduct
+	updatedProduct.Images = images
+	updatedProduct.UpdatedAt = p.clock.Now()
+
+	err = p.productRepo.UpdateProductByProductId(ctx, updatedProduct)
+	if err != nil {
+		return domain.ProductImage{}, err
+	}
+	p.eventBus.Publish(ctx, events.ProductUpdated, updatedProduct)
+	return images[indexOfImage(images, imageId)], nil
+}
+
+func (p *ProductService) DeleteProductImage(ctx context.Context, merchantId, skuId, imageId uuid.UUID) error {
+	ctx, span := tracing.Start(ctx, "ProductService.DeleteProductImage")
+	defer span.End()
+	defer p.skuLocks.acquire(skuId)()
+
+	existingProduct, err := p.productRepo.GetProductBySkuId(ctx, skuId)
+	if err != nil {
+		return err
+	}
+
+	if merchantId != existingProduct.MerchantId {
+		return ErrUserNotAuthorized
+	}
+
+	images := copyImages(existingProduct.Images)
+	index := indexOfImage(images, imageId)
+	if index < 0 {
+		return ErrImageNotFound
+	}
+	removedImage := images[index]
+	images = append(images[:index], images[index+1:]...)
+	for i := range images {
+		images[i].Position = i
+	}
+	if removedImage.IsPrimary && len(images) > 0 {
+		setPrimaryImage(images, images[0].ID)
+	}
+
+	updatedProduct := existingProduct
+	updatedProduct.Images = images
+	updatedProduct.UpdatedAt = p.clock.Now()
+
+	err = p.productRepo.UpdateProductByProductId(ctx, updatedProduct)
+	if err != nil {
+		return err
+	}
+	p.eventBus.Publish(ctx, events.ProductUpdated, updatedProduct)
+
+	return p.deleteImageBlobs(ctx, removedImage)
+}
+
+func (p *ProductService) GetProductImageContent(ctx context.Context, skuId, imageId uuid.UUID, thumbnail bool) (string, io.ReadCloser, error) {
+	ctx, span := tracing.Start(ctx, "ProductService.GetProductImageContent")
+	defer span.End()
+
+	existingProduct, err := p.productRepo.GetProductBySkuId(ctx, skuId)
+	if err != nil {
+		return "", nil, err
+	}
+
+	index := indexOfImage(existingProduct.Images, imageId)
+	if index < 0 {
+		return "", nil, ErrImageNotFound
+	}
+	productImage := existingProduct.Images[index]
+
+	key, contentType := productImage.OriginalKey, productImage.ContentType
+	if thumbnail {
+		key, contentType = productImage.ThumbnailKey, productImage.ThumbnailContentType
+	}
+
+	content, err := p.blobStorage.Get(ctx, key)
+	if err != nil {
+		return "", nil, err
+	}
+	return contentType, content, nil
+}
+
+func (p *ProductService) deleteImageBlobs(ctx context.Context, productImage domain.ProductImage) error {
+	if err := p.blobStorage.Delete(ctx, productImage.OriginalKey); err != nil {
+		return err
+	}
+	return p.blobStorage.Delete(ctx, productImage.ThumbnailKey)
+}
+
+func encodeThumbnail(src image.Image, contentType string) ([]byte, string, error) {
+	thumbnail := imaging.Thumbnail(src, ThumbnailMaxWidth, ThumbnailMaxHeight)
+
+	var buf bytes.Buffer
+	switch contentType {
+	case "image/jpeg":
+		if err := jpeg.Encode(&buf, thumbnail, &jpeg.Options{Quality: 85}); err != nil {
+			return nil, "", err
+		}
+		return buf.Bytes(), "image/jpeg", nil
+	default:
+		if err := png.Encode(&buf, thumbnail); err != nil {
+			return nil, "", err
+		}
+		return buf.Bytes(), "image/png", nil
+	}
+}
+
+func imageBlobKey(skuId, imageId uuid.UUID, variant string) string {
+	return fmt.Sprintf("products/%s/images/%s/%s", skuId, imageId, variant)
+}
+
+func copyImages(images []domain.ProductImage) []domain.ProductImage {
+	copied := make([]domain.ProductImage, len(images))
+	copy(copied, images)
+	return copied
+}
+
+func indexOfImage(images []domain.ProductImage, imageId uuid.UUID) int {
+	for index, productImage := range images {
+		if productImage.ID == imageId {
+			return index
+		}
+	}
+	return -1
+}
+
+func setPrimaryImage(images []domain.ProductImage, imageId uuid.UUID) {
+	for i := range images {
+		images[i].IsPrimary = images[i].ID == imageId
+	}
+}
+
+func moveImage(images []domain.ProductImage, from, to int) []domain.ProductImage {
+	if to < 0 {
+		to = 0
+	}
+	if to > len(images)-1 {
+		to = len(images) - 1
+	}
+	moved := images[from]
+	images = append(images[:from], images[from+1:]...)
+	images = append(images[:to], append([]domain.ProductImage{moved}, images[to:]...)...)
+	for i := range images {
+		images[i].Position = i
+	}
+	return images
+}
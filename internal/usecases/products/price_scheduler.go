@@ -0,0 +1,181 @@
+package products
+
+import (
+	"container/heap"
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/olad5/sal-backend-service/internal/events"
+	"github.com/olad5/sal-backend-service/internal/infra"
+	"github.com/olad5/sal-backend-service/pkg/clock"
+)
+
+// DefaultSchedulerPollInterval bounds how long the scheduler sleeps, so
+// schedules are still picked up if the wall clock jumps.
+const DefaultSchedulerPollInterval = time.Second
+
+// schedulerStalledPolls is how many poll intervals the scheduler may go
+// without waking before it is reported unhealthy.
+const schedulerStalledPolls = 10
+
+type scheduledPrice struct {
+	dueAt time.Time
+	skuId uuid.UUID
+}
+
+type scheduledPriceQueue []scheduledPrice
+
+func (q scheduledPriceQueue) Len() int            { return len(q) }
+func (q scheduledPriceQueue) Less(i, j int) bool  { return q[i].dueAt.Before(q[j].dueAt) }
+func (q scheduledPriceQueue) Swap(i, j int)       { q[i], q[j] = q[j], q[i] }
+func (q *scheduledPriceQueue) Push(x interface{}) { *q = append(*q, x.(scheduledPrice)) }
+func (q *scheduledPriceQueue) Pop() interface{} {
+	old := *q
+	item := old[len(old)-1]
+	*q = old[:len(old)-1]
+	return item
+}
+
+// PriceScheduler applies scheduled price changes and sales when they fall
+// due. It learns about schedules from product events, so it only needs to be
+// subscribed to the same bus the ProductService publishes on.
+type PriceScheduler struct {
+	productService *ProductService
+	clock          clock.Clock
+	logger         *slog.Logger
+	pollInterval   time.Duration
+	queue          scheduledPriceQueue
+	queued         map[scheduledPrice]bool
+	wake           chan struct{}
+	lock           sync.Mutex
+	// running and lastWoken are kept for CheckHealth
+	running   bool
+	lastWoken time.Time
+}
+
+func NewPriceScheduler(productService *ProductService, clock clock.Clock, logger *slog.Logger, pollInterval time.Duration) (*PriceScheduler, error) {
+	if productService == nil {
+		return nil, errors.New("PriceScheduler failed to initialize, productService is nil")
+	}
+	if clock == nil {
+		return nil, errors.New("PriceScheduler failed to initialize, clock is nil")
+	}
+	if logger == nil {
+		return nil, errors.New("PriceScheduler failed to initialize, logger is nil")
+	}
+	if pollInterval <= 0 {
+		pollInterval = DefaultSchedulerPollInterval
+	}
+	return &PriceScheduler{
+		productService: productService,
+		clock:          clock,
+		logger:         logger,
+		pollInterval:   pollInterval,
+		queued:         map[scheduledPrice]bool{},
+		wake:           make(chan struct{}, 1),
+	}, nil
+}
+
+func (s *PriceScheduler) HandleProductEvent(ctx context.Context, event events.ProductEvent) {
+	if event.Type == events.ProductDeleted {
+		return
+	}
+	times := nextPriceScheduleTimes(event.Product)
+	if len(times) == 0 {
+		return
+	}
+
+	s.lock.Lock()
+	for _, dueAt := range times {
+		item := scheduledPrice{dueAt, event.Product.SKUID}
+		if s.queued[item] {
+			continue
+		}
+		s.queued[item] = true
+		heap.Push(&s.queue, item)
+	}
+	s.lock.Unlock()
+
+	select {
+	case s.wake <- struct{}{}:
+	default:
+	}
+}
+
+// RunDue applies every schedule that is due at the current time.
+func (s *PriceScheduler) RunDue(ctx context.Context) {
+	now := s.clock.Now()
+	due := map[uuid.UUID]bool{}
+
+	s.lock.Lock()
+	for s.queue.Len() > 0 && !s.queue[0].dueAt.After(now) {
+		item := heap.Pop(&s.queue).(scheduledPrice)
+		delete(s.queued, item)
+		due[item.skuId] = true
+	}
+	s.lock.Unlock()
+
+	for skuId := range due {
+		err := s.productService.ApplyDuePriceSchedules(ctx, skuId)
+		if err != nil && !errors.Is(err, infra.ErrProductNotFound) {
+			s.logger.ErrorContext(ctx, "failed to apply price schedules", "sku_id", skuId, "error", err)
+		}
+	}
+}
+
+// Run applies schedules as they fall due until ctx is cancelled.
+func (s *PriceScheduler) Run(ctx context.Context) {
+	s.lock.Lock()
+	s.running = true
+	s.lock.Unlock()
+	defer func() {
+		s.lock.Lock()
+		s.running = false
+		s.lock.Unlock()
+	}()
+
+	for {
+		wait := s.pollInterval
+		s.lock.Lock()
+		s.lastWoken = s.clock.Now()
+		if s.queue.Len() > 0 {
+			if untilDue := s.queue[0].dueAt.Sub(s.clock.Now()); untilDue < wait {
+				wait = untilDue
+			}
+		}
+		s.lock.Unlock()
+		if wait < 0 {
+			wait = 0
+		}
+
+		timer := time.NewTimer(wait)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return
+		case <-s.wake:
+			timer.Stop()
+		case <-timer.C:
+		}
+		s.RunDue(ctx)
+	}
+}
+
+// CheckHealth fails when Run is not running or has stopped waking up, such
+// as when applying schedules hangs.
+func (s *PriceScheduler) CheckHealth(ctx context.Context) error {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	if !s.running {
+		return errors.New("price scheduler is not running")
+	}
+	if idle := s.clock.Now().Sub(s.lastWoken); idle > schedulerStalledPolls*s.pollInterval {
+		return fmt.Errorf("price scheduler has not woken for %s", idle.Round(time.Second))
+	}
+	return nil
+}
@@ -0,0 +1,235 @@
+package products
+
+import (
+	"context"
+	"errors"
+	"sort"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/olad5/sal-backend-service/internal/domain"
+	"github.com/olad5/sal-backend-service/internal/events"
+	"github.com/olad5/sal-backend-service/pkg/tracing"
+)
+
+var (
+	ErrScheduleInPast       = errors.New("scheduled time must be in the future")
+	ErrInvalidSaleWindow    = errors.New("sale must end after it starts")
+	ErrOverlappingSale      = errors.New("sale overlaps with an existing sale")
+	ErrPriceScheduleMissing = errors.New("price schedule not found")
+)
+
+func (p *ProductService) SchedulePriceChange(ctx context.Context, merchantId, skuId uuid.UUID, price float64, effectiveAt time.Time) (domain.Product, error) {
+	ctx, span := tracing.Start(ctx, "ProductService.SchedulePriceChange")
+	defer span.End()
+	defer p.skuLocks.acquire(skuId)()
+
+	existingProduct, err := p.productRepo.GetProductBySkuId(ctx, skuId)
+	if err != nil {
+		return domain.Product{}, err
+	}
+
+	if merchantId != existingProduct.MerchantId {
+		return domain.Product{}, ErrUserNotAuthorized
+	}
+
+	if !effectiveAt.After(p.clock.Now()) {
+		return domain.Product{}, ErrScheduleInPast
+	}
+
+	updatedProduct := existingProduct
+	updatedProduct.PriceChanges = append(copyPriceChanges(existingProduct.PriceChanges), domain.PriceChange{
+		ID:          uuid.New(),
+		Price:       price,
+		EffectiveAt: effectiveAt,
+	})
+	sort.SliceStable(updatedProduct.PriceChanges, func(a, b int) bool {
+		return updatedProduct.PriceChanges[a].EffectiveAt.Before(updatedProduct.PriceChanges[b].EffectiveAt)
+	})
+	updatedProduct.UpdatedAt = p.clock.Now()
+
+	err = p.productRepo.UpdateProductByProductId(ctx, updatedProduct)
+	if err != nil {
+		return domain.Product{}, err
+	}
+	p.eventBus.Publish(ctx, events.ProductUpdated, updatedProduct)
+	return updatedProduct, nil
+}
+
+// ScheduleSale adds a time boxed sale price. A sale whose window has already
+// started is activated straight away.
+func (p *ProductService) ScheduleSale(ctx context.Context, merchantId, skuId uuid.UUID, price float64, startsAt, endsAt time.Time) (domain.Product, error) {
+	ctx, span := tracing.Start(ctx, "ProductService.ScheduleSale")
+	defer span.End()
+	defer p.skuLocks.acquire(skuId)()
+
+	existingProduct, err := p.productRepo.GetProductBySkuId(ctx, skuId)
+	if err != nil {
+		return domain.Product{}, err
+	}
+
+	if merchantId != existingProduct.MerchantId {
+		return domain.Product{}, ErrUserNotAuthorized
+	}
+
+	if !endsAt.After(startsAt) {
+		return domain.Product{}, ErrInvalidSaleWindow
+	}
+	if !endsAt.After(p.clock.Now()) {
+		return domain.Product{}, ErrScheduleInPast
+	}
+	for _, sale := range existingProduct.Sales {
+		if startsAt.Before(sale.EndsAt) && sale.StartsAt.Before(endsAt) {
+			return domain.Product{}, ErrOverlappingSale
+		}
+	}
+
+	updatedProduct := existingProduct
+	updatedProduct.Sales = append(copySales(existingProduct.Sales), domain.Sale{
+		ID:       uuid.New(),
+		Price:    price,
+		StartsAt: startsAt,
+		EndsAt:   endsAt,
+	})
+	sort.SliceStable(updatedProduct.Sales, func(a, b int) bool {
+		return updatedProduct.Sales[a].StartsAt.Before(updatedProduct.Sales[b].StartsAt)
+	})
+	updatedProduct, _ = applyDuePriceSchedules(updatedProduct, p.clock.Now())
+	updatedProduct.UpdatedAt = p.clock.Now()
+
+	err = p.productRepo.UpdateProductByProductId(ctx, updatedProduct)
+	if err != nil {
+		return domain.Product{}, err
+	}
+	p.eventBus.Publish(ctx, events.ProductUpdated, updatedProduct)
+	return updatedProduct, nil
+}
+
+// CancelPriceSchedule removes a pending price change or a sale. Cancelling an
+// active sale reverts the product to its regular price.
+func (p *ProductService) CancelPriceSchedule(ctx context.Context, merchantId, skuId, scheduleId uuid.UUID) (domain.Product, error) {
+	ctx, span := tracing.Start(ctx, "ProductService.CancelPriceSchedule")
+	defer span.End()
+	defer p.skuLocks.acquire(skuId)()
+
+	existingProduct, err := p.productRepo.GetProductBySkuId(ctx, skuId)
+	if err != nil {
+		return domain.Product{}, err
+	}
+
+	if merchantId != existingProduct.MerchantId {
+		return domain.Product{}, ErrUserNotAuthorized
+	}
+
+	updatedProduct := existingProduct
+	updatedProduct.PriceChanges = []domain.PriceChange{}
+	for _, change := range existingProduct.PriceChanges {
+		if change.ID != scheduleId {
+			updatedProduct.PriceChanges = append(updatedProduct.PriceChanges, change)
+		}
+	}
+	updatedProduct.Sales = []domain.Sale{}
+	for _, sale := range existingProduct.Sales {
+		if sale.ID != scheduleId {
+			updatedProduct.Sales = append(updatedProduct.Sales, sale)
+		}
+	}
+	if len(updatedProduct.PriceChanges) == len(existingProduct.PriceChanges) && len(updatedProduct.Sales) == len(existingProduct.Sales) {
+		return domain.Product{}, ErrPriceScheduleMissing
+	}
+	updatedProduct.UpdatedAt = p.clock.Now()
+
+	err = p.productRepo.UpdateProductByProductId(ctx, updatedProduct)
+	if err != nil {
+		return domain.Product{}, err
+	}
+	p.eventBus.Publish(ctx, events.ProductUpdated, updatedProduct)
+	return updatedProduct, nil
+}
+
+// ApplyDuePriceSchedules brings a product's prices in line with the current
+// time, applying due price changes and starting or ending sales. It is a no-op
+// when nothing is due.
+func (p *ProductService) ApplyDuePriceSchedules(ctx context.Context, skuId uuid.UUID) error {
+	ctx, span := tracing.Start(ctx, "ProductService.ApplyDuePriceSchedules")
+	defer span.End()
+	defer p.skuLocks.acquire(skuId)()
+
+	existingProduct, err := p.productRepo.GetProductBySkuId(ctx, skuId)
+	if err != nil {
+		return err
+	}
+
+	updatedProduct, changed := applyDuePriceSchedules(existingProduct, p.clock.Now())
+	if !changed {
+		return nil
+	}
+	updatedProduct.UpdatedAt = p.clock.Now()
+
+	err = p.productRepo.UpdateProductByProductId(ctx, updatedProduct)
+	if err != nil {
+		return err
+	}
+	p.eventBus.Publish(ctx, events.ProductUpdated, updatedProduct)
+	return nil
+}
+
+func applyDuePriceSchedules(product domain.Product, now time.Time) (domain.Product, bool) {
+	changed := false
+
+	pendingChanges := []domain.PriceChange{}
+	for _, change := range product.PriceChanges {
+		if change.EffectiveAt.After(now) {
+			pendingChanges = append(pendingChanges, change)
+			continue
+		}
+		product.Price = change.Price
+		changed = true
+	}
+	product.PriceChanges = pendingChanges
+
+	currentSales := []domain.Sale{}
+	for _, sale := range product.Sales {
+		if !sale.EndsAt.After(now) {
+			changed = true
+			continue
+		}
+		active := !sale.StartsAt.After(now)
+		if active != sale.Active {
+			sale.Active = active
+			changed = true
+		}
+		currentSales = append(currentSales, sale)
+	}
+	product.Sales = currentSales
+
+	return product, changed
+}
+
+// nextPriceScheduleTimes lists every future moment at which the product's
+// prices change.
+func nextPriceScheduleTimes(product domain.Product) []time.Time {
+	times := []time.Time{}
+	for _, change := range product.PriceChanges {
+		times = append(times, change.EffectiveAt)
+	}
+	for _, sale := range product.Sales {
+		if !sale.Active {
+			times = append(times, sale.StartsAt)
+		}
+		times = append(times, sale.EndsAt)
+	}
+	return times
+}
+
+func copyPriceChanges(changes []domain.PriceChange) []domain.PriceChange {
+	copied := make([]domain.PriceChange, len(changes))
+	copy(copied, changes)
+	return copied
+}
+
+func copySales(sales []domain.Sale) []domain.Sale {
+	copied := make([]domain.Sale, len(sales))
+	copy(copied, sales)
+	return copied
+}
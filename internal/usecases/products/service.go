@@ -4,16 +4,29 @@ import (
 	"context"
 	"errors"
 	"fmt"
-	"time"
+	"log/slog"
+	"strings"
 
 	"github.com/olad5/sal-backend-service/internal/domain"
+	"github.com/olad5/sal-backend-service/internal/events"
 	"github.com/olad5/sal-backend-service/internal/infra"
+	"github.com/olad5/sal-backend-service/internal/usecases/plans"
+	"github.com/olad5/sal-backend-service/pkg/clock"
+	"github.com/olad5/sal-backend-service/pkg/tracing"
 
 	"github.com/google/uuid"
 )
 
 type ProductService struct {
 	productRepo infra.ProductRepository
+	blobStorage infra.BlobStorage
+	searchIndex infra.ProductSearchIndex
+	eventBus    *events.Bus
+	clock       clock.Clock
+	planService *plans.PlanService
+	skuCodeRepo infra.SKUCodePatternRepository
+	logger      *slog.Logger
+	skuLocks    *skuLocks
 }
 
 var (
@@ -21,37 +34,107 @@ var (
 	ErrUserNotAuthorized    = errors.New("unauthorized")
 )
 
-func NewProductService(productRepo infra.ProductRepository) (*ProductService, error) {
+func NewProductService(productRepo infra.ProductRepository, blobStorage infra.BlobStorage, searchIndex infra.ProductSearchIndex, eventBus *events.Bus, clock clock.Clock, planService *plans.PlanService, skuCodeRepo infra.SKUCodePatternRepository, logger *slog.Logger) (*ProductService, error) {
 	if productRepo == nil {
 		return &ProductService{}, fmt.Errorf("ProductService failed to initialize, productRepo is nil")
 	}
-	return &ProductService{productRepo}, nil
+	if blobStorage == nil {
+		return &ProductService{}, fmt.Errorf("ProductService failed to initialize, blobStorage is nil")
+	}
+	if searchIndex == nil {
+		return &ProductService{}, fmt.Errorf("ProductService failed to initialize, searchIndex is nil")
+	}
+	if eventBus == nil {
+		return &ProductService{}, fmt.Errorf("ProductService failed to initialize, eventBus is nil")
+	}
+	if clock == nil {
+		return &ProductService{}, fmt.Errorf("ProductService failed to initialize, clock is nil")
+	}
+	if planService == nil {
+		return &ProductService{}, fmt.Errorf("ProductService failed to initialize, planService is nil")
+	}
+	if skuCodeRepo == nil {
+		return &ProductService{}, fmt.Errorf("ProductService failed to initialize, skuCodeRepo is nil")
+	}
+	if logger == nil {
+		return &ProductService{}, fmt.Errorf("ProductService failed to initialize, logger is nil")
+	}
+	return &ProductService{productRepo, blobStorage, searchIndex, eventBus, clock, planService, skuCodeRepo, logger, newSKULocks()}, nil
 }
 
-func (p *ProductService) CreateProduct(ctx context.Context, merchantId, skuId uuid.UUID, name, description string, price float64) (domain.Product, error) {
-	existingProduct, err := p.productRepo.GetProductBySkuId(ctx, skuId)
-	if err == nil && existingProduct.SKUID == skuId {
+// CreateProduct adds a product to the merchant's catalogue. A nil skuId is
+// replaced with a new one and an empty skuCode is generated from the
+// merchant's SKU code pattern. gtin, isbn, tags and attributes are optional
+// and must already be normalized. Products without a tax class are taxed at
+// the standard rate and an empty category leaves the product uncategorized.
+func (p *ProductService) CreateProduct(ctx context.Context, merchantId, skuId uuid.UUID, skuCode, gtin, isbn, name, description string, price float64, taxClass domain.TaxClass, category string, tags []string, attributes map[string]string) (domain.Product, error) {
+	ctx, span := tracing.Start(ctx, "ProductService.CreateProduct")
+	defer span.End()
+
+	if skuId == uuid.Nil {
+		skuId = uuid.New()
+	} else if existingProduct, err := p.productRepo.GetProductBySkuId(ctx, skuId); err == nil && existingProduct.SKUID == skuId {
 		return domain.Product{}, ErrProductAlreadyExists
 	}
 
+	// the repository enforces the plan's limit as it stores the product, so
+	// concurrent creates cannot take a merchant over it
+	plan, err := p.planService.GetMerchantPlan(ctx, merchantId)
+	if err != nil {
+		return domain.Product{}, err
+	}
+
+	if taxClass == "" {
+		taxClass = domain.StandardTaxClass
+	}
+
 	newProduct := domain.Product{
 		SKUID:       skuId,
+		SKUCode:     skuCode,
+		GTIN:        gtin,
+		ISBN:        isbn,
 		MerchantId:  merchantId,
 		Name:        name,
 		Description: description,
 		Price:       price,
-		CreatedAt:   time.Now(),
-		UpdatedAt:   time.Now(),
+		TaxClass:    taxClass,
+		Category:    strings.TrimSpace(category),
+		Tags:        tags,
+		Attributes:  attributes,
+		CreatedAt:   p.clock.Now(),
+		UpdatedAt:   p.clock.Now(),
 	}
 
-	err = p.productRepo.CreateProduct(ctx, newProduct)
+	// a generated code can still be taken by a concurrent request between
+	// generating and storing it, so generate a fresh one and try again
+	for attempt := 0; ; attempt++ {
+		if skuCode == "" {
+			newProduct.SKUCode, err = p.generateSKUCode(ctx, merchantId)
+			if err != nil {
+				return domain.Product{}, err
+			}
+		}
+		err = p.productRepo.CreateProduct(ctx, newProduct, plan.MaxProducts)
+		if skuCode == "" && errors.Is(err, infra.ErrSkuCodeTaken) && attempt < maxSKUCodeAttempts {
+			continue
+		}
+		break
+	}
+	if errors.Is(err, infra.ErrProductLimitReached) {
+		return domain.Product{}, plans.ProductQuotaExceeded(plan)
+	}
 	if err != nil {
 		return domain.Product{}, err
 	}
+	p.eventBus.Publish(ctx, events.ProductCreated, newProduct)
 	return newProduct, nil
 }
 
-func (p *ProductService) UpdateProduct(ctx context.Context, merchantId, skuId uuid.UUID, name, description string, price float64) (domain.Product, error) {
+func (p *ProductService) UpdateProduct(ctx context.Context, merchantId, skuId uuid.UUID, skuCode, gtin, isbn, name, description string, price float64, taxClass domain.TaxClass, category string, tags []string, attributes map[string]string) (domain.Product, error) {
+	ctx, span := tracing.Start(ctx, "ProductService.UpdateProduct")
+	defer span.End()
+	defer p.skuLocks.acquire(skuId)()
+
 	existingProduct, err := p.productRepo.GetProductBySkuId(ctx, skuId)
 	if err != nil {
 		return domain.Product{}, err
@@ -82,24 +165,62 @@ func (p *ProductService) UpdateProduct(ctx context.Context, merchantId, skuId uu
 		updatedPrice = existingProduct.Price
 	}
 
-	updatedProduct := domain.Product{
-		SKUID:       existingProduct.SKUID,
-		Name:        updatedName,
-		Description: updatedDescription,
-		Price:       updatedPrice,
-		MerchantId:  existingProduct.MerchantId,
-		CreatedAt:   existingProduct.CreatedAt,
-		UpdatedAt:   time.Now(),
+	updatedProduct := existingProduct
+	updatedProduct.Name = updatedName
+	updatedProduct.Description = updatedDescription
+	updatedProduct.Price = updatedPrice
+	if taxClass != "" {
+		updatedProduct.TaxClass = taxClass
+	}
+	if category = strings.TrimSpace(category); category != "" {
+		updatedProduct.Category = category
+	}
+	// nil tags and attributes are left unchanged, empty ones clear them
+	if tags != nil {
+		updatedProduct.Tags = tags
 	}
+	if attributes != nil {
+		updatedProduct.Attributes = attributes
+	}
+	if skuCode != "" {
+		updatedProduct.SKUCode = skuCode
+	}
+	if gtin != "" {
+		updatedProduct.GTIN = gtin
+	}
+	if isbn != "" {
+		updatedProduct.ISBN = isbn
+	}
+	updatedProduct.UpdatedAt = p.clock.Now()
 
 	err = p.productRepo.UpdateProductByProductId(ctx, updatedProduct)
 	if err != nil {
 		return domain.Product{}, err
 	}
+	p.eventBus.Publish(ctx, events.ProductUpdated, updatedProduct)
 	return updatedProduct, nil
 }
 
+// GetProduct returns one of the merchant's products. Another merchant's
+// product is reported as ErrUserNotAuthorized.
+func (p *ProductService) GetProduct(ctx context.Context, merchantId, skuId uuid.UUID) (domain.Product, error) {
+	ctx, span := tracing.Start(ctx, "ProductService.GetProduct")
+	defer span.End()
+
+	product, err := p.productRepo.GetProductBySkuId(ctx, skuId)
+	if err != nil {
+		return domain.Product{}, err
+	}
+	if merchantId != product.MerchantId {
+		return domain.Product{}, ErrUserNotAuthorized
+	}
+	return product, nil
+}
+
 func (p *ProductService) GetProductsByMerchantId(ctx context.Context, merchantId uuid.UUID) ([]domain.Product, error) {
+	ctx, span := tracing.Start(ctx, "ProductService.GetProductsByMerchantId")
+	defer span.End()
+
 	products, err := p.productRepo.GetProductsByMerchantId(ctx, merchantId)
 	if err != nil {
 		return []domain.Product{}, err
@@ -108,7 +229,44 @@ func (p *ProductService) GetProductsByMerchantId(ctx context.Context, merchantId
 	return products, nil
 }
 
+func (p *ProductService) SearchProducts(ctx context.Context, merchantId uuid.UUID, query string, limit, offset int) ([]domain.ProductSearchHit, int, error) {
+	ctx, span := tracing.Start(ctx, "ProductService.SearchProducts")
+	defer span.End()
+
+	hits, total, err := p.searchIndex.SearchProducts(ctx, merchantId, query, limit, offset)
+	if err != nil {
+		return []domain.ProductSearchHit{}, 0, err
+	}
+
+	return hits, total, nil
+}
+
+// GetCatalogStats counts merchants and products across every catalogue, for
+// the business metrics.
+func (p *ProductService) GetCatalogStats(ctx context.Context) (domain.CatalogStats, error) {
+	ctx, span := tracing.Start(ctx, "ProductService.GetCatalogStats")
+	defer span.End()
+
+	return p.productRepo.GetCatalogStats(ctx)
+}
+
+func (p *ProductService) SuggestProductNames(ctx context.Context, merchantId uuid.UUID, prefix string, limit int) ([]domain.ProductSuggestion, error) {
+	ctx, span := tracing.Start(ctx, "ProductService.SuggestProductNames")
+	defer span.End()
+
+	suggestions, err := p.searchIndex.SuggestProductNames(ctx, merchantId, prefix, limit)
+	if err != nil {
+		return []domain.ProductSuggestion{}, err
+	}
+
+	return suggestions, nil
+}
+
 func (p *ProductService) DeleteProduct(ctx context.Context, merchantId, skuId uuid.UUID) error {
+	ctx, span := tracing.Start(ctx, "ProductService.DeleteProduct")
+	defer span.End()
+	defer p.skuLocks.acquire(skuId)()
+
 	existingProduct, err := p.productRepo.GetProductBySkuId(ctx, skuId)
 	if err != nil {
 		return err
@@ -122,6 +280,15 @@ func (p *ProductService) DeleteProduct(ctx context.Context, merchantId, skuId uu
 	if err != nil {
 		return err
 	}
+	p.eventBus.Publish(ctx, events.ProductDeleted, existingProduct)
+
+	// the product is already gone at this point, so a failed cleanup only
+	// leaves orphaned files behind and should not fail the request
+	for _, image := range existingProduct.Images {
+		if err := p.deleteImageBlobs(ctx, image); err != nil {
+			p.logger.ErrorContext(ctx, "failed to delete image blobs", "sku_id", skuId, "image_id", image.ID, "error", err)
+		}
+	}
 
 	return nil
 }
@@ -0,0 +1,136 @@
+package products
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/olad5/sal-backend-service/internal/domain"
+	"github.com/olad5/sal-backend-service/internal/infra"
+	"github.com/olad5/sal-backend-service/pkg/tracing"
+)
+
+// DefaultSKUCodePattern is used for merchants that have not set their own.
+const DefaultSKUCodePattern = "SKU-{seq:6}"
+
+// maxSKUCodeAttempts bounds how many sequence numbers are tried when codes
+// the merchant picked by hand are already in the way.
+const maxSKUCodeAttempts = 1000
+
+var (
+	ErrInvalidSkuCode        = errors.New("sku_code must be 1 to 64 letters, digits, dots, dashes or underscores")
+	ErrInvalidSkuCodePattern = errors.New("pattern must contain exactly one {seq} or {seq:N} placeholder and produce valid sku codes")
+	ErrSkuCodesExhausted     = errors.New("no free sku code could be generated from the pattern")
+)
+
+var (
+	skuCode             = regexp.MustCompile(`^[A-Za-z0-9][A-Za-z0-9._-]{0,63}$`)
+	skuCodePlaceholders = regexp.MustCompile(`\{(seq(?::(\d))?|yyyy|yy|mm|dd)\}`)
+)
+
+func NormalizeSKUCode(code string) (string, error) {
+	code = strings.TrimSpace(code)
+	if !skuCode.MatchString(code) {
+		return "", ErrInvalidSkuCode
+	}
+	return code, nil
+}
+
+// RenderSKUCode fills in a pattern's placeholders: {seq} is the sequence
+// number, {seq:N} the sequence number zero padded to N digits, and {yyyy},
+// {yy}, {mm} and {dd} the creation date.
+func RenderSKUCode(pattern string, sequence int, now time.Time) string {
+	return skuCodePlaceholders.ReplaceAllStringFunc(pattern, func(placeholder string) string {
+		match := skuCodePlaceholders.FindStringSubmatch(placeholder)
+		switch match[1] {
+		case "yyyy":
+			return now.Format("2006")
+		case "yy":
+			return now.Format("06")
+		case "mm":
+			return now.Format("01")
+		case "dd":
+			return now.Format("02")
+		}
+		if match[2] == "" {
+			return strconv.Itoa(sequence)
+		}
+		width, _ := strconv.Atoi(match[2])
+		return fmt.Sprintf("%0*d", width, sequence)
+	})
+}
+
+func (p *ProductService) SetSKUCodePattern(ctx context.Context, merchantId uuid.UUID, pattern string) (domain.SKUCodePattern, error) {
+	ctx, span := tracing.Start(ctx, "ProductService.SetSKUCodePattern")
+	defer span.End()
+
+	pattern = strings.TrimSpace(pattern)
+	if strings.Count(pattern, "{seq") != 1 || len(skuCodePlaceholders.FindAllString(pattern, -1)) != strings.Count(pattern, "{") {
+		return domain.SKUCodePattern{}, ErrInvalidSkuCodePattern
+	}
+	if _, err := NormalizeSKUCode(RenderSKUCode(pattern, 1, p.clock.Now())); err != nil {
+		return domain.SKUCodePattern{}, ErrInvalidSkuCodePattern
+	}
+
+	skuCodePattern := domain.SKUCodePattern{
+		MerchantId: merchantId,
+		Pattern:    pattern,
+		UpdatedAt:  p.clock.Now(),
+	}
+	err := p.skuCodeRepo.SaveSKUCodePattern(ctx, skuCodePattern)
+	if err != nil {
+		return domain.SKUCodePattern{}, err
+	}
+	return skuCodePattern, nil
+}
+
+func (p *ProductService) GetSKUCodePattern(ctx context.Context, merchantId uuid.UUID) (domain.SKUCodePattern, error) {
+	ctx, span := tracing.Start(ctx, "ProductService.GetSKUCodePattern")
+	defer span.End()
+
+	pattern, err := p.skuCodeRepo.GetSKUCodePatternByMerchantId(ctx, merchantId)
+	if err != nil {
+		if errors.Is(err, infra.ErrSKUCodePatternNotFound) {
+			return domain.SKUCodePattern{MerchantId: merchantId, Pattern: DefaultSKUCodePattern}, nil
+		}
+		return domain.SKUCodePattern{}, err
+	}
+	return pattern, nil
+}
+
+func (p *ProductService) GetProductBySkuCode(ctx context.Context, merchantId uuid.UUID, code string) (domain.Product, error) {
+	ctx, span := tracing.Start(ctx, "ProductService.GetProductBySkuCode")
+	defer span.End()
+
+	return p.productRepo.GetProductBySkuCode(ctx, merchantId, code)
+}
+
+// generateSKUCode returns the first code from the merchant's pattern that no
+// product uses yet.
+func (p *ProductService) generateSKUCode(ctx context.Context, merchantId uuid.UUID) (string, error) {
+	pattern, err := p.GetSKUCodePattern(ctx, merchantId)
+	if err != nil {
+		return "", err
+	}
+
+	for attempt := 0; attempt < maxSKUCodeAttempts; attempt++ {
+		sequence, err := p.skuCodeRepo.NextSKUCodeSequence(ctx, merchantId)
+		if err != nil {
+			return "", err
+		}
+		code := RenderSKUCode(pattern.Pattern, sequence, p.clock.Now())
+		_, err = p.productRepo.GetProductBySkuCode(ctx, merchantId, code)
+		if errors.Is(err, infra.ErrProductNotFound) {
+			return code, nil
+		}
+		if err != nil {
+			return "", err
+		}
+	}
+	return "", ErrSkuCodesExhausted
+}
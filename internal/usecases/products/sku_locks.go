@@ -0,0 +1,50 @@
+package products
+
+import (
+	"sync"
+
+	"github.com/google/uuid"
+)
+
+// skuLocks serializes the writes to each product, so that concurrent read,
+// modify, write cycles on the same SKU, say a price schedule being applied
+// while the merchant edits the product, cannot overwrite each other. Only the
+// writes made through this process are serialized. Locks are dropped once
+// nothing holds or waits for them.
+type skuLocks struct {
+	lock  sync.Mutex
+	bySKU map[uuid.UUID]*skuLock
+}
+
+type skuLock struct {
+	sync.Mutex
+	waiters int
+}
+
+func newSKULocks() *skuLocks {
+	return &skuLocks{bySKU: map[uuid.UUID]*skuLock{}}
+}
+
+// acquire blocks until no other write to skuId is in progress and returns the
+// function that ends this one.
+func (s *skuLocks) acquire(skuId uuid.UUID) func() {
+	s.lock.Lock()
+	l, ok := s.bySKU[skuId]
+	if !ok {
+		l = &skuLock{}
+		s.bySKU[skuId] = l
+	}
+	l.waiters++
+	s.lock.Unlock()
+
+	l.Lock()
+	return func() {
+		l.Unlock()
+		s.lock.Lock()
+		l.waiters--
+		if l.waiters == 0 {
+			delete(s.bySKU, skuId)
+		}
+		s.lock.Unlock()
+	}
+}
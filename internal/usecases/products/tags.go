@@ -0,0 +1,51 @@
+package products
+
+import (
+	"errors"
+	"strings"
+)
+
+var ErrInvalidAttributeName = errors.New("attribute names cannot be empty or differ only in case")
+
+// NormalizeTags trims tags and drops empty ones and repeats, comparing tags
+// case insensitively and keeping the first spelling. A nil slice stays nil so
+// updates can tell "leave the tags alone" from "remove every tag".
+func NormalizeTags(tags []string) []string {
+	if tags == nil {
+		return nil
+	}
+	normalized := []string{}
+	seen := map[string]bool{}
+	for _, tag := range tags {
+		tag = strings.TrimSpace(tag)
+		key := strings.ToLower(tag)
+		if tag == "" || seen[key] {
+			continue
+		}
+		seen[key] = true
+		normalized = append(normalized, tag)
+	}
+	return normalized
+}
+
+// NormalizeAttributes lower cases and trims attribute names and trims
+// values, dropping attributes without a value. A nil map stays nil, as with
+// NormalizeTags.
+func NormalizeAttributes(attributes map[string]string) (map[string]string, error) {
+	if attributes == nil {
+		return nil, nil
+	}
+	normalized := map[string]string{}
+	seen := map[string]bool{}
+	for name, value := range attributes {
+		name = strings.ToLower(strings.TrimSpace(name))
+		if name == "" || seen[name] {
+			return nil, ErrInvalidAttributeName
+		}
+		seen[name] = true
+		if value = strings.TrimSpace(value); value != "" {
+			normalized[name] = value
+		}
+	}
+	return normalized, nil
+}
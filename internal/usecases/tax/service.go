@@ -0,0 +1,210 @@
+package tax
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"os"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/olad5/sal-backend-service/internal/domain"
+	"github.com/olad5/sal-backend-service/internal/infra"
+	"github.com/olad5/sal-backend-service/internal/usecases/currency"
+	"github.com/olad5/sal-backend-service/pkg/clock"
+)
+
+var (
+	ErrInvalidRegion     = errors.New("region must be an ISO 3166 code such as DE or US-CA")
+	ErrInvalidTaxClass   = errors.New("tax class must be one of standard, reduced or zero")
+	ErrInvalidTaxRate    = errors.New("tax rates must be between 0 and 100")
+	ErrMissingTaxRate    = errors.New("standard and reduced tax rates are required")
+	ErrZeroTaxRate       = errors.New("the zero tax class cannot have a rate")
+	ErrInvalidTaxRates   = errors.New("invalid tax rates file")
+	ErrUnsupportedRegion = errors.New("region is not in the tax rate table")
+	ErrDuplicateRegion   = errors.New("region is listed more than once")
+)
+
+var regionCode = regexp.MustCompile(`^[A-Z]{2}(-[A-Z0-9]{1,3})?$`)
+
+// taxRounding rounds every tax amount to the cent, half up, so the same net
+// price always produces the same tax.
+var taxRounding = domain.CurrencyRounding{Increment: 0.01, Mode: domain.RoundHalfUp}
+
+type TaxService struct {
+	rateRepo    infra.TaxRateRepository
+	clock       clock.Clock
+	fileRegions *fileRegions
+}
+
+// fileRegions holds the regions the last tax rates file loaded, its lock
+// serializes LoadTaxRatesFile.
+type fileRegions struct {
+	lock  sync.Mutex
+	codes map[string]bool
+}
+
+// TaxRatesFile is the shape of the tax rates file, keyed by region.
+type TaxRatesFile struct {
+	Regions map[string]map[string]float64 `json:"regions"`
+}
+
+func NewTaxService(rateRepo infra.TaxRateRepository, clock clock.Clock) (*TaxService, error) {
+	if rateRepo == nil {
+		return &TaxService{}, fmt.Errorf("TaxService failed to initialize, rateRepo is nil")
+	}
+	if clock == nil {
+		return &TaxService{}, fmt.Errorf("TaxService failed to initialize, clock is nil")
+	}
+	return &TaxService{rateRepo, clock, &fileRegions{}}, nil
+}
+
+// SetTaxRegion creates or replaces the tax rates of a region. The zero class
+// is always present with a rate of 0.
+func (t *TaxService) SetTaxRegion(ctx context.Context, code string, rates map[string]float64) (domain.TaxRegion, error) {
+	region, err := t.buildTaxRegion(code, rates)
+	if err != nil {
+		return domain.TaxRegion{}, err
+	}
+	err = t.rateRepo.SaveTaxRegion(ctx, region)
+	if err != nil {
+		return domain.TaxRegion{}, err
+	}
+	return region, nil
+}
+
+// buildTaxRegion validates a region's code and rates.
+func (t *TaxService) buildTaxRegion(code string, rates map[string]float64) (domain.TaxRegion, error) {
+	normalized, err := NormalizeRegion(code)
+	if err != nil {
+		return domain.TaxRegion{}, err
+	}
+
+	region := domain.TaxRegion{
+		Code:      normalized,
+		Rates:     map[domain.TaxClass]float64{domain.ZeroTaxClass: 0},
+		UpdatedAt: t.clock.Now(),
+	}
+	for class, rate := range rates {
+		taxClass, err := ParseTaxClass(class)
+		if err != nil {
+			return domain.TaxRegion{}, err
+		}
+		if rate < 0 || rate > 100 || math.IsNaN(rate) {
+			return domain.TaxRegion{}, ErrInvalidTaxRate
+		}
+		if taxClass == domain.ZeroTaxClass && rate != 0 {
+			return domain.TaxRegion{}, ErrZeroTaxRate
+		}
+		region.Rates[taxClass] = rate
+	}
+	for _, required := range []domain.TaxClass{domain.StandardTaxClass, domain.ReducedTaxClass} {
+		if _, ok := region.Rates[required]; !ok {
+			return domain.TaxRegion{}, ErrMissingTaxRate
+		}
+	}
+	return region, nil
+}
+
+// LoadTaxRatesFile reads the tax rates of every region in a JSON file in the
+// TaxRatesFile format. Every region is checked before any is saved, so a file
+// with a mistake changes nothing. Regions an earlier file loaded that are no
+// longer in the file are removed, regions set through SetTaxRegion alone are
+// kept.
+func (t *TaxService) LoadTaxRatesFile(ctx context.Context, path string) ([]domain.TaxRegion, error) {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return []domain.TaxRegion{}, err
+	}
+
+	var file TaxRatesFile
+	err = json.Unmarshal(content, &file)
+	if err != nil {
+		return []domain.TaxRegion{}, fmt.Errorf("%w: %v", ErrInvalidTaxRates, err)
+	}
+
+	regions := []domain.TaxRegion{}
+	loaded := map[string]bool{}
+	for code, rates := range file.Regions {
+		region, err := t.buildTaxRegion(code, rates)
+		if err != nil {
+			return []domain.TaxRegion{}, fmt.Errorf("region %s: %w", code, err)
+		}
+		if loaded[region.Code] {
+			return []domain.TaxRegion{}, fmt.Errorf("region %s: %w", code, ErrDuplicateRegion)
+		}
+		loaded[region.Code] = true
+		regions = append(regions, region)
+	}
+	sort.Slice(regions, func(a, b int) bool { return regions[a].Code < regions[b].Code })
+
+	t.fileRegions.lock.Lock()
+	defer t.fileRegions.lock.Unlock()
+	removed := []string{}
+	for code := range t.fileRegions.codes {
+		if !loaded[code] {
+			removed = append(removed, code)
+		}
+	}
+	err = t.rateRepo.SaveTaxRegions(ctx, regions, removed)
+	if err != nil {
+		return []domain.TaxRegion{}, err
+	}
+	t.fileRegions.codes = loaded
+	return regions, nil
+}
+
+func (t *TaxService) GetTaxRegions(ctx context.Context) ([]domain.TaxRegion, error) {
+	return t.rateRepo.GetTaxRegions(ctx)
+}
+
+func (t *TaxService) GetTaxRegion(ctx context.Context, code string) (domain.TaxRegion, error) {
+	normalized, err := NormalizeRegion(code)
+	if err != nil {
+		return domain.TaxRegion{}, err
+	}
+
+	region, err := t.rateRepo.GetTaxRegionByCode(ctx, normalized)
+	if err != nil {
+		if errors.Is(err, infra.ErrTaxRegionNotFound) {
+			return domain.TaxRegion{}, ErrUnsupportedRegion
+		}
+		return domain.TaxRegion{}, err
+	}
+	return region, nil
+}
+
+// Calculate adds the region's tax for class to a tax exclusive amount.
+func Calculate(region domain.TaxRegion, class domain.TaxClass, net float64) domain.TaxedPrice {
+	if class == "" {
+		class = domain.StandardTaxClass
+	}
+	net = currency.Round(net, taxRounding)
+	tax := currency.Round(net*region.Rates[class]/100, taxRounding)
+	return domain.TaxedPrice{
+		Net:   net,
+		Tax:   tax,
+		Gross: currency.Round(net+tax, taxRounding),
+	}
+}
+
+func ParseTaxClass(class string) (domain.TaxClass, error) {
+	switch taxClass := domain.TaxClass(strings.ToLower(strings.TrimSpace(class))); taxClass {
+	case domain.StandardTaxClass, domain.ReducedTaxClass, domain.ZeroTaxClass:
+		return taxClass, nil
+	default:
+		return "", ErrInvalidTaxClass
+	}
+}
+
+func NormalizeRegion(code string) (string, error) {
+	normalized := strings.ToUpper(strings.TrimSpace(code))
+	if !regionCode.MatchString(normalized) {
+		return "", ErrInvalidRegion
+	}
+	return normalized, nil
+}
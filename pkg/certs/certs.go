@@ -0,0 +1,155 @@
+// Package certs serves TLS certificates that are reloaded when their files
+// change, so certificates can be rotated without restarting the server.
+package certs
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"os"
+	"sync"
+	"time"
+)
+
+// Reloader holds the server certificate and, for mutual TLS, the CAs client
+// certificates are verified against, as last loaded from their files.
+type Reloader struct {
+	certFile     string
+	keyFile      string
+	clientCAFile string
+	interval     time.Duration
+	onError      func(err error)
+
+	lock        sync.RWMutex
+	certificate *tls.Certificate
+	clientCAs   *x509.CertPool
+	stamps      map[string]fileStamp
+}
+
+// fileStamp tells whether a file has changed since it was loaded.
+type fileStamp struct {
+	modTime time.Time
+	size    int64
+}
+
+// NewReloader loads the files, failing if they cannot be used. clientCAFile
+// is optional. The files are checked for changes every interval once Run is
+// running, onError is called with changes that failed to load and may be
+// nil.
+func NewReloader(certFile, keyFile, clientCAFile string, interval time.Duration, onError func(err error)) (*Reloader, error) {
+	if certFile == "" || keyFile == "" {
+		return nil, errors.New("certs Reloader failed to initialize, certFile and keyFile are required")
+	}
+	if interval <= 0 {
+		return nil, errors.New("certs Reloader failed to initialize, interval must be positive")
+	}
+	r := &Reloader{certFile: certFile, keyFile: keyFile, clientCAFile: clientCAFile, interval: interval, onError: onError}
+	if _, err := r.ReloadIfChanged(); err != nil {
+		return nil, err
+	}
+	return r, nil
+}
+
+func (r *Reloader) files() []string {
+	files := []string{r.certFile, r.keyFile}
+	if r.clientCAFile != "" {
+		files = append(files, r.clientCAFile)
+	}
+	return files
+}
+
+// ReloadIfChanged loads the files again when any of them has changed. A
+// change that fails to load leaves the previous certificates in use.
+func (r *Reloader) ReloadIfChanged() (bool, error) {
+	stamps := map[string]fileStamp{}
+	for _, file := range r.files() {
+		info, err := os.Stat(file)
+		if err != nil {
+			return false, err
+		}
+		stamps[file] = fileStamp{info.ModTime(), info.Size()}
+	}
+
+	r.lock.RLock()
+	changed := r.stamps == nil
+	for file, stamp := range stamps {
+		if r.stamps[file] != stamp {
+			changed = true
+		}
+	}
+	r.lock.RUnlock()
+	if !changed {
+		return false, nil
+	}
+
+	certificate, err := tls.LoadX509KeyPair(r.certFile, r.keyFile)
+	if err != nil {
+		return false, fmt.Errorf("failed to load certificate: %w", err)
+	}
+	var clientCAs *x509.CertPool
+	if r.clientCAFile != "" {
+		pem, err := os.ReadFile(r.clientCAFile)
+		if err != nil {
+			return false, err
+		}
+		clientCAs = x509.NewCertPool()
+		if !clientCAs.AppendCertsFromPEM(pem) {
+			return false, fmt.Errorf("no certificates found in %s", r.clientCAFile)
+		}
+	}
+
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	r.certificate = &certificate
+	r.clientCAs = clientCAs
+	r.stamps = stamps
+	return true, nil
+}
+
+// Run checks the files for changes until ctx is done.
+func (r *Reloader) Run(ctx context.Context) {
+	ticker := time.NewTicker(r.interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+		if _, err := r.ReloadIfChanged(); err != nil && r.onError != nil {
+			r.onError(err)
+		}
+	}
+}
+
+func (r *Reloader) GetCertificate(*tls.ClientHelloInfo) (*tls.Certificate, error) {
+	r.lock.RLock()
+	defer r.lock.RUnlock()
+	return r.certificate, nil
+}
+
+// ServerConfig returns a TLS config that picks up reloaded certificates on
+// each new connection. clientAuth only applies with a client CA file.
+func (r *Reloader) ServerConfig(clientAuth tls.ClientAuthType) *tls.Config {
+	config := &tls.Config{
+		MinVersion:     tls.VersionTLS12,
+		GetCertificate: r.GetCertificate,
+	}
+	if r.clientCAFile == "" {
+		return config
+	}
+	config.GetConfigForClient = func(*tls.ClientHelloInfo) (*tls.Config, error) {
+		r.lock.RLock()
+		defer r.lock.RUnlock()
+		return &tls.Config{
+			MinVersion:     tls.VersionTLS12,
+			GetCertificate: r.GetCertificate,
+			ClientAuth:     clientAuth,
+			ClientCAs:      r.clientCAs,
+			NextProtos:     []string{"h2", "http/1.1"},
+		}, nil
+	}
+	return config
+}
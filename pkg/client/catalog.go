@@ -0,0 +1,83 @@
+package client
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+	"strconv"
+
+	"github.com/google/uuid"
+)
+
+// UpdateExchangeRatesRequest publishes a new exchange rate table. Rates are
+// units of each currency per unit of BaseCurrency.
+type UpdateExchangeRatesRequest struct {
+	BaseCurrency string                      `json:"base_currency"`
+	Rates        map[string]float64          `json:"rates"`
+	Rounding     map[string]CurrencyRounding `json:"rounding,omitempty"`
+}
+
+func (c *Client) GetExchangeRates(ctx context.Context) (ExchangeRateTable, error) {
+	var table ExchangeRateTable
+	err := c.do(ctx, http.MethodGet, "/api/exchange-rates", nil, nil, &table)
+	return table, err
+}
+
+func (c *Client) GetExchangeRatesByVersion(ctx context.Context, version int) (ExchangeRateTable, error) {
+	var table ExchangeRateTable
+	err := c.do(ctx, http.MethodGet, "/api/exchange-rates/"+strconv.Itoa(version), nil, nil, &table)
+	return table, err
+}
+
+// UpdateExchangeRates is an admin call, see WithAdminToken.
+func (c *Client) UpdateExchangeRates(ctx context.Context, request UpdateExchangeRatesRequest) (ExchangeRateTable, error) {
+	var table ExchangeRateTable
+	err := c.do(ctx, http.MethodPut, "/admin/exchange-rates", nil, request, &table)
+	return table, err
+}
+
+func (c *Client) ListTaxRegions(ctx context.Context) ([]TaxRegion, error) {
+	var regions struct {
+		Regions []TaxRegion `json:"regions"`
+	}
+	err := c.do(ctx, http.MethodGet, "/api/tax-regions", nil, nil, &regions)
+	return regions.Regions, err
+}
+
+// UpdateTaxRegion sets a region's rates by tax class, for example
+// {"standard": 0.19, "reduced": 0.07}. It is an admin call, see
+// WithAdminToken.
+func (c *Client) UpdateTaxRegion(ctx context.Context, region string, rates map[string]float64) (TaxRegion, error) {
+	request := struct {
+		Rates map[string]float64 `json:"rates"`
+	}{rates}
+
+	var updated TaxRegion
+	err := c.do(ctx, http.MethodPut, "/admin/tax-regions/"+url.PathEscape(region), nil, request, &updated)
+	return updated, err
+}
+
+func (c *Client) ListPlans(ctx context.Context) ([]Plan, error) {
+	var plans struct {
+		Plans []Plan `json:"plans"`
+	}
+	err := c.do(ctx, http.MethodGet, "/api/plans", nil, nil, &plans)
+	return plans.Plans, err
+}
+
+// AssignPlan is an admin call, see WithAdminToken.
+func (c *Client) AssignPlan(ctx context.Context, merchantId uuid.UUID, plan string) (Plan, error) {
+	request := struct {
+		Plan string `json:"plan"`
+	}{plan}
+
+	var assigned Plan
+	err := c.do(ctx, http.MethodPut, "/admin/merchants/"+merchantId.String()+"/plan", nil, request, &assigned)
+	return assigned, err
+}
+
+func (c *Client) GetUsage(ctx context.Context, merchantId uuid.UUID) (PlanUsage, error) {
+	var usage PlanUsage
+	err := c.do(ctx, http.MethodGet, merchantPath(merchantId)+"/usage", nil, nil, &usage)
+	return usage, err
+}
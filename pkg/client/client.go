@@ -0,0 +1,271 @@
+// Package client is a typed Go client for the SAL Backend Service API.
+//
+//	c, err := client.New("https://sal.example.com", client.WithMerchant(merchantId))
+//	product, err := c.CreateProduct(ctx, client.CreateProductRequest{...})
+//	if errors.Is(err, client.ErrSkuCodeTaken) { ... }
+package client
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math/rand"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/olad5/sal-backend-service/pkg/tracing"
+)
+
+const (
+	merchantHeader       = "X-Merchant-ID"
+	idempotencyKeyHeader = "Idempotency-Key"
+	defaultTimeout       = 30 * time.Second
+)
+
+// RetryPolicy controls how failed requests are retried. Network errors, 429
+// and 5xx responses are retried with exponential backoff and jitter, or after
+// the server's Retry-After when it sends one. Writes are retried under an
+// Idempotency-Key so they are applied at most once.
+type RetryPolicy struct {
+	// MaxAttempts includes the first attempt, 1 disables retries
+	MaxAttempts int
+	MinBackoff  time.Duration
+	MaxBackoff  time.Duration
+}
+
+var DefaultRetryPolicy = RetryPolicy{MaxAttempts: 3, MinBackoff: 100 * time.Millisecond, MaxBackoff: 2 * time.Second}
+
+type Client struct {
+	baseURL    *url.URL
+	httpClient *http.Client
+	retry      RetryPolicy
+	merchantId uuid.UUID
+	adminToken string
+	userAgent  string
+}
+
+type Option func(*Client)
+
+func WithHTTPClient(httpClient *http.Client) Option {
+	return func(c *Client) {
+		c.httpClient = httpClient
+	}
+}
+
+func WithRetryPolicy(policy RetryPolicy) Option {
+	return func(c *Client) {
+		c.retry = policy
+	}
+}
+
+// WithMerchant sends the merchant's ID with every request, so rate limits,
+// quotas and idempotency keys are scoped to the merchant instead of the
+// client's IP.
+func WithMerchant(merchantId uuid.UUID) Option {
+	return func(c *Client) {
+		c.merchantId = merchantId
+	}
+}
+
+// WithAdminToken sends the server's admin token, which the /admin routes
+// behind calls such as AssignPlan require.
+func WithAdminToken(token string) Option {
+	return func(c *Client) {
+		c.adminToken = token
+	}
+}
+
+func WithUserAgent(userAgent string) Option {
+	return func(c *Client) {
+		c.userAgent = userAgent
+	}
+}
+
+func New(baseURL string, opts ...Option) (*Client, error) {
+	parsed, err := url.Parse(strings.TrimRight(baseURL, "/"))
+	if err != nil {
+		return nil, fmt.Errorf("client failed to initialize, invalid base URL: %w", err)
+	}
+	if parsed.Scheme != "http" && parsed.Scheme != "https" {
+		return nil, fmt.Errorf("client failed to initialize, base URL must be http or https, got %q", baseURL)
+	}
+
+	c := &Client{
+		baseURL:    parsed,
+		httpClient: &http.Client{Timeout: defaultTimeout},
+		retry:      DefaultRetryPolicy,
+		userAgent:  "sal-go-client",
+	}
+	for _, opt := range opts {
+		opt(c)
+	}
+	if c.retry.MaxAttempts < 1 {
+		c.retry.MaxAttempts = 1
+	}
+	return c, nil
+}
+
+// envelope is the body of every JSON response, see utils.SuccessResponse
+type envelope struct {
+	Status  bool            `json:"status"`
+	Message string          `json:"message"`
+	Data    json.RawMessage `json:"data"`
+}
+
+// do sends body as JSON and decodes the data of the response into out, which
+// may be nil.
+func (c *Client) do(ctx context.Context, method, path string, query url.Values, body, out interface{}) error {
+	var payload []byte
+	contentType := ""
+	if body != nil {
+		var err error
+		payload, err = json.Marshal(body)
+		if err != nil {
+			return err
+		}
+		contentType = "application/json"
+	}
+
+	response, err := c.send(ctx, method, path, query, payload, contentType)
+	if err != nil {
+		return err
+	}
+	if err := decodeData(response.body, out); err != nil {
+		return fmt.Errorf("decoding %s %s response: %w", method, path, err)
+	}
+	return nil
+}
+
+// decodeData decodes the data of a response envelope into out, which may be
+// nil.
+func decodeData(body []byte, out interface{}) error {
+	var decoded envelope
+	if err := json.Unmarshal(body, &decoded); err != nil {
+		return err
+	}
+	if out == nil || len(decoded.Data) == 0 || string(decoded.Data) == "null" {
+		return nil
+	}
+	return json.Unmarshal(decoded.Data, out)
+}
+
+type rawResponse struct {
+	header http.Header
+	body   []byte
+}
+
+// send makes the request, retrying it according to the retry policy. Error
+// responses are returned as *Error.
+func (c *Client) send(ctx context.Context, method, path string, query url.Values, payload []byte, contentType string) (rawResponse, error) {
+	target := *c.baseURL
+	target.Path += path
+	target.RawQuery = query.Encode()
+
+	idempotencyKey := ""
+	if c.retry.MaxAttempts > 1 && method != http.MethodGet && method != http.MethodHead {
+		idempotencyKey = uuid.NewString()
+	}
+
+	for attempt := 1; ; attempt++ {
+		req, err := http.NewRequestWithContext(ctx, method, target.String(), bytes.NewReader(payload))
+		if err != nil {
+			return rawResponse{}, err
+		}
+		if contentType != "" {
+			req.Header.Set("Content-Type", contentType)
+		}
+		req.Header.Set("User-Agent", c.userAgent)
+		if c.merchantId != uuid.Nil {
+			req.Header.Set(merchantHeader, c.merchantId.String())
+		}
+		if c.adminToken != "" {
+			req.Header.Set("Authorization", "Bearer "+c.adminToken)
+		}
+		if idempotencyKey != "" {
+			req.Header.Set(idempotencyKeyHeader, idempotencyKey)
+		}
+		// calls made while tracing continue the caller's trace on the server
+		if sc := tracing.SpanContextFromContext(ctx); sc.IsValid() {
+			req.Header.Set(tracing.TraceparentHeader, sc.Traceparent())
+		}
+
+		resp, err := c.httpClient.Do(req)
+		if err != nil {
+			if ctx.Err() != nil {
+				return rawResponse{}, ctx.Err()
+			}
+			if attempt < c.retry.MaxAttempts {
+				if err := c.wait(ctx, attempt, 0); err != nil {
+					return rawResponse{}, err
+				}
+				continue
+			}
+			return rawResponse{}, err
+		}
+		body, err := io.ReadAll(resp.Body)
+		resp.Body.Close()
+		if err != nil {
+			return rawResponse{}, err
+		}
+
+		if resp.StatusCode < http.StatusBadRequest {
+			return rawResponse{header: resp.Header, body: body}, nil
+		}
+		apiErr := newError(resp, body)
+		if attempt < c.retry.MaxAttempts && retryable(resp.StatusCode) {
+			if err := c.wait(ctx, attempt, apiErr.RetryAfter); err != nil {
+				return rawResponse{}, err
+			}
+			continue
+		}
+		return rawResponse{}, apiErr
+	}
+}
+
+func retryable(statusCode int) bool {
+	return statusCode == http.StatusTooManyRequests || statusCode >= http.StatusInternalServerError
+}
+
+// wait sleeps before the next attempt, for retryAfter when the server asked
+// for it and with capped exponential backoff otherwise.
+func (c *Client) wait(ctx context.Context, attempt int, retryAfter time.Duration) error {
+	delay := retryAfter
+	if delay <= 0 && c.retry.MinBackoff > 0 {
+		backoff := c.retry.MinBackoff << (attempt - 1)
+		if backoff <= 0 || (c.retry.MaxBackoff > 0 && backoff > c.retry.MaxBackoff) {
+			backoff = c.retry.MaxBackoff
+		}
+		// full jitter keeps clients that failed together from retrying together
+		delay = time.Duration(rand.Int63n(int64(backoff) + 1))
+	}
+	if delay <= 0 {
+		return ctx.Err()
+	}
+
+	timer := time.NewTimer(delay)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
+
+func setInt(query url.Values, name string, value int) {
+	if value != 0 {
+		query.Set(name, strconv.Itoa(value))
+	}
+}
+
+func setString(query url.Values, name, value string) {
+	if value != "" {
+		query.Set(name, value)
+	}
+}
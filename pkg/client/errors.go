@@ -0,0 +1,72 @@
+package client
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	appErrors "github.com/olad5/sal-backend-service/pkg/errors"
+)
+
+// Error is an error response from the API. Compare it against the sentinel
+// errors below with errors.Is: the status class sentinels match any message,
+// the others match the server's message as well.
+type Error struct {
+	StatusCode int
+	Message    string
+	// RetryAfter is set on 429 responses
+	RetryAfter time.Duration
+}
+
+func (e *Error) Error() string {
+	if e.Message == "" {
+		return fmt.Sprintf("sal api: %d %s", e.StatusCode, http.StatusText(e.StatusCode))
+	}
+	return fmt.Sprintf("sal api: %d %s", e.StatusCode, e.Message)
+}
+
+func (e *Error) Is(target error) bool {
+	t, ok := target.(*Error)
+	if !ok {
+		return false
+	}
+	return t.StatusCode == e.StatusCode && (t.Message == "" || t.Message == e.Message)
+}
+
+var (
+	ErrBadRequest = &Error{StatusCode: http.StatusBadRequest}
+	// ErrQuotaExceeded is returned when the merchant's plan has no room left
+	ErrQuotaExceeded       = &Error{StatusCode: http.StatusForbidden}
+	ErrNotFound            = &Error{StatusCode: http.StatusNotFound}
+	ErrConflict            = &Error{StatusCode: http.StatusConflict}
+	ErrRequestTooLarge     = &Error{StatusCode: http.StatusRequestEntityTooLarge}
+	ErrUnsupportedMedia    = &Error{StatusCode: http.StatusUnsupportedMediaType}
+	ErrUnprocessableEntity = &Error{StatusCode: http.StatusUnprocessableEntity}
+	ErrRateLimited         = &Error{StatusCode: http.StatusTooManyRequests}
+	ErrInternal            = &Error{StatusCode: http.StatusInternalServerError}
+
+	ErrProductNotFound      = &Error{StatusCode: http.StatusNotFound, Message: "product not found"}
+	ErrProductAlreadyExists = &Error{StatusCode: http.StatusBadRequest, Message: "product already exists"}
+	ErrSkuCodeTaken         = &Error{StatusCode: http.StatusConflict, Message: "sku_code is already used by another product"}
+	ErrBarcodeTaken         = &Error{StatusCode: http.StatusConflict, Message: "gtin or isbn is already used by another product"}
+	ErrPricingRuleNotFound  = &Error{StatusCode: http.StatusNotFound, Message: "pricing rule not found"}
+	ErrInvalidJSON          = &Error{StatusCode: http.StatusBadRequest, Message: appErrors.ErrInvalidJson}
+	ErrInvalidID            = &Error{StatusCode: http.StatusBadRequest, Message: appErrors.ErrInvalidID.Error()}
+	// ErrUnauthorized is returned, as a 404, when a merchant acts on another
+	// merchant's resources
+	ErrUnauthorized = &Error{StatusCode: http.StatusNotFound, Message: appErrors.ErrUnauthorized}
+)
+
+func newError(resp *http.Response, body []byte) *Error {
+	apiErr := &Error{StatusCode: resp.StatusCode}
+	var decoded envelope
+	if err := json.Unmarshal(body, &decoded); err == nil {
+		apiErr.Message = decoded.Message
+	}
+	if seconds, err := strconv.Atoi(resp.Header.Get("Retry-After")); err == nil && seconds > 0 {
+		apiErr.RetryAfter = time.Duration(seconds) * time.Second
+	}
+	return apiErr
+}
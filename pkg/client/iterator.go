@@ -0,0 +1,83 @@
+package client
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+)
+
+const defaultSearchPageSize = 20
+
+// SearchIterator walks every result of a search, fetching pages as it goes.
+//
+//	it := c.SearchProductsIter(merchantId, "coat", client.SearchOptions{})
+//	for it.Next(ctx) {
+//		result := it.Result()
+//	}
+//	if err := it.Err(); err != nil { ... }
+type SearchIterator struct {
+	client     *Client
+	merchantId uuid.UUID
+	query      string
+	opts       SearchOptions
+	page       []SearchResult
+	index      int
+	total      int
+	fetched    bool
+	err        error
+}
+
+// SearchProductsIter starts at opts.Offset and fetches opts.Limit results per
+// page.
+func (c *Client) SearchProductsIter(merchantId uuid.UUID, query string, opts SearchOptions) *SearchIterator {
+	if opts.Limit <= 0 {
+		opts.Limit = defaultSearchPageSize
+	}
+	return &SearchIterator{client: c, merchantId: merchantId, query: query, opts: opts}
+}
+
+func (it *SearchIterator) Next(ctx context.Context) bool {
+	if it.err != nil {
+		return false
+	}
+	if it.index+1 < len(it.page) {
+		it.index++
+		return true
+	}
+	if it.fetched && it.opts.Offset >= it.total {
+		return false
+	}
+
+	page, err := it.client.SearchProducts(ctx, it.merchantId, it.query, it.opts)
+	if err != nil {
+		it.err = err
+		return false
+	}
+	it.fetched = true
+	it.total = page.Total
+	it.page = page.Results
+	it.index = 0
+	it.opts.Offset += len(page.Results)
+	if len(page.Results) == 0 {
+		// guards against looping when the catalogue shrinks mid iteration
+		it.total = it.opts.Offset
+		return false
+	}
+	return true
+}
+
+func (it *SearchIterator) Result() SearchResult {
+	if it.index < len(it.page) {
+		return it.page[it.index]
+	}
+	return SearchResult{}
+}
+
+// Total is the number of results the last page reported.
+func (it *SearchIterator) Total() int {
+	return it.total
+}
+
+func (it *SearchIterator) Err() error {
+	return it.err
+}
@@ -0,0 +1,94 @@
+package client
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"strconv"
+
+	"github.com/google/uuid"
+)
+
+type UploadProductImageRequest struct {
+	MerchantId uuid.UUID
+	// Image is read in full before the upload so that it can be retried
+	Image     io.Reader
+	Filename  string
+	AltText   string
+	IsPrimary bool
+}
+
+// EditProductImageRequest changes an image. Nil AltText and Position keep
+// their current values.
+type EditProductImageRequest struct {
+	MerchantId uuid.UUID `json:"merchant_id"`
+	AltText    *string   `json:"alt_text,omitempty"`
+	Position   *int      `json:"position,omitempty"`
+	IsPrimary  bool      `json:"is_primary"`
+}
+
+func (c *Client) UploadProductImage(ctx context.Context, skuId uuid.UUID, request UploadProductImageRequest) (ProductImage, error) {
+	body := &bytes.Buffer{}
+	form := multipart.NewWriter(body)
+	if err := form.WriteField("merchant_id", request.MerchantId.String()); err != nil {
+		return ProductImage{}, err
+	}
+	if request.AltText != "" {
+		if err := form.WriteField("alt_text", request.AltText); err != nil {
+			return ProductImage{}, err
+		}
+	}
+	if err := form.WriteField("is_primary", strconv.FormatBool(request.IsPrimary)); err != nil {
+		return ProductImage{}, err
+	}
+	filename := request.Filename
+	if filename == "" {
+		filename = "image"
+	}
+	part, err := form.CreateFormFile("image", filename)
+	if err != nil {
+		return ProductImage{}, err
+	}
+	if request.Image != nil {
+		if _, err := io.Copy(part, request.Image); err != nil {
+			return ProductImage{}, err
+		}
+	}
+	if err := form.Close(); err != nil {
+		return ProductImage{}, err
+	}
+
+	response, err := c.send(ctx, http.MethodPost, productPath(skuId)+"/images", nil, body.Bytes(), form.FormDataContentType())
+	if err != nil {
+		return ProductImage{}, err
+	}
+	var image ProductImage
+	err = decodeData(response.body, &image)
+	return image, err
+}
+
+func (c *Client) EditProductImage(ctx context.Context, skuId, imageId uuid.UUID, request EditProductImageRequest) (ProductImage, error) {
+	var image ProductImage
+	err := c.do(ctx, http.MethodPatch, productPath(skuId)+"/images/"+imageId.String(), nil, request, &image)
+	return image, err
+}
+
+func (c *Client) DeleteProductImage(ctx context.Context, merchantId, skuId, imageId uuid.UUID) error {
+	return c.do(ctx, http.MethodDelete, productPath(skuId)+"/images/"+imageId.String(), nil, merchantRequest{merchantId}, nil)
+}
+
+// DownloadProductImage returns an image, or its thumbnail, and its content
+// type.
+func (c *Client) DownloadProductImage(ctx context.Context, skuId, imageId uuid.UUID, thumbnail bool) ([]byte, string, error) {
+	path := "/media/products/" + skuId.String() + "/images/" + imageId.String()
+	if thumbnail {
+		path += "/thumbnail"
+	}
+	response, err := c.send(ctx, http.MethodGet, path, nil, nil, "")
+	if err != nil {
+		return nil, "", err
+	}
+	return response.body, response.header.Get("Content-Type"), nil
+}
@@ -0,0 +1,239 @@
+package client
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type Product struct {
+	SKUID       uuid.UUID `json:"sku_id"`
+	SKUCode     string    `json:"sku_code"`
+	GTIN        string    `json:"gtin"`
+	ISBN        string    `json:"isbn"`
+	MerchantId  uuid.UUID `json:"merchant_id"`
+	Name        string    `json:"name"`
+	Description string    `json:"description"`
+	// Price is the regular price, RegularPrice repeats it
+	Price          float64           `json:"price"`
+	RegularPrice   float64           `json:"regular_price"`
+	EffectivePrice float64           `json:"effective_price"`
+	TaxClass       string            `json:"tax_class"`
+	Category       string            `json:"category"`
+	Tags           []string          `json:"tags"`
+	Attributes     map[string]string `json:"attributes"`
+	ActiveSale     *Sale             `json:"active_sale"`
+	Sales          []Sale            `json:"sales"`
+	PriceChanges   []PriceChange     `json:"price_changes"`
+	Images         []ProductImage    `json:"images"`
+	// Converted is only set when PriceDisplay.Currency was requested
+	Converted *ConvertedPrices `json:"converted"`
+	// Tax is only set when PriceDisplay.Region was requested
+	Tax       *TaxedPrices `json:"tax"`
+	CreatedAt time.Time    `json:"created_at"`
+	UpdatedAt time.Time    `json:"updated_at"`
+}
+
+type Sale struct {
+	ID       uuid.UUID `json:"id"`
+	Price    float64   `json:"price"`
+	StartsAt time.Time `json:"starts_at"`
+	EndsAt   time.Time `json:"ends_at"`
+	Active   bool      `json:"active"`
+}
+
+type PriceChange struct {
+	ID          uuid.UUID `json:"id"`
+	Price       float64   `json:"price"`
+	EffectiveAt time.Time `json:"effective_at"`
+}
+
+type ProductImage struct {
+	ID           uuid.UUID `json:"id"`
+	URL          string    `json:"url"`
+	ThumbnailURL string    `json:"thumbnail_url"`
+	AltText      string    `json:"alt_text"`
+	Position     int       `json:"position"`
+	IsPrimary    bool      `json:"is_primary"`
+	ContentType  string    `json:"content_type"`
+	Size         int64     `json:"size"`
+	CreatedAt    time.Time `json:"created_at"`
+}
+
+type ExchangeRate struct {
+	BaseCurrency string    `json:"base_currency"`
+	Currency     string    `json:"currency"`
+	Rate         float64   `json:"rate"`
+	Version      int       `json:"version"`
+	UpdatedAt    time.Time `json:"updated_at"`
+}
+
+type ConvertedPrices struct {
+	Currency       string       `json:"currency"`
+	Price          float64      `json:"price"`
+	RegularPrice   float64      `json:"regular_price"`
+	EffectivePrice float64      `json:"effective_price"`
+	ExchangeRate   ExchangeRate `json:"exchange_rate"`
+}
+
+type TaxedPrice struct {
+	Net   float64 `json:"net"`
+	Tax   float64 `json:"tax"`
+	Gross float64 `json:"gross"`
+}
+
+type TaxedPrices struct {
+	Region         string     `json:"region"`
+	TaxClass       string     `json:"tax_class"`
+	Rate           float64    `json:"rate"`
+	TaxInclusive   bool       `json:"tax_inclusive"`
+	Price          float64    `json:"price"`
+	RegularPrice   float64    `json:"regular_price"`
+	EffectivePrice float64    `json:"effective_price"`
+	Regular        TaxedPrice `json:"regular"`
+	Effective      TaxedPrice `json:"effective"`
+}
+
+type PriceBucket struct {
+	From float64 `json:"from"`
+	// To is nil for the open ended last bucket
+	To    *float64 `json:"to"`
+	Count int      `json:"count"`
+}
+
+type StatusCount struct {
+	Status string `json:"status"`
+	Count  int    `json:"count"`
+}
+
+type TermCount struct {
+	Term  string `json:"term"`
+	Count int    `json:"count"`
+}
+
+type AttributeFacet struct {
+	Name   string      `json:"name"`
+	Values []TermCount `json:"values"`
+}
+
+// Facets holds the requested facets, term counts are most common first and
+// empty when no product has a term.
+type Facets struct {
+	Price      []PriceBucket    `json:"price"`
+	Status     []StatusCount    `json:"status"`
+	Category   []TermCount      `json:"category"`
+	Tags       []TermCount      `json:"tags"`
+	Attributes []AttributeFacet `json:"attributes"`
+}
+
+type ProductPage struct {
+	Limit    int       `json:"limit"`
+	Products []Product `json:"products"`
+	Facets   *Facets   `json:"facets"`
+}
+
+type SearchHighlights struct {
+	Name        string `json:"name"`
+	Description string `json:"description"`
+}
+
+type SearchResult struct {
+	Product    Product          `json:"product"`
+	Score      float64          `json:"score"`
+	Highlights SearchHighlights `json:"highlights"`
+}
+
+type SearchPage struct {
+	Limit   int            `json:"limit"`
+	Offset  int            `json:"offset"`
+	Total   int            `json:"total"`
+	Results []SearchResult `json:"results"`
+	Facets  *Facets        `json:"facets"`
+}
+
+type Suggestion struct {
+	Name     string      `json:"name"`
+	SKUIDs   []uuid.UUID `json:"sku_ids"`
+	Distance int         `json:"distance"`
+}
+
+type Suggestions struct {
+	Prefix      string       `json:"prefix"`
+	Suggestions []Suggestion `json:"suggestions"`
+}
+
+type SKUCodePattern struct {
+	MerchantId uuid.UUID `json:"merchant_id"`
+	Pattern    string    `json:"pattern"`
+	// UpdatedAt is zero while the merchant uses the default pattern
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+type PricingRule struct {
+	ID         uuid.UUID `json:"id"`
+	MerchantId uuid.UUID `json:"merchant_id"`
+	// SKUID is nil for rules that apply to every product of the merchant
+	SKUID *uuid.UUID `json:"sku_id"`
+	// Category is empty for rules that are not scoped to a category
+	Category      string    `json:"category"`
+	CustomerGroup string    `json:"customer_group"`
+	MinQuantity   int       `json:"min_quantity"`
+	Type          string    `json:"type"`
+	Value         float64   `json:"value"`
+	CreatedAt     time.Time `json:"created_at"`
+}
+
+type AppliedPricingRule struct {
+	Rule           PricingRule `json:"rule"`
+	UnitPriceAfter float64     `json:"unit_price_after"`
+}
+
+type PriceQuote struct {
+	SKUID         uuid.UUID            `json:"sku_id"`
+	Quantity      int                  `json:"quantity"`
+	CustomerGroup string               `json:"customer_group"`
+	BaseUnitPrice float64              `json:"base_unit_price"`
+	UnitPrice     float64              `json:"unit_price"`
+	Total         float64              `json:"total"`
+	AppliedRules  []AppliedPricingRule `json:"applied_rules"`
+}
+
+type CurrencyRounding struct {
+	Increment float64 `json:"increment"`
+	Mode      string  `json:"mode"`
+}
+
+type ExchangeRateTable struct {
+	Version      int                         `json:"version"`
+	BaseCurrency string                      `json:"base_currency"`
+	Rates        map[string]float64          `json:"rates"`
+	Rounding     map[string]CurrencyRounding `json:"rounding"`
+	UpdatedAt    time.Time                   `json:"updated_at"`
+}
+
+type TaxRegion struct {
+	Region    string             `json:"region"`
+	Rates     map[string]float64 `json:"rates"`
+	UpdatedAt time.Time          `json:"updated_at"`
+}
+
+type Plan struct {
+	Name              string `json:"name"`
+	MaxProducts       int    `json:"max_products"`
+	MaxImages         int    `json:"max_images"`
+	RequestsPerMinute int    `json:"requests_per_minute"`
+}
+
+type QuotaUsage struct {
+	Used      int `json:"used"`
+	Limit     int `json:"limit"`
+	Remaining int `json:"remaining"`
+}
+
+type PlanUsage struct {
+	MerchantId        uuid.UUID  `json:"merchant_id"`
+	Plan              Plan       `json:"plan"`
+	Products          QuotaUsage `json:"products"`
+	Images            QuotaUsage `json:"images"`
+	RequestsPerMinute int        `json:"requests_per_minute"`
+}
@@ -0,0 +1,50 @@
+package client
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/google/uuid"
+)
+
+// CreatePricingRuleRequest creates a quantity break or customer group price.
+// Type is "percentage", "fixed_amount" or "fixed_price". A rule is scoped to
+// a SKUID or a Category; with neither it applies to every product of the
+// merchant. A nil MinQuantity applies it to any quantity.
+type CreatePricingRuleRequest struct {
+	SKUID         *uuid.UUID `json:"sku_id,omitempty"`
+	Category      string     `json:"category,omitempty"`
+	CustomerGroup string     `json:"customer_group,omitempty"`
+	MinQuantity   *int       `json:"min_quantity,omitempty"`
+	Type          string     `json:"type"`
+	Value         float64    `json:"value"`
+}
+
+type QuoteRequest struct {
+	Quantity      int    `json:"quantity"`
+	CustomerGroup string `json:"customer_group,omitempty"`
+}
+
+func (c *Client) CreatePricingRule(ctx context.Context, merchantId uuid.UUID, request CreatePricingRuleRequest) (PricingRule, error) {
+	var rule PricingRule
+	err := c.do(ctx, http.MethodPost, merchantPath(merchantId)+"/pricing-rules", nil, request, &rule)
+	return rule, err
+}
+
+func (c *Client) ListPricingRules(ctx context.Context, merchantId uuid.UUID) ([]PricingRule, error) {
+	var page struct {
+		Rules []PricingRule `json:"rules"`
+	}
+	err := c.do(ctx, http.MethodGet, merchantPath(merchantId)+"/pricing-rules", nil, nil, &page)
+	return page.Rules, err
+}
+
+func (c *Client) DeletePricingRule(ctx context.Context, merchantId, ruleId uuid.UUID) error {
+	return c.do(ctx, http.MethodDelete, merchantPath(merchantId)+"/pricing-rules/"+ruleId.String(), nil, nil, nil)
+}
+
+func (c *Client) QuoteProductPrice(ctx context.Context, skuId uuid.UUID, request QuoteRequest) (PriceQuote, error) {
+	var quote PriceQuote
+	err := c.do(ctx, http.MethodPost, productPath(skuId)+"/quote", nil, request, &quote)
+	return quote, err
+}
@@ -0,0 +1,252 @@
+package client
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// CreateProductRequest creates a product. SKUID, SKUCode, GTIN, ISBN,
+// TaxClass, Category, Tags and Attributes are optional: the server generates
+// the ID and code when they are left empty and taxes products at the
+// standard rate by default.
+type CreateProductRequest struct {
+	SKUID       uuid.UUID         `json:"sku_id"`
+	SKUCode     string            `json:"sku_code,omitempty"`
+	GTIN        string            `json:"gtin,omitempty"`
+	ISBN        string            `json:"isbn,omitempty"`
+	MerchantId  uuid.UUID         `json:"merchant_id"`
+	Name        string            `json:"name"`
+	Description string            `json:"description"`
+	Price       float64           `json:"price"`
+	TaxClass    string            `json:"tax_class,omitempty"`
+	Category    string            `json:"category,omitempty"`
+	Tags        []string          `json:"tags,omitempty"`
+	Attributes  map[string]string `json:"attributes,omitempty"`
+}
+
+// UpdateProductRequest replaces a product's name, description and price.
+// Empty SKUCode, GTIN, ISBN, TaxClass and Category keep their current values,
+// as do nil Tags and Attributes; empty non-nil ones remove them all.
+type UpdateProductRequest struct {
+	MerchantId  uuid.UUID         `json:"merchant_id"`
+	SKUCode     string            `json:"sku_code,omitempty"`
+	GTIN        string            `json:"gtin,omitempty"`
+	ISBN        string            `json:"isbn,omitempty"`
+	Name        string            `json:"name"`
+	Description string            `json:"description"`
+	Price       float64           `json:"price"`
+	TaxClass    string            `json:"tax_class,omitempty"`
+	Category    string            `json:"category,omitempty"`
+	Tags        []string          `json:"tags"`
+	Attributes  map[string]string `json:"attributes"`
+}
+
+// PriceDisplay asks read endpoints to add converted or taxed prices to each
+// product.
+type PriceDisplay struct {
+	// Currency is an ISO 4217 code to convert prices to
+	Currency string
+	// Region is a tax region code, TaxInclusive reports its prices gross
+	Region       string
+	TaxInclusive bool
+}
+
+func (d PriceDisplay) encode(query url.Values) {
+	setString(query, "currency", d.Currency)
+	if d.Region != "" {
+		query.Set("region", d.Region)
+		if d.TaxInclusive {
+			query.Set("tax_display", "inclusive")
+		} else {
+			query.Set("tax_display", "exclusive")
+		}
+	}
+}
+
+// FacetOptions asks listing and search endpoints for facet counts.
+type FacetOptions struct {
+	// PriceBuckets are the lower bounds of the price histogram buckets, an
+	// empty slice uses the server's default buckets
+	Price        bool
+	PriceBuckets []float64
+	Status       bool
+	Category     bool
+	Tags         bool
+	Attributes   bool
+}
+
+func (f FacetOptions) encode(query url.Values) {
+	facets := []string{}
+	for _, facet := range []struct {
+		name      string
+		requested bool
+	}{{"price", f.Price}, {"status", f.Status}, {"category", f.Category}, {"tags", f.Tags}, {"attributes", f.Attributes}} {
+		if facet.requested {
+			facets = append(facets, facet.name)
+		}
+	}
+	if len(facets) == 0 {
+		return
+	}
+	query.Set("facets", strings.Join(facets, ","))
+	if f.Price && len(f.PriceBuckets) > 0 {
+		bounds := make([]string, 0, len(f.PriceBuckets))
+		for _, bound := range f.PriceBuckets {
+			bounds = append(bounds, strconv.FormatFloat(bound, 'f', -1, 64))
+		}
+		query.Set("price_buckets", strings.Join(bounds, ","))
+	}
+}
+
+type ListProductsOptions struct {
+	// GTIN or ISBN limit the listing to the product with that barcode
+	GTIN string
+	ISBN string
+	PriceDisplay
+	Facets FacetOptions
+}
+
+type SearchOptions struct {
+	Limit  int
+	Offset int
+	PriceDisplay
+	Facets FacetOptions
+}
+
+func productPath(skuId uuid.UUID) string {
+	return "/api/products/" + skuId.String()
+}
+
+func merchantPath(merchantId uuid.UUID) string {
+	return "/api/merchants/" + merchantId.String()
+}
+
+type merchantRequest struct {
+	MerchantId uuid.UUID `json:"merchant_id"`
+}
+
+func (c *Client) CreateProduct(ctx context.Context, request CreateProductRequest) (Product, error) {
+	var product Product
+	err := c.do(ctx, http.MethodPost, "/api/products", nil, request, &product)
+	return product, err
+}
+
+func (c *Client) UpdateProduct(ctx context.Context, skuId uuid.UUID, request UpdateProductRequest) (Product, error) {
+	var product Product
+	err := c.do(ctx, http.MethodPatch, productPath(skuId), nil, request, &product)
+	return product, err
+}
+
+func (c *Client) DeleteProduct(ctx context.Context, merchantId, skuId uuid.UUID) error {
+	return c.do(ctx, http.MethodDelete, productPath(skuId), nil, merchantRequest{merchantId}, nil)
+}
+
+func (c *Client) ListProducts(ctx context.Context, merchantId uuid.UUID, opts ListProductsOptions) (ProductPage, error) {
+	query := url.Values{}
+	setString(query, "gtin", opts.GTIN)
+	setString(query, "isbn", opts.ISBN)
+	opts.PriceDisplay.encode(query)
+	opts.Facets.encode(query)
+
+	var page ProductPage
+	err := c.do(ctx, http.MethodGet, merchantPath(merchantId)+"/products", query, nil, &page)
+	return page, err
+}
+
+// GetProductByBarcode finds the merchant's product with a GTIN or ISBN,
+// returning ErrProductNotFound when there is none.
+func (c *Client) GetProductByBarcode(ctx context.Context, merchantId uuid.UUID, gtin string) (Product, error) {
+	page, err := c.ListProducts(ctx, merchantId, ListProductsOptions{GTIN: gtin})
+	if err != nil {
+		return Product{}, err
+	}
+	if len(page.Products) == 0 {
+		return Product{}, ErrProductNotFound
+	}
+	return page.Products[0], nil
+}
+
+func (c *Client) GetProductByCode(ctx context.Context, merchantId uuid.UUID, code string) (Product, error) {
+	var product Product
+	err := c.do(ctx, http.MethodGet, merchantPath(merchantId)+"/products/by-code/"+url.PathEscape(code), nil, nil, &product)
+	return product, err
+}
+
+func (c *Client) SearchProducts(ctx context.Context, merchantId uuid.UUID, q string, opts SearchOptions) (SearchPage, error) {
+	query := url.Values{}
+	query.Set("q", q)
+	setInt(query, "limit", opts.Limit)
+	setInt(query, "offset", opts.Offset)
+	opts.PriceDisplay.encode(query)
+	opts.Facets.encode(query)
+
+	var page SearchPage
+	err := c.do(ctx, http.MethodGet, merchantPath(merchantId)+"/products/search", query, nil, &page)
+	return page, err
+}
+
+func (c *Client) SuggestProductNames(ctx context.Context, merchantId uuid.UUID, prefix string, limit int) (Suggestions, error) {
+	query := url.Values{}
+	query.Set("prefix", prefix)
+	setInt(query, "limit", limit)
+
+	var suggestions Suggestions
+	err := c.do(ctx, http.MethodGet, merchantPath(merchantId)+"/products/suggest", query, nil, &suggestions)
+	return suggestions, err
+}
+
+func (c *Client) GetSKUCodePattern(ctx context.Context, merchantId uuid.UUID) (SKUCodePattern, error) {
+	var pattern SKUCodePattern
+	err := c.do(ctx, http.MethodGet, merchantPath(merchantId)+"/sku-code-pattern", nil, nil, &pattern)
+	return pattern, err
+}
+
+// SetSKUCodePattern sets the pattern codes are generated from for products
+// created without one, such as "TSHIRT-{seq:4}".
+func (c *Client) SetSKUCodePattern(ctx context.Context, merchantId uuid.UUID, pattern string) (SKUCodePattern, error) {
+	request := struct {
+		Pattern string `json:"pattern"`
+	}{pattern}
+
+	var updated SKUCodePattern
+	err := c.do(ctx, http.MethodPut, merchantPath(merchantId)+"/sku-code-pattern", nil, request, &updated)
+	return updated, err
+}
+
+func (c *Client) SchedulePriceChange(ctx context.Context, merchantId, skuId uuid.UUID, price float64, effectiveAt time.Time) (Product, error) {
+	request := struct {
+		MerchantId  uuid.UUID `json:"merchant_id"`
+		Price       float64   `json:"price"`
+		EffectiveAt time.Time `json:"effective_at"`
+	}{merchantId, price, effectiveAt}
+
+	var product Product
+	err := c.do(ctx, http.MethodPost, productPath(skuId)+"/price-changes", nil, request, &product)
+	return product, err
+}
+
+func (c *Client) ScheduleSale(ctx context.Context, merchantId, skuId uuid.UUID, price float64, startsAt, endsAt time.Time) (Product, error) {
+	request := struct {
+		MerchantId uuid.UUID `json:"merchant_id"`
+		Price      float64   `json:"price"`
+		StartsAt   time.Time `json:"starts_at"`
+		EndsAt     time.Time `json:"ends_at"`
+	}{merchantId, price, startsAt, endsAt}
+
+	var product Product
+	err := c.do(ctx, http.MethodPost, productPath(skuId)+"/sales", nil, request, &product)
+	return product, err
+}
+
+// CancelPriceSchedule cancels a scheduled price change or sale.
+func (c *Client) CancelPriceSchedule(ctx context.Context, merchantId, skuId, scheduleId uuid.UUID) (Product, error) {
+	var product Product
+	err := c.do(ctx, http.MethodDelete, productPath(skuId)+"/price-schedules/"+scheduleId.String(), nil, merchantRequest{merchantId}, &product)
+	return product, err
+}
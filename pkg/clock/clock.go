@@ -0,0 +1,18 @@
+package clock
+
+import "time"
+
+// Clock lets time dependent code be driven by a fake clock in tests.
+type Clock interface {
+	Now() time.Time
+}
+
+type systemClock struct{}
+
+func New() Clock {
+	return systemClock{}
+}
+
+func (systemClock) Now() time.Time {
+	return time.Now()
+}
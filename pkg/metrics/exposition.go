@@ -0,0 +1,91 @@
+package metrics
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+// ContentType is the Prometheus text exposition format, version 0.0.4.
+const ContentType = "text/plain; version=0.0.4; charset=utf-8"
+
+// WriteText writes every metric in registration order. A GaugeFunc that fails
+// is left out, the other metrics are still written and its error returned.
+func (r *Registry) WriteText(ctx context.Context, w io.Writer) error {
+	r.lock.Lock()
+	families := append([]family(nil), r.families...)
+	r.lock.Unlock()
+
+	out := bufio.NewWriter(w)
+	var errs []error
+	for _, f := range families {
+		name, help, kind := f.describe()
+		samples, err := f.samples(ctx)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("collecting %s: %w", name, err))
+			continue
+		}
+		fmt.Fprintf(out, "# HELP %s %s\n", name, escapeHelp(help))
+		fmt.Fprintf(out, "# TYPE %s %s\n", name, kind)
+		for _, s := range samples {
+			out.WriteString(name + s.suffix)
+			if len(s.labels) > 0 {
+				out.WriteByte('{')
+				for i, label := range s.labels {
+					if i > 0 {
+						out.WriteByte(',')
+					}
+					out.WriteString(label.name + `="` + escapeLabelValue(label.value) + `"`)
+				}
+				out.WriteByte('}')
+			}
+			out.WriteString(" " + formatFloat(s.value) + "\n")
+		}
+	}
+	if err := out.Flush(); err != nil {
+		return err
+	}
+	return errors.Join(errs...)
+}
+
+// Handler serves the registry's metrics. Collection errors are passed to
+// onError, which may be nil, and do not fail the scrape.
+func Handler(r *Registry, onError func(ctx context.Context, err error)) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		w.Header().Set("Content-Type", ContentType)
+		if err := r.WriteText(req.Context(), w); err != nil && onError != nil {
+			onError(req.Context(), err)
+		}
+	})
+}
+
+func formatFloat(v float64) string {
+	switch {
+	case math.IsInf(v, 1):
+		return "+Inf"
+	case math.IsInf(v, -1):
+		return "-Inf"
+	case math.IsNaN(v):
+		return "NaN"
+	}
+	return strconv.FormatFloat(v, 'g', -1, 64)
+}
+
+var (
+	helpEscaper       = strings.NewReplacer(`\`, `\\`, "\n", `\n`)
+	labelValueEscaper = strings.NewReplacer(`\`, `\\`, "\n", `\n`, `"`, `\"`)
+)
+
+func escapeHelp(help string) string {
+	return helpEscaper.Replace(help)
+}
+
+func escapeLabelValue(value string) string {
+	return labelValueEscaper.Replace(value)
+}
@@ -0,0 +1,349 @@
+// Package metrics records counters, gauges and histograms and writes them in
+// the Prometheus text exposition format, without depending on the Prometheus
+// client library.
+//
+// Metric and label names are fixed by the code that defines them, so invalid
+// or duplicate names panic when the metric is created.
+package metrics
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+)
+
+var (
+	metricNamePattern = regexp.MustCompile(`^[a-zA-Z_:][a-zA-Z0-9_:]*$`)
+	labelNamePattern  = regexp.MustCompile(`^[a-zA-Z_][a-zA-Z0-9_]*$`)
+)
+
+// DefaultBuckets suit latencies in seconds, from 5ms to 10s.
+var DefaultBuckets = []float64{0.005, 0.01, 0.025, 0.05, 0.1, 0.25, 0.5, 1, 2.5, 5, 10}
+
+// Registry holds every metric written by WriteText.
+type Registry struct {
+	families []family
+	names    map[string]bool
+	lock     sync.Mutex
+}
+
+func NewRegistry() *Registry {
+	return &Registry{names: map[string]bool{}}
+}
+
+// family is one metric name and all of its series.
+type family interface {
+	describe() (name, help, kind string)
+	// samples returns the family's lines without the HELP and TYPE header
+	samples(ctx context.Context) ([]sample, error)
+}
+
+type sample struct {
+	suffix string
+	labels []labelPair
+	value  float64
+}
+
+type labelPair struct {
+	name, value string
+}
+
+func (r *Registry) register(f family, labelNames []string) {
+	name, _, kind := f.describe()
+	if !metricNamePattern.MatchString(name) {
+		panic(fmt.Sprintf("metrics: invalid metric name %q", name))
+	}
+	for _, label := range labelNames {
+		if !labelNamePattern.MatchString(label) || strings.HasPrefix(label, "__") || (kind == "histogram" && label == "le") {
+			panic(fmt.Sprintf("metrics: invalid label name %q for %s", label, name))
+		}
+	}
+
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	if r.names[name] {
+		panic(fmt.Sprintf("metrics: %s is already registered", name))
+	}
+	r.names[name] = true
+	r.families = append(r.families, f)
+}
+
+// vec holds the series of a metric with labels, keyed by their label values.
+type vec[T any] struct {
+	labelNames []string
+	series     map[string]*T
+	values     map[string][]string
+	newSeries  func() *T
+	lock       sync.RWMutex
+}
+
+func newVec[T any](labelNames []string, newSeries func() *T) *vec[T] {
+	return &vec[T]{labelNames: labelNames, series: map[string]*T{}, values: map[string][]string{}, newSeries: newSeries}
+}
+
+func (v *vec[T]) with(labelValues []string) *T {
+	if len(labelValues) != len(v.labelNames) {
+		panic(fmt.Sprintf("metrics: expected %d label values, got %d", len(v.labelNames), len(labelValues)))
+	}
+	key := strings.Join(labelValues, "\xff")
+	v.lock.RLock()
+	series, ok := v.series[key]
+	v.lock.RUnlock()
+	if ok {
+		return series
+	}
+
+	v.lock.Lock()
+	defer v.lock.Unlock()
+	if series, ok := v.series[key]; ok {
+		return series
+	}
+	series = v.newSeries()
+	v.series[key] = series
+	v.values[key] = append([]string(nil), labelValues...)
+	return series
+}
+
+// each calls fn for every series in label value order, so output is stable.
+func (v *vec[T]) each(fn func(labels []labelPair, series *T)) {
+	v.lock.RLock()
+	keys := make([]string, 0, len(v.series))
+	for key := range v.series {
+		keys = append(keys, key)
+	}
+	v.lock.RUnlock()
+	sort.Strings(keys)
+
+	for _, key := range keys {
+		v.lock.RLock()
+		series, values := v.series[key], v.values[key]
+		v.lock.RUnlock()
+		fn(pairs(v.labelNames, values), series)
+	}
+}
+
+func pairs(names, values []string) []labelPair {
+	labels := make([]labelPair, len(names))
+	for i := range names {
+		labels[i] = labelPair{names[i], values[i]}
+	}
+	return labels
+}
+
+// value is a float64 guarded by a lock.
+type value struct {
+	v    float64
+	lock sync.Mutex
+}
+
+func (v *value) add(delta float64) {
+	v.lock.Lock()
+	v.v += delta
+	v.lock.Unlock()
+}
+
+func (v *value) set(to float64) {
+	v.lock.Lock()
+	v.v = to
+	v.lock.Unlock()
+}
+
+func (v *value) get() float64 {
+	v.lock.Lock()
+	defer v.lock.Unlock()
+	return v.v
+}
+
+type Counter struct {
+	value value
+}
+
+func (c *Counter) Inc() {
+	c.value.add(1)
+}
+
+// Add panics when delta is negative, counters only go up.
+func (c *Counter) Add(delta float64) {
+	if delta < 0 {
+		panic("metrics: counters cannot decrease")
+	}
+	c.value.add(delta)
+}
+
+type CounterVec struct {
+	name, help string
+	vec        *vec[Counter]
+}
+
+func (r *Registry) NewCounterVec(name, help string, labelNames ...string) *CounterVec {
+	c := &CounterVec{name: name, help: help, vec: newVec(labelNames, func() *Counter { return &Counter{} })}
+	r.register(c, labelNames)
+	return c
+}
+
+func (c *CounterVec) WithLabelValues(labelValues ...string) *Counter {
+	return c.vec.with(labelValues)
+}
+
+func (c *CounterVec) describe() (string, string, string) { return c.name, c.help, "counter" }
+
+func (c *CounterVec) samples(ctx context.Context) ([]sample, error) {
+	var samples []sample
+	c.vec.each(func(labels []labelPair, counter *Counter) {
+		samples = append(samples, sample{labels: labels, value: counter.value.get()})
+	})
+	return samples, nil
+}
+
+type Gauge struct {
+	value value
+}
+
+func (g *Gauge) Set(to float64)    { g.value.set(to) }
+func (g *Gauge) Add(delta float64) { g.value.add(delta) }
+func (g *Gauge) Inc()              { g.value.add(1) }
+func (g *Gauge) Dec()              { g.value.add(-1) }
+
+type GaugeVec struct {
+	name, help string
+	vec        *vec[Gauge]
+}
+
+func (r *Registry) NewGaugeVec(name, help string, labelNames ...string) *GaugeVec {
+	g := &GaugeVec{name: name, help: help, vec: newVec(labelNames, func() *Gauge { return &Gauge{} })}
+	r.register(g, labelNames)
+	return g
+}
+
+// NewGauge registers a gauge without labels.
+func (r *Registry) NewGauge(name, help string) *Gauge {
+	return r.NewGaugeVec(name, help).WithLabelValues()
+}
+
+func (g *GaugeVec) WithLabelValues(labelValues ...string) *Gauge {
+	return g.vec.with(labelValues)
+}
+
+func (g *GaugeVec) describe() (string, string, string) { return g.name, g.help, "gauge" }
+
+func (g *GaugeVec) samples(ctx context.Context) ([]sample, error) {
+	var samples []sample
+	g.vec.each(func(labels []labelPair, gauge *Gauge) {
+		samples = append(samples, sample{labels: labels, value: gauge.value.get()})
+	})
+	return samples, nil
+}
+
+// Sample is one value reported by a GaugeFunc.
+type Sample struct {
+	LabelValues []string
+	Value       float64
+}
+
+type gaugeFunc struct {
+	name, help string
+	labelNames []string
+	collect    func(ctx context.Context) ([]Sample, error)
+}
+
+// NewGaugeFunc registers gauges whose values are read by collect when the
+// metrics are written, for values such as row counts that are cheaper to read
+// on demand than to keep up to date.
+func (r *Registry) NewGaugeFunc(name, help string, labelNames []string, collect func(ctx context.Context) ([]Sample, error)) {
+	r.register(&gaugeFunc{name: name, help: help, labelNames: labelNames, collect: collect}, labelNames)
+}
+
+func (g *gaugeFunc) describe() (string, string, string) { return g.name, g.help, "gauge" }
+
+func (g *gaugeFunc) samples(ctx context.Context) ([]sample, error) {
+	collected, err := g.collect(ctx)
+	if err != nil {
+		return nil, err
+	}
+	sort.Slice(collected, func(i, j int) bool {
+		return strings.Join(collected[i].LabelValues, "\xff") < strings.Join(collected[j].LabelValues, "\xff")
+	})
+	samples := make([]sample, 0, len(collected))
+	for _, s := range collected {
+		if len(s.LabelValues) != len(g.labelNames) {
+			return nil, fmt.Errorf("expected %d label values, got %d", len(g.labelNames), len(s.LabelValues))
+		}
+		samples = append(samples, sample{labels: pairs(g.labelNames, s.LabelValues), value: s.Value})
+	}
+	return samples, nil
+}
+
+type Histogram struct {
+	upperBounds []float64
+	counts      []uint64
+	sum         float64
+	count       uint64
+	lock        sync.Mutex
+}
+
+func (h *Histogram) Observe(v float64) {
+	h.lock.Lock()
+	defer h.lock.Unlock()
+	// counts are per bucket here and made cumulative when written
+	i := sort.SearchFloat64s(h.upperBounds, v)
+	if i < len(h.counts) {
+		h.counts[i]++
+	}
+	h.sum += v
+	h.count++
+}
+
+type HistogramVec struct {
+	name, help string
+	vec        *vec[Histogram]
+}
+
+// NewHistogramVec registers a histogram, buckets are upper bounds in
+// increasing order and a +Inf bucket is always added.
+func (r *Registry) NewHistogramVec(name, help string, buckets []float64, labelNames ...string) *HistogramVec {
+	upperBounds := append([]float64(nil), buckets...)
+	if !sort.Float64sAreSorted(upperBounds) {
+		panic(fmt.Sprintf("metrics: buckets of %s are not in increasing order", name))
+	}
+	if n := len(upperBounds); n > 0 && math.IsInf(upperBounds[n-1], 1) {
+		upperBounds = upperBounds[:n-1]
+	}
+	h := &HistogramVec{name: name, help: help, vec: newVec(labelNames, func() *Histogram {
+		return &Histogram{upperBounds: upperBounds, counts: make([]uint64, len(upperBounds))}
+	})}
+	r.register(h, labelNames)
+	return h
+}
+
+func (h *HistogramVec) WithLabelValues(labelValues ...string) *Histogram {
+	return h.vec.with(labelValues)
+}
+
+func (h *HistogramVec) describe() (string, string, string) { return h.name, h.help, "histogram" }
+
+func (h *HistogramVec) samples(ctx context.Context) ([]sample, error) {
+	var samples []sample
+	h.vec.each(func(labels []labelPair, histogram *Histogram) {
+		histogram.lock.Lock()
+		var cumulative uint64
+		for i, bound := range histogram.upperBounds {
+			cumulative += histogram.counts[i]
+			samples = append(samples, sample{suffix: "_bucket", labels: withLabel(labels, "le", formatFloat(bound)), value: float64(cumulative)})
+		}
+		samples = append(samples,
+			sample{suffix: "_bucket", labels: withLabel(labels, "le", "+Inf"), value: float64(histogram.count)},
+			sample{suffix: "_sum", labels: labels, value: histogram.sum},
+			sample{suffix: "_count", labels: labels, value: float64(histogram.count)},
+		)
+		histogram.lock.Unlock()
+	})
+	return samples, nil
+}
+
+func withLabel(labels []labelPair, name, value string) []labelPair {
+	return append(append([]labelPair(nil), labels...), labelPair{name, value})
+}
@@ -0,0 +1,180 @@
+// Package ratelimit implements token bucket rate limiting with a bounded
+// number of tracked keys.
+package ratelimit
+
+import (
+	"container/list"
+	"errors"
+	"math"
+	"sync"
+	"time"
+
+	"github.com/olad5/sal-backend-service/pkg/clock"
+)
+
+// Rate allows Requests requests every Per, with bursts of up to Requests.
+type Rate struct {
+	Requests int
+	Per      time.Duration
+}
+
+func PerMinute(requests int) Rate {
+	return Rate{Requests: requests, Per: time.Minute}
+}
+
+// Enabled reports whether the rate limits anything. A zero rate disables
+// limiting.
+func (r Rate) Enabled() bool {
+	return r.Requests > 0 && r.Per > 0
+}
+
+type Result struct {
+	Allowed   bool
+	Limit     int
+	Remaining int
+	// Reset is how long until the bucket is full again.
+	Reset time.Duration
+	// RetryAfter is how long until the next request would be allowed, zero
+	// when Allowed.
+	RetryAfter time.Duration
+}
+
+type bucket struct {
+	key      string
+	tokens   float64
+	lastSeen time.Time
+}
+
+// Limiter tracks one token bucket per key. Buckets are kept in least
+// recently used order so that idle keys, and the oldest keys once maxKeys is
+// reached, can be dropped cheaply. Dropping an idle bucket loses nothing as
+// long as idleTimeout is at least as long as the slowest rate's period,
+// since the bucket would have refilled by then anyway.
+type Limiter struct {
+	clock       clock.Clock
+	maxKeys     int
+	idleTimeout time.Duration
+	buckets     map[string]*list.Element
+	recent      *list.List
+	lock        sync.Mutex
+}
+
+func NewLimiter(clock clock.Clock, maxKeys int, idleTimeout time.Duration) (*Limiter, error) {
+	if clock == nil {
+		return nil, errors.New("Limiter failed to initialize, clock is nil")
+	}
+	if maxKeys < 1 {
+		return nil, errors.New("Limiter failed to initialize, maxKeys must be at least 1")
+	}
+	if idleTimeout <= 0 {
+		return nil, errors.New("Limiter failed to initialize, idleTimeout must be greater than zero")
+	}
+	return &Limiter{
+		clock:       clock,
+		maxKeys:     maxKeys,
+		idleTimeout: idleTimeout,
+		buckets:     map[string]*list.Element{},
+		recent:      list.New(),
+	}, nil
+}
+
+// Allow takes a token from key's bucket if one is available.
+func (l *Limiter) Allow(key string, rate Rate) Result {
+	return l.AllowAll([]Bucket{{Key: key, Rate: rate}})[0]
+}
+
+// AllowAll takes a token from every bucket if each of them has one available
+// and from none of them otherwise, so a request refused by one bucket does not
+// use up the others. Each result reports whether its own bucket had a token,
+// in the order of buckets.
+func (l *Limiter) AllowAll(buckets []Bucket) []Result {
+	l.lock.Lock()
+	defer l.lock.Unlock()
+	now := l.clock.Now()
+	l.evict(now)
+
+	refilled := make([]*bucket, len(buckets))
+	allowed := true
+	for i, b := range buckets {
+		if !b.Rate.Enabled() {
+			continue
+		}
+		refilled[i] = l.refill(b.Key, b.Rate, now)
+		if refilled[i].tokens < 1 {
+			allowed = false
+		}
+	}
+
+	results := make([]Result, len(buckets))
+	for i, b := range refilled {
+		if b == nil {
+			results[i] = Result{Allowed: true}
+			continue
+		}
+		rate := buckets[i].Rate
+		capacity := float64(rate.Requests)
+		refillPerSecond := capacity / rate.Per.Seconds()
+		result := Result{Limit: rate.Requests, Allowed: b.tokens >= 1}
+		if !result.Allowed {
+			result.RetryAfter = secondsToDuration((1 - b.tokens) / refillPerSecond)
+		} else if allowed {
+			b.tokens--
+		}
+		result.Remaining = int(math.Floor(b.tokens))
+		result.Reset = secondsToDuration((capacity - b.tokens) / refillPerSecond)
+		results[i] = result
+	}
+	return results
+}
+
+// refill returns key's bucket topped up for the time since it was last seen,
+// creating a full one for a new key.
+func (l *Limiter) refill(key string, rate Rate, now time.Time) *bucket {
+	capacity := float64(rate.Requests)
+	element, ok := l.buckets[key]
+	if !ok {
+		b := &bucket{key: key, tokens: capacity, lastSeen: now}
+		l.buckets[key] = l.recent.PushFront(b)
+		for len(l.buckets) > l.maxKeys {
+			l.removeOldest()
+		}
+		return b
+	}
+	b := element.Value.(*bucket)
+	if elapsed := now.Sub(b.lastSeen).Seconds(); elapsed > 0 {
+		b.tokens = math.Min(capacity, b.tokens+elapsed*capacity/rate.Per.Seconds())
+	}
+	b.lastSeen = now
+	l.recent.MoveToFront(element)
+	return b
+}
+
+// Len returns the number of keys currently tracked.
+func (l *Limiter) Len() int {
+	l.lock.Lock()
+	defer l.lock.Unlock()
+	return len(l.buckets)
+}
+
+func (l *Limiter) evict(now time.Time) {
+	for {
+		oldest := l.recent.Back()
+		if oldest == nil || now.Sub(oldest.Value.(*bucket).lastSeen) < l.idleTimeout {
+			return
+		}
+		l.removeOldest()
+	}
+}
+
+func (l *Limiter) removeOldest() {
+	oldest := l.recent.Back()
+	if oldest == nil {
+		return
+	}
+	l.recent.Remove(oldest)
+	delete(l.buckets, oldest.Value.(*bucket).key)
+}
+
+func secondsToDuration(seconds float64) time.Duration {
+	return time.Duration(math.Ceil(seconds * float64(time.Second)))
+}
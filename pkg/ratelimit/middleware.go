@@ -0,0 +1,59 @@
+package ratelimit
+
+import (
+	"math"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/olad5/sal-backend-service/pkg/utils"
+)
+
+// Bucket is one limit a request counts against.
+type Bucket struct {
+	Key  string
+	Rate Rate
+}
+
+// Policy returns the buckets a request counts against. A request is only
+// let through, and only charged, if every bucket has a token to spare.
+type Policy func(r *http.Request) []Bucket
+
+// Middleware rate limits requests according to policy. Responses carry
+// RateLimit-Limit, RateLimit-Remaining and RateLimit-Reset headers for the
+// most restrictive bucket, and rejected requests get a 429 with Retry-After.
+func Middleware(limiter *Limiter, policy Policy) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			buckets := policy(r)
+			var tightest *Result
+			for i, result := range limiter.AllowAll(buckets) {
+				if !buckets[i].Rate.Enabled() {
+					continue
+				}
+				if tightest == nil || (tightest.Allowed && (!result.Allowed || result.Remaining < tightest.Remaining)) {
+					result := result
+					tightest = &result
+				}
+			}
+			if tightest == nil {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			w.Header().Set("RateLimit-Limit", strconv.Itoa(tightest.Limit))
+			w.Header().Set("RateLimit-Remaining", strconv.Itoa(tightest.Remaining))
+			w.Header().Set("RateLimit-Reset", strconv.Itoa(ceilSeconds(tightest.Reset)))
+			if !tightest.Allowed {
+				w.Header().Set("Retry-After", strconv.Itoa(ceilSeconds(tightest.RetryAfter)))
+				utils.ErrorResponse(w, "rate limit exceeded", http.StatusTooManyRequests)
+				return
+			}
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+func ceilSeconds(d time.Duration) int {
+	return int(math.Ceil(d.Seconds()))
+}
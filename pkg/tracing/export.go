@@ -0,0 +1,146 @@
+package tracing
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"sync"
+	"time"
+)
+
+// Exporter sends finished spans to where they are stored. Tracer calls it as
+// each span ends, so exporters that do network calls should be wrapped in a
+// BatchExporter.
+type Exporter interface {
+	ExportSpans(ctx context.Context, spans []SpanData) error
+}
+
+// JSONExporter writes each span as a line of JSON, to stdout or a file.
+type JSONExporter struct {
+	lock    sync.Mutex
+	encoder *json.Encoder
+}
+
+func NewJSONExporter(w io.Writer) *JSONExporter {
+	return &JSONExporter{encoder: json.NewEncoder(w)}
+}
+
+func (e *JSONExporter) ExportSpans(ctx context.Context, spans []SpanData) error {
+	e.lock.Lock()
+	defer e.lock.Unlock()
+	for _, span := range spans {
+		if err := e.encoder.Encode(span); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+var ErrSpanQueueFull = errors.New("span queue is full, spans were dropped")
+
+// BatchExporter queues spans and hands them to another exporter in batches,
+// keeping slow exports off the request path. Spans beyond maxQueue are
+// dropped rather than holding up requests.
+type BatchExporter struct {
+	exporter Exporter
+	maxBatch int
+	maxQueue int
+	interval time.Duration
+	onError  func(err error)
+
+	lock  sync.Mutex
+	queue []SpanData
+	full  chan struct{}
+}
+
+// NewBatchExporter starts a batch when maxBatch spans are queued or interval
+// has passed, once Run is running. onError is called with failed exports and
+// may be nil.
+func NewBatchExporter(exporter Exporter, maxBatch, maxQueue int, interval time.Duration, onError func(err error)) (*BatchExporter, error) {
+	if exporter == nil {
+		return nil, errors.New("BatchExporter failed to initialize, exporter is nil")
+	}
+	if maxBatch < 1 || maxQueue < maxBatch {
+		return nil, errors.New("BatchExporter failed to initialize, maxQueue must be at least maxBatch, which must be positive")
+	}
+	if interval <= 0 {
+		return nil, errors.New("BatchExporter failed to initialize, interval must be positive")
+	}
+	return &BatchExporter{
+		exporter: exporter,
+		maxBatch: maxBatch,
+		maxQueue: maxQueue,
+		interval: interval,
+		onError:  onError,
+		full:     make(chan struct{}, 1),
+	}, nil
+}
+
+func (b *BatchExporter) ExportSpans(ctx context.Context, spans []SpanData) error {
+	b.lock.Lock()
+	defer b.lock.Unlock()
+	room := b.maxQueue - len(b.queue)
+	dropped := len(spans) > room
+	if dropped {
+		spans = spans[:room]
+	}
+	b.queue = append(b.queue, spans...)
+	if len(b.queue) >= b.maxBatch {
+		select {
+		case b.full <- struct{}{}:
+		default:
+		}
+	}
+	if dropped {
+		return ErrSpanQueueFull
+	}
+	return nil
+}
+
+// Run exports batches until ctx is done, then exports what is left.
+func (b *BatchExporter) Run(ctx context.Context) {
+	ticker := time.NewTicker(b.interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			// ctx is already done, give the last export a context of its own
+			flushCtx, cancel := context.WithTimeout(context.Background(), b.interval)
+			b.report(b.Flush(flushCtx))
+			cancel()
+			return
+		case <-ticker.C:
+		case <-b.full:
+		}
+		b.report(b.Flush(ctx))
+	}
+}
+
+// Flush exports every queued span.
+func (b *BatchExporter) Flush(ctx context.Context) error {
+	for {
+		b.lock.Lock()
+		if len(b.queue) == 0 {
+			b.lock.Unlock()
+			return nil
+		}
+		size := b.maxBatch
+		if size > len(b.queue) {
+			size = len(b.queue)
+		}
+		batch := b.queue[:size:size]
+		b.queue = b.queue[size:]
+		b.lock.Unlock()
+
+		if err := b.exporter.ExportSpans(ctx, batch); err != nil {
+			return err
+		}
+	}
+}
+
+func (b *BatchExporter) report(err error) {
+	if err != nil && b.onError != nil {
+		b.onError(err)
+	}
+}
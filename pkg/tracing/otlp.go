@@ -0,0 +1,213 @@
+package tracing
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+	"strconv"
+)
+
+// OTLPTraceRequest is an OTLP ExportTraceServiceRequest in its JSON
+// encoding, see
+// https://opentelemetry.io/docs/specs/otlp/#json-protobuf-encoding
+type OTLPTraceRequest struct {
+	ResourceSpans []OTLPResourceSpans `json:"resourceSpans"`
+}
+
+type OTLPResourceSpans struct {
+	Resource   OTLPResource     `json:"resource"`
+	ScopeSpans []OTLPScopeSpans `json:"scopeSpans"`
+}
+
+type OTLPResource struct {
+	Attributes []OTLPKeyValue `json:"attributes"`
+}
+
+type OTLPScopeSpans struct {
+	Scope OTLPScope  `json:"scope"`
+	Spans []OTLPSpan `json:"spans"`
+}
+
+type OTLPScope struct {
+	Name string `json:"name"`
+}
+
+type OTLPSpan struct {
+	TraceID           string         `json:"traceId"`
+	SpanID            string         `json:"spanId"`
+	ParentSpanID      string         `json:"parentSpanId,omitempty"`
+	Name              string         `json:"name"`
+	Kind              int            `json:"kind"`
+	StartTimeUnixNano string         `json:"startTimeUnixNano"`
+	EndTimeUnixNano   string         `json:"endTimeUnixNano"`
+	Attributes        []OTLPKeyValue `json:"attributes,omitempty"`
+	Status            OTLPStatus     `json:"status"`
+}
+
+type OTLPKeyValue struct {
+	Key   string       `json:"key"`
+	Value OTLPAnyValue `json:"value"`
+}
+
+// OTLPAnyValue holds one of its fields. 64 bit integers are strings in the
+// JSON encoding.
+type OTLPAnyValue struct {
+	StringValue *string  `json:"stringValue,omitempty"`
+	BoolValue   *bool    `json:"boolValue,omitempty"`
+	IntValue    *string  `json:"intValue,omitempty"`
+	DoubleValue *float64 `json:"doubleValue,omitempty"`
+}
+
+type OTLPStatus struct {
+	Code    int    `json:"code,omitempty"`
+	Message string `json:"message,omitempty"`
+}
+
+// span kinds and status codes from the OTLP protobuf enums
+const (
+	otlpSpanKindInternal = 1
+	otlpSpanKindServer   = 2
+	otlpStatusCodeError  = 2
+)
+
+// OTLPClient sends trace requests to an OTLP collector, over HTTP, gRPC or
+// anything else that carries them.
+type OTLPClient interface {
+	UploadTraces(ctx context.Context, request OTLPTraceRequest) error
+}
+
+// OTLPExporter converts spans to OTLP and hands them to a client.
+type OTLPExporter struct {
+	client OTLPClient
+	scope  string
+}
+
+// NewOTLPExporter exports spans through client, naming scope as the
+// instrumentation that recorded them.
+func NewOTLPExporter(client OTLPClient, scope string) (*OTLPExporter, error) {
+	if client == nil {
+		return nil, errors.New("OTLPExporter failed to initialize, client is nil")
+	}
+	return &OTLPExporter{client, scope}, nil
+}
+
+func (e *OTLPExporter) ExportSpans(ctx context.Context, spans []SpanData) error {
+	if len(spans) == 0 {
+		return nil
+	}
+	return e.client.UploadTraces(ctx, e.request(spans))
+}
+
+// request groups spans by the service that recorded them, which OTLP carries
+// as a resource attribute.
+func (e *OTLPExporter) request(spans []SpanData) OTLPTraceRequest {
+	byService := map[string][]OTLPSpan{}
+	var services []string
+	for _, span := range spans {
+		if _, ok := byService[span.Service]; !ok {
+			services = append(services, span.Service)
+		}
+		byService[span.Service] = append(byService[span.Service], toOTLPSpan(span))
+	}
+
+	request := OTLPTraceRequest{ResourceSpans: []OTLPResourceSpans{}}
+	for _, service := range services {
+		request.ResourceSpans = append(request.ResourceSpans, OTLPResourceSpans{
+			Resource:   OTLPResource{Attributes: []OTLPKeyValue{{Key: "service.name", Value: toOTLPValue(service)}}},
+			ScopeSpans: []OTLPScopeSpans{{Scope: OTLPScope{Name: e.scope}, Spans: byService[service]}},
+		})
+	}
+	return request
+}
+
+func toOTLPSpan(span SpanData) OTLPSpan {
+	kind := otlpSpanKindInternal
+	if span.Kind == SpanKindServer {
+		kind = otlpSpanKindServer
+	}
+	otlpSpan := OTLPSpan{
+		TraceID:           span.TraceID,
+		SpanID:            span.SpanID,
+		ParentSpanID:      span.ParentSpanID,
+		Name:              span.Name,
+		Kind:              kind,
+		StartTimeUnixNano: strconv.FormatInt(span.StartTime.UnixNano(), 10),
+		EndTimeUnixNano:   strconv.FormatInt(span.EndTime.UnixNano(), 10),
+	}
+	if span.Status == StatusError {
+		otlpSpan.Status = OTLPStatus{Code: otlpStatusCodeError, Message: span.StatusMessage}
+	}
+
+	keys := make([]string, 0, len(span.Attributes))
+	for key := range span.Attributes {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		otlpSpan.Attributes = append(otlpSpan.Attributes, OTLPKeyValue{Key: key, Value: toOTLPValue(span.Attributes[key])})
+	}
+	return otlpSpan
+}
+
+func toOTLPValue(value interface{}) OTLPAnyValue {
+	switch v := value.(type) {
+	case string:
+		return OTLPAnyValue{StringValue: &v}
+	case bool:
+		return OTLPAnyValue{BoolValue: &v}
+	case int:
+		s := strconv.Itoa(v)
+		return OTLPAnyValue{IntValue: &s}
+	case int64:
+		s := strconv.FormatInt(v, 10)
+		return OTLPAnyValue{IntValue: &s}
+	case float64:
+		return OTLPAnyValue{DoubleValue: &v}
+	}
+	s := fmt.Sprint(value)
+	return OTLPAnyValue{StringValue: &s}
+}
+
+// OTLPHTTPClient posts trace requests as JSON to an OTLP/HTTP endpoint, such
+// as a collector's http://localhost:4318/v1/traces.
+type OTLPHTTPClient struct {
+	endpoint   string
+	httpClient *http.Client
+}
+
+func NewOTLPHTTPClient(endpoint string, httpClient *http.Client) (*OTLPHTTPClient, error) {
+	if endpoint == "" {
+		return nil, errors.New("OTLPHTTPClient failed to initialize, endpoint is empty")
+	}
+	if httpClient == nil {
+		return nil, errors.New("OTLPHTTPClient failed to initialize, httpClient is nil")
+	}
+	return &OTLPHTTPClient{endpoint, httpClient}, nil
+}
+
+func (c *OTLPHTTPClient) UploadTraces(ctx context.Context, request OTLPTraceRequest) error {
+	body, err := json.Marshal(request)
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, c.endpoint, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	response, err := c.httpClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer response.Body.Close()
+	_, _ = io.Copy(io.Discard, response.Body)
+	if response.StatusCode < 200 || response.StatusCode > 299 {
+		return fmt.Errorf("OTLP collector responded with %s", response.Status)
+	}
+	return nil
+}
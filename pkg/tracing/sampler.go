@@ -0,0 +1,37 @@
+package tracing
+
+import (
+	"encoding/binary"
+	"math"
+)
+
+// Sampler decides whether a new trace is recorded. Spans in a trace that was
+// started elsewhere follow the caller's decision instead.
+type Sampler interface {
+	ShouldSample(traceID TraceID) bool
+}
+
+type ratioSampler struct {
+	threshold uint64
+}
+
+// TraceIDRatio samples about ratio of new traces, 0 records none and 1 all.
+// The decision is taken from the trace ID, so it is the same in every
+// process that sees the trace.
+func TraceIDRatio(ratio float64) Sampler {
+	switch {
+	case ratio >= 1:
+		return ratioSampler{math.MaxUint64}
+	case ratio <= 0:
+		return ratioSampler{0}
+	}
+	return ratioSampler{uint64(ratio * math.MaxUint64)}
+}
+
+func (s ratioSampler) ShouldSample(traceID TraceID) bool {
+	if s.threshold == math.MaxUint64 {
+		return true
+	}
+	// the low 8 bytes are the random part of W3C trace IDs
+	return binary.BigEndian.Uint64(traceID[8:]) < s.threshold
+}
@@ -0,0 +1,305 @@
+// Package tracing records spans for the work done while serving a request and
+// carries them between services in W3C traceparent headers.
+//
+// A Tracer starts the root span of a request, Start starts child spans from
+// the span in a context, so code below the HTTP layer needs no tracer of its
+// own and traces nothing when called outside a request.
+package tracing
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/olad5/sal-backend-service/pkg/clock"
+)
+
+// TraceparentHeader carries the caller's span, see
+// https://www.w3.org/TR/trace-context/#traceparent-header
+const TraceparentHeader = "traceparent"
+
+type TraceID [16]byte
+
+func (id TraceID) String() string { return hex.EncodeToString(id[:]) }
+func (id TraceID) IsValid() bool  { return id != TraceID{} }
+
+type SpanID [8]byte
+
+func (id SpanID) String() string { return hex.EncodeToString(id[:]) }
+func (id SpanID) IsValid() bool  { return id != SpanID{} }
+
+// SpanContext identifies a span across process boundaries.
+type SpanContext struct {
+	TraceID TraceID
+	SpanID  SpanID
+	// Sampled spans are exported, the decision is made once at the root of a
+	// trace and followed by every span in it
+	Sampled bool
+}
+
+func (sc SpanContext) IsValid() bool {
+	return sc.TraceID.IsValid() && sc.SpanID.IsValid()
+}
+
+// Traceparent formats sc as a traceparent header value.
+func (sc SpanContext) Traceparent() string {
+	flags := "00"
+	if sc.Sampled {
+		flags = "01"
+	}
+	return "00-" + sc.TraceID.String() + "-" + sc.SpanID.String() + "-" + flags
+}
+
+var ErrInvalidTraceparent = errors.New("invalid traceparent")
+
+// ParseTraceparent reads a traceparent header value. Versions after 00 are
+// read as 00, as the specification asks, ignoring any fields they add.
+func ParseTraceparent(value string) (SpanContext, error) {
+	parts := strings.Split(strings.TrimSpace(value), "-")
+	if len(parts) < 4 || len(parts[0]) != 2 || len(parts[1]) != 32 || len(parts[2]) != 16 || len(parts[3]) != 2 {
+		return SpanContext{}, ErrInvalidTraceparent
+	}
+	version, err := hex.DecodeString(parts[0])
+	if err != nil || version[0] == 0xff || (version[0] == 0 && len(parts) != 4) {
+		return SpanContext{}, ErrInvalidTraceparent
+	}
+	var sc SpanContext
+	// upper case hex is not allowed
+	if strings.ToLower(value) != value {
+		return SpanContext{}, ErrInvalidTraceparent
+	}
+	if _, err := hex.Decode(sc.TraceID[:], []byte(parts[1])); err != nil {
+		return SpanContext{}, ErrInvalidTraceparent
+	}
+	if _, err := hex.Decode(sc.SpanID[:], []byte(parts[2])); err != nil {
+		return SpanContext{}, ErrInvalidTraceparent
+	}
+	flags, err := hex.DecodeString(parts[3])
+	if err != nil || !sc.IsValid() {
+		return SpanContext{}, ErrInvalidTraceparent
+	}
+	sc.Sampled = flags[0]&1 == 1
+	return sc, nil
+}
+
+type SpanKind string
+
+const (
+	SpanKindInternal SpanKind = "internal"
+	SpanKindServer   SpanKind = "server"
+)
+
+type StatusCode string
+
+const (
+	StatusUnset StatusCode = "unset"
+	StatusError StatusCode = "error"
+)
+
+// SpanData is a finished span, as handed to exporters.
+type SpanData struct {
+	Service       string                 `json:"service"`
+	TraceID       string                 `json:"trace_id"`
+	SpanID        string                 `json:"span_id"`
+	ParentSpanID  string                 `json:"parent_span_id,omitempty"`
+	Name          string                 `json:"name"`
+	Kind          SpanKind               `json:"kind"`
+	StartTime     time.Time              `json:"start_time"`
+	EndTime       time.Time              `json:"end_time"`
+	Attributes    map[string]interface{} `json:"attributes,omitempty"`
+	Status        StatusCode             `json:"status"`
+	StatusMessage string                 `json:"status_message,omitempty"`
+}
+
+// Span is an operation being timed. Spans that are not sampled only carry
+// their SpanContext, so it can still be propagated. A nil *Span does nothing.
+type Span struct {
+	tracer  *Tracer
+	context SpanContext
+
+	lock  sync.Mutex
+	data  SpanData
+	ended bool
+}
+
+func (s *Span) SpanContext() SpanContext {
+	if s == nil {
+		return SpanContext{}
+	}
+	return s.context
+}
+
+func (s *Span) recording() bool {
+	return s != nil && s.context.Sampled
+}
+
+// SetName renames the span, for names only known once the work is done, such
+// as the route a request matched.
+func (s *Span) SetName(name string) {
+	if !s.recording() {
+		return
+	}
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	s.data.Name = name
+}
+
+// SetAttribute records a string, bool, integer or float value on the span.
+func (s *Span) SetAttribute(key string, value interface{}) {
+	if !s.recording() {
+		return
+	}
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	if s.data.Attributes == nil {
+		s.data.Attributes = map[string]interface{}{}
+	}
+	s.data.Attributes[key] = value
+}
+
+// RecordError marks the span as failed, a nil err is ignored.
+func (s *Span) RecordError(err error) {
+	if err == nil || !s.recording() {
+		return
+	}
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	s.data.Status = StatusError
+	s.data.StatusMessage = err.Error()
+}
+
+// End finishes the span and exports it, later calls do nothing.
+func (s *Span) End() {
+	if !s.recording() {
+		return
+	}
+	s.lock.Lock()
+	if s.ended {
+		s.lock.Unlock()
+		return
+	}
+	s.ended = true
+	s.data.EndTime = s.tracer.clock.Now()
+	data := s.data
+	s.lock.Unlock()
+
+	if err := s.tracer.exporter.ExportSpans(context.Background(), []SpanData{data}); err != nil && s.tracer.onError != nil {
+		s.tracer.onError(fmt.Errorf("failed to export span %s: %w", data.Name, err))
+	}
+}
+
+// Tracer starts spans and hands them to its exporter when they end.
+type Tracer struct {
+	service  string
+	exporter Exporter
+	sampler  Sampler
+	clock    clock.Clock
+	onError  func(err error)
+}
+
+// NewTracer builds a tracer for service. onError is called with spans that
+// failed to export and may be nil.
+func NewTracer(service string, exporter Exporter, sampler Sampler, clock clock.Clock, onError func(err error)) (*Tracer, error) {
+	if exporter == nil {
+		return nil, errors.New("Tracer failed to initialize, exporter is nil")
+	}
+	if sampler == nil {
+		return nil, errors.New("Tracer failed to initialize, sampler is nil")
+	}
+	if clock == nil {
+		return nil, errors.New("Tracer failed to initialize, clock is nil")
+	}
+	return &Tracer{service, exporter, sampler, clock, onError}, nil
+}
+
+// Start starts a span as a child of the span in ctx, local or remote. Spans
+// without a parent start a new trace, which the sampler decides on.
+func (t *Tracer) Start(ctx context.Context, name string, kind SpanKind) (context.Context, *Span) {
+	parent := SpanContextFromContext(ctx)
+	sc := SpanContext{SpanID: newSpanID()}
+	if parent.IsValid() {
+		sc.TraceID = parent.TraceID
+		sc.Sampled = parent.Sampled
+	} else {
+		sc.TraceID = newTraceID()
+		sc.Sampled = t.sampler.ShouldSample(sc.TraceID)
+	}
+
+	span := &Span{tracer: t, context: sc}
+	if sc.Sampled {
+		span.data = SpanData{
+			Service:   t.service,
+			TraceID:   sc.TraceID.String(),
+			SpanID:    sc.SpanID.String(),
+			Name:      name,
+			Kind:      kind,
+			StartTime: t.clock.Now(),
+			Status:    StatusUnset,
+		}
+		if parent.IsValid() {
+			span.data.ParentSpanID = parent.SpanID.String()
+		}
+	}
+	return ContextWithSpan(ctx, span), span
+}
+
+// Start starts an internal span as a child of the span in ctx. Without one
+// it returns ctx and a nil span, so callers can always defer span.End().
+func Start(ctx context.Context, name string) (context.Context, *Span) {
+	parent := SpanFromContext(ctx)
+	if parent == nil {
+		return ctx, nil
+	}
+	return parent.tracer.Start(ctx, name, SpanKindInternal)
+}
+
+type spanKey struct{}
+type remoteSpanContextKey struct{}
+
+func ContextWithSpan(ctx context.Context, span *Span) context.Context {
+	return context.WithValue(ctx, spanKey{}, span)
+}
+
+// SpanFromContext returns the span started in this process that ctx carries,
+// or nil.
+func SpanFromContext(ctx context.Context) *Span {
+	span, _ := ctx.Value(spanKey{}).(*Span)
+	return span
+}
+
+// ContextWithRemoteSpanContext records the caller's span, read from its
+// traceparent, as the parent of the spans started from ctx.
+func ContextWithRemoteSpanContext(ctx context.Context, sc SpanContext) context.Context {
+	return context.WithValue(ctx, remoteSpanContextKey{}, sc)
+}
+
+// SpanContextFromContext returns the current span's context, preferring a
+// span started in this process over the caller's.
+func SpanContextFromContext(ctx context.Context) SpanContext {
+	if span := SpanFromContext(ctx); span != nil {
+		return span.SpanContext()
+	}
+	sc, _ := ctx.Value(remoteSpanContextKey{}).(SpanContext)
+	return sc
+}
+
+func newTraceID() TraceID {
+	var id TraceID
+	for !id.IsValid() {
+		_, _ = rand.Read(id[:])
+	}
+	return id
+}
+
+func newSpanID() SpanID {
+	var id SpanID
+	for !id.IsValid() {
+		_, _ = rand.Read(id[:])
+	}
+	return id
+}
@@ -2,7 +2,7 @@ package utils
 
 import (
 	"encoding/json"
-	"log"
+	"log/slog"
 	"net/http"
 )
 
@@ -18,7 +18,7 @@ func SuccessResponse(w http.ResponseWriter, message string, data interface{}) {
 		Message: message,
 		Data:    data,
 	}); err != nil {
-		log.Printf("Error sending response: %v", err)
+		slog.Warn("failed to send response", "error", err)
 	}
 }
 
@@ -29,6 +29,6 @@ func ErrorResponse(w http.ResponseWriter, message string, statusCode int) {
 	}
 	w.WriteHeader(statusCode)
 	if err := json.NewEncoder(w).Encode(SuccessResponse{Status: false, Message: message}); err != nil {
-		log.Printf("Error sending response: %v", err)
+		slog.Warn("failed to send response", "error", err)
 	}
 }
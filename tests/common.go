@@ -8,7 +8,9 @@ import (
 )
 
 func ExecuteRequest(req *http.Request, r http.Handler) *httptest.ResponseRecorder {
-	req.Header.Set("Content-Type", "application/json")
+	if req.Header.Get("Content-Type") == "" {
+		req.Header.Set("Content-Type", "application/json")
+	}
 
 	rr := httptest.NewRecorder()
 	r.ServeHTTP(rr, req)
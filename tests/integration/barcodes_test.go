@@ -0,0 +1,115 @@
+//go:build integration
+// +build integration
+
+package integration
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/olad5/sal-backend-service/pkg/client"
+)
+
+func TestProductBarcodes(t *testing.T) {
+	ctx := context.Background()
+	t.Run(`Given a merchant creates a product with a GTIN and an ISBN-10,
+    when they make a POST request to the create product endpoint,
+    then the GTIN should be stored without separators and the ISBN as an ISBN-13. `,
+		func(t *testing.T) {
+			product, err := createProductWithBarcodes(uuid.New(), "4006381-333931", "0-306-40615-2")
+			if err != nil {
+				t.Fatal(err)
+			}
+			if product.GTIN != "4006381333931" || product.ISBN != "9780306406157" {
+				t.Fatalf("expected gtin 4006381333931 and isbn 9780306406157, got %v and %v", product.GTIN, product.ISBN)
+			}
+		},
+	)
+
+	t.Run(`Given a barcode with a wrong check digit,
+    when the merchant creates a product with it,
+    then the API should return a bad request error. `,
+		func(t *testing.T) {
+			for _, barcodes := range [][2]string{{"4006381333932", ""}, {"", "0306406153"}, {"12345", ""}} {
+				if _, err := createProductWithBarcodes(uuid.New(), barcodes[0], barcodes[1]); !errors.Is(err, client.ErrBadRequest) {
+					t.Fatalf("%v: expected a bad request error, got %v", barcodes, err)
+				}
+			}
+		},
+	)
+
+	t.Run(`Given a product with a UPC-A,
+    when the same merchant creates a product with the same number as a GTIN-13,
+    then the API should return a conflict error. `,
+		func(t *testing.T) {
+			merchantId := uuid.New()
+			if _, err := createProductWithBarcodes(merchantId, "036000291452", ""); err != nil {
+				t.Fatal(err)
+			}
+			if _, err := createProductWithBarcodes(merchantId, "0036000291452", ""); !errors.Is(err, client.ErrBarcodeTaken) {
+				t.Fatalf("expected %v, got %v", client.ErrBarcodeTaken, err)
+			}
+			if _, err := createProductWithBarcodes(uuid.New(), "036000291452", ""); err != nil {
+				t.Fatal(err)
+			}
+		},
+	)
+
+	t.Run(`Given products with barcodes,
+    when a scanner looks them up on the merchant listing by gtin or isbn,
+    then only the matching product should be returned. `,
+		func(t *testing.T) {
+			merchantId := uuid.New()
+			gtinProduct, err := createProductWithBarcodes(merchantId, "96385074", "")
+			if err != nil {
+				t.Fatal(err)
+			}
+			isbnProduct, err := createProductWithBarcodes(merchantId, "", "9780306406157")
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			for _, lookup := range []struct {
+				opts     client.ListProductsOptions
+				expected uuid.UUID
+			}{
+				{client.ListProductsOptions{GTIN: "96385074"}, gtinProduct.SKUID},
+				{client.ListProductsOptions{GTIN: "00000096385074"}, gtinProduct.SKUID},
+				{client.ListProductsOptions{GTIN: "9780306406157"}, isbnProduct.SKUID},
+				{client.ListProductsOptions{ISBN: "0-306-40615-2"}, isbnProduct.SKUID},
+				{client.ListProductsOptions{GTIN: "4006381333931"}, uuid.Nil},
+			} {
+				page, err := c.ListProducts(ctx, merchantId, lookup.opts)
+				if err != nil {
+					t.Fatal(err)
+				}
+				if lookup.expected == uuid.Nil {
+					if len(page.Products) != 0 {
+						t.Fatalf("%+v: expected no products, got %d", lookup.opts, len(page.Products))
+					}
+					continue
+				}
+				if len(page.Products) != 1 || page.Products[0].SKUID != lookup.expected {
+					t.Fatalf("%+v: expected only product %v, got %v", lookup.opts, lookup.expected, page.Products)
+				}
+			}
+
+			if _, err := c.GetProductByBarcode(ctx, merchantId, "123"); !errors.Is(err, client.ErrBadRequest) {
+				t.Fatalf("expected a bad request error, got %v", err)
+			}
+		},
+	)
+}
+
+func createProductWithBarcodes(merchantId uuid.UUID, gtin, isbn string) (client.Product, error) {
+	return c.CreateProduct(context.Background(), client.CreateProductRequest{
+		MerchantId:  merchantId,
+		GTIN:        gtin,
+		ISBN:        isbn,
+		Name:        "some-product-name" + uuid.New().String(),
+		Description: "some-product-description",
+		Price:       10,
+	})
+}
@@ -0,0 +1,112 @@
+//go:build integration
+// +build integration
+
+package integration
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/olad5/sal-backend-service/pkg/client"
+)
+
+func TestClient(t *testing.T) {
+	ctx := context.Background()
+	t.Run(`Given a server that fails the first request with a 503,
+    when the client creates a product,
+    then it should retry with the same Idempotency-Key and succeed. `,
+		func(t *testing.T) {
+			var mu sync.Mutex
+			var keys []string
+			flaky := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+				mu.Lock()
+				keys = append(keys, req.Header.Get("Idempotency-Key"))
+				attempt := len(keys)
+				mu.Unlock()
+				if attempt == 1 {
+					w.WriteHeader(http.StatusServiceUnavailable)
+					return
+				}
+				r.ServeHTTP(w, req)
+			}))
+			defer flaky.Close()
+
+			flakyClient, err := client.New(flaky.URL, client.WithRetryPolicy(client.RetryPolicy{MaxAttempts: 2, MinBackoff: time.Millisecond, MaxBackoff: time.Millisecond}))
+			if err != nil {
+				t.Fatal(err)
+			}
+			merchantId := uuid.New()
+			product, err := flakyClient.CreateProduct(ctx, buildProduct(merchantId, uuid.New()))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if product.MerchantId != merchantId {
+				t.Fatalf("expected merchant %v, got %v", merchantId, product.MerchantId)
+			}
+			if len(keys) != 2 || keys[0] == "" || keys[0] != keys[1] {
+				t.Fatalf("expected two attempts with the same Idempotency-Key, got %q", keys)
+			}
+		},
+	)
+
+	t.Run(`Given a server that keeps returning 429 with Retry-After,
+    when the client has no attempts left,
+    then it should return a rate limited error with the Retry-After. `,
+		func(t *testing.T) {
+			limited := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+				w.Header().Set("Retry-After", "30")
+				w.WriteHeader(http.StatusTooManyRequests)
+			}))
+			defer limited.Close()
+
+			limitedClient, err := client.New(limited.URL, client.WithRetryPolicy(client.RetryPolicy{MaxAttempts: 1}))
+			if err != nil {
+				t.Fatal(err)
+			}
+			_, err = limitedClient.ListPlans(ctx)
+			var apiErr *client.Error
+			if !errors.Is(err, client.ErrRateLimited) || !errors.As(err, &apiErr) {
+				t.Fatalf("expected a rate limited error, got %v", err)
+			}
+			if apiErr.RetryAfter != 30*time.Second {
+				t.Fatalf("expected Retry-After of 30s, got %v", apiErr.RetryAfter)
+			}
+		},
+	)
+
+	t.Run(`Given a merchant with more matching products than fit on one page,
+    when the search iterator is walked,
+    then every product should be returned exactly once. `,
+		func(t *testing.T) {
+			merchantId := uuid.New()
+			created := map[uuid.UUID]bool{}
+			for i := 0; i < 5; i++ {
+				prd := buildProduct(merchantId, uuid.New())
+				prd.Name = "Wool Scarf " + uuid.NewString()
+				created[createProduct(t, prd)] = true
+			}
+
+			it := c.SearchProductsIter(merchantId, "scarf", client.SearchOptions{Limit: 2})
+			seen := map[uuid.UUID]bool{}
+			for it.Next(ctx) {
+				skuId := it.Result().Product.SKUID
+				if seen[skuId] || !created[skuId] {
+					t.Fatalf("unexpected or repeated result %v", skuId)
+				}
+				seen[skuId] = true
+			}
+			if err := it.Err(); err != nil {
+				t.Fatal(err)
+			}
+			if len(seen) != len(created) || it.Total() != len(created) {
+				t.Fatalf("expected %d results, got %d with total %d", len(created), len(seen), it.Total())
+			}
+		},
+	)
+}
@@ -0,0 +1,141 @@
+//go:build integration
+// +build integration
+
+package integration
+
+import (
+	"bytes"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/olad5/sal-backend-service/internal/config"
+)
+
+func TestConfig(t *testing.T) {
+	t.Run(`Given a value set in the config file, the environment and a flag,
+    when the configuration is loaded,
+    then flags should override the environment, which overrides the file. `,
+		func(t *testing.T) {
+			file := writeConfigFile(t, `{
+  "server": {"port": 5000, "shutdown_timeout": "30s"},
+  "rate_limit": {"reads_per_minute": 10, "writes_per_minute": 5}
+}`)
+			env := fakeEnv(map[string]string{
+				"CONFIG_FILE":                  file,
+				"RATE_LIMIT_READS_PER_MINUTE":  "20",
+				"RATE_LIMIT_WRITES_PER_MINUTE": "6",
+			})
+
+			cfg, opts, err := config.Load([]string{"-rate-limit-writes-per-minute", "7"}, env)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if opts.File != file {
+				t.Fatalf("expected the config file from CONFIG_FILE, got %q", opts.File)
+			}
+			if cfg.Server.Port != 5000 || cfg.Server.ShutdownTimeout.Duration != 30*time.Second {
+				t.Fatalf("expected port 5000 and a 30s shutdown timeout from the file, got %d and %v", cfg.Server.Port, cfg.Server.ShutdownTimeout)
+			}
+			if cfg.RateLimit.ReadsPerMinute != 20 || cfg.RateLimit.WritesPerMinute != 7 {
+				t.Fatalf("expected 20 reads from the environment and 7 writes from the flag, got %d and %d", cfg.RateLimit.ReadsPerMinute, cfg.RateLimit.WritesPerMinute)
+			}
+			if cfg.Idempotency.KeyTTL.Duration != 24*time.Hour {
+				t.Fatalf("expected the default idempotency key TTL, got %v", cfg.Idempotency.KeyTTL)
+			}
+			if cfg.GRPC.Port != 0 {
+				t.Fatalf("expected the gRPC API to be off unless a port is set, got port %d", cfg.GRPC.Port)
+			}
+		},
+	)
+
+	t.Run(`Given several invalid values,
+    when the configuration is loaded,
+    then every problem should be reported. `,
+		func(t *testing.T) {
+			env := fakeEnv(map[string]string{
+				"PORT":           "70000",
+				"STORAGE_DRIVER": "postgres",
+			})
+			_, _, err := config.Load([]string{"-idempotency-key-ttl", "0s", "-tax-rates-file", filepath.Join(t.TempDir(), "missing.json")}, env)
+			if err == nil {
+				t.Fatal("expected a validation error")
+			}
+			for _, key := range []string{"server.port", "storage.driver", "idempotency.key_ttl", "catalog.tax_rates_file"} {
+				if !strings.Contains(err.Error(), key) {
+					t.Fatalf("expected %s in the error, got %v", key, err)
+				}
+			}
+
+			_, _, err = config.Load([]string{"-tls-client-auth", "require"}, fakeEnv(map[string]string{"TLS_CERT_FILE": filepath.Join(t.TempDir(), "missing.pem")}))
+			if err == nil {
+				t.Fatal("expected a validation error")
+			}
+			for _, key := range []string{"tls.cert_file", "tls.key_file", "tls.client_ca_file", "tls.client_identities_file"} {
+				if !strings.Contains(err.Error(), key) {
+					t.Fatalf("expected %s in the error, got %v", key, err)
+				}
+			}
+
+			_, _, err = config.Load(nil, fakeEnv(map[string]string{"SHUTDOWN_TIMEOUT": "soon"}))
+			if err == nil || !strings.Contains(err.Error(), "SHUTDOWN_TIMEOUT") {
+				t.Fatalf("expected an error naming SHUTDOWN_TIMEOUT, got %v", err)
+			}
+
+			_, _, err = config.Load([]string{"-config", writeConfigFile(t, `{"server": {"prot": 1}}`)}, fakeEnv(nil))
+			if err == nil || !strings.Contains(err.Error(), "prot") {
+				t.Fatalf("expected an error naming the unknown key, got %v", err)
+			}
+		},
+	)
+
+	t.Run(`Given -print-config,
+    when the configuration is printed,
+    then it should be a config file that loads back to the same configuration. `,
+		func(t *testing.T) {
+			cfg, opts, err := config.Load([]string{"-print-config", "-port", "4500"}, fakeEnv(nil))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !opts.PrintConfig {
+				t.Fatal("expected PrintConfig to be set")
+			}
+
+			var printed bytes.Buffer
+			if err := config.Print(&printed, cfg); err != nil {
+				t.Fatal(err)
+			}
+			var decoded map[string]interface{}
+			if err := json.Unmarshal(printed.Bytes(), &decoded); err != nil {
+				t.Fatal(err)
+			}
+
+			reloaded, _, err := config.Load([]string{"-config", writeConfigFile(t, printed.String())}, fakeEnv(nil))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if reloaded != cfg {
+				t.Fatalf("expected the printed configuration to load back unchanged, got %+v", reloaded)
+			}
+		},
+	)
+}
+
+func writeConfigFile(t *testing.T, contents string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "config.json")
+	if err := os.WriteFile(path, []byte(contents), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func fakeEnv(values map[string]string) func(string) (string, bool) {
+	return func(name string) (string, bool) {
+		value, ok := values[name]
+		return value, ok
+	}
+}
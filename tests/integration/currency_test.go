@@ -0,0 +1,180 @@
+//go:build integration
+// +build integration
+
+package integration
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/olad5/sal-backend-service/internal/infra/memory"
+	"github.com/olad5/sal-backend-service/internal/usecases/currency"
+	"github.com/olad5/sal-backend-service/tests"
+)
+
+func TestProductPriceCurrency(t *testing.T) {
+	response := updateExchangeRates(t, map[string]interface{}{
+		"base_currency": "USD",
+		"rates":         map[string]float64{"EUR": 0.9, "JPY": 151.37, "CHF": 0.88},
+		"rounding": map[string]interface{}{
+			"JPY": map[string]interface{}{"increment": 1},
+			"CHF": map[string]interface{}{"increment": 0.05, "mode": "half_up"},
+		},
+	})
+	tests.AssertStatusCode(t, http.StatusOK, response.Code)
+	version := tests.ParseResponse(t, response)["data"].(map[string]interface{})["version"].(float64)
+
+	t.Run(`Given an exchange rate table with per currency rounding,
+    when a merchant lists their products with a currency,
+    then each product should keep its original price and carry the converted price and rate used. `,
+		func(t *testing.T) {
+			merchantId := uuid.New()
+			prd := buildProduct(merchantId, uuid.New())
+			prd.Price = 19.99
+			createProduct(t, prd)
+
+			for _, tc := range []struct {
+				currency string
+				expected float64
+			}{
+				{"eur", 17.99},
+				{"JPY", 3026},
+				{"CHF", 17.60},
+				{"USD", 19.99},
+			} {
+				product := fetchProductsInCurrency(t, merchantId, tc.currency)[0].(map[string]interface{})
+				if product["price"].(float64) != 19.99 {
+					t.Fatalf("expected the original price 19.99 to be kept, got %v", product["price"])
+				}
+				converted := product["converted"].(map[string]interface{})
+				if converted["effective_price"].(float64) != tc.expected {
+					t.Fatalf("expected %v in %s, got %v", tc.expected, tc.currency, converted["effective_price"])
+				}
+				rate := converted["exchange_rate"].(map[string]interface{})
+				if rate["base_currency"] != "USD" || rate["version"].(float64) != version {
+					t.Fatalf("expected USD rates at version %v, got %v", version, rate)
+				}
+			}
+		},
+	)
+
+	t.Run(`Given a currency missing from the exchange rate table,
+    when a merchant lists their products in that currency,
+    then the API should return a bad request error. `,
+		func(t *testing.T) {
+			merchantId := uuid.New()
+			for _, code := range []string{"GBP", "EURO"} {
+				req, _ := http.NewRequest(http.MethodGet, "/api/merchants/"+merchantId.String()+"/products?currency="+code, nil)
+				response := tests.ExecuteRequest(req, r)
+				tests.AssertStatusCode(t, http.StatusBadRequest, response.Code)
+			}
+		},
+	)
+
+	t.Run(`Given a product fetched by its SKU code,
+    when a currency is requested,
+    then the product should carry the converted prices as it does in listings. `,
+		func(t *testing.T) {
+			prd := buildProduct(uuid.New(), uuid.New())
+			prd.Price = 19.99
+			product, err := c.CreateProduct(context.Background(), prd)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			req, _ := http.NewRequest(http.MethodGet, "/api/merchants/"+prd.MerchantId.String()+"/products/by-code/"+product.SKUCode+"?currency=EUR", nil)
+			response := tests.ExecuteRequest(req, r)
+			tests.AssertStatusCode(t, http.StatusOK, response.Code)
+			converted := tests.ParseResponse(t, response)["data"].(map[string]interface{})["converted"].(map[string]interface{})
+			if converted["currency"] != "EUR" || converted["effective_price"].(float64) != 17.99 {
+				t.Fatalf("expected 17.99 EUR, got %v", converted)
+			}
+
+			req, _ = http.NewRequest(http.MethodGet, "/api/merchants/"+prd.MerchantId.String()+"/products/by-code/"+product.SKUCode+"?currency=GBP", nil)
+			tests.AssertStatusCode(t, http.StatusBadRequest, tests.ExecuteRequest(req, r).Code)
+		},
+	)
+
+	t.Run(`Given the exchange rates are updated,
+    when the admin fetches an earlier version,
+    then the earlier rates should still be returned. `,
+		func(t *testing.T) {
+			response := updateExchangeRates(t, map[string]interface{}{
+				"base_currency": "USD",
+				"rates":         map[string]float64{"EUR": 0.95},
+			})
+			tests.AssertStatusCode(t, http.StatusOK, response.Code)
+			latest := tests.ParseResponse(t, response)["data"].(map[string]interface{})
+			if latest["version"].(float64) != version+1 {
+				t.Fatalf("expected version %v, got %v", version+1, latest["version"])
+			}
+
+			req, _ := http.NewRequest(http.MethodGet, fmt.Sprintf("/api/exchange-rates/%d", int(version)), nil)
+			response = tests.ExecuteRequest(req, r)
+			tests.AssertStatusCode(t, http.StatusOK, response.Code)
+			rates := tests.ParseResponse(t, response)["data"].(map[string]interface{})["rates"].(map[string]interface{})
+			if rates["EUR"].(float64) != 0.9 {
+				t.Fatalf("expected EUR rate 0.9 in version %v, got %v", version, rates["EUR"])
+			}
+		},
+	)
+}
+
+func TestLoadExchangeRatesFile(t *testing.T) {
+	ctx := context.Background()
+	loadedAt := time.Date(2024, time.March, 1, 9, 0, 0, 0, time.UTC)
+	rateRepo, _ := memory.NewMemoryExchangeRateRepo()
+	currencyService, err := currency.NewCurrencyService(rateRepo, tests.NewFakeClock(loadedAt))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	path := filepath.Join(t.TempDir(), "rates.json")
+	content := `{"base_currency": "usd", "rates": {"eur": 0.9}, "rounding": {"EUR": {"increment": 0.1, "mode": "down"}}}`
+	if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	table, err := currencyService.LoadExchangeRatesFile(ctx, path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if table.Version != 1 || table.BaseCurrency != "USD" || !table.UpdatedAt.Equal(loadedAt) {
+		t.Fatalf("unexpected exchange rate table %+v", table)
+	}
+
+	rate, err := currencyService.GetExchangeRate(ctx, "EUR")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if converted := currency.Convert(rate, 19.99); converted != 17.9 {
+		t.Fatalf("expected 19.99 USD to round down to 17.9 EUR, got %v", converted)
+	}
+}
+
+func updateExchangeRates(t *testing.T, rates map[string]interface{}) *httptest.ResponseRecorder {
+	t.Helper()
+	requestBody, err := json.Marshal(rates)
+	if err != nil {
+		t.Fatal(err)
+	}
+	req, _ := http.NewRequest(http.MethodPut, "/admin/exchange-rates", bytes.NewBuffer(requestBody))
+	return tests.ExecuteRequest(asAdmin(req), r)
+}
+
+func fetchProductsInCurrency(t *testing.T, merchantId uuid.UUID, code string) []interface{} {
+	t.Helper()
+	req, _ := http.NewRequest(http.MethodGet, "/api/merchants/"+merchantId.String()+"/products?currency="+code, nil)
+	response := tests.ExecuteRequest(req, r)
+	tests.AssertStatusCode(t, http.StatusOK, response.Code)
+	return tests.ParseResponse(t, response)["data"].(map[string]interface{})["products"].([]interface{})
+}
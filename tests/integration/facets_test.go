@@ -0,0 +1,149 @@
+//go:build integration
+// +build integration
+
+package integration
+
+import (
+	"encoding/json"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/olad5/sal-backend-service/tests"
+)
+
+func TestProductFacets(t *testing.T) {
+	merchantId := uuid.New()
+	for index, price := range []float64{5, 15, 20, 60, 250} {
+		prd := buildProduct(merchantId, uuid.New())
+		prd.Price = price
+		if index%2 == 0 {
+			prd.Name = "Linen Shirt"
+		}
+		_ = createProduct(t, prd)
+	}
+
+	t.Run(`Given a merchant with products at different prices,
+    when they list their products with price facets and custom buckets,
+    then the response should count the products in each price range. `,
+		func(t *testing.T) {
+			req, _ := http.NewRequest(http.MethodGet, "/api/merchants/"+merchantId.String()+"/products?facets=price&price_buckets=10,50,100", nil)
+			response := tests.ExecuteRequest(req, r)
+			tests.AssertStatusCode(t, http.StatusOK, response.Code)
+			data := tests.ParseResponse(t, response)["data"].(map[string]interface{})
+			assertPriceBuckets(t, data, []float64{1, 2, 1, 1})
+		},
+	)
+
+	t.Run(`Given a merchant searching their catalog with price facets,
+    when only some products match the query and a single page is requested,
+    then the counts should cover every matching product and nothing else. `,
+		func(t *testing.T) {
+			req, _ := http.NewRequest(http.MethodGet, "/api/merchants/"+merchantId.String()+"/products/search?q=linen&limit=1&facets=price&price_buckets=10,50,100", nil)
+			response := tests.ExecuteRequest(req, r)
+			tests.AssertStatusCode(t, http.StatusOK, response.Code)
+			data := tests.ParseResponse(t, response)["data"].(map[string]interface{})
+			if results := data["results"].([]interface{}); len(results) != 1 {
+				t.Fatalf("expected a single result on the page, got %d", len(results))
+			}
+			assertPriceBuckets(t, data, []float64{1, 1, 0, 1})
+		},
+	)
+
+	t.Run(`Given a merchant with a product on sale, one with a sale coming up and two regular ones,
+    when they list or search their products with status facets,
+    then the response should count the products in every status. `,
+		func(t *testing.T) {
+			statusMerchantId := uuid.New()
+			skuIds := []uuid.UUID{}
+			for i := 0; i < 4; i++ {
+				prd := buildProduct(statusMerchantId, uuid.New())
+				prd.Name = "Wool Scarf"
+				skuIds = append(skuIds, createProduct(t, prd))
+			}
+			now := time.Now()
+			tests.AssertStatusCode(t, http.StatusOK, scheduleSale(t, skuIds[0], statusMerchantId, 1, now.Add(-time.Minute), now.Add(time.Hour)).Code)
+			tests.AssertStatusCode(t, http.StatusOK, scheduleSale(t, skuIds[1], statusMerchantId, 1, now.Add(time.Hour), now.Add(2*time.Hour)).Code)
+
+			for _, path := range []string{"/products?facets=status,price", "/products/search?q=scarf&limit=1&facets=status"} {
+				req, _ := http.NewRequest(http.MethodGet, "/api/merchants/"+statusMerchantId.String()+path, nil)
+				response := tests.ExecuteRequest(req, r)
+				tests.AssertStatusCode(t, http.StatusOK, response.Code)
+				facets := tests.ParseResponse(t, response)["data"].(map[string]interface{})["facets"].(map[string]interface{})
+				counts := map[string]float64{}
+				for _, item := range facets["status"].([]interface{}) {
+					count := item.(map[string]interface{})
+					counts[count["status"].(string)] = count["count"].(float64)
+				}
+				if counts["on_sale"] != 1 || counts["scheduled"] != 1 || counts["regular"] != 2 {
+					t.Fatalf("expected 1 on sale, 1 scheduled and 2 regular products for %s, got %v", path, counts)
+				}
+			}
+		},
+	)
+
+	t.Run(`Given a merchant with products in categories, with tags and with attributes,
+    when they list or search their products with category, tags and attributes facets,
+    then the response should count the products per term, most common first,
+    under the most used spelling or the first in byte order on a tie. `,
+		func(t *testing.T) {
+			termMerchantId := uuid.New()
+			for _, prd := range []struct {
+				category   string
+				tags       []string
+				attributes map[string]string
+			}{
+				{"Shoes", []string{"sale", "summer"}, map[string]string{"Colour": "red", "size": "42"}},
+				{"shoes", []string{"Summer"}, map[string]string{"colour": "Red"}},
+				{"Hats", nil, map[string]string{"colour": "blue"}},
+			} {
+				product := buildProduct(termMerchantId, uuid.New())
+				product.Name = "Canvas Item"
+				product.Category, product.Tags, product.Attributes = prd.category, prd.tags, prd.attributes
+				_ = createProduct(t, product)
+			}
+
+			expected := `{"attributes":[{"name":"colour","values":[{"count":2,"term":"Red"},{"count":1,"term":"blue"}]},` +
+				`{"name":"size","values":[{"count":1,"term":"42"}]}],` +
+				`"category":[{"count":2,"term":"Shoes"},{"count":1,"term":"Hats"}],` +
+				`"tags":[{"count":2,"term":"Summer"},{"count":1,"term":"sale"}]}`
+			for _, path := range []string{"/products?facets=category,tags,attributes", "/products/search?q=canvas&limit=1&facets=category,tags,attributes"} {
+				req, _ := http.NewRequest(http.MethodGet, "/api/merchants/"+termMerchantId.String()+path, nil)
+				response := tests.ExecuteRequest(req, r)
+				tests.AssertStatusCode(t, http.StatusOK, response.Code)
+				facets, _ := json.Marshal(tests.ParseResponse(t, response)["data"].(map[string]interface{})["facets"])
+				if string(facets) != expected {
+					t.Fatalf("expected %s for %s, got %s", expected, path, facets)
+				}
+			}
+		},
+	)
+
+	t.Run(`Given a listing request with unordered price buckets,
+    when the listing endpoint is called,
+    then the API should return a validation error. `,
+		func(t *testing.T) {
+			req, _ := http.NewRequest(http.MethodGet, "/api/merchants/"+merchantId.String()+"/products?facets=price&price_buckets=50,10", nil)
+			response := tests.ExecuteRequest(req, r)
+			tests.AssertStatusCode(t, http.StatusBadRequest, response.Code)
+		},
+	)
+}
+
+func assertPriceBuckets(t *testing.T, data map[string]interface{}, expected []float64) {
+	t.Helper()
+	facets, ok := data["facets"].(map[string]interface{})
+	if !ok {
+		t.Fatalf("expected facets in the response")
+	}
+	buckets := facets["price"].([]interface{})
+	if len(buckets) != len(expected) {
+		t.Fatalf("expected %d buckets, got %d", len(expected), len(buckets))
+	}
+	for index, bucket := range buckets {
+		if count := bucket.(map[string]interface{})["count"].(float64); count != expected[index] {
+			t.Errorf("expected bucket %d to have %v products, got %v", index, expected[index], count)
+		}
+	}
+}
@@ -0,0 +1,383 @@
+//go:build integration
+// +build integration
+
+package integration
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/olad5/sal-backend-service/internal/config"
+	"github.com/olad5/sal-backend-service/internal/logging"
+	"github.com/olad5/sal-backend-service/tests"
+)
+
+type graphqlResult struct {
+	Data   map[string]interface{} `json:"data"`
+	Errors []struct {
+		Message    string                 `json:"message"`
+		Extensions map[string]interface{} `json:"extensions"`
+	} `json:"errors"`
+}
+
+// errorCode is the code of the result's first error, or "".
+func (r graphqlResult) errorCode() string {
+	if len(r.Errors) == 0 {
+		return ""
+	}
+	code, _ := r.Errors[0].Extensions["code"].(string)
+	return code
+}
+
+func runGraphQL(t *testing.T, handler http.Handler, query string, variables map[string]interface{}) (int, graphqlResult) {
+	t.Helper()
+	requestBody, _ := json.Marshal(map[string]interface{}{"query": query, "variables": variables})
+	req, _ := http.NewRequest(http.MethodPost, "/graphql", bytes.NewBuffer(requestBody))
+	req.Header.Set("Content-Type", "application/json")
+	response := tests.ExecuteRequest(req, handler)
+
+	var result graphqlResult
+	if err := json.Unmarshal(response.Body.Bytes(), &result); err != nil {
+		t.Fatalf("expected a GraphQL result, got %q: %v", response.Body.String(), err)
+	}
+	return response.Code, result
+}
+
+const createProductMutation = `
+mutation Create($input: CreateProductInput!) {
+  createProduct(input: $input) { skuId skuCode name price taxClass merchantId }
+}`
+
+func graphqlCreateProduct(t *testing.T, merchantId uuid.UUID, name string) string {
+	t.Helper()
+	status, result := runGraphQL(t, r, createProductMutation, map[string]interface{}{
+		"input": map[string]interface{}{
+			"merchantId":  merchantId.String(),
+			"name":        name,
+			"description": "described",
+			"price":       10,
+		},
+	})
+	tests.AssertStatusCode(t, http.StatusOK, status)
+	if len(result.Errors) > 0 {
+		t.Fatalf("expected the product to be created, got %+v", result.Errors)
+	}
+	return result.Data["createProduct"].(map[string]interface{})["skuId"].(string)
+}
+
+func TestGraphQL(t *testing.T) {
+	merchantId := uuid.New()
+
+	t.Run(`Given a product created through a mutation,
+    when it is queried, updated and deleted,
+    then each operation should see the previous one's change. `,
+		func(t *testing.T) {
+			skuId := graphqlCreateProduct(t, merchantId, "graphql product")
+
+			query := `query($merchantId: ID!, $skuId: ID!) { product(merchantId: $merchantId, skuId: $skuId) { name taxClass gtin images { id } } }`
+			ids := map[string]interface{}{"merchantId": merchantId.String(), "skuId": skuId}
+			_, result := runGraphQL(t, r, query, ids)
+			product := result.Data["product"].(map[string]interface{})
+			if product["name"] != "graphql product" || product["taxClass"] != "standard" || product["gtin"] != nil {
+				t.Fatalf("expected the created product, got %v", product)
+			}
+
+			_, result = runGraphQL(t, r, `mutation($skuId: ID!, $merchantId: ID!) {
+  updateProduct(skuId: $skuId, input: {merchantId: $merchantId, name: "renamed", description: "again", price: 4.5, taxClass: "reduced"}) { name price taxClass }
+}`, ids)
+			updated := result.Data["updateProduct"].(map[string]interface{})
+			if updated["name"] != "renamed" || updated["price"] != 4.5 || updated["taxClass"] != "reduced" {
+				t.Fatalf("expected the update to be applied, got %v", updated)
+			}
+
+			_, result = runGraphQL(t, r, `mutation($merchantId: ID!, $skuId: ID!) { deleteProduct(merchantId: $merchantId, skuId: $skuId) }`, ids)
+			if result.Data["deleteProduct"] != skuId {
+				t.Fatalf("expected the deleted product's ID, got %v", result.Data)
+			}
+			_, result = runGraphQL(t, r, query, ids)
+			if result.errorCode() != "NOT_FOUND" {
+				t.Fatalf("expected NOT_FOUND once deleted, got %+v", result.Errors)
+			}
+		},
+	)
+
+	t.Run(`Given exchange rates for EUR,
+    when a product is queried with its prices converted to EUR, or to a currency without a rate,
+    then the converted prices should be returned, and BAD_USER_INPUT for the unknown currency. `,
+		func(t *testing.T) {
+			response := updateExchangeRates(t, map[string]interface{}{"base_currency": "USD", "rates": map[string]float64{"EUR": 0.5}})
+			tests.AssertStatusCode(t, http.StatusOK, response.Code)
+			skuId := graphqlCreateProduct(t, merchantId, "converted product")
+
+			query := `query($merchantId: ID!, $skuId: ID!, $currency: String!) {
+  product(merchantId: $merchantId, skuId: $skuId) { price converted(currency: $currency) { currency price baseCurrency } }
+}`
+			_, result := runGraphQL(t, r, query, map[string]interface{}{"merchantId": merchantId.String(), "skuId": skuId, "currency": "eur"})
+			converted := result.Data["product"].(map[string]interface{})["converted"].(map[string]interface{})
+			if converted["currency"] != "EUR" || converted["price"] != 5.0 || converted["baseCurrency"] != "USD" {
+				t.Fatalf("expected 5 EUR converted from USD, got %v", converted)
+			}
+
+			_, result = runGraphQL(t, r, query, map[string]interface{}{"merchantId": merchantId.String(), "skuId": skuId, "currency": "XYZ"})
+			if result.errorCode() != "BAD_USER_INPUT" {
+				t.Fatalf("expected BAD_USER_INPUT, got %+v", result.Errors)
+			}
+		},
+	)
+
+	t.Run(`Given tax rates for DE,
+    when a product is queried with its prices taxed for DE, or for a region without rates,
+    then the taxed prices should be returned, and BAD_USER_INPUT for the unknown region. `,
+		func(t *testing.T) {
+			req, _ := http.NewRequest(http.MethodPut, "/admin/tax-regions/DE", bytes.NewBufferString(`{"rates": {"standard": 19, "reduced": 7}}`))
+			tests.AssertStatusCode(t, http.StatusOK, tests.ExecuteRequest(asAdmin(req), r).Code)
+			skuId := graphqlCreateProduct(t, merchantId, "taxed product")
+
+			query := `query($merchantId: ID!, $skuId: ID!, $region: String!) {
+  product(merchantId: $merchantId, skuId: $skuId) {
+    inclusive: tax(region: $region) { region taxClass price }
+    exclusive: tax(region: $region, display: EXCLUSIVE) { price effective { net tax gross } }
+  }
+}`
+			_, result := runGraphQL(t, r, query, map[string]interface{}{"merchantId": merchantId.String(), "skuId": skuId, "region": "de"})
+			if len(result.Errors) > 0 {
+				t.Fatal(result.Errors)
+			}
+			product := result.Data["product"].(map[string]interface{})
+			inclusive := product["inclusive"].(map[string]interface{})
+			exclusive := product["exclusive"].(map[string]interface{})
+			if inclusive["region"] != "DE" || inclusive["taxClass"] != "standard" || inclusive["price"] != 11.9 || exclusive["price"] != 10.0 {
+				t.Fatalf("expected 11.9 inclusive and 10 exclusive in DE, got %v", product)
+			}
+
+			_, result = runGraphQL(t, r, query, map[string]interface{}{"merchantId": merchantId.String(), "skuId": skuId, "region": "FR"})
+			if result.errorCode() != "BAD_USER_INPUT" {
+				t.Fatalf("expected BAD_USER_INPUT, got %+v", result.Errors)
+			}
+		},
+	)
+
+	t.Run(`Given a product of another merchant,
+    when it is queried, updated or deleted,
+    then the API should answer NOT_FOUND as the REST API does. `,
+		func(t *testing.T) {
+			skuId := graphqlCreateProduct(t, merchantId, "private product")
+			ids := map[string]interface{}{"merchantId": uuid.New().String(), "skuId": skuId}
+
+			_, result := runGraphQL(t, r, `query($merchantId: ID!, $skuId: ID!) { product(merchantId: $merchantId, skuId: $skuId) { name } }`, ids)
+			if result.errorCode() != "NOT_FOUND" || result.Data["product"] != nil {
+				t.Fatalf("expected NOT_FOUND without data, got %v %+v", result.Data, result.Errors)
+			}
+			_, result = runGraphQL(t, r, `mutation($merchantId: ID!, $skuId: ID!) { deleteProduct(merchantId: $merchantId, skuId: $skuId) }`, ids)
+			if result.errorCode() != "NOT_FOUND" {
+				t.Fatalf("expected NOT_FOUND, got %+v", result.Errors)
+			}
+		},
+	)
+
+	t.Run(`Given invalid input,
+    when a product is created,
+    then the API should answer BAD_USER_INPUT with the REST API's message. `,
+		func(t *testing.T) {
+			_, result := runGraphQL(t, r, createProductMutation, map[string]interface{}{
+				"input": map[string]interface{}{"merchantId": merchantId.String(), "name": "", "description": "d", "price": 1},
+			})
+			if result.errorCode() != "BAD_USER_INPUT" || result.Errors[0].Message != "name required" {
+				t.Fatalf("expected BAD_USER_INPUT for the missing name, got %+v", result.Errors)
+			}
+		},
+	)
+
+	t.Run(`Given a merchant with five products,
+    when they are paged through two at a time,
+    then every product should be returned once, in creation order. `,
+		func(t *testing.T) {
+			pagedMerchantId := uuid.New()
+			created := []string{}
+			for i := 0; i < 5; i++ {
+				created = append(created, graphqlCreateProduct(t, pagedMerchantId, fmt.Sprintf("paged %d", i)))
+			}
+
+			query := `query($merchantId: ID!, $after: String) {
+  merchantProducts(merchantId: $merchantId, first: 2, after: $after) {
+    totalCount
+    edges { cursor node { skuId } }
+    pageInfo { hasNextPage endCursor }
+  }
+}`
+			listed := []string{}
+			var after interface{}
+			for pages := 0; pages < 5; pages++ {
+				_, result := runGraphQL(t, r, query, map[string]interface{}{"merchantId": pagedMerchantId.String(), "after": after})
+				if len(result.Errors) > 0 {
+					t.Fatal(result.Errors)
+				}
+				connection := result.Data["merchantProducts"].(map[string]interface{})
+				if connection["totalCount"] != float64(5) {
+					t.Fatalf("expected 5 products in total, got %v", connection["totalCount"])
+				}
+				for _, edge := range connection["edges"].([]interface{}) {
+					listed = append(listed, edge.(map[string]interface{})["node"].(map[string]interface{})["skuId"].(string))
+				}
+				pageInfo := connection["pageInfo"].(map[string]interface{})
+				if pageInfo["hasNextPage"] != true {
+					break
+				}
+				after = pageInfo["endCursor"]
+			}
+			if fmt.Sprint(listed) != fmt.Sprint(created) {
+				t.Fatalf("expected %v, got %v", created, listed)
+			}
+
+			_, result := runGraphQL(t, r, query, map[string]interface{}{"merchantId": pagedMerchantId.String(), "after": "bogus"})
+			if result.errorCode() != "BAD_USER_INPUT" {
+				t.Fatalf("expected BAD_USER_INPUT for an invalid cursor, got %+v", result.Errors)
+			}
+		},
+	)
+
+	t.Run(`Given products in categories spelled in different cases and an uncategorized product,
+    when the merchant's categories are queried,
+    then each category should be listed once, sorted, with its product count. `,
+		func(t *testing.T) {
+			categoryMerchantId := uuid.New()
+			for _, category := range []string{"Shoes", "hats", "shoes", ""} {
+				_, result := runGraphQL(t, r, createProductMutation, map[string]interface{}{
+					"input": map[string]interface{}{
+						"merchantId":  categoryMerchantId.String(),
+						"name":        "categorized",
+						"description": "described",
+						"price":       10,
+						"category":    category,
+					},
+				})
+				if len(result.Errors) > 0 {
+					t.Fatal(result.Errors)
+				}
+			}
+
+			_, result := runGraphQL(t, r, `query($merchantId: ID!) { categories(merchantId: $merchantId) { name productCount } }`,
+				map[string]interface{}{"merchantId": categoryMerchantId.String()})
+			if len(result.Errors) > 0 {
+				t.Fatal(result.Errors)
+			}
+			categories, _ := json.Marshal(result.Data["categories"])
+			expected := `[{"name":"hats","productCount":1},{"name":"Shoes","productCount":2}]`
+			if string(categories) != expected {
+				t.Fatalf("expected %s, got %s", expected, categories)
+			}
+		},
+	)
+
+	t.Run(`Given products matching a search,
+    when they are searched for,
+    then the matches should be returned with their scores. `,
+		func(t *testing.T) {
+			searchMerchantId := uuid.New()
+			graphqlCreateProduct(t, searchMerchantId, "walnut bookshelf")
+			graphqlCreateProduct(t, searchMerchantId, "walnut desk")
+			graphqlCreateProduct(t, searchMerchantId, "oak chair")
+
+			_, result := runGraphQL(t, r, `query($merchantId: ID!) {
+  search(merchantId: $merchantId, query: "walnut", first: 1) {
+    totalCount
+    edges { score node { name } }
+    pageInfo { hasNextPage }
+  }
+}`, map[string]interface{}{"merchantId": searchMerchantId.String()})
+			if len(result.Errors) > 0 {
+				t.Fatal(result.Errors)
+			}
+			connection := result.Data["search"].(map[string]interface{})
+			edges := connection["edges"].([]interface{})
+			if connection["totalCount"] != float64(2) || len(edges) != 1 || connection["pageInfo"].(map[string]interface{})["hasNextPage"] != true {
+				t.Fatalf("expected the first of 2 walnut products, got %v", connection)
+			}
+			if score, _ := edges[0].(map[string]interface{})["score"].(float64); score <= 0 {
+				t.Fatalf("expected a positive score, got %v", edges[0])
+			}
+		},
+	)
+
+	t.Run(`Given a query that does not match the schema,
+    when it is run,
+    then the API should answer 400 with the validation errors. `,
+		func(t *testing.T) {
+			status, result := runGraphQL(t, r, `{ product(merchantId: "x") { price } }`, nil)
+			tests.AssertStatusCode(t, http.StatusBadRequest, status)
+			if len(result.Errors) == 0 {
+				t.Fatal("expected validation errors")
+			}
+		},
+	)
+}
+
+func TestGraphQLLimits(t *testing.T) {
+	cfg := config.Default()
+	cfg.GraphQL.MaxDepth = 4
+	cfg.GraphQL.MaxComplexity = 50
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	limited := newRouter(ctx, cfg, logging.Discard(), newHealthChecker())
+	merchantId := uuid.New().String()
+
+	t.Run(`Given a depth limit of 4,
+    when a query selects fields 5 levels deep, even through fragments,
+    then it should be rejected before it runs. `,
+		func(t *testing.T) {
+			status, result := runGraphQL(t, limited, `query($merchantId: ID!) {
+  merchantProducts(merchantId: $merchantId, first: 1) { edges { node { ...images } } }
+}
+fragment images on Product { images { id } }`, map[string]interface{}{"merchantId": merchantId})
+			tests.AssertStatusCode(t, http.StatusBadRequest, status)
+			if result.errorCode() != "QUERY_TOO_LARGE" {
+				t.Fatalf("expected QUERY_TOO_LARGE, got %+v", result.Errors)
+			}
+
+			status, _ = runGraphQL(t, limited, `query($merchantId: ID!) {
+  merchantProducts(merchantId: $merchantId, first: 1) { edges { node { name } } }
+}`, map[string]interface{}{"merchantId": merchantId})
+			tests.AssertStatusCode(t, http.StatusOK, status)
+		},
+	)
+
+	t.Run(`Given a complexity limit of 50,
+    when a query asks for more items than the limit allows, by argument, variable or default page size,
+    then it should be rejected while a smaller page is allowed. `,
+		func(t *testing.T) {
+			query := `query($merchantId: ID!, $first: Int) {
+  merchantProducts(merchantId: $merchantId, first: $first) { edges { cursor node { skuId name price } } }
+}`
+			for _, first := range []interface{}{50, nil} {
+				status, result := runGraphQL(t, limited, query, map[string]interface{}{"merchantId": merchantId, "first": first})
+				tests.AssertStatusCode(t, http.StatusBadRequest, status)
+				if result.errorCode() != "QUERY_TOO_LARGE" {
+					t.Fatalf("expected QUERY_TOO_LARGE for first %v, got %+v", first, result.Errors)
+				}
+			}
+
+			status, result := runGraphQL(t, limited, query, map[string]interface{}{"merchantId": merchantId, "first": 5})
+			tests.AssertStatusCode(t, http.StatusOK, status)
+			if len(result.Errors) > 0 {
+				t.Fatal(result.Errors)
+			}
+		},
+	)
+
+	t.Run(`Given the limits,
+    when the schema is introspected,
+    then introspection should not count against them. `,
+		func(t *testing.T) {
+			status, result := runGraphQL(t, limited, `{ __schema { queryType { fields { name args { name type { name ofType { name } } } } } } }`, nil)
+			tests.AssertStatusCode(t, http.StatusOK, status)
+			if len(result.Errors) > 0 {
+				t.Fatal(result.Errors)
+			}
+		},
+	)
+}
@@ -0,0 +1,347 @@
+//go:build integration
+// +build integration
+
+package integration
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	productsv1 "github.com/olad5/sal-backend-service/api/proto/products/v1"
+	"github.com/olad5/sal-backend-service/internal/app/router"
+	"github.com/olad5/sal-backend-service/internal/config"
+	"github.com/olad5/sal-backend-service/internal/lifecycle"
+	"github.com/olad5/sal-backend-service/internal/logging"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+)
+
+// newGRPCClient serves the gRPC API in process over bufconn until the test
+// ends.
+func newGRPCClient(t *testing.T) productsv1.ProductServiceClient {
+	t.Helper()
+	ctx, cancel := context.WithCancel(context.Background())
+	server := grpc.NewServer()
+	manager, err := lifecycle.NewManager(logging.Discard().Logger)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cfg := config.Default()
+	cfg.Catalog.ExchangeRatesFile = filepath.Join(t.TempDir(), "rates.json")
+	if err := os.WriteFile(cfg.Catalog.ExchangeRatesFile, []byte(`{"base_currency": "USD", "rates": {"EUR": 0.5}}`), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	cfg.Catalog.TaxRatesFile = filepath.Join(t.TempDir(), "tax.json")
+	if err := os.WriteFile(cfg.Catalog.TaxRatesFile, []byte(`{"regions": {"DE": {"standard": 19, "reduced": 7}}}`), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	router.NewHttpRouter(ctx, cfg, logging.Discard(), newHealthChecker(), manager, server)
+
+	listener := bufconn.Listen(1 << 20)
+	go server.Serve(listener)
+	conn, err := grpc.NewClient("passthrough:///bufnet",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) {
+			return listener.DialContext(ctx)
+		}),
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() {
+		conn.Close()
+		server.Stop()
+		cancel()
+	})
+	return productsv1.NewProductServiceClient(conn)
+}
+
+func newCreateProductRequest(merchantId uuid.UUID) *productsv1.CreateProductRequest {
+	product := buildProduct(merchantId, uuid.New())
+	return &productsv1.CreateProductRequest{
+		MerchantId:  merchantId.String(),
+		SkuId:       product.SKUID.String(),
+		Name:        product.Name,
+		Description: product.Description,
+		Price:       product.Price,
+	}
+}
+
+func assertCode(t *testing.T, expected codes.Code, err error) {
+	t.Helper()
+	if code := status.Code(err); code != expected {
+		t.Fatalf("expected %s, got %s: %v", expected, code, err)
+	}
+}
+
+func TestGRPCProducts(t *testing.T) {
+	client := newGRPCClient(t)
+	ctx := context.Background()
+	merchantId := uuid.New()
+
+	t.Run(`Given a valid product,
+    when it is created, read, updated and deleted over gRPC,
+    then each call should see the previous one's change. `,
+		func(t *testing.T) {
+			request := newCreateProductRequest(merchantId)
+			request.Gtin = "4006381333931"
+			created, err := client.CreateProduct(ctx, request)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if created.SkuId != request.SkuId || created.SkuCode == "" || created.TaxClass != "standard" {
+				t.Fatalf("expected the product with a generated sku code and the standard tax class, got %v", created)
+			}
+
+			fetched, err := client.GetProduct(ctx, &productsv1.GetProductRequest{MerchantId: merchantId.String(), SkuId: created.SkuId})
+			if err != nil {
+				t.Fatal(err)
+			}
+			if fetched.Name != request.Name || fetched.Gtin != request.Gtin {
+				t.Fatalf("expected the created product, got %v", fetched)
+			}
+
+			updated, err := client.UpdateProduct(ctx, &productsv1.UpdateProductRequest{
+				MerchantId:  merchantId.String(),
+				SkuId:       created.SkuId,
+				Name:        "renamed",
+				Description: "described again",
+				Price:       12.5,
+				TaxClass:    "reduced",
+			})
+			if err != nil {
+				t.Fatal(err)
+			}
+			if updated.Name != "renamed" || updated.Price != 12.5 || updated.TaxClass != "reduced" {
+				t.Fatalf("expected the update to be applied, got %v", updated)
+			}
+
+			if _, err := client.DeleteProduct(ctx, &productsv1.DeleteProductRequest{MerchantId: merchantId.String(), SkuId: created.SkuId}); err != nil {
+				t.Fatal(err)
+			}
+			_, err = client.GetProduct(ctx, &productsv1.GetProductRequest{MerchantId: merchantId.String(), SkuId: created.SkuId})
+			assertCode(t, codes.NotFound, err)
+		},
+	)
+
+	t.Run(`Given exchange rates for EUR,
+    when a product is read with a currency,
+    then its converted prices should be returned, and INVALID_ARGUMENT for a currency without a rate. `,
+		func(t *testing.T) {
+			request := newCreateProductRequest(merchantId)
+			request.Price = 10
+			if _, err := client.CreateProduct(ctx, request); err != nil {
+				t.Fatal(err)
+			}
+
+			fetched, err := client.GetProduct(ctx, &productsv1.GetProductRequest{MerchantId: merchantId.String(), SkuId: request.SkuId, Currency: "EUR"})
+			if err != nil {
+				t.Fatal(err)
+			}
+			if fetched.Converted.GetCurrency() != "EUR" || fetched.Converted.GetPrice() != 5 || fetched.Converted.GetExchangeRateVersion() != 1 {
+				t.Fatalf("expected 5 EUR at the first rates, got %v", fetched.Converted)
+			}
+
+			_, err = client.GetProduct(ctx, &productsv1.GetProductRequest{MerchantId: merchantId.String(), SkuId: request.SkuId, Currency: "GBP"})
+			assertCode(t, codes.InvalidArgument, err)
+		},
+	)
+
+	t.Run(`Given tax rates for DE,
+    when a product is read with a region,
+    then its taxed prices should be returned, and INVALID_ARGUMENT for a region without rates or an unknown display. `,
+		func(t *testing.T) {
+			request := newCreateProductRequest(merchantId)
+			request.Price = 10
+			request.TaxClass = "reduced"
+			if _, err := client.CreateProduct(ctx, request); err != nil {
+				t.Fatal(err)
+			}
+
+			fetched, err := client.GetProduct(ctx, &productsv1.GetProductRequest{MerchantId: merchantId.String(), SkuId: request.SkuId, Region: "DE", TaxDisplay: "exclusive"})
+			if err != nil {
+				t.Fatal(err)
+			}
+			if fetched.Tax.GetRegion() != "DE" || fetched.Tax.GetPrice() != 10 || fetched.Tax.GetRegular().GetGross() != 10.7 {
+				t.Fatalf("expected a net 10 and gross 10.7 in DE, got %v", fetched.Tax)
+			}
+
+			_, err = client.GetProduct(ctx, &productsv1.GetProductRequest{MerchantId: merchantId.String(), SkuId: request.SkuId, Region: "FR"})
+			assertCode(t, codes.InvalidArgument, err)
+			_, err = client.GetProduct(ctx, &productsv1.GetProductRequest{MerchantId: merchantId.String(), SkuId: request.SkuId, Region: "DE", TaxDisplay: "net"})
+			assertCode(t, codes.InvalidArgument, err)
+		},
+	)
+
+	t.Run(`Given requests the REST API would reject,
+    when they are made over gRPC,
+    then the status codes should match the REST statuses. `,
+		func(t *testing.T) {
+			request := newCreateProductRequest(merchantId)
+			if _, err := client.CreateProduct(ctx, request); err != nil {
+				t.Fatal(err)
+			}
+			_, err := client.CreateProduct(ctx, request)
+			assertCode(t, codes.AlreadyExists, err)
+
+			_, err = client.GetProduct(ctx, &productsv1.GetProductRequest{MerchantId: uuid.New().String(), SkuId: request.SkuId})
+			assertCode(t, codes.NotFound, err)
+
+			_, err = client.GetProduct(ctx, &productsv1.GetProductRequest{MerchantId: "not-an-id", SkuId: request.SkuId})
+			assertCode(t, codes.InvalidArgument, err)
+
+			invalid := newCreateProductRequest(merchantId)
+			invalid.Gtin = "4006381333932"
+			_, err = client.CreateProduct(ctx, invalid)
+			assertCode(t, codes.InvalidArgument, err)
+
+			taken := newCreateProductRequest(merchantId)
+			taken.SkuCode = "TAKEN-1"
+			if _, err := client.CreateProduct(ctx, taken); err != nil {
+				t.Fatal(err)
+			}
+			taken = newCreateProductRequest(merchantId)
+			taken.SkuCode = "TAKEN-1"
+			_, err = client.CreateProduct(ctx, taken)
+			assertCode(t, codes.AlreadyExists, err)
+		},
+	)
+
+	t.Run(`Given a merchant with several products,
+    when they are listed,
+    then every product should be streamed and no other merchant's. `,
+		func(t *testing.T) {
+			listedMerchantId := uuid.New()
+			expected := map[string]bool{}
+			for i := 0; i < 3; i++ {
+				product, err := client.CreateProduct(ctx, newCreateProductRequest(listedMerchantId))
+				if err != nil {
+					t.Fatal(err)
+				}
+				expected[product.SkuId] = true
+			}
+			if _, err := client.CreateProduct(ctx, newCreateProductRequest(uuid.New())); err != nil {
+				t.Fatal(err)
+			}
+
+			stream, err := client.ListProducts(ctx, &productsv1.ListProductsRequest{MerchantId: listedMerchantId.String()})
+			if err != nil {
+				t.Fatal(err)
+			}
+			listed := 0
+			for {
+				product, err := stream.Recv()
+				if errors.Is(err, io.EOF) {
+					break
+				}
+				if err != nil {
+					t.Fatal(err)
+				}
+				if !expected[product.SkuId] {
+					t.Fatalf("unexpected product %s in the listing", product.SkuId)
+				}
+				listed++
+			}
+			if listed != len(expected) {
+				t.Fatalf("expected %d products, got %d", len(expected), listed)
+			}
+		},
+	)
+
+	t.Run(`Given a watch on a merchant's products,
+    when products are created, updated and deleted,
+    then the watcher should receive each change in order and none from other merchants. `,
+		func(t *testing.T) {
+			watchedMerchantId := uuid.New()
+			watchCtx, cancel := context.WithTimeout(ctx, 5*time.Second)
+			defer cancel()
+			stream, err := client.WatchProducts(watchCtx, &productsv1.WatchProductsRequest{MerchantId: watchedMerchantId.String()})
+			if err != nil {
+				t.Fatal(err)
+			}
+			// the headers arrive once the watch has started
+			if _, err := stream.Header(); err != nil {
+				t.Fatal(err)
+			}
+
+			if _, err := client.CreateProduct(ctx, newCreateProductRequest(uuid.New())); err != nil {
+				t.Fatal(err)
+			}
+			created, err := client.CreateProduct(ctx, newCreateProductRequest(watchedMerchantId))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if _, err := client.UpdateProduct(ctx, &productsv1.UpdateProductRequest{MerchantId: watchedMerchantId.String(), SkuId: created.SkuId, Name: "watched", Description: "watched", Price: 1}); err != nil {
+				t.Fatal(err)
+			}
+			if _, err := client.DeleteProduct(ctx, &productsv1.DeleteProductRequest{MerchantId: watchedMerchantId.String(), SkuId: created.SkuId}); err != nil {
+				t.Fatal(err)
+			}
+
+			for _, expected := range []productsv1.ProductEvent_Type{
+				productsv1.ProductEvent_TYPE_CREATED,
+				productsv1.ProductEvent_TYPE_UPDATED,
+				productsv1.ProductEvent_TYPE_DELETED,
+			} {
+				event, err := stream.Recv()
+				if err != nil {
+					t.Fatal(err)
+				}
+				if event.Type != expected || event.Product.SkuId != created.SkuId {
+					t.Fatalf("expected %s for %s, got %s for %s", expected, created.SkuId, event.Type, event.Product.SkuId)
+				}
+			}
+		},
+	)
+}
+
+func TestGRPCServerStop(t *testing.T) {
+	t.Run(`Given a running watch,
+    when the gRPC server is stopped,
+    then the watch should end with UNAVAILABLE without holding shutdown up. `,
+		func(t *testing.T) {
+			ctx, cancel := context.WithCancel(context.Background())
+			defer cancel()
+			server := lifecycle.NewGRPCServer("127.0.0.1:0")
+			manager, err := lifecycle.NewManager(logging.Discard().Logger)
+			if err != nil {
+				t.Fatal(err)
+			}
+			router.NewHttpRouter(ctx, config.Default(), logging.Discard(), newHealthChecker(), manager, server.Server())
+			if err := server.Start(ctx); err != nil {
+				t.Fatal(err)
+			}
+
+			conn, err := grpc.NewClient(server.Addr().String(), grpc.WithTransportCredentials(insecure.NewCredentials()))
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer conn.Close()
+			stream, err := productsv1.NewProductServiceClient(conn).WatchProducts(ctx, &productsv1.WatchProductsRequest{MerchantId: uuid.New().String()})
+			if err != nil {
+				t.Fatal(err)
+			}
+			if _, err := stream.Header(); err != nil {
+				t.Fatal(err)
+			}
+
+			stopCtx, stopCancel := context.WithTimeout(context.Background(), 5*time.Second)
+			defer stopCancel()
+			if err := server.Stop(stopCtx); err != nil {
+				t.Fatalf("expected the server to stop cleanly, got %v", err)
+			}
+			_, err = stream.Recv()
+			assertCode(t, codes.Unavailable, err)
+		},
+	)
+}
@@ -0,0 +1,173 @@
+//go:build integration
+// +build integration
+
+package integration
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/olad5/sal-backend-service/internal/config"
+	"github.com/olad5/sal-backend-service/internal/health"
+	"github.com/olad5/sal-backend-service/internal/logging"
+	"github.com/olad5/sal-backend-service/tests"
+)
+
+type healthFunc func(ctx context.Context) error
+
+func (f healthFunc) CheckHealth(ctx context.Context) error { return f(ctx) }
+
+func TestHealth(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	newProbedRouter := func(t *testing.T) (http.Handler, *health.Checker, config.Config) {
+		t.Helper()
+		cfg := config.Default()
+		cfg.Storage.MediaDir = filepath.Join(t.TempDir(), "media")
+		checker, err := health.NewChecker(100 * time.Millisecond)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return newRouter(ctx, cfg, logging.Discard(), checker), checker, cfg
+	}
+	probe := func(t *testing.T, handler http.Handler, path string) (int, health.Report) {
+		t.Helper()
+		req, _ := http.NewRequest(http.MethodGet, path, nil)
+		response := tests.ExecuteRequest(req, handler)
+		var report health.Report
+		if err := json.Unmarshal(response.Body.Bytes(), &report); err != nil {
+			t.Fatalf("expected a JSON health report, got %q", response.Body.String())
+		}
+		return response.Code, report
+	}
+	// the price scheduler starts in the background, so wait for it before
+	// checking anything else
+	waitForReady := func(t *testing.T, handler http.Handler) health.Report {
+		t.Helper()
+		deadline := time.Now().Add(2 * time.Second)
+		for {
+			code, report := probe(t, handler, "/readyz")
+			if code == http.StatusOK || time.Now().After(deadline) {
+				tests.AssertStatusCode(t, http.StatusOK, code)
+				return report
+			}
+			time.Sleep(10 * time.Millisecond)
+		}
+	}
+
+	t.Run(`Given a service that has not finished starting,
+    when it is probed,
+    then it should be alive but not ready until startup is marked done. `,
+		func(t *testing.T) {
+			probed, checker, _ := newProbedRouter(t)
+
+			code, report := probe(t, probed, "/readyz")
+			tests.AssertStatusCode(t, http.StatusServiceUnavailable, code)
+			if report.Phase != health.PhaseStarting || report.Status != health.StatusUnavailable {
+				t.Fatalf("expected an unavailable report while starting, got %+v", report)
+			}
+			code, _ = probe(t, probed, "/healthz")
+			tests.AssertStatusCode(t, http.StatusOK, code)
+
+			checker.MarkReady()
+			report = waitForReady(t, probed)
+			if report.Phase != health.PhaseReady {
+				t.Fatalf("expected the ready phase, got %+v", report)
+			}
+			for _, name := range []string{"product_repository", "blob_storage", "search_index", "idempotency_repository", "price_scheduler"} {
+				if report.Components[name].Status != health.StatusOK {
+					t.Fatalf("expected %s to be reported ok, got %+v", name, report.Components)
+				}
+			}
+		},
+	)
+
+	t.Run(`Given a ready service,
+    when it starts draining,
+    then readiness should fail and stay failed while liveness passes. `,
+		func(t *testing.T) {
+			probed, checker, _ := newProbedRouter(t)
+			checker.MarkReady()
+			waitForReady(t, probed)
+
+			checker.MarkDraining()
+			checker.MarkReady()
+			code, report := probe(t, probed, "/readyz")
+			tests.AssertStatusCode(t, http.StatusServiceUnavailable, code)
+			if report.Phase != health.PhaseDraining {
+				t.Fatalf("expected the draining phase, got %+v", report)
+			}
+			code, _ = probe(t, probed, "/healthz")
+			tests.AssertStatusCode(t, http.StatusOK, code)
+		},
+	)
+
+	t.Run(`Given the media directory has gone,
+    when readiness is probed,
+    then blob storage should be reported unavailable. `,
+		func(t *testing.T) {
+			probed, checker, cfg := newProbedRouter(t)
+			checker.MarkReady()
+			waitForReady(t, probed)
+
+			if err := os.RemoveAll(cfg.Storage.MediaDir); err != nil {
+				t.Fatal(err)
+			}
+			code, report := probe(t, probed, "/readyz")
+			tests.AssertStatusCode(t, http.StatusServiceUnavailable, code)
+			if component := report.Components["blob_storage"]; component.Status != health.StatusUnavailable || component.Error == "" {
+				t.Fatalf("expected blob storage to be unavailable, got %+v", component)
+			}
+			if report.Components["product_repository"].Status != health.StatusOK {
+				t.Fatalf("expected other components to stay ok, got %+v", report.Components)
+			}
+		},
+	)
+
+	t.Run(`Given components that fail or hang,
+    when they are probed,
+    then readiness should report each and liveness should only fail for liveness components. `,
+		func(t *testing.T) {
+			probed, checker, _ := newProbedRouter(t)
+			checker.MarkReady()
+			waitForReady(t, probed)
+
+			checker.Register("failing", healthFunc(func(ctx context.Context) error {
+				return errors.New("connection refused")
+			}))
+			checker.Register("hanging", healthFunc(func(ctx context.Context) error {
+				time.Sleep(time.Second)
+				return nil
+			}))
+			start := time.Now()
+			code, report := probe(t, probed, "/readyz")
+			tests.AssertStatusCode(t, http.StatusServiceUnavailable, code)
+			if elapsed := time.Since(start); elapsed > 500*time.Millisecond {
+				t.Fatalf("expected the hanging check to time out, took %s", elapsed)
+			}
+			if got := report.Components["failing"]; got.Error != "connection refused" {
+				t.Fatalf("expected the failing component's error, got %+v", got)
+			}
+			if got := report.Components["hanging"]; got.Error != "health check timed out" {
+				t.Fatalf("expected the hanging component to time out, got %+v", got)
+			}
+			code, _ = probe(t, probed, "/healthz")
+			tests.AssertStatusCode(t, http.StatusOK, code)
+
+			checker.RegisterLiveness("stuck_worker", healthFunc(func(ctx context.Context) error {
+				return errors.New("worker stopped")
+			}))
+			code, report = probe(t, probed, "/healthz")
+			tests.AssertStatusCode(t, http.StatusServiceUnavailable, code)
+			if _, ok := report.Components["failing"]; ok || report.Components["stuck_worker"].Error != "worker stopped" {
+				t.Fatalf("expected only liveness components in the liveness report, got %+v", report.Components)
+			}
+		},
+	)
+}
@@ -0,0 +1,150 @@
+//go:build integration
+// +build integration
+
+package integration
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/olad5/sal-backend-service/internal/app/middleware"
+	"github.com/olad5/sal-backend-service/internal/infra/memory"
+	"github.com/olad5/sal-backend-service/internal/logging"
+	"github.com/olad5/sal-backend-service/tests"
+)
+
+func TestIdempotencyKeys(t *testing.T) {
+	createWithKey := func(key string, np Product) *httptest.ResponseRecorder {
+		requestBody, _ := json.Marshal(np)
+		req, _ := http.NewRequest(http.MethodPost, "/api/products", bytes.NewBuffer(requestBody))
+		req.Header.Set(middleware.IdempotencyKeyHeader, key)
+		return tests.ExecuteRequest(req, r)
+	}
+
+	t.Run(`Given a product was created with an idempotency key,
+    when the same request is retried with the same key,
+    then the original response should be replayed instead of an already exists error. `,
+		func(t *testing.T) {
+			key := uuid.New().String()
+			prd := buildProduct(uuid.New(), uuid.New())
+
+			first := createWithKey(key, prd)
+			tests.AssertStatusCode(t, http.StatusOK, first.Code)
+			retry := createWithKey(key, prd)
+			tests.AssertStatusCode(t, http.StatusOK, retry.Code)
+			if retry.Header().Get(middleware.IdempotentReplayedHeader) != "true" {
+				t.Fatalf("expected the response to be marked as replayed")
+			}
+			if retry.Body.String() != first.Body.String() {
+				t.Fatalf("expected the replayed body %s, got %s", first.Body.String(), retry.Body.String())
+			}
+		},
+	)
+
+	t.Run(`Given a product was created with an idempotency key and a request ID,
+    when the request is retried with the same key and its own request ID,
+    then the replayed response should echo the retry's request ID. `,
+		func(t *testing.T) {
+			key := uuid.New().String()
+			requestBody, _ := json.Marshal(buildProduct(uuid.New(), uuid.New()))
+			for _, requestId := range []string{"first-attempt", "retry"} {
+				req, _ := http.NewRequest(http.MethodPost, "/api/products", bytes.NewBuffer(requestBody))
+				req.Header.Set(middleware.IdempotencyKeyHeader, key)
+				req.Header.Set(middleware.RequestIDHeader, requestId)
+				response := tests.ExecuteRequest(req, r)
+				tests.AssertStatusCode(t, http.StatusOK, response.Code)
+				if got := response.Header().Get(middleware.RequestIDHeader); got != requestId {
+					t.Fatalf("expected request ID %q, got %q", requestId, got)
+				}
+			}
+		},
+	)
+
+	t.Run(`Given an idempotency key was already used,
+    when it is reused with a different payload,
+    then the API should return an unprocessable entity error. `,
+		func(t *testing.T) {
+			key, merchantId := uuid.New().String(), uuid.New()
+			tests.AssertStatusCode(t, http.StatusOK, createWithKey(key, buildProduct(merchantId, uuid.New())).Code)
+			tests.AssertStatusCode(t, http.StatusUnprocessableEntity, createWithKey(key, buildProduct(merchantId, uuid.New())).Code)
+		},
+	)
+
+	t.Run(`Given two merchants behind the same client IP,
+    when they use the same idempotency key with their merchant_id in the body or the path,
+    then each request should be handled on its own. `,
+		func(t *testing.T) {
+			key := uuid.New().String()
+			for i := 0; i < 2; i++ {
+				response := createWithKey(key, buildProduct(uuid.New(), uuid.New()))
+				tests.AssertStatusCode(t, http.StatusOK, response.Code)
+				if response.Header().Get(middleware.IdempotentReplayedHeader) != "" {
+					t.Fatalf("expected create %d not to be a replay", i+1)
+				}
+			}
+
+			for i := 0; i < 2; i++ {
+				req, _ := http.NewRequest(http.MethodPut, "/api/merchants/"+uuid.New().String()+"/sku-code-pattern", bytes.NewBufferString(`{"pattern": "SKU-{seq:5}"}`))
+				req.Header.Set(middleware.IdempotencyKeyHeader, key)
+				response := tests.ExecuteRequest(req, r)
+				tests.AssertStatusCode(t, http.StatusOK, response.Code)
+				if response.Header().Get(middleware.IdempotentReplayedHeader) != "" {
+					t.Fatalf("expected pattern update %d not to be a replay", i+1)
+				}
+			}
+		},
+	)
+}
+
+func TestIdempotencyKeyExpiry(t *testing.T) {
+	fakeClock := tests.NewFakeClock(time.Date(2024, time.June, 1, 8, 0, 0, 0, time.UTC))
+	repo, _ := memory.NewMemoryIdempotencyRepo()
+	idempotency, err := middleware.Idempotency(repo, fakeClock, logging.Discard().Logger, time.Hour)
+	if err != nil {
+		t.Fatal(err)
+	}
+	calls := 0
+	handler := idempotency(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		if calls == 1 {
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+		w.WriteHeader(http.StatusCreated)
+	}))
+	post := func() int {
+		req, _ := http.NewRequest(http.MethodPost, "/anything", bytes.NewBufferString(`{}`))
+		req.Header.Set(middleware.IdempotencyKeyHeader, "retry-me")
+		return tests.ExecuteRequest(req, handler).Code
+	}
+
+	t.Run(`Given the first attempt failed with a server error,
+    when the request is retried with the same key,
+    then it should be handled again and its response stored. `,
+		func(t *testing.T) {
+			tests.AssertStatusCode(t, http.StatusInternalServerError, post())
+			tests.AssertStatusCode(t, http.StatusCreated, post())
+			tests.AssertStatusCode(t, http.StatusCreated, post())
+			if calls != 2 {
+				t.Fatalf("expected the handler to run twice, ran %d times", calls)
+			}
+		},
+	)
+
+	t.Run(`Given a stored response older than the key TTL,
+    when the request is retried with the same key,
+    then it should be handled again. `,
+		func(t *testing.T) {
+			fakeClock.Advance(time.Hour)
+			tests.AssertStatusCode(t, http.StatusCreated, post())
+			if calls != 3 {
+				t.Fatalf("expected the handler to run again once the key expired, ran %d times", calls)
+			}
+		},
+	)
+}
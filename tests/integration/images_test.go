@@ -0,0 +1,170 @@
+//go:build integration
+// +build integration
+
+package integration
+
+import (
+	"bytes"
+	"encoding/json"
+	"image"
+	"image/color"
+	"image/png"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/olad5/sal-backend-service/internal/usecases/products"
+	"github.com/olad5/sal-backend-service/tests"
+)
+
+func TestProductImages(t *testing.T) {
+	t.Run(`Given a merchant has an existing product,
+    when they upload an image for it,
+    then the image should be attached as the primary image
+    and a resized thumbnail should be served from its thumbnail url. `,
+		func(t *testing.T) {
+			merchantId := uuid.New()
+			skuId := createProduct(t, buildProduct(merchantId, uuid.New()))
+
+			response := uploadImage(t, skuId, merchantId, buildPNG(t, 800, 400), "front view")
+			tests.AssertStatusCode(t, http.StatusOK, response.Code)
+			data := tests.ParseResponse(t, response)["data"].(map[string]interface{})
+			tests.AssertResponseMessage(t, data["alt_text"].(string), "front view")
+			if isPrimary := data["is_primary"].(bool); !isPrimary {
+				t.Fatalf("expected first image to be primary")
+			}
+
+			req, _ := http.NewRequest(http.MethodGet, data["thumbnail_url"].(string), nil)
+			thumbnailResponse := tests.ExecuteRequest(req, r)
+			tests.AssertStatusCode(t, http.StatusOK, thumbnailResponse.Code)
+			thumbnail, _, err := image.Decode(thumbnailResponse.Body)
+			if err != nil {
+				t.Fatalf("unable to decode thumbnail: %v", err)
+			}
+			if bounds := thumbnail.Bounds(); bounds.Dx() != 320 || bounds.Dy() != 160 {
+				t.Fatalf("expected a 320x160 thumbnail, got %dx%d", bounds.Dx(), bounds.Dy())
+			}
+		},
+	)
+
+	t.Run(`Given a product with two images,
+    when the merchant marks the second image as primary and moves it to the front,
+    then the product should list it first as the only primary image. `,
+		func(t *testing.T) {
+			merchantId := uuid.New()
+			skuId := createProduct(t, buildProduct(merchantId, uuid.New()))
+			_ = uploadImage(t, skuId, merchantId, buildPNG(t, 10, 10), "first")
+			second := tests.ParseResponse(t, uploadImage(t, skuId, merchantId, buildPNG(t, 10, 10), "second"))["data"].(map[string]interface{})
+
+			requestBody, err := json.Marshal(map[string]interface{}{
+				"merchant_id": merchantId,
+				"position":    0,
+				"is_primary":  true,
+			})
+			if err != nil {
+				t.Fatal(err)
+			}
+			req, _ := http.NewRequest(http.MethodPatch, "/api/products/"+skuId.String()+"/images/"+second["id"].(string), bytes.NewBuffer(requestBody))
+			response := tests.ExecuteRequest(req, r)
+			tests.AssertStatusCode(t, http.StatusOK, response.Code)
+
+			req, _ = http.NewRequest(http.MethodGet, "/api/merchants/"+merchantId.String()+"/products", nil)
+			listResponse := tests.ExecuteRequest(req, r)
+			data := tests.ParseResponse(t, listResponse)["data"].(map[string]interface{})
+			product := data["products"].([]interface{})[0].(map[string]interface{})
+			images := product["images"].([]interface{})
+			if len(images) != 2 {
+				t.Fatalf("expected 2 images, got %d", len(images))
+			}
+			first := images[0].(map[string]interface{})
+			tests.AssertResponseMessage(t, first["alt_text"].(string), "second")
+			if !first["is_primary"].(bool) || images[1].(map[string]interface{})["is_primary"].(bool) {
+				t.Fatalf("expected only the first image to be primary")
+			}
+		},
+	)
+
+	t.Run(`Given a merchant uploads a file that is not an image,
+    when the upload endpoint is called,
+    then the API should reject it as an unsupported media type. `,
+		func(t *testing.T) {
+			merchantId := uuid.New()
+			skuId := createProduct(t, buildProduct(merchantId, uuid.New()))
+
+			response := uploadImage(t, skuId, merchantId, []byte("definitely not an image"), "")
+			tests.AssertStatusCode(t, http.StatusUnsupportedMediaType, response.Code)
+		},
+	)
+
+	t.Run(`Given a merchant uploads an image wider than the maximum,
+    when the upload endpoint is called,
+    then the API should reject it before decoding it. `,
+		func(t *testing.T) {
+			merchantId := uuid.New()
+			skuId := createProduct(t, buildProduct(merchantId, uuid.New()))
+
+			response := uploadImage(t, skuId, merchantId, buildPNG(t, products.MaxImageWidth+1, 1), "")
+			tests.AssertStatusCode(t, http.StatusBadRequest, response.Code)
+			tests.AssertResponseMessage(t, tests.ParseResponse(t, response)["message"].(string), products.ErrImageTooManyPixels.Error())
+		},
+	)
+
+	t.Run(`Given a product with an image,
+    when the merchant deletes the product,
+    then the image should no longer be served. `,
+		func(t *testing.T) {
+			merchantId := uuid.New()
+			skuId := createProduct(t, buildProduct(merchantId, uuid.New()))
+			data := tests.ParseResponse(t, uploadImage(t, skuId, merchantId, buildPNG(t, 10, 10), ""))["data"].(map[string]interface{})
+
+			requestBody, err := json.Marshal(map[string]interface{}{"merchant_id": merchantId})
+			if err != nil {
+				t.Fatal(err)
+			}
+			req, _ := http.NewRequest(http.MethodDelete, "/api/products/"+skuId.String(), bytes.NewBuffer(requestBody))
+			tests.AssertStatusCode(t, http.StatusOK, tests.ExecuteRequest(req, r).Code)
+
+			req, _ = http.NewRequest(http.MethodGet, data["url"].(string), nil)
+			tests.AssertStatusCode(t, http.StatusNotFound, tests.ExecuteRequest(req, r).Code)
+		},
+	)
+}
+
+func uploadImage(t testing.TB, skuId, merchantId uuid.UUID, content []byte, altText string) *httptest.ResponseRecorder {
+	t.Helper()
+	var body bytes.Buffer
+	writer := multipart.NewWriter(&body)
+	_ = writer.WriteField("merchant_id", merchantId.String())
+	_ = writer.WriteField("alt_text", altText)
+	part, err := writer.CreateFormFile("image", "image.png")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := part.Write(content); err != nil {
+		t.Fatal(err)
+	}
+	if err := writer.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	req, _ := http.NewRequest(http.MethodPost, "/api/products/"+skuId.String()+"/images", &body)
+	req.Header.Set("Content-Type", writer.FormDataContentType())
+	return tests.ExecuteRequest(req, r)
+}
+
+func buildPNG(t testing.TB, width, height int) []byte {
+	t.Helper()
+	img := image.NewRGBA(image.Rect(0, 0, width, height))
+	for y := 0; y < height; y++ {
+		for x := 0; x < width; x++ {
+			img.Set(x, y, color.RGBA{R: uint8(x), G: uint8(y), B: 128, A: 255})
+		}
+	}
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, img); err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
@@ -0,0 +1,217 @@
+//go:build integration
+// +build integration
+
+package integration
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"net"
+	"net/http"
+	"reflect"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/olad5/sal-backend-service/internal/app/router"
+	"github.com/olad5/sal-backend-service/internal/config"
+	"github.com/olad5/sal-backend-service/internal/lifecycle"
+	"github.com/olad5/sal-backend-service/internal/logging"
+	"github.com/olad5/sal-backend-service/tests"
+)
+
+// lifecycleRecorder notes the order components start, stop and reload in.
+type lifecycleRecorder struct {
+	lock   sync.Mutex
+	events []string
+}
+
+func (r *lifecycleRecorder) record(event string) {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	r.events = append(r.events, event)
+}
+
+func (r *lifecycleRecorder) component(name string, startErr error) lifecycle.Hooks {
+	return lifecycle.Hooks{
+		OnStart: func(ctx context.Context) error {
+			r.record("start " + name)
+			return startErr
+		},
+		OnStop: func(ctx context.Context) error {
+			r.record("stop " + name)
+			return nil
+		},
+		OnReload: func(ctx context.Context, cfg config.Config) error {
+			r.record("reload " + name)
+			return nil
+		},
+	}
+}
+
+func TestLifecycle(t *testing.T) {
+	newManager := func(t *testing.T) *lifecycle.Manager {
+		t.Helper()
+		manager, err := lifecycle.NewManager(logging.Discard().Logger)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return manager
+	}
+	assertEvents := func(t *testing.T, recorder *lifecycleRecorder, expected ...string) {
+		t.Helper()
+		if !reflect.DeepEqual(recorder.events, expected) {
+			t.Fatalf("expected %v, got %v", expected, recorder.events)
+		}
+	}
+
+	t.Run(`Given components added in dependency order,
+    when the manager starts, reloads and stops them,
+    then they should start and reload in order and stop in reverse. `,
+		func(t *testing.T) {
+			recorder := &lifecycleRecorder{}
+			manager := newManager(t)
+			for _, name := range []string{"repository", "worker", "server"} {
+				manager.Add(name, recorder.component(name, nil), time.Second)
+			}
+
+			if err := manager.Start(context.Background()); err != nil {
+				t.Fatalf("expected the components to start, got %v", err)
+			}
+			if err := manager.Reload(context.Background(), config.Default()); err != nil {
+				t.Fatalf("expected the components to reload, got %v", err)
+			}
+			if err := manager.Stop(); err != nil {
+				t.Fatalf("expected the components to stop, got %v", err)
+			}
+			assertEvents(t, recorder,
+				"start repository", "start worker", "start server",
+				"reload repository", "reload worker", "reload server",
+				"stop server", "stop worker", "stop repository",
+			)
+		},
+	)
+
+	t.Run(`Given a component that fails to start,
+    when the manager starts,
+    then the components already started should be stopped again. `,
+		func(t *testing.T) {
+			recorder := &lifecycleRecorder{}
+			manager := newManager(t)
+			manager.Add("repository", recorder.component("repository", nil), time.Second)
+			manager.Add("worker", recorder.component("worker", nil), time.Second)
+			manager.Add("server", recorder.component("server", errors.New("address in use")), time.Second)
+
+			err := manager.Start(context.Background())
+			if err == nil || err.Error() != "failed to start server: address in use" {
+				t.Fatalf("expected the server's start error, got %v", err)
+			}
+			assertEvents(t, recorder,
+				"start repository", "start worker", "start server",
+				"stop worker", "stop repository",
+			)
+			if err := manager.Stop(); err != nil {
+				t.Fatalf("expected nothing left to stop, got %v", err)
+			}
+		},
+	)
+
+	t.Run(`Given components that hang or fail while stopping,
+    when the manager stops,
+    then it should report them and still stop the rest. `,
+		func(t *testing.T) {
+			recorder := &lifecycleRecorder{}
+			manager := newManager(t)
+			manager.Add("repository", recorder.component("repository", nil), time.Second)
+			manager.Add("flusher", lifecycle.Hooks{OnStop: func(ctx context.Context) error {
+				return errors.New("disk full")
+			}}, time.Second)
+			manager.Add("worker", lifecycle.NewWorker(func(ctx context.Context) {
+				<-ctx.Done()
+				// ignores its deadline
+				time.Sleep(time.Second)
+			}), 50*time.Millisecond)
+			if err := manager.Start(context.Background()); err != nil {
+				t.Fatal(err)
+			}
+
+			start := time.Now()
+			err := manager.Stop()
+			if elapsed := time.Since(start); elapsed > 500*time.Millisecond {
+				t.Fatalf("expected the hanging worker to be given up on, took %s", elapsed)
+			}
+			var stopErr *lifecycle.StopError
+			if !errors.As(err, &stopErr) || len(stopErr.Failures) != 2 {
+				t.Fatalf("expected two components to fail to stop, got %v", err)
+			}
+			if failure := stopErr.Failures[0]; failure.Component != "worker" || !errors.Is(failure.Err, lifecycle.ErrStopTimedOut) {
+				t.Fatalf("expected the worker to time out, got %+v", failure)
+			}
+			if failure := stopErr.Failures[1]; failure.Component != "flusher" || failure.Err.Error() != "disk full" {
+				t.Fatalf("expected the flusher's error, got %+v", failure)
+			}
+			assertEvents(t, recorder, "start repository", "stop repository")
+		},
+	)
+
+	t.Run(`Given an address that is already taken,
+    when the HTTP server starts,
+    then starting should fail. `,
+		func(t *testing.T) {
+			taken, err := net.Listen("tcp", "127.0.0.1:0")
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer taken.Close()
+
+			server := lifecycle.NewHTTPServer(&http.Server{Addr: taken.Addr().String(), Handler: http.NotFoundHandler()})
+			if err := server.Start(context.Background()); err == nil {
+				server.Stop(context.Background())
+				t.Fatal("expected the server to fail to start")
+			}
+		},
+	)
+
+	t.Run(`Given a running service,
+    when the config is reloaded with a new log level and rate limits,
+    then they should apply without a restart. `,
+		func(t *testing.T) {
+			cfg := config.Default()
+			cfg.RateLimit.ReadsPerMinute = 0
+			cfg.RateLimit.WritesPerMinute = 0
+			logger := logging.New(&logBuffer{}, slog.LevelInfo)
+			manager := newManager(t)
+			reloaded := router.NewHttpRouter(context.Background(), cfg, logger, newHealthChecker(), manager, nil)
+			if err := manager.Start(context.Background()); err != nil {
+				t.Fatal(err)
+			}
+			defer manager.Stop()
+
+			readFrom := func(remoteAddr string) int {
+				req, _ := http.NewRequest(http.MethodGet, "/api/plans", nil)
+				req.RemoteAddr = remoteAddr
+				return tests.ExecuteRequest(req, reloaded).Code
+			}
+			tests.AssertStatusCode(t, http.StatusOK, readFrom("10.0.1.1:5000"))
+			tests.AssertStatusCode(t, http.StatusOK, readFrom("10.0.1.1:5000"))
+
+			next := cfg
+			next.Log.Level = "debug"
+			next.RateLimit.ReadsPerMinute = 1
+			next.Server.Port = cfg.Server.Port + 1
+			if keys := config.RestartRequired(cfg, next); !reflect.DeepEqual(keys, []string{"server.port"}) {
+				t.Fatalf("expected only the port to need a restart, got %v", keys)
+			}
+			if err := manager.Reload(context.Background(), next); err != nil {
+				t.Fatalf("expected the config to reload, got %v", err)
+			}
+
+			if logger.Level() != slog.LevelDebug {
+				t.Fatalf("expected the debug log level, got %s", logger.Level())
+			}
+			tests.AssertStatusCode(t, http.StatusOK, readFrom("10.0.1.2:5000"))
+			tests.AssertStatusCode(t, http.StatusTooManyRequests, readFrom("10.0.1.2:5000"))
+		},
+	)
+}
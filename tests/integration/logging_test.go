@@ -0,0 +1,163 @@
+//go:build integration
+// +build integration
+
+package integration
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/olad5/sal-backend-service/internal/app/middleware"
+	"github.com/olad5/sal-backend-service/internal/config"
+	"github.com/olad5/sal-backend-service/internal/logging"
+	"github.com/olad5/sal-backend-service/tests"
+)
+
+// logBuffer collects log lines, the price scheduler may log from its own
+// goroutine
+type logBuffer struct {
+	lock sync.Mutex
+	buf  bytes.Buffer
+}
+
+func (b *logBuffer) Write(p []byte) (int, error) {
+	b.lock.Lock()
+	defer b.lock.Unlock()
+	return b.buf.Write(p)
+}
+
+// entries returns the decoded log lines with the given message.
+func (b *logBuffer) entries(t *testing.T, msg string) []map[string]interface{} {
+	t.Helper()
+	b.lock.Lock()
+	defer b.lock.Unlock()
+	var found []map[string]interface{}
+	for _, line := range strings.Split(strings.TrimSpace(b.buf.String()), "\n") {
+		if line == "" {
+			continue
+		}
+		var entry map[string]interface{}
+		if err := json.Unmarshal([]byte(line), &entry); err != nil {
+			t.Fatalf("expected JSON log lines, got %q", line)
+		}
+		if entry["msg"] == msg {
+			found = append(found, entry)
+		}
+	}
+	return found
+}
+
+func TestLogging(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	logs := &logBuffer{}
+	cfg := config.Default()
+	cfg.RateLimit.ReadsPerMinute = 0
+	cfg.RateLimit.WritesPerMinute = 0
+	cfg.Admin.Token = adminToken
+	logged := newRouter(ctx, cfg, logging.New(logs, slog.LevelInfo), newHealthChecker())
+
+	t.Run(`Given a request with an X-Request-ID,
+    when it is served,
+    then the ID should be echoed and the access log should carry it with the route and merchant. `,
+		func(t *testing.T) {
+			merchantId := uuid.New()
+			requestId := "req-" + uuid.NewString()
+			req, _ := http.NewRequest(http.MethodGet, "/api/merchants/"+merchantId.String()+"/products", nil)
+			req.Header.Set(middleware.RequestIDHeader, requestId)
+			response := tests.ExecuteRequest(req, logged)
+			tests.AssertStatusCode(t, http.StatusOK, response.Code)
+			if got := response.Header().Get(middleware.RequestIDHeader); got != requestId {
+				t.Fatalf("expected X-Request-ID %q to be echoed, got %q", requestId, got)
+			}
+
+			var entry map[string]interface{}
+			for _, candidate := range logs.entries(t, "request") {
+				if candidate["request_id"] == requestId {
+					entry = candidate
+				}
+			}
+			if entry == nil {
+				t.Fatalf("expected an access log line with request_id %q", requestId)
+			}
+			if entry["route"] != "/api/merchants/{merchant_id}/products" || entry["merchant_id"] != merchantId.String() {
+				t.Fatalf("expected the route pattern and merchant, got %v and %v", entry["route"], entry["merchant_id"])
+			}
+			if entry["status"] != float64(http.StatusOK) || entry["bytes"] != float64(response.Body.Len()) {
+				t.Fatalf("expected status 200 and %d bytes, got %v and %v", response.Body.Len(), entry["status"], entry["bytes"])
+			}
+			if _, ok := entry["latency_ms"]; !ok {
+				t.Fatalf("expected the latency to be logged, got %v", entry)
+			}
+		},
+	)
+
+	t.Run(`Given a request without a usable X-Request-ID,
+    when it is served,
+    then a new ID should be generated. `,
+		func(t *testing.T) {
+			for _, requestId := range []string{"", "bad id\r\ninjected: true", strings.Repeat("a", 200)} {
+				req, _ := http.NewRequest(http.MethodGet, "/", nil)
+				if requestId != "" {
+					req.Header[middleware.RequestIDHeader] = []string{requestId}
+				}
+				response := tests.ExecuteRequest(req, logged)
+				if _, err := uuid.Parse(response.Header().Get(middleware.RequestIDHeader)); err != nil {
+					t.Fatalf("expected a generated request ID for %q, got %q", requestId, response.Header().Get(middleware.RequestIDHeader))
+				}
+			}
+		},
+	)
+
+	t.Run(`Given the log level is changed to debug at runtime,
+    when it is fetched,
+    then debug should be reported, and an unknown level should be rejected. `,
+		func(t *testing.T) {
+			setLevel := func(level string) *http.Response {
+				req, _ := http.NewRequest(http.MethodPut, "/admin/log-level", strings.NewReader(`{"level": "`+level+`"}`))
+				return tests.ExecuteRequest(asAdmin(req), logged).Result()
+			}
+			tests.AssertStatusCode(t, http.StatusOK, setLevel("DEBUG").StatusCode)
+			tests.AssertStatusCode(t, http.StatusBadRequest, setLevel("verbose").StatusCode)
+
+			req, _ := http.NewRequest(http.MethodGet, "/admin/log-level", nil)
+			response := tests.ExecuteRequest(asAdmin(req), logged)
+			tests.AssertStatusCode(t, http.StatusOK, response.Code)
+			data := tests.ParseResponse(t, response)["data"].(map[string]interface{})
+			if data["level"] != "debug" {
+				t.Fatalf("expected the debug level, got %v", data["level"])
+			}
+			if len(logs.entries(t, "log level changed")) != 1 {
+				t.Fatalf("expected the level change to be logged once")
+			}
+		},
+	)
+
+	t.Run(`Given a request to an admin route,
+    when it has no admin token, the wrong one, or the server has none configured,
+    then it should be refused. `,
+		func(t *testing.T) {
+			for _, authorization := range []string{"", "Bearer wrong-token", adminToken} {
+				req, _ := http.NewRequest(http.MethodPut, "/admin/log-level", strings.NewReader(`{"level": "error"}`))
+				if authorization != "" {
+					req.Header.Set("Authorization", authorization)
+				}
+				response := tests.ExecuteRequest(req, logged)
+				tests.AssertStatusCode(t, http.StatusUnauthorized, response.Code)
+			}
+
+			cfg := config.Default()
+			disabled := newRouter(ctx, cfg, logging.Discard(), newHealthChecker())
+			req, _ := http.NewRequest(http.MethodGet, "/admin/log-level", nil)
+			response := tests.ExecuteRequest(asAdmin(req), disabled)
+			tests.AssertStatusCode(t, http.StatusForbidden, response.Code)
+		},
+	)
+}
@@ -0,0 +1,151 @@
+//go:build integration
+// +build integration
+
+package integration
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/olad5/sal-backend-service/internal/config"
+	"github.com/olad5/sal-backend-service/internal/logging"
+	"github.com/olad5/sal-backend-service/pkg/client"
+	"github.com/olad5/sal-backend-service/pkg/metrics"
+	"github.com/olad5/sal-backend-service/tests"
+)
+
+func TestMetrics(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	cfg := config.Default()
+	cfg.RateLimit.ReadsPerMinute = 0
+	cfg.RateLimit.WritesPerMinute = 0
+	// a router of its own, so the counts are not mixed with the rest of the suite
+	metered := newRouter(ctx, cfg, logging.Discard(), newHealthChecker())
+	server := httptest.NewServer(metered)
+	defer server.Close()
+	meteredClient, err := client.New(server.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	merchantId := uuid.New()
+	scheduled, err := meteredClient.CreateProduct(ctx, buildProduct(merchantId, uuid.New()))
+	if err != nil {
+		t.Fatalf("unable to create product: %v", err)
+	}
+	if _, err := meteredClient.CreateProduct(ctx, buildProduct(merchantId, uuid.New())); err != nil {
+		t.Fatalf("unable to create product: %v", err)
+	}
+	if _, err := meteredClient.CreateProduct(ctx, buildProduct(uuid.New(), uuid.New())); err != nil {
+		t.Fatalf("unable to create product: %v", err)
+	}
+	startsAt := time.Now().Add(time.Hour)
+	if _, err := meteredClient.ScheduleSale(ctx, merchantId, scheduled.SKUID, 1, startsAt, startsAt.Add(time.Hour)); err != nil {
+		t.Fatalf("unable to schedule sale: %v", err)
+	}
+	if _, err := meteredClient.ListProducts(ctx, merchantId, client.ListProductsOptions{}); err != nil {
+		t.Fatalf("unable to list products: %v", err)
+	}
+	if _, err := meteredClient.GetProductByCode(ctx, merchantId, "no-such-code"); err == nil {
+		t.Fatal("expected looking up a missing code to fail")
+	}
+
+	scrape := func(t *testing.T) string {
+		t.Helper()
+		req, _ := http.NewRequest(http.MethodGet, "/metrics", nil)
+		response := tests.ExecuteRequest(req, metered)
+		tests.AssertStatusCode(t, http.StatusOK, response.Code)
+		if got := response.Header().Get("Content-Type"); got != metrics.ContentType {
+			t.Fatalf("expected Content-Type %q, got %q", metrics.ContentType, got)
+		}
+		return response.Body.String()
+	}
+	assertLines := func(t *testing.T, exposition string, lines ...string) {
+		t.Helper()
+		for _, line := range lines {
+			if !strings.Contains("\n"+exposition, "\n"+line+"\n") {
+				t.Errorf("expected line %q in:\n%s", line, exposition)
+			}
+		}
+	}
+
+	t.Run(`Given requests to routes with IDs in their paths,
+    when the metrics are scraped,
+    then requests should be counted and timed by route pattern and status. `,
+		func(t *testing.T) {
+			exposition := scrape(t)
+			assertLines(t, exposition,
+				"# HELP sal_http_requests_total HTTP requests served, by route pattern and status.",
+				"# TYPE sal_http_requests_total counter",
+				`sal_http_requests_total{method="POST",route="/api/products",status="200"} 3`,
+				`sal_http_requests_total{method="POST",route="/api/products/{sku_id}/sales",status="200"} 1`,
+				`sal_http_requests_total{method="GET",route="/api/merchants/{merchant_id}/products",status="200"} 1`,
+				`sal_http_requests_total{method="GET",route="/api/merchants/{merchant_id}/products/by-code/{code}",status="404"} 1`,
+				"# TYPE sal_http_request_duration_seconds histogram",
+				`sal_http_request_duration_seconds_bucket{method="POST",route="/api/products",le="+Inf"} 3`,
+				`sal_http_request_duration_seconds_count{method="POST",route="/api/products"} 3`,
+				"# TYPE sal_http_requests_in_flight gauge",
+				// the scrape itself is in flight while the metrics are written
+				"sal_http_requests_in_flight 1",
+			)
+			if strings.Contains(exposition, merchantId.String()) {
+				t.Error("expected no raw paths in the route labels")
+			}
+		},
+	)
+
+	t.Run(`Given a scrape,
+    when it is counted,
+    then it should be counted under the metrics route. `,
+		func(t *testing.T) {
+			scrape(t)
+			// the scrape being served is only counted once it is done
+			assertLines(t, scrape(t),
+				`sal_http_requests_total{method="GET",route="/metrics",status="200"} 2`,
+			)
+		},
+	)
+
+	t.Run(`Given repository calls,
+    when the metrics are scraped,
+    then their latencies should be reported and failed lookups counted as not found. `,
+		func(t *testing.T) {
+			exposition := scrape(t)
+			assertLines(t, exposition,
+				"# TYPE sal_repository_operation_duration_seconds histogram",
+				`sal_repository_operation_duration_seconds_count{repository="product",operation="CreateProduct"} 3`,
+			)
+			// creating a product also looks up its SKU code, so only the
+			// series are checked for the lookups
+			for _, series := range []string{
+				`sal_repository_operation_duration_seconds_bucket{repository="product",operation="GetProductsByMerchantId",le="0.005"}`,
+				`sal_repository_operation_errors_total{repository="product",operation="GetProductBySkuCode",kind="not_found"}`,
+			} {
+				if !strings.Contains(exposition, "\n"+series+" ") {
+					t.Errorf("expected series %s in:\n%s", series, exposition)
+				}
+			}
+		},
+	)
+
+	t.Run(`Given products in two catalogues, one of them with a sale coming up,
+    when the metrics are scraped,
+    then the product and merchant gauges should count them. `,
+		func(t *testing.T) {
+			assertLines(t, scrape(t),
+				"# TYPE sal_products gauge",
+				`sal_products{status="on_sale"} 0`,
+				`sal_products{status="regular"} 2`,
+				`sal_products{status="scheduled"} 1`,
+				"# TYPE sal_merchants gauge",
+				"sal_merchants 2",
+			)
+		},
+	)
+}
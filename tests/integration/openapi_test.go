@@ -0,0 +1,84 @@
+//go:build integration
+// +build integration
+
+package integration
+
+import (
+	"encoding/json"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/olad5/sal-backend-service/tests"
+)
+
+func TestOpenAPISpec(t *testing.T) {
+	req, _ := http.NewRequest(http.MethodGet, "/openapi.json", nil)
+	response := tests.ExecuteRequest(req, r)
+	tests.AssertStatusCode(t, http.StatusOK, response.Code)
+
+	var spec struct {
+		OpenAPI    string                                `json:"openapi"`
+		Paths      map[string]map[string]json.RawMessage `json:"paths"`
+		Components struct {
+			Schemas map[string]json.RawMessage `json:"schemas"`
+		} `json:"components"`
+	}
+	if err := json.NewDecoder(response.Body).Decode(&spec); err != nil {
+		t.Fatal(err)
+	}
+
+	t.Run(`Given the routes registered on the router,
+    when the OpenAPI document is fetched,
+    then every route should have an operation in it. `,
+		func(t *testing.T) {
+			routes := 0
+			err := chi.Walk(r.(chi.Routes), func(method, route string, handler http.Handler, middlewares ...func(http.Handler) http.Handler) error {
+				routes++
+				if _, ok := spec.Paths[route][strings.ToLower(method)]; !ok {
+					t.Errorf("%s %s is missing from the OpenAPI document", method, route)
+				}
+				return nil
+			})
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			operations := 0
+			for _, item := range spec.Paths {
+				operations += len(item)
+			}
+			if operations != routes {
+				t.Errorf("expected %d operations to match the routes, got %d", routes, operations)
+			}
+		},
+	)
+
+	t.Run(`Given the product endpoints,
+    when the OpenAPI document is fetched,
+    then it should describe the product DTOs and the error envelope. `,
+		func(t *testing.T) {
+			if !strings.HasPrefix(spec.OpenAPI, "3.") {
+				t.Fatalf("expected an OpenAPI 3 document, got %q", spec.OpenAPI)
+			}
+			for _, name := range []string{"ProductDTO", "ProductPagedDTO", "ErrorResponse"} {
+				if _, ok := spec.Components.Schemas[name]; !ok {
+					t.Errorf("expected a %s schema", name)
+				}
+			}
+
+			var product struct {
+				Properties map[string]json.RawMessage `json:"properties"`
+			}
+			if err := json.Unmarshal(spec.Components.Schemas["ProductDTO"], &product); err != nil {
+				t.Fatal(err)
+			}
+			for _, field := range []string{"sku_id", "sku_code", "merchant_id", "price", "effective_price", "images"} {
+				if _, ok := product.Properties[field]; !ok {
+					t.Errorf("expected ProductDTO to have a %s property", field)
+				}
+			}
+		},
+	)
+}
@@ -0,0 +1,192 @@
+//go:build integration
+// +build integration
+
+package integration
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/olad5/sal-backend-service/pkg/client"
+	"github.com/olad5/sal-backend-service/tests"
+)
+
+func TestMerchantPlanQuotas(t *testing.T) {
+	ctx := context.Background()
+	t.Run(`Given a merchant on the free plan with as many products as it allows,
+    when they create one more product,
+    then the API should return a quota exceeded error and usage should show the plan is full. `,
+		func(t *testing.T) {
+			merchantId := uuid.New()
+			plan, err := c.AssignPlan(ctx, merchantId, "free")
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			for i := 0; i < plan.MaxProducts; i++ {
+				createProduct(t, buildProduct(merchantId, uuid.New()))
+			}
+
+			_, err = c.CreateProduct(ctx, buildProduct(merchantId, uuid.New()))
+			var apiErr *client.Error
+			if !errors.Is(err, client.ErrQuotaExceeded) || !errors.As(err, &apiErr) {
+				t.Fatalf("expected a quota exceeded error, got %v", err)
+			}
+			if !strings.Contains(apiErr.Message, "quota exceeded") {
+				t.Fatalf("expected a quota exceeded message, got %q", apiErr.Message)
+			}
+
+			usage, err := c.GetUsage(ctx, merchantId)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if usage.Products.Used != plan.MaxProducts || usage.Products.Remaining != 0 {
+				t.Fatalf("expected %d products used and none remaining, got %+v", plan.MaxProducts, usage.Products)
+			}
+		},
+	)
+
+	t.Run(`Given a merchant on the free plan a few products short of its limit,
+    when more products than it has room for are created at once,
+    then only as many as the plan allows should be created, and deleting one should make room again. `,
+		func(t *testing.T) {
+			merchantId := uuid.New()
+			plan, err := c.AssignPlan(ctx, merchantId, "free")
+			if err != nil {
+				t.Fatal(err)
+			}
+			for i := 0; i < plan.MaxProducts-3; i++ {
+				createProduct(t, buildProduct(merchantId, uuid.New()))
+			}
+
+			var wg sync.WaitGroup
+			results := make(chan error, 10)
+			for i := 0; i < cap(results); i++ {
+				wg.Add(1)
+				go func() {
+					defer wg.Done()
+					_, err := c.CreateProduct(ctx, buildProduct(merchantId, uuid.New()))
+					results <- err
+				}()
+			}
+			wg.Wait()
+			close(results)
+			created := 0
+			for err := range results {
+				switch {
+				case err == nil:
+					created++
+				case !errors.Is(err, client.ErrQuotaExceeded):
+					t.Fatalf("expected a quota exceeded error, got %v", err)
+				}
+			}
+			if created != 3 {
+				t.Fatalf("expected 3 products to be created, got %d", created)
+			}
+
+			lastSkuId := uuid.New()
+			_, err = c.CreateProduct(ctx, buildProduct(merchantId, lastSkuId))
+			if !errors.Is(err, client.ErrQuotaExceeded) {
+				t.Fatalf("expected a quota exceeded error, got %v", err)
+			}
+			products, err := c.ListProducts(ctx, merchantId, client.ListProductsOptions{})
+			if err != nil {
+				t.Fatal(err)
+			}
+			if err := c.DeleteProduct(ctx, merchantId, products.Products[0].SKUID); err != nil {
+				t.Fatal(err)
+			}
+			createProduct(t, buildProduct(merchantId, lastSkuId))
+		},
+	)
+
+	t.Run(`Given a merchant on the free plan a few images short of its limit,
+    when more images than it has room for are uploaded at once to different products,
+    then only as many as the plan allows should be stored. `,
+		func(t *testing.T) {
+			merchantId := uuid.New()
+			plan, err := c.AssignPlan(ctx, merchantId, "free")
+			if err != nil {
+				t.Fatal(err)
+			}
+			image := buildPNG(t, 10, 10)
+			skuId := createProduct(t, buildProduct(merchantId, uuid.New()))
+			for i := 0; i < plan.MaxImages-3; i++ {
+				tests.AssertStatusCode(t, http.StatusOK, uploadImage(t, skuId, merchantId, image, "").Code)
+			}
+
+			skuIds := []uuid.UUID{}
+			for i := 0; i < 10; i++ {
+				skuIds = append(skuIds, createProduct(t, buildProduct(merchantId, uuid.New())))
+			}
+			var wg sync.WaitGroup
+			results := make(chan int, len(skuIds))
+			for _, skuId := range skuIds {
+				wg.Add(1)
+				go func(skuId uuid.UUID) {
+					defer wg.Done()
+					results <- uploadImage(t, skuId, merchantId, image, "").Code
+				}(skuId)
+			}
+			wg.Wait()
+			close(results)
+			uploaded := 0
+			for code := range results {
+				switch code {
+				case http.StatusOK:
+					uploaded++
+				case http.StatusForbidden:
+				default:
+					t.Fatalf("expected a quota exceeded error, got status %d", code)
+				}
+			}
+			if uploaded != 3 {
+				t.Fatalf("expected 3 images to be uploaded, got %d", uploaded)
+			}
+
+			usage, err := c.GetUsage(ctx, merchantId)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if usage.Images.Used != plan.MaxImages {
+				t.Fatalf("expected %d images used, got %d", plan.MaxImages, usage.Images.Used)
+			}
+		},
+	)
+
+	t.Run(`Given a merchant with no assigned plan,
+    when they fetch their usage,
+    then the standard plan should be reported. `,
+		func(t *testing.T) {
+			merchantId := uuid.New()
+			createProduct(t, buildProduct(merchantId, uuid.New()))
+
+			usage, err := c.GetUsage(ctx, merchantId)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if usage.Plan.Name != "standard" {
+				t.Fatalf("expected the standard plan, got %v", usage.Plan.Name)
+			}
+			if usage.Products.Used != 1 {
+				t.Fatalf("expected 1 product used, got %v", usage.Products.Used)
+			}
+		},
+	)
+
+	t.Run(`Given a plan that does not exist,
+    when it is assigned to a merchant,
+    then the API should return a bad request error. `,
+		func(t *testing.T) {
+			_, err := c.AssignPlan(ctx, uuid.New(), "platinum")
+			if !errors.Is(err, client.ErrBadRequest) {
+				t.Fatalf("expected a bad request error, got %v", err)
+			}
+		},
+	)
+}
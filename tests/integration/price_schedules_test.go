@@ -0,0 +1,267 @@
+//go:build integration
+// +build integration
+
+package integration
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/olad5/sal-backend-service/internal/domain"
+	"github.com/olad5/sal-backend-service/internal/events"
+	"github.com/olad5/sal-backend-service/internal/infra"
+	"github.com/olad5/sal-backend-service/internal/infra/memory"
+	"github.com/olad5/sal-backend-service/internal/logging"
+	"github.com/olad5/sal-backend-service/internal/usecases/products"
+	"github.com/olad5/sal-backend-service/tests"
+)
+
+func TestPriceScheduler(t *testing.T) {
+	ctx := context.Background()
+	start := time.Date(2024, time.November, 29, 0, 0, 0, 0, time.UTC)
+	fakeClock := tests.NewFakeClock(start)
+
+	productRepo, _ := memory.NewMemoryProductRepo()
+	eventBus := events.NewBus()
+	productService := newProductService(t, productRepo, eventBus, fakeClock)
+	scheduler, err := products.NewPriceScheduler(productService, fakeClock, logging.Discard().Logger, time.Minute)
+	if err != nil {
+		t.Fatal(err)
+	}
+	eventBus.Subscribe(scheduler.HandleProductEvent)
+	updates := 0
+	eventBus.Subscribe(func(ctx context.Context, event events.ProductEvent) {
+		if event.Type == events.ProductUpdated {
+			updates++
+		}
+	})
+
+	merchantId, skuId := uuid.New(), uuid.New()
+	if _, err := productService.CreateProduct(ctx, merchantId, skuId, "", "", "", "Winter Coat", "Warm coat", 100, domain.StandardTaxClass, "", nil, nil); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := productService.ScheduleSale(ctx, merchantId, skuId, 80, start.Add(time.Hour), start.Add(2*time.Hour)); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := productService.SchedulePriceChange(ctx, merchantId, skuId, 120, start.Add(3*time.Hour)); err != nil {
+		t.Fatal(err)
+	}
+
+	assertPrices := func(t *testing.T, regular, effective float64) {
+		t.Helper()
+		product, err := productRepo.GetProductBySkuId(ctx, skuId)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if product.Price != regular || product.EffectivePrice() != effective {
+			t.Fatalf("expected regular price %v and effective price %v, got %v and %v", regular, effective, product.Price, product.EffectivePrice())
+		}
+	}
+
+	t.Run(`Given a sale and a price change scheduled for later,
+    when the scheduler runs before either is due,
+    then the product should keep its current price. `,
+		func(t *testing.T) {
+			updates = 0
+			scheduler.RunDue(ctx)
+			assertPrices(t, 100, 100)
+			if updates != 0 {
+				t.Fatalf("expected no product updates, got %d", updates)
+			}
+		},
+	)
+
+	t.Run(`Given a scheduled sale,
+    when its start time is reached,
+    then the sale price should become the effective price and a product updated event should be emitted. `,
+		func(t *testing.T) {
+			updates = 0
+			fakeClock.Advance(time.Hour)
+			scheduler.RunDue(ctx)
+			assertPrices(t, 100, 80)
+			if updates != 1 {
+				t.Fatalf("expected 1 product update, got %d", updates)
+			}
+		},
+	)
+
+	t.Run(`Given an active sale,
+    when its end time is reached,
+    then the product should revert to its regular price. `,
+		func(t *testing.T) {
+			fakeClock.Advance(time.Hour)
+			scheduler.RunDue(ctx)
+			assertPrices(t, 100, 100)
+		},
+	)
+
+	t.Run(`Given a scheduled price change,
+    when its effective time is reached,
+    then it should replace the regular price. `,
+		func(t *testing.T) {
+			fakeClock.Advance(time.Hour)
+			scheduler.RunDue(ctx)
+			assertPrices(t, 120, 120)
+		},
+	)
+}
+
+func TestScheduleSale(t *testing.T) {
+	t.Run(`Given a merchant starts a sale that is already running,
+    when they call the schedule sale endpoint,
+    then the response should show the sale price as effective alongside the regular price. `,
+		func(t *testing.T) {
+			merchantId := uuid.New()
+			prd := buildProduct(merchantId, uuid.New())
+			prd.Price = 50
+			skuId := createProduct(t, prd)
+
+			response := scheduleSale(t, skuId, merchantId, 40, time.Now().Add(-time.Minute), time.Now().Add(time.Hour))
+			tests.AssertStatusCode(t, http.StatusOK, response.Code)
+			data := tests.ParseResponse(t, response)["data"].(map[string]interface{})
+			if data["regular_price"].(float64) != 50 || data["effective_price"].(float64) != 40 {
+				t.Fatalf("expected regular price 50 and effective price 40, got %v and %v", data["regular_price"], data["effective_price"])
+			}
+			if data["active_sale"] == nil {
+				t.Fatalf("expected an active sale window")
+			}
+		},
+	)
+
+	t.Run(`Given a product with a scheduled sale,
+    when the merchant schedules another sale overlapping it,
+    then the API should return a conflict error. `,
+		func(t *testing.T) {
+			merchantId := uuid.New()
+			skuId := createProduct(t, buildProduct(merchantId, uuid.New()))
+			startsAt := time.Now().Add(time.Hour)
+
+			response := scheduleSale(t, skuId, merchantId, 1, startsAt, startsAt.Add(2*time.Hour))
+			tests.AssertStatusCode(t, http.StatusOK, response.Code)
+			response = scheduleSale(t, skuId, merchantId, 1, startsAt.Add(time.Hour), startsAt.Add(3*time.Hour))
+			tests.AssertStatusCode(t, http.StatusConflict, response.Code)
+		},
+	)
+}
+
+// slowProductRepo widens the window between reading a product and writing it
+// back, so unserialized writes would overwrite each other.
+type slowProductRepo struct {
+	infra.ProductRepository
+}
+
+func (s slowProductRepo) GetProductBySkuId(ctx context.Context, skuId uuid.UUID) (domain.Product, error) {
+	product, err := s.ProductRepository.GetProductBySkuId(ctx, skuId)
+	time.Sleep(time.Millisecond)
+	return product, err
+}
+
+func TestConcurrentProductWrites(t *testing.T) {
+	ctx := context.Background()
+	fakeClock := tests.NewFakeClock(time.Date(2024, time.November, 29, 0, 0, 0, 0, time.UTC))
+	memoryRepo, _ := memory.NewMemoryProductRepo()
+	productService := newProductService(t, slowProductRepo{memoryRepo}, events.NewBus(), fakeClock)
+
+	t.Run(`Given a merchant schedules sales and price changes on one product at the same time,
+    when the writes run concurrently,
+    then every schedule should be kept, and only one of two overlapping sales accepted. `,
+		func(t *testing.T) {
+			merchantId, skuId := uuid.New(), uuid.New()
+			if _, err := productService.CreateProduct(ctx, merchantId, skuId, "", "", "", "Winter Coat", "Warm coat", 100, domain.StandardTaxClass, "", nil, nil); err != nil {
+				t.Fatal(err)
+			}
+			startsAt := fakeClock.Now().Add(time.Hour)
+
+			const writes = 10
+			errs := make(chan error, 2*writes+1)
+			var wg sync.WaitGroup
+			for i := 0; i < writes; i++ {
+				windowStart := startsAt.Add(time.Duration(i) * time.Hour)
+				wg.Add(2)
+				go func() {
+					defer wg.Done()
+					_, err := productService.ScheduleSale(ctx, merchantId, skuId, 80, windowStart, windowStart.Add(time.Hour))
+					errs <- err
+				}()
+				go func() {
+					defer wg.Done()
+					_, err := productService.SchedulePriceChange(ctx, merchantId, skuId, 120, windowStart)
+					errs <- err
+				}()
+			}
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				_, err := productService.ScheduleSale(ctx, merchantId, skuId, 70, startsAt, startsAt.Add(time.Hour))
+				errs <- err
+			}()
+			wg.Wait()
+			close(errs)
+
+			overlaps := 0
+			for err := range errs {
+				switch {
+				case errors.Is(err, products.ErrOverlappingSale):
+					overlaps++
+				case err != nil:
+					t.Fatal(err)
+				}
+			}
+			if overlaps != 1 {
+				t.Fatalf("expected exactly one overlapping sale to be refused, got %d", overlaps)
+			}
+
+			product, err := memoryRepo.GetProductBySkuId(ctx, skuId)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(product.Sales) != writes || len(product.PriceChanges) != writes {
+				t.Fatalf("expected %d sales and price changes, got %d and %d", writes, len(product.Sales), len(product.PriceChanges))
+			}
+		},
+	)
+
+	t.Run(`Given a merchant's product listing that was read before a price change,
+    when the product is updated while the listing is still in use,
+    then the listing should keep the product as it was read. `,
+		func(t *testing.T) {
+			merchantId, skuId := uuid.New(), uuid.New()
+			if _, err := productService.CreateProduct(ctx, merchantId, skuId, "", "", "", "Winter Coat", "Warm coat", 100, domain.StandardTaxClass, "", nil, nil); err != nil {
+				t.Fatal(err)
+			}
+			listed, err := productService.GetProductsByMerchantId(ctx, merchantId)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if _, err := productService.UpdateProduct(ctx, merchantId, skuId, "", "", "", "Winter Coat", "Warm coat", 150, "", "", nil, nil); err != nil {
+				t.Fatal(err)
+			}
+			if listed[0].Price != 100 {
+				t.Fatalf("expected the listing read earlier to keep price 100, got %v", listed[0].Price)
+			}
+		},
+	)
+}
+
+func scheduleSale(t *testing.T, skuId, merchantId uuid.UUID, price float64, startsAt, endsAt time.Time) *httptest.ResponseRecorder {
+	t.Helper()
+	requestBody, err := json.Marshal(map[string]interface{}{
+		"merchant_id": merchantId,
+		"price":       price,
+		"starts_at":   startsAt,
+		"ends_at":     endsAt,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	req, _ := http.NewRequest(http.MethodPost, "/api/products/"+skuId.String()+"/sales", bytes.NewBuffer(requestBody))
+	return tests.ExecuteRequest(req, r)
+}
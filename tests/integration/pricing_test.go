@@ -0,0 +1,159 @@
+//go:build integration
+// +build integration
+
+package integration
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/olad5/sal-backend-service/pkg/client"
+)
+
+func TestQuoteProductPrice(t *testing.T) {
+	ctx := context.Background()
+	t.Run(`Given a product with overlapping quantity breaks,
+    when a quote is requested for a quantity meeting both,
+    then only the better break should apply. `,
+		func(t *testing.T) {
+			merchantId := uuid.New()
+			prd := buildProduct(merchantId, uuid.New())
+			prd.Price = 20
+			skuId := createProduct(t, prd)
+
+			createPricingRule(t, merchantId, client.CreatePricingRuleRequest{SKUID: &skuId, MinQuantity: intPtr(10), Type: "percentage", Value: 10})
+			createPricingRule(t, merchantId, client.CreatePricingRuleRequest{SKUID: &skuId, MinQuantity: intPtr(50), Type: "percentage", Value: 20})
+
+			assertQuote(t, skuId, 5, "", 20, 100, 0)
+			assertQuote(t, skuId, 10, "", 18, 180, 1)
+			assertQuote(t, skuId, 50, "", 16, 800, 1)
+		},
+	)
+
+	t.Run(`Given a customer group price list and a merchant wide discount,
+    when a quote is requested for that customer group,
+    then the discount should apply on top of the price list. `,
+		func(t *testing.T) {
+			merchantId := uuid.New()
+			prd := buildProduct(merchantId, uuid.New())
+			prd.Price = 30
+			skuId := createProduct(t, prd)
+
+			createPricingRule(t, merchantId, client.CreatePricingRuleRequest{SKUID: &skuId, CustomerGroup: "wholesale", Type: "fixed_price", Value: 25})
+			createPricingRule(t, merchantId, client.CreatePricingRuleRequest{MinQuantity: intPtr(2), Type: "fixed_amount", Value: 2.5})
+
+			assertQuote(t, skuId, 1, "wholesale", 25, 25, 1)
+			assertQuote(t, skuId, 4, "wholesale", 22.5, 90, 2)
+			assertQuote(t, skuId, 4, "retail", 27.5, 110, 1)
+		},
+	)
+
+	t.Run(`Given a merchant creates a pricing rule for a product they do not own,
+    when they call the create pricing rule endpoint,
+    then the API should return an unauthorized error. `,
+		func(t *testing.T) {
+			skuId := createProduct(t, buildProduct(uuid.New(), uuid.New()))
+
+			_, err := c.CreatePricingRule(ctx, uuid.New(), client.CreatePricingRuleRequest{SKUID: &skuId, Type: "percentage", Value: 10})
+			if !errors.Is(err, client.ErrNotFound) {
+				t.Fatalf("expected a not found error, got %v", err)
+			}
+		},
+	)
+
+	t.Run(`Given a merchant creates a pricing rule with an invalid percentage,
+    when they call the create pricing rule endpoint,
+    then the API should return a bad request error. `,
+		func(t *testing.T) {
+			_, err := c.CreatePricingRule(ctx, uuid.New(), client.CreatePricingRuleRequest{Type: "percentage", Value: 150})
+			if !errors.Is(err, client.ErrBadRequest) {
+				t.Fatalf("expected a bad request error, got %v", err)
+			}
+		},
+	)
+
+	t.Run(`Given a discount scoped to a category,
+    when quotes are requested for products in and out of that category,
+    then the discount should only apply to the products in the category. `,
+		func(t *testing.T) {
+			merchantId := uuid.New()
+			shoe := buildProduct(merchantId, uuid.New())
+			shoe.Price = 40
+			shoe.Category = "Shoes"
+			shoeId := createProduct(t, shoe)
+			hat := buildProduct(merchantId, uuid.New())
+			hat.Price = 40
+			hat.Category = "Hats"
+			hatId := createProduct(t, hat)
+
+			createPricingRule(t, merchantId, client.CreatePricingRuleRequest{Category: "shoes", Type: "percentage", Value: 25})
+
+			assertQuote(t, shoeId, 2, "", 30, 60, 1)
+			assertQuote(t, hatId, 2, "", 40, 80, 0)
+		},
+	)
+
+	t.Run(`Given a merchant creates a pricing rule scoped to both a product and a category,
+    when they call the create pricing rule endpoint,
+    then the API should return a bad request error. `,
+		func(t *testing.T) {
+			merchantId := uuid.New()
+			skuId := createProduct(t, buildProduct(merchantId, uuid.New()))
+
+			_, err := c.CreatePricingRule(ctx, merchantId, client.CreatePricingRuleRequest{SKUID: &skuId, Category: "shoes", Type: "percentage", Value: 10})
+			if !errors.Is(err, client.ErrBadRequest) {
+				t.Fatalf("expected a bad request error, got %v", err)
+			}
+		},
+	)
+
+	t.Run(`Given a merchant deletes one of their pricing rules,
+    when a quote is requested afterwards,
+    then the rule should no longer apply. `,
+		func(t *testing.T) {
+			merchantId := uuid.New()
+			prd := buildProduct(merchantId, uuid.New())
+			prd.Price = 10
+			skuId := createProduct(t, prd)
+
+			rule := createPricingRule(t, merchantId, client.CreatePricingRuleRequest{Type: "fixed_amount", Value: 1})
+			if err := c.DeletePricingRule(ctx, merchantId, rule.ID); err != nil {
+				t.Fatal(err)
+			}
+
+			assertQuote(t, skuId, 1, "", 10, 10, 0)
+			if rules, err := c.ListPricingRules(ctx, merchantId); err != nil || len(rules) != 0 {
+				t.Fatalf("expected no pricing rules, got %v and %v", rules, err)
+			}
+		},
+	)
+}
+
+func createPricingRule(t *testing.T, merchantId uuid.UUID, request client.CreatePricingRuleRequest) client.PricingRule {
+	t.Helper()
+	rule, err := c.CreatePricingRule(context.Background(), merchantId, request)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return rule
+}
+
+func assertQuote(t *testing.T, skuId uuid.UUID, quantity int, customerGroup string, unitPrice, total float64, appliedRules int) {
+	t.Helper()
+	quote, err := c.QuoteProductPrice(context.Background(), skuId, client.QuoteRequest{Quantity: quantity, CustomerGroup: customerGroup})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if quote.UnitPrice != unitPrice || quote.Total != total {
+		t.Fatalf("expected unit price %v and total %v for quantity %d, got %v and %v", unitPrice, total, quantity, quote.UnitPrice, quote.Total)
+	}
+	if got := len(quote.AppliedRules); got != appliedRules {
+		t.Fatalf("expected %d applied rules, got %d", appliedRules, got)
+	}
+}
+
+func intPtr(value int) *int {
+	return &value
+}
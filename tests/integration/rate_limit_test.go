@@ -0,0 +1,223 @@
+//go:build integration
+// +build integration
+
+package integration
+
+import (
+	"bytes"
+	"context"
+	"crypto/x509/pkix"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/olad5/sal-backend-service/internal/app/middleware"
+	"github.com/olad5/sal-backend-service/internal/config"
+	"github.com/olad5/sal-backend-service/internal/logging"
+	"github.com/olad5/sal-backend-service/pkg/ratelimit"
+	"github.com/olad5/sal-backend-service/tests"
+)
+
+func TestRateLimiting(t *testing.T) {
+	cfg := config.Default()
+	cfg.RateLimit.ReadsPerMinute = 1000
+	cfg.RateLimit.WritesPerMinute = 2
+	cfg.Admin.Token = adminToken
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	limited := newRouter(ctx, cfg, logging.Discard(), newHealthChecker())
+
+	createFrom := func(remoteAddr string) *http.Response {
+		requestBody, _ := json.Marshal(buildProduct(uuid.New(), uuid.New()))
+		req, _ := http.NewRequest(http.MethodPost, "/api/products", bytes.NewBuffer(requestBody))
+		req.RemoteAddr = remoteAddr
+		return tests.ExecuteRequest(req, limited).Result()
+	}
+
+	t.Run(`Given a client that has used up its write limit,
+    when it makes another write,
+    then the API should return 429 with Retry-After while other clients are unaffected. `,
+		func(t *testing.T) {
+			for i := 0; i < 2; i++ {
+				response := createFrom("10.0.0.1:5000")
+				tests.AssertStatusCode(t, http.StatusOK, response.StatusCode)
+				if remaining := response.Header.Get("RateLimit-Remaining"); remaining != fmt.Sprint(1-i) {
+					t.Fatalf("expected %d requests remaining, got %q", 1-i, remaining)
+				}
+			}
+
+			response := createFrom("10.0.0.1:5001")
+			tests.AssertStatusCode(t, http.StatusTooManyRequests, response.StatusCode)
+			if response.Header.Get("Retry-After") != "30" || response.Header.Get("RateLimit-Limit") != "2" {
+				t.Fatalf("expected Retry-After 30 and RateLimit-Limit 2, got %q and %q", response.Header.Get("Retry-After"), response.Header.Get("RateLimit-Limit"))
+			}
+
+			response = createFrom("10.0.0.2:5000")
+			tests.AssertStatusCode(t, http.StatusOK, response.StatusCode)
+		},
+	)
+
+	t.Run(`Given requests naming a merchant on the free plan in X-Merchant-ID without a client certificate,
+    when more are made in a minute than the merchant's plan allows,
+    then the API should return 429 while requests from the same IP naming no merchant are unaffected. `,
+		func(t *testing.T) {
+			merchantId := uuid.New()
+			requestBody, _ := json.Marshal(map[string]string{"plan": "free"})
+			req, _ := http.NewRequest(http.MethodPut, "/admin/merchants/"+merchantId.String()+"/plan", bytes.NewBuffer(requestBody))
+			tests.AssertStatusCode(t, http.StatusOK, tests.ExecuteRequest(asAdmin(req), limited).Code)
+
+			usage := func(header string) int {
+				req, _ := http.NewRequest(http.MethodGet, "/api/merchants/"+merchantId.String()+"/usage", nil)
+				req.Header.Set(middleware.MerchantHeader, header)
+				return tests.ExecuteRequest(req, limited).Code
+			}
+			for i := 0; i < 60; i++ {
+				tests.AssertStatusCode(t, http.StatusOK, usage(merchantId.String()))
+			}
+			tests.AssertStatusCode(t, http.StatusTooManyRequests, usage(merchantId.String()))
+			tests.AssertStatusCode(t, http.StatusOK, usage(""))
+		},
+	)
+}
+
+func TestMerchantRateLimiting(t *testing.T) {
+	ca := newTestCA(t)
+	merchantId, firstMerchantId, secondMerchantId := uuid.New(), uuid.New(), uuid.New()
+	cfg := config.Default()
+	cfg.RateLimit.ReadsPerMinute = 1000
+	cfg.RateLimit.WritesPerMinute = 2
+	cfg.Admin.Token = adminToken
+	cfg = mutualTLSConfig(t, cfg, ca, []middleware.ClientIdentity{
+		{Subject: "CN=acme-sync", MerchantId: merchantId},
+		{Subject: "CN=globex-sync", MerchantId: firstMerchantId},
+		{Subject: "CN=initech-sync", MerchantId: secondMerchantId},
+	})
+	serverURL, _ := startTLSServer(t, cfg)
+	merchantClient := newTLSClient(ca, ca.clientCertificate(t, pkix.Name{CommonName: "acme-sync"}))
+
+	t.Run(`Given a merchant on the free plan calling with its client certificate,
+    when they make more requests in a minute than their plan allows,
+    then the API should return 429 even though the read limit is not reached. `,
+		func(t *testing.T) {
+			requestBody, _ := json.Marshal(map[string]string{"plan": "free"})
+			req, _ := http.NewRequest(http.MethodPut, serverURL+"/admin/merchants/"+merchantId.String()+"/plan", bytes.NewBuffer(requestBody))
+			req.Header.Set("Content-Type", "application/json")
+			response, err := merchantClient.Do(asAdmin(req))
+			if err != nil {
+				t.Fatal(err)
+			}
+			response.Body.Close()
+			tests.AssertStatusCode(t, http.StatusOK, response.StatusCode)
+
+			status := http.StatusOK
+			requests := 0
+			for ; status == http.StatusOK && requests < 100; requests++ {
+				response, err := merchantClient.Get(serverURL + "/api/merchants/" + merchantId.String() + "/usage")
+				if err != nil {
+					t.Fatal(err)
+				}
+				response.Body.Close()
+				status = response.StatusCode
+			}
+			tests.AssertStatusCode(t, http.StatusTooManyRequests, status)
+			if requests != 61 {
+				t.Fatalf("expected the 61st request to be limited, got request %d", requests)
+			}
+		},
+	)
+	t.Run(`Given two merchants calling with their client certificates from the same IP,
+    when each makes as many writes as the write limit allows,
+    then neither should be limited by the other's writes. `,
+		func(t *testing.T) {
+			for _, merchant := range []struct {
+				id     uuid.UUID
+				client *http.Client
+			}{
+				{firstMerchantId, newTLSClient(ca, ca.clientCertificate(t, pkix.Name{CommonName: "globex-sync"}))},
+				{secondMerchantId, newTLSClient(ca, ca.clientCertificate(t, pkix.Name{CommonName: "initech-sync"}))},
+			} {
+				for i := 0; i < cfg.RateLimit.WritesPerMinute; i++ {
+					requestBody, _ := json.Marshal(buildProduct(merchant.id, uuid.New()))
+					response, err := merchant.client.Post(serverURL+"/api/products", "application/json", bytes.NewBuffer(requestBody))
+					if err != nil {
+						t.Fatal(err)
+					}
+					response.Body.Close()
+					tests.AssertStatusCode(t, http.StatusOK, response.StatusCode)
+				}
+			}
+		},
+	)
+}
+
+func TestRateLimiter(t *testing.T) {
+	fakeClock := tests.NewFakeClock(time.Date(2024, time.May, 1, 12, 0, 0, 0, time.UTC))
+	limiter, err := ratelimit.NewLimiter(fakeClock, 3, 5*time.Minute)
+	if err != nil {
+		t.Fatal(err)
+	}
+	rate := ratelimit.PerMinute(6)
+
+	t.Run(`Given an empty bucket,
+    when enough time passes to refill a token,
+    then the next request should be allowed. `,
+		func(t *testing.T) {
+			for i := 0; i < 6; i++ {
+				if !limiter.Allow("refill", rate).Allowed {
+					t.Fatalf("expected request %d to be allowed", i+1)
+				}
+			}
+			result := limiter.Allow("refill", rate)
+			if result.Allowed || result.RetryAfter != 10*time.Second {
+				t.Fatalf("expected the request to be limited for 10s, got %+v", result)
+			}
+
+			fakeClock.Advance(10 * time.Second)
+			if !limiter.Allow("refill", rate).Allowed {
+				t.Fatalf("expected a refilled token to be available")
+			}
+		},
+	)
+
+	t.Run(`Given two buckets where the second has no tokens left,
+    when a request counts against both,
+    then it should be refused without taking a token from the first. `,
+		func(t *testing.T) {
+			buckets := []ratelimit.Bucket{{Key: "ip", Rate: rate}, {Key: "plan", Rate: ratelimit.PerMinute(1)}}
+			if results := limiter.AllowAll(buckets); !results[0].Allowed || !results[1].Allowed {
+				t.Fatalf("expected the first request to be allowed, got %+v", results)
+			}
+			for i := 0; i < 3; i++ {
+				results := limiter.AllowAll(buckets)
+				if !results[0].Allowed || results[1].Allowed {
+					t.Fatalf("expected only the plan bucket to refuse the request, got %+v", results)
+				}
+				if results[0].Remaining != 5 {
+					t.Fatalf("expected the refused request to leave 5 tokens in the first bucket, got %d", results[0].Remaining)
+				}
+			}
+		},
+	)
+
+	t.Run(`Given more keys than the limiter tracks,
+    when idle keys age out,
+    then the number of tracked keys should stay bounded and drop to zero. `,
+		func(t *testing.T) {
+			for i := 0; i < 10; i++ {
+				limiter.Allow(fmt.Sprintf("client-%d", i), rate)
+			}
+			if limiter.Len() != 3 {
+				t.Fatalf("expected 3 tracked keys, got %d", limiter.Len())
+			}
+
+			fakeClock.Advance(5 * time.Minute)
+			limiter.Allow("fresh", rate)
+			if limiter.Len() != 1 {
+				t.Fatalf("expected idle keys to be evicted, got %d tracked keys", limiter.Len())
+			}
+		},
+	)
+}
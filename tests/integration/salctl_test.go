@@ -0,0 +1,140 @@
+//go:build integration
+// +build integration
+
+package integration
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/olad5/sal-backend-service/internal/salctl"
+	"github.com/olad5/sal-backend-service/pkg/client"
+)
+
+func TestSalctl(t *testing.T) {
+	t.Run(`Given a profile pointing at the API,
+    when salctl creates a product and fetches it by code,
+    then the product should be printed as JSON. `,
+		func(t *testing.T) {
+			config := writeSalctlConfig(t, uuid.New())
+
+			stdout, stderr, code := runSalctl(t, "", "-config", config, "products", "create",
+				"-name", "Blue Mug", "-description", "A blue mug", "-price", "9.5", "-sku-code", "MUG-1")
+			if code != salctl.ExitOK {
+				t.Fatalf("expected exit code 0, got %d: %s", code, stderr)
+			}
+			if !strings.Contains(stdout, "MUG-1") {
+				t.Fatalf("expected the created product in the table, got %q", stdout)
+			}
+
+			stdout, stderr, code = runSalctl(t, "", "-config", config, "products", "get", "mug-1", "-o", "json")
+			if code != salctl.ExitOK {
+				t.Fatalf("expected exit code 0, got %d: %s", code, stderr)
+			}
+			var product client.Product
+			if err := json.Unmarshal([]byte(stdout), &product); err != nil {
+				t.Fatal(err)
+			}
+			if product.Name != "Blue Mug" || product.Price != 9.5 {
+				t.Fatalf("expected Blue Mug at 9.5, got %v at %v", product.Name, product.Price)
+			}
+
+			stdout, _, _ = runSalctl(t, "", "-config", config, "products", "get", product.SKUID.String(), "-o", "yaml")
+			if !strings.Contains(stdout, "name: Blue Mug\n") || !strings.Contains(stdout, "sku_code: MUG-1\n") {
+				t.Fatalf("expected the product as YAML, got %q", stdout)
+			}
+		},
+	)
+
+	t.Run(`Given API errors,
+    when salctl commands fail,
+    then the exit code should reflect the kind of error. `,
+		func(t *testing.T) {
+			config := writeSalctlConfig(t, uuid.New())
+
+			if _, _, code := runSalctl(t, "", "-config", config, "products", "get", "NOPE"); code != salctl.ExitNotFound {
+				t.Fatalf("expected exit code %d, got %d", salctl.ExitNotFound, code)
+			}
+			args := []string{"-config", config, "products", "create", "-name", "Mug", "-description", "A mug", "-price", "1", "-sku-code", "DUP"}
+			if _, stderr, code := runSalctl(t, "", args...); code != salctl.ExitOK {
+				t.Fatalf("expected exit code 0, got %d: %s", code, stderr)
+			}
+			if _, _, code := runSalctl(t, "", args...); code != salctl.ExitConflict {
+				t.Fatalf("expected exit code %d, got %d", salctl.ExitConflict, code)
+			}
+			if _, _, code := runSalctl(t, "", "-config", config, "products", "list", "-o", "xml"); code != salctl.ExitUsage {
+				t.Fatalf("expected exit code %d, got %d", salctl.ExitUsage, code)
+			}
+		},
+	)
+
+	t.Run(`Given a merchant with products,
+    when they are exported as CSV and imported for another merchant,
+    then the other merchant should have the same products. `,
+		func(t *testing.T) {
+			merchantId := uuid.New()
+			config := writeSalctlConfig(t, merchantId)
+			if _, stderr, code := runSalctl(t, "", "-config", config, "seed", "-count", "3", "-seed", "1"); code != salctl.ExitOK {
+				t.Fatalf("expected exit code 0, got %d: %s", code, stderr)
+			}
+
+			exported, stderr, code := runSalctl(t, "", "-config", config, "export", "-format", "csv")
+			if code != salctl.ExitOK {
+				t.Fatalf("expected exit code 0, got %d: %s", code, stderr)
+			}
+			if lines := strings.Count(exported, "\n"); lines != 4 {
+				t.Fatalf("expected a header and 3 products, got %q", exported)
+			}
+
+			// sku_ids are unique across merchants, so the copies get new ones
+			var rows []string
+			for _, line := range strings.Split(strings.TrimSpace(exported), "\n") {
+				rows = append(rows, line[strings.Index(line, ","):])
+			}
+			other := uuid.New()
+			_, stderr, code = runSalctl(t, "sku_id"+strings.Join(rows, "\n"), "-config", config, "-merchant", other.String(), "import", "-format", "csv")
+			if code != salctl.ExitOK {
+				t.Fatalf("expected exit code 0, got %d: %s", code, stderr)
+			}
+
+			page, err := c.ListProducts(context.Background(), other, client.ListProductsOptions{})
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(page.Products) != 3 {
+				t.Fatalf("expected 3 imported products, got %d", len(page.Products))
+			}
+		},
+	)
+}
+
+func writeSalctlConfig(t *testing.T, merchantId uuid.UUID) string {
+	t.Helper()
+	config, err := json.Marshal(salctl.Config{
+		CurrentProfile: "test",
+		Profiles: map[string]salctl.Profile{
+			"test": {BaseURL: serverURL, MerchantId: merchantId.String()},
+		},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	path := filepath.Join(t.TempDir(), "config.json")
+	if err := os.WriteFile(path, config, 0o600); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func runSalctl(t *testing.T, stdin string, args ...string) (string, string, int) {
+	t.Helper()
+	var stdout, stderr bytes.Buffer
+	code := salctl.Run(context.Background(), args, strings.NewReader(stdin), &stdout, &stderr)
+	return stdout.String(), stderr.String(), code
+}
@@ -0,0 +1,176 @@
+//go:build integration
+// +build integration
+
+package integration
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/url"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/olad5/sal-backend-service/tests"
+)
+
+func TestSearchMerchantProducts(t *testing.T) {
+	merchantId := uuid.New()
+	shirt := createProduct(t, Product{
+		SKUID: uuid.New(), MerchantId: merchantId, Price: 20,
+		Name:        "Red Cotton Shirt",
+		Description: "A soft shirt made from organic cotton, perfect for running errands.",
+	})
+	_ = createProduct(t, Product{
+		SKUID: uuid.New(), MerchantId: merchantId, Price: 35,
+		Name:        "Running Shoes",
+		Description: "Lightweight shoes with a red sole.",
+	})
+	_ = createProduct(t, Product{
+		SKUID: uuid.New(), MerchantId: merchantId, Price: 15,
+		Name:        "Cotton Socks",
+		Description: "Red and blue socks sold as a pair.",
+	})
+	_ = createProduct(t, buildProduct(uuid.New(), uuid.New()))
+
+	t.Run(`Given a merchant with several products,
+    when they search for a stemmed term,
+    then products matching the term in their name should rank first. `,
+		func(t *testing.T) {
+			results := searchProducts(t, merchantId, "shirts")
+			if len(results) != 1 {
+				t.Fatalf("expected 1 result, got %d", len(results))
+			}
+			product := results[0]["product"].(map[string]interface{})
+			tests.AssertResponseMessage(t, product["sku_id"].(string), shirt.String())
+			highlights := results[0]["highlights"].(map[string]interface{})
+			tests.AssertResponseMessage(t, highlights["name"].(string), "Red Cotton <em>Shirt</em>")
+		},
+	)
+
+	t.Run(`Given a merchant with several products,
+    when they search for a term found in names and descriptions,
+    then name matches should be ranked above description matches. `,
+		func(t *testing.T) {
+			results := searchProducts(t, merchantId, "running")
+			if len(results) != 2 {
+				t.Fatalf("expected 2 results, got %d", len(results))
+			}
+			first := results[0]["product"].(map[string]interface{})
+			tests.AssertResponseMessage(t, first["name"].(string), "Running Shoes")
+		},
+	)
+
+	t.Run(`Given a merchant with several products,
+    when they search for a quoted phrase,
+    then only products containing the words next to each other should match. `,
+		func(t *testing.T) {
+			results := searchProducts(t, merchantId, `"cotton shirt"`)
+			if len(results) != 1 {
+				t.Fatalf("expected 1 result, got %d", len(results))
+			}
+			results = searchProducts(t, merchantId, `"red cotton socks"`)
+			if len(results) != 0 {
+				t.Fatalf("expected no results, got %d", len(results))
+			}
+		},
+	)
+
+	t.Run(`Given a merchant with several products,
+    when they search with a prefix query,
+    then every product with a word starting with the prefix should match. `,
+		func(t *testing.T) {
+			results := searchProducts(t, merchantId, "cott*")
+			if len(results) != 2 {
+				t.Fatalf("expected 2 results, got %d", len(results))
+			}
+		},
+	)
+
+	t.Run(`Given a product that has been renamed and another that has been deleted,
+    when the merchant searches,
+    then the results, prefix queries included, should reflect the latest state of the catalog. `,
+		func(t *testing.T) {
+			socks := searchProducts(t, merchantId, "socks")[0]["product"].(map[string]interface{})
+			requestBody, err := json.Marshal(map[string]interface{}{
+				"merchant_id": merchantId,
+				"name":        "Wool Stockings",
+				"description": "Warm stockings.",
+				"price":       18,
+			})
+			if err != nil {
+				t.Fatal(err)
+			}
+			req, _ := http.NewRequest(http.MethodPatch, "/api/products/"+socks["sku_id"].(string), bytes.NewBuffer(requestBody))
+			tests.AssertStatusCode(t, http.StatusOK, tests.ExecuteRequest(req, r).Code)
+
+			requestBody, err = json.Marshal(map[string]interface{}{"merchant_id": merchantId})
+			if err != nil {
+				t.Fatal(err)
+			}
+			req, _ = http.NewRequest(http.MethodDelete, "/api/products/"+shirt.String(), bytes.NewBuffer(requestBody))
+			tests.AssertStatusCode(t, http.StatusOK, tests.ExecuteRequest(req, r).Code)
+
+			if results := searchProducts(t, merchantId, "socks"); len(results) != 0 {
+				t.Fatalf("expected renamed product to no longer match, got %d results", len(results))
+			}
+			if results := searchProducts(t, merchantId, "stocking"); len(results) != 1 {
+				t.Fatalf("expected renamed product to match its new name, got %d results", len(results))
+			}
+			if results := searchProducts(t, merchantId, "shirt"); len(results) != 0 {
+				t.Fatalf("expected deleted product to no longer match, got %d results", len(results))
+			}
+			for query, expected := range map[string]int{"sock*": 0, "stock*": 1, "cott*": 0} {
+				if results := searchProducts(t, merchantId, query); len(results) != expected {
+					t.Fatalf("expected %d results for %s, got %d", expected, query, len(results))
+				}
+			}
+		},
+	)
+
+	t.Run(`Given a product whose name and description contain HTML,
+    when it is found by a search,
+    then its highlights should carry the text escaped around the <em> tags. `,
+		func(t *testing.T) {
+			htmlMerchantId := uuid.New()
+			createProduct(t, Product{
+				SKUID: uuid.New(), MerchantId: htmlMerchantId, Price: 10,
+				Name:        `<img src=x onerror=alert(1)> Lamp`,
+				Description: `A "bright" lamp & <script>bulb</script>`,
+			})
+
+			results := searchProducts(t, htmlMerchantId, "lamp")
+			if len(results) != 1 {
+				t.Fatalf("expected 1 result, got %d", len(results))
+			}
+			highlights := results[0]["highlights"].(map[string]interface{})
+			tests.AssertResponseMessage(t, highlights["name"].(string), "&lt;img src=x onerror=alert(1)&gt; <em>Lamp</em>")
+			tests.AssertResponseMessage(t, highlights["description"].(string), "A &#34;bright&#34; <em>lamp</em> &amp; &lt;script&gt;bulb&lt;/script&gt;")
+		},
+	)
+
+	t.Run(`Given a search request without a query,
+    when the search endpoint is called,
+    then the API should return a validation error. `,
+		func(t *testing.T) {
+			req, _ := http.NewRequest(http.MethodGet, "/api/merchants/"+merchantId.String()+"/products/search", nil)
+			response := tests.ExecuteRequest(req, r)
+			tests.AssertStatusCode(t, http.StatusBadRequest, response.Code)
+			message := tests.ParseResponse(t, response)["message"].(string)
+			tests.AssertResponseMessage(t, message, "q required")
+		},
+	)
+}
+
+func searchProducts(t *testing.T, merchantId uuid.UUID, query string) []map[string]interface{} {
+	t.Helper()
+	req, _ := http.NewRequest(http.MethodGet, "/api/merchants/"+merchantId.String()+"/products/search?q="+url.QueryEscape(query), nil)
+	response := tests.ExecuteRequest(req, r)
+	tests.AssertStatusCode(t, http.StatusOK, response.Code)
+	data := tests.ParseResponse(t, response)["data"].(map[string]interface{})
+	results := []map[string]interface{}{}
+	for _, result := range data["results"].([]interface{}) {
+		results = append(results, result.(map[string]interface{}))
+	}
+	return results
+}
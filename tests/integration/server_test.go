@@ -4,43 +4,127 @@
 package integration
 
 import (
-	"bytes"
 	"context"
-	"encoding/json"
+	"errors"
+	"fmt"
 	"math/rand"
 	"net/http"
+	"net/http/httptest"
 	"os"
-	"strconv"
 	"testing"
 	"time"
 
 	"github.com/google/uuid"
 	"github.com/olad5/sal-backend-service/internal/app/router"
+	"github.com/olad5/sal-backend-service/internal/config"
+	"github.com/olad5/sal-backend-service/internal/events"
+	"github.com/olad5/sal-backend-service/internal/health"
+	"github.com/olad5/sal-backend-service/internal/infra"
+	"github.com/olad5/sal-backend-service/internal/infra/local"
+	"github.com/olad5/sal-backend-service/internal/infra/memory"
+	"github.com/olad5/sal-backend-service/internal/infra/search"
+	"github.com/olad5/sal-backend-service/internal/lifecycle"
+	"github.com/olad5/sal-backend-service/internal/logging"
+	"github.com/olad5/sal-backend-service/internal/usecases/plans"
+	"github.com/olad5/sal-backend-service/internal/usecases/products"
+	"github.com/olad5/sal-backend-service/pkg/client"
+	"github.com/olad5/sal-backend-service/pkg/clock"
 	"github.com/olad5/sal-backend-service/tests"
 )
 
-var r http.Handler
+// adminToken is the bearer token the suite's routers accept on /admin routes.
+const adminToken = "test-admin-token"
+
+var (
+	r         http.Handler
+	c         *client.Client
+	serverURL string
+)
 
 func TestMain(m *testing.M) {
 	ctx := context.Background()
-	r = router.NewHttpRouter(ctx)
+	// the suite sends every request from the same client, rate limiting is
+	// covered separately in rate_limit_test.go
+	cfg := config.Default()
+	cfg.RateLimit.ReadsPerMinute = 0
+	cfg.RateLimit.WritesPerMinute = 0
+	cfg.Admin.Token = adminToken
+	r = newRouter(ctx, cfg, logging.Discard(), newHealthChecker())
+
+	server := httptest.NewServer(r)
+	serverURL = server.URL
+	var err error
+	c, err = client.New(serverURL, client.WithAdminToken(adminToken))
+	if err != nil {
+		panic(err)
+	}
 
 	exitVal := m.Run()
+	server.Close()
 	os.Exit(exitVal)
 }
 
-func TestCreateProduct(t *testing.T) {
-	route := "/api/products"
-	type Product struct {
-		SKUID       uuid.UUID `json:"sku_id"`
-		MerchantId  uuid.UUID `json:"merchant_id"`
-		Name        string    `json:"name"`
-		Description string    `json:"description"`
-		Price       float64   `json:"price"`
+// newRouter builds a router whose background components run until ctx is
+// done.
+func newRouter(ctx context.Context, cfg config.Config, logger *logging.Logger, checker *health.Checker) http.Handler {
+	manager, err := lifecycle.NewManager(logger.Logger)
+	if err != nil {
+		panic(err)
+	}
+	handler := router.NewHttpRouter(ctx, cfg, logger, checker, manager, nil)
+	if err := manager.Start(ctx); err != nil {
+		panic(err)
+	}
+	go func() {
+		<-ctx.Done()
+		manager.Stop()
+	}()
+	return handler
+}
+
+// newProductService builds a ProductService on in-memory stores around
+// productRepo, for tests that drive the service directly.
+func newProductService(t *testing.T, productRepo infra.ProductRepository, eventBus *events.Bus, clock clock.Clock) *products.ProductService {
+	t.Helper()
+	blobStorage, err := local.NewLocalBlobStorage(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	searchIndex, _ := search.NewProductIndex()
+	merchantPlanRepo, _ := memory.NewMemoryMerchantPlanRepo()
+	planService, err := plans.NewPlanService(merchantPlanRepo, productRepo, clock)
+	if err != nil {
+		t.Fatal(err)
+	}
+	skuCodePatternRepo, _ := memory.NewMemorySKUCodePatternRepo()
+	productService, err := products.NewProductService(productRepo, blobStorage, searchIndex, eventBus, clock, planService, skuCodePatternRepo, logging.Discard().Logger)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return productService
+}
+
+// asAdmin authorizes req for the /admin routes.
+func asAdmin(req *http.Request) *http.Request {
+	req.Header.Set("Authorization", "Bearer "+adminToken)
+	return req
+}
+
+// newHealthChecker returns a checker that has finished starting up.
+func newHealthChecker() *health.Checker {
+	checker, err := health.NewChecker(time.Second)
+	if err != nil {
+		panic(err)
 	}
+	checker.MarkReady()
+	return checker
+}
+
+func TestCreateProduct(t *testing.T) {
+	ctx := context.Background()
 	t.Run("test for invalid json request body",
 		func(t *testing.T) {
-			req, _ := http.NewRequest(http.MethodPost, route, nil)
+			req, _ := http.NewRequest(http.MethodPost, "/api/products", nil)
 			response := tests.ExecuteRequest(req, r)
 			tests.AssertStatusCode(t, http.StatusBadRequest, response.Code)
 		},
@@ -52,25 +136,20 @@ func TestCreateProduct(t *testing.T) {
 			merchantId := uuid.New()
 			skuId := uuid.New()
 			var price float64 = 30.00
-			np := Product{
+			product, err := c.CreateProduct(ctx, Product{
 				MerchantId:  merchantId,
 				SKUID:       skuId,
 				Name:        "some-product-name",
 				Description: "some-product-description",
 				Price:       price,
-			}
-			requestBody, err := json.Marshal(&np)
+			})
 			if err != nil {
 				t.Fatal(err)
 			}
-			req, _ := http.NewRequest(http.MethodPost, route, bytes.NewBuffer(requestBody))
-			response := tests.ExecuteRequest(req, r)
-			tests.AssertStatusCode(t, http.StatusOK, response.Code)
-			data := tests.ParseResponse(t, response)["data"].(map[string]interface{})
-			tests.AssertResponseMessage(t, data["merchant_id"].(string), merchantId.String())
-			tests.AssertResponseMessage(t, data["sku_id"].(string), skuId.String())
-			if responsePrice, ok := data["price"].(float64); ok {
-				tests.AssertResponseMessage(t, strconv.FormatFloat(responsePrice, 'f', -1, 64), strconv.FormatFloat(price, 'f', -1, 64))
+			tests.AssertResponseMessage(t, product.MerchantId.String(), merchantId.String())
+			tests.AssertResponseMessage(t, product.SKUID.String(), skuId.String())
+			if product.Price != price {
+				t.Errorf("expected price %v, got %v", price, product.Price)
 			}
 		},
 	)
@@ -82,37 +161,26 @@ func TestCreateProduct(t *testing.T) {
 		func(t *testing.T) {
 			merchantId := uuid.New()
 			skuId := uuid.New()
-			var price float64 = 10.00
-			np := Product{
+			_, err := c.CreateProduct(ctx, Product{
 				MerchantId:  merchantId,
 				SKUID:       skuId,
 				Name:        "some-product-name",
 				Description: "some-product-description",
-				Price:       price,
-			}
-			requestBody, err := json.Marshal(&np)
+				Price:       10.00,
+			})
 			if err != nil {
 				t.Fatal(err)
 			}
-			req, _ := http.NewRequest(http.MethodPost, route, bytes.NewBuffer(requestBody))
-			response := tests.ExecuteRequest(req, r)
-			tests.AssertStatusCode(t, http.StatusOK, response.Code)
-			newNp := Product{
+			_, err = c.CreateProduct(ctx, Product{
 				MerchantId:  merchantId,
 				SKUID:       skuId,
 				Name:        "new-product-name",
 				Description: "new-product-description",
 				Price:       100.00,
+			})
+			if !errors.Is(err, client.ErrProductAlreadyExists) {
+				t.Fatalf("expected %v, got %v", client.ErrProductAlreadyExists, err)
 			}
-			newRequestBody, err := json.Marshal(&newNp)
-			if err != nil {
-				t.Fatal(err)
-			}
-			newReq, _ := http.NewRequest(http.MethodPost, route, bytes.NewBuffer(newRequestBody))
-			newResponse := tests.ExecuteRequest(newReq, r)
-			tests.AssertStatusCode(t, http.StatusBadRequest, newResponse.Code)
-			message := tests.ParseResponse(t, newResponse)["message"].(string)
-			tests.AssertResponseMessage(t, message, "product already exists")
 		},
 	)
 
@@ -121,73 +189,78 @@ func TestCreateProduct(t *testing.T) {
          then the API should return a validation error indicating that the price 
          must be a positive number. `,
 		func(t *testing.T) {
-			merchantId := uuid.New()
-			skuId := uuid.New()
-			var price float64 = -20.00
-			np := Product{
-				MerchantId:  merchantId,
-				SKUID:       skuId,
+			_, err := c.CreateProduct(ctx, Product{
+				MerchantId:  uuid.New(),
+				SKUID:       uuid.New(),
 				Name:        "some-product-name",
 				Description: "some-product-description",
-				Price:       price,
-			}
-			requestBody, err := json.Marshal(&np)
-			if err != nil {
-				t.Fatal(err)
+				Price:       -20.00,
+			})
+			var apiErr *client.Error
+			if !errors.As(err, &apiErr) || apiErr.StatusCode != http.StatusBadRequest {
+				t.Fatalf("expected a bad request error, got %v", err)
 			}
-			req, _ := http.NewRequest(http.MethodPost, route, bytes.NewBuffer(requestBody))
-			response := tests.ExecuteRequest(req, r)
-			tests.AssertStatusCode(t, http.StatusBadRequest, response.Code)
-			message := tests.ParseResponse(t, response)["message"].(string)
-			tests.AssertResponseMessage(t, message, "price cannot be less than zero")
+			tests.AssertResponseMessage(t, apiErr.Message, "price cannot be less than zero")
 		},
 	)
 }
 
 func TestUpdateProduct(t *testing.T) {
-	route := "/api/products"
+	ctx := context.Background()
 	t.Run(`Given a merchant wants to update a product with valid data,
          When the update product endpoint is called with the correct SKU ID,
          Then the product information should be updated in the database,
          And the response status should be 200 OK. `,
 		func(t *testing.T) {
 			merchantId := uuid.New()
-			skuId := uuid.New()
-			var price float64 = 30.00
-			np := Product{
+			createdProductSkuId := createProduct(t, buildProduct(merchantId, uuid.New()))
+			updateReq := client.UpdateProductRequest{
 				MerchantId:  merchantId,
-				SKUID:       skuId,
-				Name:        "some-product-name",
-				Description: "some-product-description",
-				Price:       price,
-			}
-			createdProductSkuId := createProduct(t, np)
-			updatedPrice := 10.01
-			updateReq := Product{
-				MerchantId:  merchantId,
-				SKUID:       createdProductSkuId,
 				Name:        "updated-product-name",
 				Description: "updated-product-description",
-				Price:       updatedPrice,
+				Price:       10.01,
 			}
 
-			requestBody, err := json.Marshal(&updateReq)
+			product, err := c.UpdateProduct(ctx, createdProductSkuId, updateReq)
 			if err != nil {
 				t.Fatal(err)
 			}
-			req, _ := http.NewRequest(http.MethodPatch, route+"/"+createdProductSkuId.String(), bytes.NewBuffer(requestBody))
-			response := tests.ExecuteRequest(req, r)
-			tests.AssertStatusCode(t, http.StatusOK, response.Code)
-			parsedRes := tests.ParseResponse(t, response)
-			message := parsedRes["message"].(string)
-			tests.AssertResponseMessage(t, message, "product updated successfully")
-			data := parsedRes["data"].(map[string]interface{})
-			tests.AssertResponseMessage(t, data["merchant_id"].(string), merchantId.String())
-			tests.AssertResponseMessage(t, data["sku_id"].(string), createdProductSkuId.String())
-			tests.AssertResponseMessage(t, data["name"].(string), updateReq.Name)
-			tests.AssertResponseMessage(t, data["description"].(string), updateReq.Description)
-			if responsePrice, ok := data["price"].(float64); ok {
-				tests.AssertResponseMessage(t, strconv.FormatFloat(responsePrice, 'f', -1, 64), strconv.FormatFloat(updatedPrice, 'f', -1, 64))
+			tests.AssertResponseMessage(t, product.MerchantId.String(), merchantId.String())
+			tests.AssertResponseMessage(t, product.SKUID.String(), createdProductSkuId.String())
+			tests.AssertResponseMessage(t, product.Name, updateReq.Name)
+			tests.AssertResponseMessage(t, product.Description, updateReq.Description)
+			if product.Price != updateReq.Price {
+				t.Errorf("expected price %v, got %v", updateReq.Price, product.Price)
+			}
+		},
+	)
+
+	t.Run(`Given a product with tags and attributes,
+         When it is updated without them and then with empty ones,
+         Then the first update should keep them and the second remove them. `,
+		func(t *testing.T) {
+			merchantId := uuid.New()
+			prd := buildProduct(merchantId, uuid.New())
+			prd.Tags = []string{" summer ", "Summer", "sale"}
+			prd.Attributes = map[string]string{" Colour ": "red"}
+			skuId := createProduct(t, prd)
+			updateReq := client.UpdateProductRequest{MerchantId: merchantId, Name: "kept", Description: "kept", Price: 1}
+
+			product, err := c.UpdateProduct(ctx, skuId, updateReq)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if fmt.Sprint(product.Tags, product.Attributes) != "[summer sale] map[colour:red]" {
+				t.Fatalf("expected the normalized tags and attributes to be kept, got %v %v", product.Tags, product.Attributes)
+			}
+
+			updateReq.Tags, updateReq.Attributes = []string{}, map[string]string{}
+			product, err = c.UpdateProduct(ctx, skuId, updateReq)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(product.Tags) != 0 || len(product.Attributes) != 0 {
+				t.Fatalf("expected the tags and attributes to be removed, got %v %v", product.Tags, product.Attributes)
 			}
 		},
 	)
@@ -197,63 +270,32 @@ func TestUpdateProduct(t *testing.T) {
          Then the endpoint should return a 404 Not Found status,
          And the product information should not be updated in the database. `,
 		func(t *testing.T) {
-			nonExisitentSkuId := uuid.New()
-			updatedPrice := 10.01
-			updateReq := Product{
+			_, err := c.UpdateProduct(ctx, uuid.New(), client.UpdateProductRequest{
 				MerchantId:  uuid.New(),
-				SKUID:       nonExisitentSkuId,
 				Name:        "updated-product-name",
 				Description: "updated-product-description",
-				Price:       updatedPrice,
-			}
-
-			requestBody, err := json.Marshal(&updateReq)
-			if err != nil {
-				t.Fatal(err)
+				Price:       10.01,
+			})
+			if !errors.Is(err, client.ErrProductNotFound) {
+				t.Fatalf("expected %v, got %v", client.ErrProductNotFound, err)
 			}
-			req, _ := http.NewRequest(http.MethodPatch, route+"/"+nonExisitentSkuId.String(), bytes.NewBuffer(requestBody))
-			response := tests.ExecuteRequest(req, r)
-			tests.AssertStatusCode(t, http.StatusNotFound, response.Code)
-			parsedRes := tests.ParseResponse(t, response)
-			message := parsedRes["message"].(string)
-			tests.AssertResponseMessage(t, message, "product not found")
 		},
 	)
 }
 
 func TestDeleteProduct(t *testing.T) {
-	route := "/api/products"
+	ctx := context.Background()
 	t.Run(`Given a merchant wants to delete an existing product with a valid SKU ID,
          when they send a DELETE request to the delete product route,
          then the product should be successfully deleted from the database,
          and the response status should be 200 OK. `,
 		func(t *testing.T) {
 			merchantId := uuid.New()
-			skuId := uuid.New()
-			var price float64 = 30.00
-			np := Product{
-				MerchantId:  merchantId,
-				SKUID:       skuId,
-				Name:        "some-product-name",
-				Description: "some-product-description",
-				Price:       price,
-			}
-			createdProductSkuId := createProduct(t, np)
-			requestBody, err := json.Marshal(&struct {
-				MerchantId uuid.UUID `json:"merchant_id"`
-			}{
-				MerchantId: merchantId,
-			})
-			if err != nil {
+			createdProductSkuId := createProduct(t, buildProduct(merchantId, uuid.New()))
+
+			if err := c.DeleteProduct(ctx, merchantId, createdProductSkuId); err != nil {
 				t.Fatal(err)
 			}
-
-			req, _ := http.NewRequest(http.MethodDelete, route+"/"+createdProductSkuId.String(), bytes.NewBuffer(requestBody))
-			response := tests.ExecuteRequest(req, r)
-			tests.AssertStatusCode(t, http.StatusOK, response.Code)
-			parsedRes := tests.ParseResponse(t, response)
-			message := parsedRes["message"].(string)
-			tests.AssertResponseMessage(t, message, "product deleted successfully")
 		},
 	)
 
@@ -262,26 +304,28 @@ func TestDeleteProduct(t *testing.T) {
          then the product should not be deleted from the database,
          and the response status should be 404 Not Found. `,
 		func(t *testing.T) {
-			nonExisitentSkuId := uuid.New()
-			requestBody, err := json.Marshal(&struct {
-				MerchantId uuid.UUID `json:"merchant_id"`
-			}{
-				MerchantId: uuid.New(),
-			})
-			if err != nil {
-				t.Fatal(err)
+			err := c.DeleteProduct(ctx, uuid.New(), uuid.New())
+			if !errors.Is(err, client.ErrProductNotFound) {
+				t.Fatalf("expected %v, got %v", client.ErrProductNotFound, err)
+			}
+		},
+	)
+
+	t.Run(`Given a merchant tries to delete another merchant's product,
+         when they send a DELETE request to the delete product route,
+         then the API should hide the product behind an unauthorized error. `,
+		func(t *testing.T) {
+			skuId := createProduct(t, buildProduct(uuid.New(), uuid.New()))
+			err := c.DeleteProduct(ctx, uuid.New(), skuId)
+			if !errors.Is(err, client.ErrUnauthorized) {
+				t.Fatalf("expected %v, got %v", client.ErrUnauthorized, err)
 			}
-			req, _ := http.NewRequest(http.MethodDelete, route+"/"+nonExisitentSkuId.String(), bytes.NewBuffer(requestBody))
-			response := tests.ExecuteRequest(req, r)
-			tests.AssertStatusCode(t, http.StatusNotFound, response.Code)
-			parsedRes := tests.ParseResponse(t, response)
-			message := parsedRes["message"].(string)
-			tests.AssertResponseMessage(t, message, "product not found")
 		},
 	)
 }
 
 func TestFetchMerchantProducts(t *testing.T) {
+	ctx := context.Background()
 	t.Run(` Given a merchant A with ID 'merchant123' has products listed,
     when the merchant requests to fetch products by their ID,
     then the system should return all products associated with 'merchant123'.
@@ -291,39 +335,22 @@ func TestFetchMerchantProducts(t *testing.T) {
 			merchantBId := uuid.New()
 			numberOfRecords := 20
 			for i := 0; i < numberOfRecords; i++ {
-				skuId := uuid.New()
-				prd := buildProduct(merchantAId, skuId)
-				_ = createProduct(t, prd)
+				_ = createProduct(t, buildProduct(merchantAId, uuid.New()))
 			}
 			for i := 0; i < 5; i++ {
-				skuId := uuid.New()
-				prd := buildProduct(merchantBId, skuId)
-				_ = createProduct(t, prd)
+				_ = createProduct(t, buildProduct(merchantBId, uuid.New()))
 			}
 
-			req, _ := http.NewRequest(http.MethodGet, "/api/merchants"+"/"+merchantAId.String()+"/"+"products", nil)
-			response := tests.ExecuteRequest(req, r)
-			tests.AssertStatusCode(t, http.StatusOK, response.Code)
-			parsedRes := tests.ParseResponse(t, response)
-			message := parsedRes["message"].(string)
-			tests.AssertResponseMessage(t, message, "products retrieved successfully")
-			data := parsedRes["data"].(map[string]interface{})
-			items := data["products"].([]interface{})
-			for _, item := range items {
-				product, ok := item.(map[string]interface{})
-				if !ok {
-					t.Fatalf("Unable to get product from response")
-				}
-				merchantID, ok := product["merchant_id"].(string)
-				if !ok {
-					t.Fatalf("Unable to get merchantID from product")
-				}
-
-				if merchantID != merchantAId.String() {
+			page, err := c.ListProducts(ctx, merchantAId, client.ListProductsOptions{})
+			if err != nil {
+				t.Fatal(err)
+			}
+			for _, product := range page.Products {
+				if product.MerchantId != merchantAId {
 					t.Fatalf("merchantID is not the same")
 				}
 			}
-			if len(items) != numberOfRecords {
+			if len(page.Products) != numberOfRecords {
 				t.Fatalf("number of records are not the same")
 			}
 		},
@@ -335,22 +362,15 @@ func TestFetchMerchantProducts(t *testing.T) {
 		func(t *testing.T) {
 			merchantAId := uuid.New()
 			merchantBId := uuid.New()
-			numberOfRecords := 20
-			for i := 0; i < numberOfRecords; i++ {
-				skuId := uuid.New()
-				prd := buildProduct(merchantAId, skuId)
-				_ = createProduct(t, prd)
+			for i := 0; i < 20; i++ {
+				_ = createProduct(t, buildProduct(merchantAId, uuid.New()))
 			}
 
-			req, _ := http.NewRequest(http.MethodGet, "/api/merchants"+"/"+merchantBId.String()+"/"+"products", nil)
-			response := tests.ExecuteRequest(req, r)
-			tests.AssertStatusCode(t, http.StatusOK, response.Code)
-			parsedRes := tests.ParseResponse(t, response)
-			message := parsedRes["message"].(string)
-			tests.AssertResponseMessage(t, message, "products retrieved successfully")
-			data := parsedRes["data"].(map[string]interface{})
-			items := data["products"].([]interface{})
-			if len(items) > 0 {
+			page, err := c.ListProducts(ctx, merchantBId, client.ListProductsOptions{})
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(page.Products) > 0 {
 				t.Fatalf("number of records are not the same")
 			}
 		},
@@ -359,27 +379,15 @@ func TestFetchMerchantProducts(t *testing.T) {
 
 func createProduct(t testing.TB, np Product) uuid.UUID {
 	t.Helper()
-	requestBody, err := json.Marshal(&np)
+	product, err := c.CreateProduct(context.Background(), np)
 	if err != nil {
-		t.Fatal(err)
+		t.Fatalf("unable to create product: %v", err)
 	}
-	req, _ := http.NewRequest(http.MethodPost, "/api/products", bytes.NewBuffer(requestBody))
-	response := tests.ExecuteRequest(req, r)
-	data := tests.ParseResponse(t, response)["data"].(map[string]interface{})
-	if id, ok := data["sku_id"].(string); !ok && id == np.SKUID.String() {
-		t.Fatalf("unable to create product")
-	}
-
-	return np.SKUID
+	return product.SKUID
 }
 
-type Product struct {
-	SKUID       uuid.UUID `json:"sku_id"`
-	MerchantId  uuid.UUID `json:"merchant_id"`
-	Name        string    `json:"name"`
-	Description string    `json:"description"`
-	Price       float64   `json:"price"`
-}
+// Product is the create product request the suite builds its fixtures from.
+type Product = client.CreateProductRequest
 
 func randomFloat64(min, max float64) float64 {
 	rand.Seed(time.Now().UnixNano())
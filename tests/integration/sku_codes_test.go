@@ -0,0 +1,133 @@
+//go:build integration
+// +build integration
+
+package integration
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/olad5/sal-backend-service/pkg/client"
+)
+
+func TestSKUCodes(t *testing.T) {
+	ctx := context.Background()
+	t.Run(`Given a merchant creates a product without an sku_id or sku_code,
+    when they make a POST request to the create product endpoint,
+    then the server should generate the ID and a code from the default pattern. `,
+		func(t *testing.T) {
+			merchantId := uuid.New()
+			product, err := createProductWithCode(merchantId, "")
+			if err != nil {
+				t.Fatal(err)
+			}
+			if product.SKUID == uuid.Nil {
+				t.Fatalf("expected a generated sku_id")
+			}
+			if product.SKUCode != "SKU-000001" {
+				t.Fatalf("expected sku_code SKU-000001, got %v", product.SKUCode)
+			}
+
+			product, err = createProductWithCode(merchantId, "")
+			if err != nil {
+				t.Fatal(err)
+			}
+			if product.SKUCode != "SKU-000002" {
+				t.Fatalf("expected sku_code SKU-000002, got %v", product.SKUCode)
+			}
+		},
+	)
+
+	t.Run(`Given a merchant with a custom code pattern,
+    when they create a product without an sku_code,
+    then the generated code should follow their pattern. `,
+		func(t *testing.T) {
+			merchantId := uuid.New()
+			if _, err := c.SetSKUCodePattern(ctx, merchantId, "TSHIRT-{seq:4}"); err != nil {
+				t.Fatal(err)
+			}
+			pattern, err := c.GetSKUCodePattern(ctx, merchantId)
+			if err != nil || pattern.Pattern != "TSHIRT-{seq:4}" {
+				t.Fatalf("expected the pattern to be saved, got %v and %v", pattern.Pattern, err)
+			}
+
+			product, err := createProductWithCode(merchantId, "")
+			if err != nil {
+				t.Fatal(err)
+			}
+			if product.SKUCode != "TSHIRT-0001" {
+				t.Fatalf("expected sku_code TSHIRT-0001, got %v", product.SKUCode)
+			}
+		},
+	)
+
+	t.Run(`Given a product with a merchant supplied sku_code,
+    when the merchant looks it up by code in a different case,
+    then the product should be returned. `,
+		func(t *testing.T) {
+			merchantId := uuid.New()
+			created, err := createProductWithCode(merchantId, "TSHIRT-RED-M")
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			product, err := c.GetProductByCode(ctx, merchantId, "tshirt-red-m")
+			if err != nil {
+				t.Fatal(err)
+			}
+			if product.SKUID != created.SKUID || product.SKUCode != "TSHIRT-RED-M" {
+				t.Fatalf("expected product %v with code TSHIRT-RED-M, got %v and %v", created.SKUID, product.SKUID, product.SKUCode)
+			}
+
+			_, err = c.GetProductByCode(ctx, uuid.New(), "TSHIRT-RED-M")
+			if !errors.Is(err, client.ErrProductNotFound) {
+				t.Fatalf("expected %v, got %v", client.ErrProductNotFound, err)
+			}
+		},
+	)
+
+	t.Run(`Given a product with an sku_code,
+    when the same merchant creates another product with that code,
+    then the API should return a conflict error. `,
+		func(t *testing.T) {
+			merchantId := uuid.New()
+			if _, err := createProductWithCode(merchantId, "MUG-01"); err != nil {
+				t.Fatal(err)
+			}
+			if _, err := createProductWithCode(merchantId, "mug-01"); !errors.Is(err, client.ErrSkuCodeTaken) {
+				t.Fatalf("expected %v, got %v", client.ErrSkuCodeTaken, err)
+			}
+			if _, err := createProductWithCode(uuid.New(), "MUG-01"); err != nil {
+				t.Fatal(err)
+			}
+		},
+	)
+
+	t.Run(`Given a merchant sets a code pattern without a sequence placeholder,
+    when they call the sku code pattern endpoint,
+    then the API should return a bad request error. `,
+		func(t *testing.T) {
+			_, err := c.SetSKUCodePattern(ctx, uuid.New(), "TSHIRT")
+			var apiErr *client.Error
+			if !errors.As(err, &apiErr) || !errors.Is(err, client.ErrBadRequest) {
+				t.Fatalf("expected a bad request error, got %v", err)
+			}
+			if !strings.Contains(apiErr.Message, "pattern") {
+				t.Fatalf("expected an invalid pattern message, got %q", apiErr.Message)
+			}
+		},
+	)
+}
+
+func createProductWithCode(merchantId uuid.UUID, skuCode string) (client.Product, error) {
+	return c.CreateProduct(context.Background(), client.CreateProductRequest{
+		MerchantId:  merchantId,
+		SKUCode:     skuCode,
+		Name:        "some-product-name" + uuid.New().String(),
+		Description: "some-product-description",
+		Price:       10,
+	})
+}
@@ -0,0 +1,101 @@
+//go:build integration
+// +build integration
+
+package integration
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/olad5/sal-backend-service/tests"
+)
+
+func TestSuggestProductNames(t *testing.T) {
+	merchantId := uuid.New()
+	for _, name := range []string{"Red Cotton Shirt", "Shirt Dress", "Running Shoes", "Short Sleeve Top"} {
+		prd := buildProduct(merchantId, uuid.New())
+		prd.Name = name
+		_ = createProduct(t, prd)
+	}
+
+	t.Run(`Given a merchant with several products,
+    when the storefront asks for suggestions for a prefix,
+    then exact prefix matches should be ranked above names that need an edit. `,
+		func(t *testing.T) {
+			suggestions := suggestProductNames(t, merchantId, "shi")
+			if len(suggestions) != 4 {
+				t.Fatalf("expected 4 suggestions, got %d", len(suggestions))
+			}
+			tests.AssertResponseMessage(t, suggestions[0]["name"].(string), "Shirt Dress")
+			tests.AssertResponseMessage(t, suggestions[1]["name"].(string), "Red Cotton Shirt")
+			for index, suggestion := range suggestions {
+				expected := 0.0
+				if index > 1 {
+					expected = 1
+				}
+				if distance := suggestion["distance"].(float64); distance != expected {
+					t.Fatalf("expected %q to have a distance of %v, got %v", suggestion["name"], expected, distance)
+				}
+			}
+		},
+	)
+
+	t.Run(`Given a merchant with several products,
+    when the storefront asks for suggestions for a misspelled prefix,
+    then names within a small edit distance should still be suggested. `,
+		func(t *testing.T) {
+			suggestions := suggestProductNames(t, merchantId, "runnign")
+			if len(suggestions) != 1 {
+				t.Fatalf("expected 1 suggestion, got %d", len(suggestions))
+			}
+			tests.AssertResponseMessage(t, suggestions[0]["name"].(string), "Running Shoes")
+			if distance := suggestions[0]["distance"].(float64); distance != 1 {
+				t.Fatalf("expected a distance of 1, got %v", distance)
+			}
+		},
+	)
+
+	t.Run(`Given a suggest request without a prefix,
+    when the suggest endpoint is called,
+    then the API should return a validation error. `,
+		func(t *testing.T) {
+			req, _ := http.NewRequest(http.MethodGet, "/api/merchants/"+merchantId.String()+"/products/suggest", nil)
+			response := tests.ExecuteRequest(req, r)
+			tests.AssertStatusCode(t, http.StatusBadRequest, response.Code)
+		},
+	)
+}
+
+func BenchmarkSuggestProductNames(b *testing.B) {
+	merchantId := uuid.New()
+	adjectives := []string{"red", "blue", "green", "cotton", "wool", "silk", "vintage", "modern", "classic", "slim"}
+	nouns := []string{"shirt", "dress", "shoes", "socks", "jacket", "trousers", "scarf", "hat", "gloves", "sweater"}
+	for i := 0; i < 10000; i++ {
+		prd := buildProduct(merchantId, uuid.New())
+		prd.Name = fmt.Sprintf("%s %s %s %d", adjectives[i%10], adjectives[(i/10)%10], nouns[(i/100)%10], i)
+		_ = createProduct(b, prd)
+	}
+	route := "/api/merchants/" + merchantId.String() + "/products/suggest?prefix=" + url.QueryEscape("vintaeg sw")
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		req, _ := http.NewRequest(http.MethodGet, route, nil)
+		_ = tests.ExecuteRequest(req, r)
+	}
+}
+
+func suggestProductNames(t *testing.T, merchantId uuid.UUID, prefix string) []map[string]interface{} {
+	t.Helper()
+	req, _ := http.NewRequest(http.MethodGet, "/api/merchants/"+merchantId.String()+"/products/suggest?prefix="+url.QueryEscape(prefix), nil)
+	response := tests.ExecuteRequest(req, r)
+	tests.AssertStatusCode(t, http.StatusOK, response.Code)
+	data := tests.ParseResponse(t, response)["data"].(map[string]interface{})
+	suggestions := []map[string]interface{}{}
+	for _, suggestion := range data["suggestions"].([]interface{}) {
+		suggestions = append(suggestions, suggestion.(map[string]interface{}))
+	}
+	return suggestions
+}
@@ -0,0 +1,224 @@
+//go:build integration
+// +build integration
+
+package integration
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/olad5/sal-backend-service/internal/domain"
+	"github.com/olad5/sal-backend-service/internal/infra/memory"
+	"github.com/olad5/sal-backend-service/internal/usecases/tax"
+	"github.com/olad5/sal-backend-service/tests"
+)
+
+func TestProductPriceTax(t *testing.T) {
+	for region, rates := range map[string]map[string]float64{
+		"DE": {"standard": 19, "reduced": 7},
+		"GB": {"standard": 20, "reduced": 10},
+	} {
+		requestBody, _ := json.Marshal(map[string]interface{}{"rates": rates})
+		req, _ := http.NewRequest(http.MethodPut, "/admin/tax-regions/"+region, bytes.NewBuffer(requestBody))
+		response := tests.ExecuteRequest(asAdmin(req), r)
+		tests.AssertStatusCode(t, http.StatusOK, response.Code)
+	}
+
+	t.Run(`Given products in each tax class,
+    when a merchant lists their products for a region,
+    then prices should be reported tax inclusive by default and tax exclusive on request. `,
+		func(t *testing.T) {
+			merchantId := uuid.New()
+			expected := map[string][2]float64{}
+			for taxClass, price := range map[string]float64{"standard": 10, "reduced": 2.55, "zero": 5} {
+				prd := buildProduct(merchantId, uuid.New())
+				prd.Price = price
+				createTaxedProduct(t, prd, taxClass)
+				expected[prd.SKUID.String()] = map[string][2]float64{
+					"standard": {11.90, 10},
+					"reduced":  {2.73, 2.55},
+					"zero":     {5, 5},
+				}[taxClass]
+			}
+
+			for index, display := range []string{"inclusive", "exclusive"} {
+				req, _ := http.NewRequest(http.MethodGet, "/api/merchants/"+merchantId.String()+"/products?region=de&tax_display="+display, nil)
+				response := tests.ExecuteRequest(req, r)
+				tests.AssertStatusCode(t, http.StatusOK, response.Code)
+				for _, item := range tests.ParseResponse(t, response)["data"].(map[string]interface{})["products"].([]interface{}) {
+					product := item.(map[string]interface{})
+					taxed := product["tax"].(map[string]interface{})
+					if want := expected[product["sku_id"].(string)][index]; taxed["effective_price"].(float64) != want {
+						t.Fatalf("expected %s %s price %v, got %v", product["tax_class"], display, want, taxed["effective_price"])
+					}
+				}
+			}
+		},
+	)
+
+	t.Run(`Given a tax amount that falls exactly on half a cent,
+    when a merchant lists their products for a region,
+    then the tax should round half up to the cent. `,
+		func(t *testing.T) {
+			merchantId := uuid.New()
+			prd := buildProduct(merchantId, uuid.New())
+			prd.Price = 0.05
+			createTaxedProduct(t, prd, "reduced")
+
+			req, _ := http.NewRequest(http.MethodGet, "/api/merchants/"+merchantId.String()+"/products?region=GB", nil)
+			response := tests.ExecuteRequest(req, r)
+			tests.AssertStatusCode(t, http.StatusOK, response.Code)
+			product := tests.ParseResponse(t, response)["data"].(map[string]interface{})["products"].([]interface{})[0].(map[string]interface{})
+			effective := product["tax"].(map[string]interface{})["effective"].(map[string]interface{})
+			if effective["tax"].(float64) != 0.01 || effective["gross"].(float64) != 0.06 {
+				t.Fatalf("expected tax 0.01 and gross 0.06, got %v and %v", effective["tax"], effective["gross"])
+			}
+		},
+	)
+
+	t.Run(`Given a product fetched by its SKU code,
+    when a region is requested,
+    then the product should carry the taxed prices as it does in listings. `,
+		func(t *testing.T) {
+			prd := buildProduct(uuid.New(), uuid.New())
+			prd.Price = 10
+			response := createTaxedProduct(t, prd, "reduced")
+			tests.AssertStatusCode(t, http.StatusOK, response.Code)
+			skuCode := tests.ParseResponse(t, response)["data"].(map[string]interface{})["sku_code"].(string)
+
+			req, _ := http.NewRequest(http.MethodGet, "/api/merchants/"+prd.MerchantId.String()+"/products/by-code/"+skuCode+"?region=DE&tax_display=exclusive", nil)
+			response = tests.ExecuteRequest(req, r)
+			tests.AssertStatusCode(t, http.StatusOK, response.Code)
+			taxed := tests.ParseResponse(t, response)["data"].(map[string]interface{})["tax"].(map[string]interface{})
+			effective := taxed["effective"].(map[string]interface{})
+			if taxed["region"] != "DE" || taxed["effective_price"].(float64) != 10 || effective["gross"].(float64) != 10.7 {
+				t.Fatalf("expected a net 10 and gross 10.7 in DE, got %v", taxed)
+			}
+
+			req, _ = http.NewRequest(http.MethodGet, "/api/merchants/"+prd.MerchantId.String()+"/products/by-code/"+skuCode+"?region=FR", nil)
+			tests.AssertStatusCode(t, http.StatusBadRequest, tests.ExecuteRequest(req, r).Code)
+		},
+	)
+
+	t.Run(`Given a region missing from the tax rate table,
+    when a merchant lists their products for that region,
+    then the API should return a bad request error. `,
+		func(t *testing.T) {
+			req, _ := http.NewRequest(http.MethodGet, "/api/merchants/"+uuid.New().String()+"/products?region=FR", nil)
+			response := tests.ExecuteRequest(req, r)
+			tests.AssertStatusCode(t, http.StatusBadRequest, response.Code)
+		},
+	)
+
+	t.Run(`Given a merchant creates a product with an unknown tax class,
+    when they call the create product endpoint,
+    then the API should return a bad request error. `,
+		func(t *testing.T) {
+			response := createTaxedProduct(t, buildProduct(uuid.New(), uuid.New()), "luxury")
+			tests.AssertStatusCode(t, http.StatusBadRequest, response.Code)
+		},
+	)
+}
+
+func TestLoadTaxRatesFile(t *testing.T) {
+	ctx := context.Background()
+	rateRepo, _ := memory.NewMemoryTaxRateRepo()
+	taxService, err := tax.NewTaxService(rateRepo, tests.NewFakeClock(time.Now()))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	path := filepath.Join(t.TempDir(), "tax_rates.json")
+	content := `{"regions": {"de": {"standard": 19, "reduced": 7}, "US-CA": {"standard": 7.25, "reduced": 0}}}`
+	if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := taxService.LoadTaxRatesFile(ctx, path); err != nil {
+		t.Fatal(err)
+	}
+
+	region, err := taxService.GetTaxRegion(ctx, "us-ca")
+	if err != nil {
+		t.Fatal(err)
+	}
+	taxed := tax.Calculate(region, domain.StandardTaxClass, 19.99)
+	if taxed.Tax != 1.45 || taxed.Gross != 21.44 {
+		t.Fatalf("expected tax 1.45 and gross 21.44, got %v and %v", taxed.Tax, taxed.Gross)
+	}
+
+	assertRegions := func(t *testing.T, expected string) {
+		t.Helper()
+		regions, err := taxService.GetTaxRegions(ctx)
+		if err != nil {
+			t.Fatal(err)
+		}
+		codes := []string{}
+		for _, region := range regions {
+			codes = append(codes, fmt.Sprintf("%s:%v", region.Code, region.Rates[domain.StandardTaxClass]))
+		}
+		if got := strings.Join(codes, ","); got != expected {
+			t.Fatalf("expected regions %s, got %s", expected, got)
+		}
+	}
+
+	t.Run(`Given a loaded tax rates file,
+    when it is reloaded with a changed rate and an invalid region,
+    then the reload should fail and no region should change. `,
+		func(t *testing.T) {
+			content := `{"regions": {"DE": {"standard": 20, "reduced": 7}, "FR": {"standard": 120, "reduced": 5.5}}}`
+			if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+				t.Fatal(err)
+			}
+			if _, err := taxService.LoadTaxRatesFile(ctx, path); !errors.Is(err, tax.ErrInvalidTaxRate) {
+				t.Fatalf("expected an invalid tax rate error, got %v", err)
+			}
+			assertRegions(t, "DE:19,US-CA:7.25")
+		},
+	)
+
+	t.Run(`Given regions loaded from the file and one set through the API,
+    when the file is reloaded without one of its regions,
+    then that region should be removed and the one set through the API kept. `,
+		func(t *testing.T) {
+			if _, err := taxService.SetTaxRegion(ctx, "GB", map[string]float64{"standard": 20, "reduced": 5}); err != nil {
+				t.Fatal(err)
+			}
+			content := `{"regions": {"DE": {"standard": 20, "reduced": 7}}}`
+			if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+				t.Fatal(err)
+			}
+			if _, err := taxService.LoadTaxRatesFile(ctx, path); err != nil {
+				t.Fatal(err)
+			}
+			assertRegions(t, "DE:20,GB:20")
+		},
+	)
+}
+
+func createTaxedProduct(t *testing.T, np Product, taxClass string) *httptest.ResponseRecorder {
+	t.Helper()
+	requestBody, err := json.Marshal(map[string]interface{}{
+		"sku_id":      np.SKUID,
+		"merchant_id": np.MerchantId,
+		"name":        np.Name,
+		"description": np.Description,
+		"price":       np.Price,
+		"tax_class":   taxClass,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	req, _ := http.NewRequest(http.MethodPost, "/api/products", bytes.NewBuffer(requestBody))
+	return tests.ExecuteRequest(req, r)
+}
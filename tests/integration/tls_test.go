@@ -0,0 +1,381 @@
+//go:build integration
+// +build integration
+
+package integration
+
+import (
+	"bytes"
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/json"
+	"encoding/pem"
+	"math/big"
+	"net"
+	"net/http"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/olad5/sal-backend-service/internal/app/middleware"
+	"github.com/olad5/sal-backend-service/internal/app/router"
+	"github.com/olad5/sal-backend-service/internal/config"
+	"github.com/olad5/sal-backend-service/internal/lifecycle"
+	"github.com/olad5/sal-backend-service/internal/logging"
+	"github.com/olad5/sal-backend-service/tests"
+)
+
+// testCA issues certificates for the TLS tests.
+type testCA struct {
+	cert   *x509.Certificate
+	key    *ecdsa.PrivateKey
+	pool   *x509.CertPool
+	serial int64
+}
+
+func newTestCA(t *testing.T) *testCA {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	template := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: "sal test CA"},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(time.Hour),
+		IsCA:                  true,
+		BasicConstraintsValid: true,
+		KeyUsage:              x509.KeyUsageCertSign,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cert, err := x509.ParseCertificate(der)
+	if err != nil {
+		t.Fatal(err)
+	}
+	pool := x509.NewCertPool()
+	pool.AddCert(cert)
+	return &testCA{cert: cert, key: key, pool: pool, serial: 1}
+}
+
+func (ca *testCA) pem() []byte {
+	return pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: ca.cert.Raw})
+}
+
+// issue returns a PEM certificate and key for subject, for 127.0.0.1 when
+// server is true and for client authentication otherwise.
+func (ca *testCA) issue(t *testing.T, subject pkix.Name, server bool) ([]byte, []byte) {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ca.serial++
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(ca.serial),
+		Subject:      subject,
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		KeyUsage:     x509.KeyUsageDigitalSignature,
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth},
+	}
+	if server {
+		template.ExtKeyUsage = []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth}
+		template.IPAddresses = []net.IP{net.ParseIP("127.0.0.1")}
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, ca.cert, &key.PublicKey, ca.key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	keyDER, err := x509.MarshalECPrivateKey(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}),
+		pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER})
+}
+
+func (ca *testCA) clientCertificate(t *testing.T, subject pkix.Name) tls.Certificate {
+	t.Helper()
+	certPEM, keyPEM := ca.issue(t, subject, false)
+	certificate, err := tls.X509KeyPair(certPEM, keyPEM)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return certificate
+}
+
+// writeFileAtomically replaces path in one step, the way certificates are
+// rotated, so a reload never sees half a file.
+func writeFileAtomically(t *testing.T, path string, data []byte) {
+	t.Helper()
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Rename(tmp, path); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func writeClientIdentities(t *testing.T, path string, identities []middleware.ClientIdentity) {
+	t.Helper()
+	data, err := json.Marshal(map[string]interface{}{"identities": identities})
+	if err != nil {
+		t.Fatal(err)
+	}
+	writeFileAtomically(t, path, data)
+}
+
+// startTLSServer serves the service with cfg on a free port until the test
+// ends, returning its URL and manager.
+func startTLSServer(t *testing.T, cfg config.Config) (string, *lifecycle.Manager) {
+	t.Helper()
+	ctx := context.Background()
+	manager, err := lifecycle.NewManager(logging.Discard().Logger)
+	if err != nil {
+		t.Fatal(err)
+	}
+	handler := router.NewHttpRouter(ctx, cfg, logging.Discard(), newHealthChecker(), manager, nil)
+	tlsConfig, err := router.NewTLSConfig(cfg.TLS, logging.Discard().Logger, manager)
+	if err != nil {
+		t.Fatal(err)
+	}
+	server := lifecycle.NewHTTPServer(&http.Server{Addr: "127.0.0.1:0", Handler: handler, TLSConfig: tlsConfig})
+	manager.Add("http_server", server, time.Second)
+	if err := manager.Start(ctx); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { manager.Stop() })
+	return "https://" + server.Addr().String(), manager
+}
+
+func newTLSClient(ca *testCA, certificates ...tls.Certificate) *http.Client {
+	return &http.Client{
+		Timeout: 5 * time.Second,
+		Transport: &http.Transport{
+			TLSClientConfig:   &tls.Config{RootCAs: ca.pool, Certificates: certificates},
+			DisableKeepAlives: true,
+		},
+	}
+}
+
+func TestTLS(t *testing.T) {
+	ca := newTestCA(t)
+	dir := t.TempDir()
+	cfg := config.Default()
+	cfg.TLS.CertFile = filepath.Join(dir, "server.pem")
+	cfg.TLS.KeyFile = filepath.Join(dir, "server-key.pem")
+	cfg.TLS.ReloadInterval = config.Duration{Duration: 20 * time.Millisecond}
+	certPEM, keyPEM := ca.issue(t, pkix.Name{CommonName: "sal"}, true)
+	writeFileAtomically(t, cfg.TLS.CertFile, certPEM)
+	writeFileAtomically(t, cfg.TLS.KeyFile, keyPEM)
+	if err := cfg.Validate(); err != nil {
+		t.Fatal(err)
+	}
+	serverURL, _ := startTLSServer(t, cfg)
+	client := newTLSClient(ca)
+
+	servedSerial := func(t *testing.T) int64 {
+		t.Helper()
+		response, err := client.Get(serverURL + "/")
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer response.Body.Close()
+		tests.AssertStatusCode(t, http.StatusOK, response.StatusCode)
+		return response.TLS.PeerCertificates[0].SerialNumber.Int64()
+	}
+
+	t.Run(`Given a certificate and key,
+    when a client connects over HTTPS,
+    then the service should serve the certificate. `,
+		func(t *testing.T) {
+			if serial := servedSerial(t); serial != ca.serial {
+				t.Fatalf("expected certificate %d to be served, got %d", ca.serial, serial)
+			}
+		},
+	)
+
+	t.Run(`Given a running server,
+    when its certificate files are replaced,
+    then new connections should get the new certificate without a restart. `,
+		func(t *testing.T) {
+			certPEM, keyPEM := ca.issue(t, pkix.Name{CommonName: "sal"}, true)
+			writeFileAtomically(t, cfg.TLS.KeyFile, keyPEM)
+			writeFileAtomically(t, cfg.TLS.CertFile, certPEM)
+
+			deadline := time.Now().Add(5 * time.Second)
+			for servedSerial(t) != ca.serial {
+				if time.Now().After(deadline) {
+					t.Fatalf("expected certificate %d to be served after the files changed", ca.serial)
+				}
+				time.Sleep(20 * time.Millisecond)
+			}
+		},
+	)
+
+	t.Run(`Given a certificate file that is replaced with garbage,
+    when the files are checked,
+    then the previous certificate should stay in use. `,
+		func(t *testing.T) {
+			serial := ca.serial
+			writeFileAtomically(t, cfg.TLS.CertFile, []byte("not a certificate"))
+			time.Sleep(100 * time.Millisecond)
+			if served := servedSerial(t); served != serial {
+				t.Fatalf("expected certificate %d to still be served, got %d", serial, served)
+			}
+		},
+	)
+}
+
+// mutualTLSConfig returns cfg serving HTTPS with a certificate from ca and
+// requiring client certificates from ca, mapped to identities.
+func mutualTLSConfig(t *testing.T, cfg config.Config, ca *testCA, identities []middleware.ClientIdentity) config.Config {
+	t.Helper()
+	dir := t.TempDir()
+	cfg.TLS.CertFile = filepath.Join(dir, "server.pem")
+	cfg.TLS.KeyFile = filepath.Join(dir, "server-key.pem")
+	cfg.TLS.ClientCAFile = filepath.Join(dir, "clients-ca.pem")
+	cfg.TLS.ClientIdentitiesFile = filepath.Join(dir, "identities.json")
+	cfg.TLS.ClientAuth = config.TLSClientAuthRequire
+	certPEM, keyPEM := ca.issue(t, pkix.Name{CommonName: "sal"}, true)
+	writeFileAtomically(t, cfg.TLS.CertFile, certPEM)
+	writeFileAtomically(t, cfg.TLS.KeyFile, keyPEM)
+	writeFileAtomically(t, cfg.TLS.ClientCAFile, ca.pem())
+	writeClientIdentities(t, cfg.TLS.ClientIdentitiesFile, identities)
+	if err := cfg.Validate(); err != nil {
+		t.Fatal(err)
+	}
+	return cfg
+}
+
+func TestMutualTLS(t *testing.T) {
+	ca := newTestCA(t)
+	merchantId := uuid.New()
+	identities := []middleware.ClientIdentity{
+		{Subject: "CN=acme-sync,O=Acme", MerchantId: merchantId},
+		{Subject: "CN=marketplace-bridge", Partner: "marketplace"},
+	}
+	cfg := mutualTLSConfig(t, config.Default(), ca, identities)
+	serverURL, manager := startTLSServer(t, cfg)
+
+	merchantClient := newTLSClient(ca, ca.clientCertificate(t, pkix.Name{CommonName: "acme-sync", Organization: []string{"Acme"}}))
+	partnerClient := newTLSClient(ca, ca.clientCertificate(t, pkix.Name{CommonName: "marketplace-bridge"}))
+	unknownClient := newTLSClient(ca, ca.clientCertificate(t, pkix.Name{CommonName: "stranger"}))
+
+	createAs := func(t *testing.T, client *http.Client, productMerchantId uuid.UUID, headerMerchantId string) int {
+		t.Helper()
+		requestBody, _ := json.Marshal(buildProduct(productMerchantId, uuid.New()))
+		req, _ := http.NewRequest(http.MethodPost, serverURL+"/api/products", bytes.NewBuffer(requestBody))
+		req.Header.Set("Content-Type", "application/json")
+		if headerMerchantId != "" {
+			req.Header.Set(middleware.MerchantHeader, headerMerchantId)
+		}
+		response, err := client.Do(req)
+		if err != nil {
+			t.Fatal(err)
+		}
+		response.Body.Close()
+		return response.StatusCode
+	}
+
+	t.Run(`Given client certificates are required,
+    when a client connects without one,
+    then the handshake should fail. `,
+		func(t *testing.T) {
+			if _, err := newTLSClient(ca).Get(serverURL + "/"); err == nil {
+				t.Fatalf("expected a client without a certificate to be refused")
+			}
+		},
+	)
+
+	t.Run(`Given a certificate mapped to a merchant,
+    when it creates a product,
+    then it should act as that merchant and not be able to claim another. `,
+		func(t *testing.T) {
+			tests.AssertStatusCode(t, http.StatusOK, createAs(t, merchantClient, merchantId, ""))
+			tests.AssertStatusCode(t, http.StatusOK, createAs(t, merchantClient, merchantId, merchantId.String()))
+			tests.AssertStatusCode(t, http.StatusForbidden, createAs(t, merchantClient, merchantId, uuid.New().String()))
+		},
+	)
+
+	t.Run(`Given a certificate mapped to a merchant,
+    when it names another merchant in a request body, path or GraphQL argument without setting X-Merchant-ID,
+    then the request should be refused. `,
+		func(t *testing.T) {
+			otherMerchantId := uuid.New()
+			tests.AssertStatusCode(t, http.StatusForbidden, createAs(t, merchantClient, otherMerchantId, ""))
+
+			response, err := merchantClient.Get(serverURL + "/api/merchants/" + otherMerchantId.String() + "/products")
+			if err != nil {
+				t.Fatal(err)
+			}
+			response.Body.Close()
+			tests.AssertStatusCode(t, http.StatusForbidden, response.StatusCode)
+
+			requestBody, _ := json.Marshal(map[string]interface{}{
+				"query":     `query($merchantId: ID!) { merchantProducts(merchantId: $merchantId) { totalCount } }`,
+				"variables": map[string]interface{}{"merchantId": otherMerchantId.String()},
+			})
+			response, err = merchantClient.Post(serverURL+"/graphql", "application/json", bytes.NewBuffer(requestBody))
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer response.Body.Close()
+			var result graphqlResult
+			if err := json.NewDecoder(response.Body).Decode(&result); err != nil {
+				t.Fatal(err)
+			}
+			if result.errorCode() != "FORBIDDEN" {
+				t.Fatalf("expected FORBIDDEN, got %+v", result.Errors)
+			}
+		},
+	)
+
+	t.Run(`Given a certificate mapped to a partner,
+    when it acts for any merchant,
+    then the request should be allowed. `,
+		func(t *testing.T) {
+			otherMerchantId := uuid.New()
+			tests.AssertStatusCode(t, http.StatusOK, createAs(t, partnerClient, otherMerchantId, otherMerchantId.String()))
+		},
+	)
+
+	t.Run(`Given a certificate from the trusted CA that is not mapped,
+    when it calls the API, and again after the identities file is reloaded to include it,
+    then it should be refused and then allowed. `,
+		func(t *testing.T) {
+			tests.AssertStatusCode(t, http.StatusForbidden, createAs(t, unknownClient, merchantId, ""))
+
+			otherMerchantId := uuid.New()
+			identities := append(identities, middleware.ClientIdentity{Subject: "CN=stranger", MerchantId: otherMerchantId})
+			writeClientIdentities(t, cfg.TLS.ClientIdentitiesFile, identities)
+			if err := manager.Reload(context.Background(), cfg); err != nil {
+				t.Fatal(err)
+			}
+			tests.AssertStatusCode(t, http.StatusOK, createAs(t, unknownClient, otherMerchantId, ""))
+		},
+	)
+
+	t.Run(`Given an identities file listing a subject as both a merchant and a partner,
+    when it is loaded,
+    then it should be rejected. `,
+		func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "invalid.json")
+			writeClientIdentities(t, path, []middleware.ClientIdentity{{Subject: "CN=both", MerchantId: merchantId, Partner: "marketplace"}})
+			if _, err := middleware.LoadClientIdentitiesFile(path); err == nil {
+				t.Fatalf("expected the identities file to be rejected")
+			}
+		},
+	)
+}
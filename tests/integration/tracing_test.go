@@ -0,0 +1,333 @@
+//go:build integration
+// +build integration
+
+package integration
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/olad5/sal-backend-service/internal/config"
+	"github.com/olad5/sal-backend-service/internal/logging"
+	"github.com/olad5/sal-backend-service/pkg/client"
+	"github.com/olad5/sal-backend-service/pkg/clock"
+	"github.com/olad5/sal-backend-service/pkg/tracing"
+	"github.com/olad5/sal-backend-service/tests"
+)
+
+func TestTracing(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	newTracedRouter := func(t *testing.T, sampleRatio float64, logs io.Writer) (http.Handler, string) {
+		t.Helper()
+		cfg := config.Default()
+		cfg.RateLimit.ReadsPerMinute = 0
+		cfg.RateLimit.WritesPerMinute = 0
+		cfg.Tracing.Exporter = config.TracingExporterFile
+		cfg.Tracing.File = filepath.Join(t.TempDir(), "spans.jsonl")
+		cfg.Tracing.SampleRatio = sampleRatio
+		return newRouter(ctx, cfg, logging.New(logs, slog.LevelInfo), newHealthChecker()), cfg.Tracing.File
+	}
+	createProductRequest := func(t *testing.T, traceparent string) *http.Request {
+		t.Helper()
+		requestBody, err := json.Marshal(buildProduct(uuid.New(), uuid.New()))
+		if err != nil {
+			t.Fatal(err)
+		}
+		req, _ := http.NewRequest(http.MethodPost, "/api/products", bytes.NewBuffer(requestBody))
+		if traceparent != "" {
+			req.Header.Set(tracing.TraceparentHeader, traceparent)
+		}
+		return req
+	}
+
+	t.Run(`Given a request with a sampled traceparent,
+    when it is served,
+    then its spans should continue the trace from the handler through the service to the repository. `,
+		func(t *testing.T) {
+			logs := &logBuffer{}
+			traced, spansFile := newTracedRouter(t, 1, logs)
+			traceId, parentId := "4bf92f3577b34da6a3ce929d0e0e4736", "00f067aa0ba902b7"
+
+			response := tests.ExecuteRequest(createProductRequest(t, "00-"+traceId+"-"+parentId+"-01"), traced)
+			tests.AssertStatusCode(t, http.StatusOK, response.Code)
+
+			spans := readSpans(t, spansFile)
+			server := findSpan(t, spans, "POST /api/products")
+			if server.TraceID != traceId || server.ParentSpanID != parentId || server.Kind != tracing.SpanKindServer {
+				t.Fatalf("expected a server span continuing %s from %s, got %+v", traceId, parentId, server)
+			}
+			if server.Service != "sal-backend-service" || server.Attributes["http.route"] != "/api/products" || server.Attributes["http.response.status_code"] != float64(http.StatusOK) {
+				t.Fatalf("expected the server span to describe the request, got %+v", server)
+			}
+			decode := findSpan(t, spans, "decode request")
+			service := findSpan(t, spans, "ProductService.CreateProduct")
+			repository := findSpan(t, spans, "product.CreateProduct")
+			for child, parent := range map[*tracing.SpanData]tracing.SpanData{&decode: server, &service: server, &repository: service} {
+				if child.TraceID != traceId || child.ParentSpanID != parent.SpanID {
+					t.Fatalf("expected %s to be a child of %s, got %+v", child.Name, parent.Name, child)
+				}
+				if child.EndTime.Before(child.StartTime) || child.StartTime.Before(parent.StartTime) {
+					t.Fatalf("expected %s to be timed within %s, got %+v", child.Name, parent.Name, child)
+				}
+			}
+
+			accessLogs := logs.entries(t, "request")
+			if len(accessLogs) != 1 || accessLogs[0]["trace_id"] != traceId || accessLogs[0]["span_id"] != server.SpanID {
+				t.Fatalf("expected the access log to carry the trace, got %v", accessLogs)
+			}
+		},
+	)
+
+	t.Run(`Given a request with a traceparent that is not sampled,
+    when it is served,
+    then no spans should be exported but its logs should still carry the trace. `,
+		func(t *testing.T) {
+			logs := &logBuffer{}
+			traced, spansFile := newTracedRouter(t, 1, logs)
+			traceId := "0af7651916cd43dd8448eb211c80319c"
+
+			response := tests.ExecuteRequest(createProductRequest(t, "00-"+traceId+"-b7ad6b7169203331-00"), traced)
+			tests.AssertStatusCode(t, http.StatusOK, response.Code)
+
+			if spans := readSpans(t, spansFile); len(spans) != 0 {
+				t.Fatalf("expected no spans, got %+v", spans)
+			}
+			if accessLogs := logs.entries(t, "request"); len(accessLogs) != 1 || accessLogs[0]["trace_id"] != traceId {
+				t.Fatalf("expected the access log to carry the trace, got %v", accessLogs)
+			}
+		},
+	)
+
+	t.Run(`Given a request with a malformed traceparent,
+    when it is served,
+    then it should start a trace of its own. `,
+		func(t *testing.T) {
+			traced, spansFile := newTracedRouter(t, 1, io.Discard)
+
+			response := tests.ExecuteRequest(createProductRequest(t, "00-4BF92F3577B34DA6A3CE929D0E0E4736-00f067aa0ba902b7-01"), traced)
+			tests.AssertStatusCode(t, http.StatusOK, response.Code)
+
+			server := findSpan(t, readSpans(t, spansFile), "POST /api/products")
+			if server.ParentSpanID != "" || server.TraceID == "4bf92f3577b34da6a3ce929d0e0e4736" {
+				t.Fatalf("expected a new trace, got %+v", server)
+			}
+		},
+	)
+
+	t.Run(`Given a sample ratio of 0,
+    when requests are served with and without a sampled traceparent,
+    then only the trace started by the caller should be exported. `,
+		func(t *testing.T) {
+			traced, spansFile := newTracedRouter(t, 0, io.Discard)
+			traceId := "5bf92f3577b34da6a3ce929d0e0e4736"
+
+			tests.AssertStatusCode(t, http.StatusOK, tests.ExecuteRequest(createProductRequest(t, ""), traced).Code)
+			tests.AssertStatusCode(t, http.StatusOK, tests.ExecuteRequest(createProductRequest(t, "00-"+traceId+"-00f067aa0ba902b7-01"), traced).Code)
+
+			spans := readSpans(t, spansFile)
+			if len(spans) == 0 {
+				t.Fatal("expected the caller's trace to be exported")
+			}
+			for _, span := range spans {
+				if span.TraceID != traceId {
+					t.Fatalf("expected only spans of trace %s, got %+v", traceId, span)
+				}
+			}
+		},
+	)
+
+	t.Run(`Given a client call made inside a span,
+    when the server handles it,
+    then the client should propagate the span in a traceparent. `,
+		func(t *testing.T) {
+			traced, spansFile := newTracedRouter(t, 1, io.Discard)
+			server := httptest.NewServer(traced)
+			defer server.Close()
+			tracedClient, err := client.New(server.URL)
+			if err != nil {
+				t.Fatal(err)
+			}
+			callerSpans := &spanRecorder{}
+			caller, err := tracing.NewTracer("caller", callerSpans, tracing.TraceIDRatio(1), clock.New(), nil)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			callCtx, span := caller.Start(context.Background(), "create product", tracing.SpanKindInternal)
+			_, err = tracedClient.CreateProduct(callCtx, buildProduct(uuid.New(), uuid.New()))
+			span.End()
+			if err != nil {
+				t.Fatalf("unable to create product: %v", err)
+			}
+
+			serverSpan := findSpan(t, readSpans(t, spansFile), "POST /api/products")
+			sc := span.SpanContext()
+			if serverSpan.TraceID != sc.TraceID.String() || serverSpan.ParentSpanID != sc.SpanID.String() {
+				t.Fatalf("expected the server span to continue the caller's span %s, got %+v", sc.Traceparent(), serverSpan)
+			}
+			if len(callerSpans.spans) != 1 {
+				t.Fatalf("expected the caller's span to be exported, got %+v", callerSpans.spans)
+			}
+		},
+	)
+
+	t.Run(`Given an OTLP collector,
+    when spans are flushed,
+    then they should be posted as OTLP JSON grouped by service. `,
+		func(t *testing.T) {
+			var received []tracing.OTLPTraceRequest
+			var lock sync.Mutex
+			collector := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				if r.URL.Path != "/v1/traces" || r.Header.Get("Content-Type") != "application/json" {
+					w.WriteHeader(http.StatusBadRequest)
+					return
+				}
+				var request tracing.OTLPTraceRequest
+				if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+					w.WriteHeader(http.StatusBadRequest)
+					return
+				}
+				lock.Lock()
+				received = append(received, request)
+				lock.Unlock()
+			}))
+			defer collector.Close()
+
+			otlpClient, err := tracing.NewOTLPHTTPClient(collector.URL+"/v1/traces", collector.Client())
+			if err != nil {
+				t.Fatal(err)
+			}
+			otlpExporter, err := tracing.NewOTLPExporter(otlpClient, "tests")
+			if err != nil {
+				t.Fatal(err)
+			}
+			batches, err := tracing.NewBatchExporter(otlpExporter, 10, 100, time.Hour, nil)
+			if err != nil {
+				t.Fatal(err)
+			}
+			tracer, err := tracing.NewTracer("sal-backend-service", batches, tracing.TraceIDRatio(1), clock.New(), nil)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			spanCtx, root := tracer.Start(context.Background(), "GET /api/plans", tracing.SpanKindServer)
+			root.SetAttribute("http.response.status_code", http.StatusOK)
+			_, child := tracing.Start(spanCtx, "merchant_plan.GetMerchantPlanByMerchantId")
+			child.RecordError(io.ErrUnexpectedEOF)
+			child.End()
+			root.End()
+			if err := batches.Flush(context.Background()); err != nil {
+				t.Fatalf("expected the spans to be flushed, got %v", err)
+			}
+
+			lock.Lock()
+			defer lock.Unlock()
+			if len(received) != 1 || len(received[0].ResourceSpans) != 1 {
+				t.Fatalf("expected one request for one service, got %+v", received)
+			}
+			resourceSpans := received[0].ResourceSpans[0]
+			if attr := resourceSpans.Resource.Attributes; len(attr) != 1 || attr[0].Key != "service.name" || *attr[0].Value.StringValue != "sal-backend-service" {
+				t.Fatalf("expected the service name resource attribute, got %+v", attr)
+			}
+			spans := resourceSpans.ScopeSpans[0].Spans
+			if len(spans) != 2 {
+				t.Fatalf("expected 2 spans, got %+v", spans)
+			}
+			otlpChild, otlpRoot := spans[0], spans[1]
+			if otlpRoot.TraceID != root.SpanContext().TraceID.String() || otlpRoot.Kind != 2 || otlpRoot.ParentSpanID != "" {
+				t.Fatalf("expected the root as a server span, got %+v", otlpRoot)
+			}
+			if attr := otlpRoot.Attributes; len(attr) != 1 || attr[0].Value.IntValue == nil || *attr[0].Value.IntValue != "200" {
+				t.Fatalf("expected integer attributes as strings, got %+v", attr)
+			}
+			if otlpChild.ParentSpanID != otlpRoot.SpanID || otlpChild.Kind != 1 || otlpChild.Status.Code != 2 || otlpChild.Status.Message != io.ErrUnexpectedEOF.Error() {
+				t.Fatalf("expected the failed child as an internal span, got %+v", otlpChild)
+			}
+		},
+	)
+}
+
+func TestParseTraceparent(t *testing.T) {
+	t.Run(`Given traceparent headers,
+    when they are parsed,
+    then only valid ones should be accepted and they should format back unchanged. `,
+		func(t *testing.T) {
+			for value, valid := range map[string]bool{
+				"00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01":        true,
+				"00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-00":        true,
+				"01-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01-future": true,
+				"00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01-extra":  false,
+				"ff-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01":        false,
+				"00-00000000000000000000000000000000-00f067aa0ba902b7-01":        false,
+				"00-4bf92f3577b34da6a3ce929d0e0e4736-0000000000000000-01":        false,
+				"00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b-01":         false,
+				"00-4bf92f3577b34da6a3ce929d0e0e473g-00f067aa0ba902b7-01":        false,
+				"": false,
+			} {
+				sc, err := tracing.ParseTraceparent(value)
+				if valid != (err == nil) {
+					t.Fatalf("expected %q to be valid: %v, got %v", value, valid, err)
+				}
+				if valid && value[:2] == "00" && sc.Traceparent() != value {
+					t.Fatalf("expected %q to format back unchanged, got %q", value, sc.Traceparent())
+				}
+			}
+		},
+	)
+}
+
+// spanRecorder keeps exported spans in memory.
+type spanRecorder struct {
+	lock  sync.Mutex
+	spans []tracing.SpanData
+}
+
+func (r *spanRecorder) ExportSpans(ctx context.Context, spans []tracing.SpanData) error {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	r.spans = append(r.spans, spans...)
+	return nil
+}
+
+func readSpans(t *testing.T, path string) []tracing.SpanData {
+	t.Helper()
+	file, err := os.Open(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer file.Close()
+	var spans []tracing.SpanData
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		var span tracing.SpanData
+		if err := json.Unmarshal(scanner.Bytes(), &span); err != nil {
+			t.Fatalf("expected JSON span lines, got %q", scanner.Text())
+		}
+		spans = append(spans, span)
+	}
+	return spans
+}
+
+func findSpan(t *testing.T, spans []tracing.SpanData, name string) tracing.SpanData {
+	t.Helper()
+	for _, span := range spans {
+		if span.Name == name {
+			return span
+		}
+	}
+	t.Fatalf("expected a span named %q in %+v", name, spans)
+	return tracing.SpanData{}
+}